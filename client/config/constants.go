@@ -32,6 +32,28 @@ const (
 	DefaultMinRate              = 20 * unit.MB
 )
 
+// Traffic shaper type.
+const (
+	// TrafficShaperTypePlain is the traffic shaper type which limits the bandwidth of each task only.
+	TrafficShaperTypePlain = "plain"
+
+	// TrafficShaperTypeSampling is the traffic shaper type which allocates bandwidth for tasks by sampling.
+	TrafficShaperTypeSampling = "sampling"
+)
+
+// Adaptive piece size.
+const (
+	DefaultPieceSizeTargetDuration = 1 * time.Second
+	DefaultPieceSizeMin            = 4 * unit.MB
+	DefaultPieceSizeMax            = 64 * unit.MB
+)
+
+// Upload compression.
+const (
+	DefaultUploadCompressionMinRatio      = 1.2
+	DefaultUploadCompressionMaxCPUPercent = 80
+)
+
 // Others.
 const (
 	DefaultTaskExpireTime  = 6 * time.Hour
@@ -39,6 +61,8 @@ const (
 	DefaultDaemonAliveTime = 5 * time.Minute
 	DefaultScheduleTimeout = 5 * time.Minute
 
+	DefaultDecentralizedInterval = 10 * time.Second
+
 	DefaultSchedulerIP   = "127.0.0.1"
 	DefaultSchedulerPort = 8002
 
@@ -46,6 +70,14 @@ const (
 	DefaultObjectMaxReplicas          = 3
 )
 
+// Peer exchange discovery types.
+const (
+	PeerExchangeDiscoverySeedPeer   = "seedPeer"
+	PeerExchangeDiscoveryStatic     = "static"
+	PeerExchangeDiscoveryDNS        = "dns"
+	PeerExchangeDiscoveryKubernetes = "kubernetes"
+)
+
 // Store strategy.
 const (
 	SimpleLocalTaskStoreStrategy  = StoreStrategy("io.d7y.storage.v2.simple")
@@ -60,6 +92,9 @@ const (
 	CmdDelete = "delete"
 )
 
+// DfcacheStdioPath is the path of dfcache that imports from stdin or exports to stdout.
+const DfcacheStdioPath = "-"
+
 // Service default port of listening.
 const (
 	DefaultEndPort                = 65535
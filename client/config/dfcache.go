@@ -70,7 +70,8 @@ type CacheOption struct {
 	// Output full output path for export task
 	Output string `yaml:"output,omitempty" mapstructure:"output,omitempty"`
 
-	// Path full input path for import task
+	// Path full input path for import task, it is imported recursively if it is a directory,
+	// and "-" imports from stdin.
 	// TODO: change to Input
 	Path string `yaml:"path,omitempty" mapstructure:"path,omitempty"`
 
@@ -79,6 +80,9 @@ type CacheOption struct {
 
 	// LocalOnly indicates check local cache only
 	LocalOnly bool `yaml:"localOnly,omitempty" mapstructure:"localOnly,omitempty"`
+
+	// Recursive exports the directory imported recursively to output.
+	Recursive bool `yaml:"recursive,omitempty" mapstructure:"recursive,omitempty"`
 }
 
 func NewDfcacheConfig() *CacheOption {
@@ -147,6 +151,10 @@ func convertCacheImport(cfg *CacheOption, args []string) error {
 		return fmt.Errorf("missing input file: %w", dferrors.ErrInvalidArgument)
 	}
 
+	if cfg.Path == DfcacheStdioPath {
+		return nil
+	}
+
 	if cfg.Path, err = filepath.Abs(cfg.Path); err != nil {
 		return fmt.Errorf("get absulate path for %s: %w", cfg.Path, err)
 	}
@@ -162,6 +170,10 @@ func ConvertCacheExport(cfg *CacheOption, args []string) error {
 		return fmt.Errorf("missing output file: %w", dferrors.ErrInvalidArgument)
 	}
 
+	if cfg.Output == DfcacheStdioPath {
+		return nil
+	}
+
 	if cfg.Output, err = filepath.Abs(cfg.Output); err != nil {
 		return fmt.Errorf("get absulate path for %s: %w", cfg.Output, err)
 	}
@@ -197,12 +209,12 @@ func (cfg *CacheOption) String() string {
 }
 
 func (cfg *CacheOption) checkInput() error {
-	stat, err := os.Stat(cfg.Path)
-	if err != nil {
-		return fmt.Errorf("stat input path %q: %w", cfg.Path, err)
+	if cfg.Path == DfcacheStdioPath {
+		return nil
 	}
-	if stat.IsDir() {
-		return fmt.Errorf("path[%q] is directory but requires file path", cfg.Path)
+
+	if _, err := os.Stat(cfg.Path); err != nil {
+		return fmt.Errorf("stat input path %q: %w", cfg.Path, err)
 	}
 	if err := syscall.Access(cfg.Path, syscall.O_RDONLY); err != nil {
 		return fmt.Errorf("access %q: %w", cfg.Path, err)
@@ -215,6 +227,14 @@ func (cfg *CacheOption) checkOutput() error {
 		return errors.New("no output file path specified")
 	}
 
+	if cfg.Output == DfcacheStdioPath {
+		if cfg.Recursive {
+			return errors.New("directory can not be exported to stdout")
+		}
+
+		return nil
+	}
+
 	if !filepath.IsAbs(cfg.Output) {
 		absPath, err := filepath.Abs(cfg.Output)
 		if err != nil {
@@ -229,7 +249,7 @@ func (cfg *CacheOption) checkOutput() error {
 	}
 
 	f, err := os.Stat(cfg.Output)
-	if err == nil && f.IsDir() {
+	if err == nil && f.IsDir() && !cfg.Recursive {
 		return fmt.Errorf("path[%s] is directory but requires file path", cfg.Output)
 	}
 
@@ -23,11 +23,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -82,6 +84,7 @@ type DaemonOption struct {
 	Network       *NetworkOption      `mapstructure:"network" yaml:"network"`
 	Announcer     AnnouncerOption     `mapstructure:"announcer" yaml:"announcer"`
 	PeerExchange  PeerExchangeOption  `mapstructure:"peerExchange" yaml:"peerExchange"`
+	Bandwidth     BandwidthOption     `mapstructure:"bandwidth" yaml:"bandwidth"`
 }
 
 func NewDaemonConfig() *DaemonOption {
@@ -198,6 +201,129 @@ func (p *DaemonOption) Validate() error {
 		}
 	}
 
+	if p.Upload.Authorization.Enable {
+		if p.Upload.Security.Insecure || !p.Upload.Security.TLSVerify {
+			return errors.New("upload authorization requires tls and tlsVerify")
+		}
+
+		if p.Upload.Security.CACert == "" || p.Upload.Security.Cert == "" || p.Upload.Security.Key == "" {
+			return errors.New("upload authorization requires caCert, cert and key")
+		}
+	}
+
+	if p.Scheduler.Decentralized.Enable {
+		if !p.PeerExchange.Enable {
+			return errors.New("decentralized mode requires peer exchange")
+		}
+
+		if p.Scheduler.Decentralized.Interval.Duration <= 0 {
+			return errors.New("decentralized interval must be greater than 0")
+		}
+	}
+
+	if p.PeerExchange.Enable {
+		switch p.PeerExchange.Discovery.Type {
+		case "", PeerExchangeDiscoverySeedPeer:
+		case PeerExchangeDiscoveryStatic:
+			if len(p.PeerExchange.Discovery.Static) == 0 {
+				return errors.New("peer exchange static discovery requires static addresses")
+			}
+		case PeerExchangeDiscoveryDNS:
+			if p.PeerExchange.Discovery.DNS.Name == "" {
+				return errors.New("peer exchange dns discovery requires name")
+			}
+		case PeerExchangeDiscoveryKubernetes:
+			if p.PeerExchange.Discovery.Kubernetes.Service == "" {
+				return errors.New("peer exchange kubernetes discovery requires service")
+			}
+		default:
+			return fmt.Errorf("peer exchange discovery type %s is not supported", p.PeerExchange.Discovery.Type)
+		}
+	}
+
+	for _, window := range p.Bandwidth.Windows {
+		start, err := parseTimeOfDay(window.Start)
+		if err != nil {
+			return err
+		}
+
+		end, err := parseTimeOfDay(window.End)
+		if err != nil {
+			return err
+		}
+
+		if start == end {
+			return errors.New("bandwidth window start must be different from end")
+		}
+	}
+
+	for _, app := range p.Bandwidth.Applications {
+		if app.Application == "" && app.Priority == "" {
+			return errors.New("application bandwidth requires application or priority")
+		}
+
+		if _, ok := commonv1.Priority_value[app.Priority]; app.Priority != "" && !ok {
+			return fmt.Errorf("application bandwidth priority %s is invalid", app.Priority)
+		}
+
+		if app.Download.Limit > 0 && p.Download.TrafficShaperType != TrafficShaperTypeSampling {
+			return errors.New("application download bandwidth requires sampling traffic shaper")
+		}
+	}
+
+	for _, rule := range p.Download.PieceSize.Rules {
+		if rule.PieceSize <= 0 || rule.PieceSize > math.MaxUint32 {
+			return errors.New("piece size of rule must be in (0, 4GiB)")
+		}
+	}
+
+	if p.Download.PieceSize.Adaptive {
+		if p.Download.PieceSize.TargetDuration <= 0 {
+			return errors.New("adaptive piece size target duration must be greater than 0")
+		}
+
+		if p.Download.PieceSize.Min <= 0 || p.Download.PieceSize.Min > p.Download.PieceSize.Max ||
+			p.Download.PieceSize.Max > math.MaxUint32 {
+			return errors.New("adaptive piece size requires 0 < min <= max < 4GiB")
+		}
+	}
+
+	if p.Download.Integrity.RequireSignature {
+		if !p.Download.Integrity.Enable {
+			return errors.New("integrity signature requires integrity enabled")
+		}
+
+		if p.Upload.Security.CACert == "" {
+			return errors.New("integrity signature requires upload caCert")
+		}
+	}
+
+	if p.Upload.Compression.Enable {
+		if p.Upload.Compression.MinRatio < 1 {
+			return errors.New("compression min ratio must be greater than or equal to 1")
+		}
+
+		if p.Upload.Compression.MaxCPUPercent <= 0 || p.Upload.Compression.MaxCPUPercent > 100 {
+			return errors.New("compression max cpu percent must be in (0, 100]")
+		}
+	}
+
+	if p.Storage.Encryption.Enable {
+		if p.Storage.Encryption.KeyFile == "" {
+			return errors.New("encryption key file is not specified")
+		}
+
+		if p.Download.SplitRunningTasks {
+			return errors.New("split running tasks is not supported when encryption is enabled")
+		}
+	}
+
+	for key := range p.Host.Labels {
+		if key == "" || strings.Contains(key, types.HostLabelSeparator) {
+			return fmt.Errorf("host label key %q is invalid", key)
+		}
+	}
+
 	if p.Reload.Interval.Duration > 0 && p.Reload.Interval.Duration < time.Second {
 		return errors.New("reload interval too short, must great than 1 second")
 	}
@@ -210,7 +336,16 @@ func (p *DaemonOption) Validate() error {
 }
 
 func (p *DaemonOption) IsSupportPeerExchange() bool {
-	return p.PeerExchange.Enable && p.Scheduler.Manager.Enable && p.Scheduler.Manager.SeedPeer.Enable
+	if !p.PeerExchange.Enable {
+		return false
+	}
+
+	// Initial members from seed peers depend on manager, other discovery types do not.
+	if p.PeerExchange.Discovery.Type == "" || p.PeerExchange.Discovery.Type == PeerExchangeDiscoverySeedPeer {
+		return p.Scheduler.Manager.Enable && p.Scheduler.Manager.SeedPeer.Enable
+	}
+
+	return true
 }
 
 type SchedulerOption struct {
@@ -222,6 +357,17 @@ type SchedulerOption struct {
 	ScheduleTimeout util.Duration `mapstructure:"scheduleTimeout" yaml:"scheduleTimeout"`
 	// DisableAutoBackSource indicates not back source normally, only scheduler says back source.
 	DisableAutoBackSource bool `mapstructure:"disableAutoBackSource" yaml:"disableAutoBackSource"`
+	// Decentralized is the option to download from peers found by peer exchange when schedulers are unavailable.
+	Decentralized DecentralizedOption `mapstructure:"decentralized" yaml:"decentralized"`
+}
+
+type DecentralizedOption struct {
+	// Enable downloads pieces from the peers found by peer exchange instead of back-to-source
+	// when schedulers are unavailable, it requires peer exchange.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Interval is the interval to refresh parents from peer exchange and probe schedulers,
+	// peer task switches back to scheduler-driven mode once a scheduler is available.
+	Interval util.Duration `mapstructure:"interval" yaml:"interval"`
 }
 
 type ManagerOption struct {
@@ -260,6 +406,9 @@ type HostOption struct {
 	Hostname string `mapstructure:"hostname" yaml:"hostname"`
 	// The ip report to scheduler, normal same with listen ip
 	AdvertiseIP net.IP `mapstructure:"advertiseIP" yaml:"advertiseIP"`
+	// Labels are custom labels of host announced to scheduler,
+	// the filter and score plugins of scheduler match the rules on them
+	Labels map[string]string `mapstructure:"labels" yaml:"labels"`
 }
 
 type DownloadOption struct {
@@ -279,6 +428,16 @@ type DownloadOption struct {
 	SyncPieceViaHTTPS    bool              `mapstructure:"syncPieceViaHTTPS" yaml:"syncPieceViaHTTPS"`
 	SplitRunningTasks    bool              `mapstructure:"splitRunningTasks" yaml:"splitRunningTasks"`
 	CancelIdlePeerTask   bool              `mapstructure:"cancelIdlePeerTask" yaml:"cancelIdlePeerTask"`
+	// QUIC indicates to download pieces via QUIC from parents which announce the QUIC port,
+	// falls back to HTTP when the QUIC connection fails
+	QUIC bool `mapstructure:"quic" yaml:"quic"`
+	// Compression indicates to accept zstd compressed pieces from parents,
+	// pieces are decompressed before verification and storing
+	Compression bool `mapstructure:"compression" yaml:"compression"`
+	// PieceSize is the piece size selection option for tasks downloaded from source
+	PieceSize PieceSizeOption `mapstructure:"pieceSize" yaml:"pieceSize"`
+	// Integrity is the option to verify pieces from parents with merkle tree of piece digests
+	Integrity IntegrityOption `mapstructure:"integrity" yaml:"integrity"`
 	// resource clients option
 	ResourceClients ResourceClientsOption `mapstructure:"resourceClients" yaml:"resourceClients"`
 
@@ -289,6 +448,76 @@ type DownloadOption struct {
 
 type ResourceClientsOption map[string]any
 
+type BandwidthOption struct {
+	// Windows override the total download and upload rate limits during periods of the day,
+	// the first matched window wins, the rate limits of download and upload options apply out of windows
+	Windows []*BandwidthWindow `mapstructure:"windows" yaml:"windows"`
+	// Applications set the bandwidth budgets shared by tasks of the matched application or priority,
+	// the first matched rule wins, download budgets are enforced by the sampling traffic shaper
+	Applications []*ApplicationBandwidth `mapstructure:"applications" yaml:"applications"`
+}
+
+type BandwidthWindow struct {
+	// Start is the local time of day the window starts at, in the format of 15:04
+	Start string `mapstructure:"start" yaml:"start"`
+	// End is the local time of day the window ends at, a window ends before start crosses midnight
+	End string `mapstructure:"end" yaml:"end"`
+	// Download is the total download rate limit in the window, zero keeps the total download rate limit
+	Download util.RateLimit `mapstructure:"download" yaml:"download"`
+	// Upload is the upload rate limit in the window, zero keeps the upload rate limit
+	Upload util.RateLimit `mapstructure:"upload" yaml:"upload"`
+}
+
+// Contains checks if the time of day of t is in the window.
+func (w *BandwidthWindow) Contains(t time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if start < end {
+		return now >= start && now < end
+	}
+
+	return now >= start || now < end
+}
+
+// parseTimeOfDay parses the time of day in the format of 15:04 to the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("bandwidth window time %q is invalid, expect 15:04 format", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+type ApplicationBandwidth struct {
+	// Application matches the application of the task, empty matches all
+	Application string `mapstructure:"application" yaml:"application"`
+	// Priority matches the priority of the task like LEVEL3, empty matches all
+	Priority string `mapstructure:"priority" yaml:"priority"`
+	// Download is the download budget of matched tasks, zero is unlimited
+	Download util.RateLimit `mapstructure:"download" yaml:"download"`
+	// Upload is the upload budget of matched tasks, zero is unlimited
+	Upload util.RateLimit `mapstructure:"upload" yaml:"upload"`
+}
+
+// Match checks if the given application and priority match the rule.
+func (a *ApplicationBandwidth) Match(application string, priority commonv1.Priority) bool {
+	if a.Application != "" && a.Application != application {
+		return false
+	}
+
+	return a.Priority == "" || a.Priority == priority.String()
+}
+
 type TransportOption struct {
 	DialTimeout           time.Duration `mapstructure:"dialTimeout" yaml:"dialTimeout"`
 	KeepAlive             time.Duration `mapstructure:"keepAlive" yaml:"keepAlive"`
@@ -314,6 +543,49 @@ type ConcurrentOption struct {
 	MaxAttempts int `mapstructure:"maxAttempts" yaml:"maxAttempts"`
 }
 
+type PieceSizeOption struct {
+	// Rules set the piece size for tasks matching the application or url, the first matched rule wins
+	Rules []*PieceSizeRule `mapstructure:"rules" yaml:"rules"`
+	// Adaptive indicates to choose piece size from the observed back source bandwidth
+	// when no rule matches, a piece is sized to be downloaded in about TargetDuration
+	Adaptive bool `mapstructure:"adaptive" yaml:"adaptive"`
+	// TargetDuration is the desired download duration of one piece in adaptive mode
+	TargetDuration time.Duration `mapstructure:"targetDuration" yaml:"targetDuration"`
+	// Min is the minimum piece size in adaptive mode
+	Min unit.Bytes `mapstructure:"min" yaml:"min"`
+	// Max is the maximum piece size in adaptive mode
+	Max unit.Bytes `mapstructure:"max" yaml:"max"`
+}
+
+type IntegrityOption struct {
+	// Enable indicates to calculate sha256 of pieces and verify every piece from parents
+	// with the merkle tree of task, the parent is blocklisted when a piece does not match.
+	// Seed peers sign the merkle tree with the upload certificate carrying seed peer uri
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// RequireSignature indicates to trust only the merkle trees signed by seed peers,
+	// the signer certificate is verified with the upload caCert. Without it, pieces are verified
+	// with the unsigned tree of every parent, which gives no protection against a malicious parent
+	RequireSignature bool `mapstructure:"requireSignature" yaml:"requireSignature"`
+}
+
+type PieceSizeRule struct {
+	// Application matches the application of the task, empty matches all
+	Application string `mapstructure:"application" yaml:"application"`
+	// URL matches the url of the task, empty matches all
+	URL *Regexp `mapstructure:"url" yaml:"url"`
+	// PieceSize is the piece size for matched tasks
+	PieceSize unit.Bytes `mapstructure:"pieceSize" yaml:"pieceSize"`
+}
+
+// Match checks if the given application and url match the rule.
+func (r *PieceSizeRule) Match(application, url string) bool {
+	if r.Application != "" && r.Application != application {
+		return false
+	}
+
+	return r.URL == nil || r.URL.Regexp == nil || r.URL.MatchString(url)
+}
+
 type RecursiveConcurrent struct {
 	// GoroutineCount indicates the concurrent goroutine count for every recursive task
 	GoroutineCount int `mapstructure:"goroutineCount" yaml:"goroutineCount"`
@@ -447,6 +719,38 @@ func (p *ProxyOption) unmarshal(unmarshal func(in []byte, out any) (err error),
 type UploadOption struct {
 	ListenOption `yaml:",inline" mapstructure:",squash"`
 	RateLimit    util.RateLimit `mapstructure:"rateLimit" yaml:"rateLimit"`
+	// QUIC is the HTTP/3 upload service option
+	QUIC QUICOption `mapstructure:"quic" yaml:"quic"`
+	// Authorization is the upload authorization option
+	Authorization UploadAuthorizationOption `mapstructure:"authorization" yaml:"authorization"`
+	// Compression is the piece compression option
+	Compression CompressionOption `mapstructure:"compression" yaml:"compression"`
+}
+
+type CompressionOption struct {
+	// Enable compresses pieces with zstd for peers which accept it
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// MinRatio is the minimal compression ratio (raw size / compressed size) of the sample piece,
+	// the pieces of task are sent raw for a while when the ratio is lower
+	MinRatio float64 `mapstructure:"minRatio" yaml:"minRatio"`
+	// MaxCPUPercent is the cpu usage above which pieces are sent raw
+	MaxCPUPercent float64 `mapstructure:"maxCPUPercent" yaml:"maxCPUPercent"`
+}
+
+type UploadAuthorizationOption struct {
+	// Enable authorizes every upload request with the verified client certificate of peer,
+	// the requester must be a member of the same scheduler cluster.
+	// The scheduler cluster of peer is in the URI SAN of certificate, like dragonfly://scheduler-cluster/1
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// CheckApplication indicates the requester must be allowed for the application of task,
+	// the allowed applications of peer are in the URI SANs of certificate, like dragonfly://application/foo
+	CheckApplication bool `mapstructure:"checkApplication" yaml:"checkApplication"`
+}
+
+type QUICOption struct {
+	// Enable serves pieces via HTTP/3 on the UDP port with the same number as the upload TCP port,
+	// it is announced to peers in the host info when peers sync pieces
+	Enable bool `mapstructure:"enable" yaml:"enable"`
 }
 
 type ObjectStorageOption struct {
@@ -598,6 +902,16 @@ type StorageOption struct {
 	WriteBufferSize unit.Bytes `mapstructure:"writeBufferSize" yaml:"writeBufferSize"`
 	// ReloadGoroutineCount indicates concurrent goroutine count when daemon load cache data
 	ReloadGoroutineCount int `mapstructure:"reloadGoroutineCount" yaml:"reloadGoroutineCount"`
+	// Encryption indicates encrypting task data at rest
+	Encryption EncryptionOption `mapstructure:"encryption" yaml:"encryption"`
+}
+
+type EncryptionOption struct {
+	// Enable encrypts the data of new tasks with AES-GCM, every task has its own data key
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// KeyFile is the master key file which wraps the data keys of tasks,
+	// it contains a 32 bytes key encoded in hex
+	KeyFile string `mapstructure:"keyFile" yaml:"keyFile"`
 }
 
 type StoreStrategy string
@@ -927,4 +1241,37 @@ type PeerExchangeOption struct {
 	ReplicaThreshold int `mapstructure:"replicaThreshold" yaml:"replicaThreshold"`
 	// ReplicaCleanPercentage is percentage probability to clean local replica when reach threshold, available values: [0, 100]
 	ReplicaCleanPercentage int32 `mapstructure:"replicaCleanPercentage" yaml:"replicaCleanPercentage"`
+	// Discovery is the discovery option of initial gossip members.
+	Discovery PeerExchangeDiscoveryOption `mapstructure:"discovery" yaml:"discovery"`
+}
+
+type PeerExchangeDiscoveryOption struct {
+	// Type is the discovery type of initial members, available values: seedPeer, static, dns, kubernetes, default is seedPeer.
+	Type string `mapstructure:"type" yaml:"type"`
+	// ResolveInterval is the interval to re-resolve initial members and join the new ones, 0 disables re-resolution.
+	ResolveInterval time.Duration `mapstructure:"resolveInterval" yaml:"resolveInterval"`
+	// Static is the gossip addresses of initial members, like 10.0.0.1:7946 or dragonfly-peer-0.dragonfly-peer:7946.
+	Static []string `mapstructure:"static" yaml:"static"`
+	// DNS is the DNS SRV discovery option.
+	DNS PeerExchangeDNSOption `mapstructure:"dns" yaml:"dns"`
+	// Kubernetes is the Kubernetes EndpointSlice discovery option.
+	Kubernetes PeerExchangeKubernetesOption `mapstructure:"kubernetes" yaml:"kubernetes"`
+}
+
+type PeerExchangeDNSOption struct {
+	// Service and Proto are the service and protocol of SRV records, the SRV records of _service._proto.name are looked up,
+	// if both of them are empty, the SRV records of name are looked up directly.
+	Service string `mapstructure:"service" yaml:"service"`
+	Proto   string `mapstructure:"proto" yaml:"proto"`
+	// Name is the domain name of SRV records.
+	Name string `mapstructure:"name" yaml:"name"`
+}
+
+type PeerExchangeKubernetesOption struct {
+	// Namespace is the namespace of service, default is the namespace of daemon pod.
+	Namespace string `mapstructure:"namespace" yaml:"namespace"`
+	// Service is the name of service whose EndpointSlices are watched.
+	Service string `mapstructure:"service" yaml:"service"`
+	// Port is the gossip port of peers behind the service.
+	Port int `mapstructure:"port" yaml:"port"`
 }
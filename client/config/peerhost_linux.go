@@ -47,6 +47,10 @@ var peerHostConfig = func() *DaemonOption {
 				},
 			},
 			ScheduleTimeout: util.Duration{Duration: DefaultScheduleTimeout},
+			Decentralized: DecentralizedOption{
+				Enable:   false,
+				Interval: util.Duration{Duration: DefaultDecentralizedInterval},
+			},
 		},
 		Host: HostOption{
 			Hostname: fqdn.FQDNHostname,
@@ -88,11 +92,21 @@ var peerHostConfig = func() *DaemonOption {
 				},
 			},
 			SplitRunningTasks: false,
+			PieceSize: PieceSizeOption{
+				TargetDuration: DefaultPieceSizeTargetDuration,
+				Min:            DefaultPieceSizeMin,
+				Max:            DefaultPieceSizeMax,
+			},
 		},
 		Upload: UploadOption{
 			RateLimit: util.RateLimit{
 				Limit: rate.Limit(DefaultUploadLimit),
 			},
+			Compression: CompressionOption{
+				Enable:        false,
+				MinRatio:      DefaultUploadCompressionMinRatio,
+				MaxCPUPercent: DefaultUploadCompressionMaxCPUPercent,
+			},
 			ListenOption: ListenOption{
 				Security: SecurityOption{
 					Insecure:  true,
@@ -179,6 +193,9 @@ var peerHostConfig = func() *DaemonOption {
 			ReSyncInterval:         10 * time.Minute,
 			ReplicaThreshold:       2,
 			ReplicaCleanPercentage: 1,
+			Discovery: PeerExchangeDiscoveryOption{
+				Type: PeerExchangeDiscoverySeedPeer,
+			},
 		},
 	}
 }
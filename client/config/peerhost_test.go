@@ -295,6 +295,9 @@ func TestPeerHostOption_Load(t *testing.T) {
 			Location:    "0.0.0.0",
 			IDC:         "d7y",
 			AdvertiseIP: net.IPv4zero,
+			Labels: map[string]string{
+				"lifecycle": "spot",
+			},
 		},
 		Download: DownloadOption{
 			TotalRateLimit: util.RateLimit{
@@ -636,6 +639,415 @@ func TestPeerHostOption_Validate(t *testing.T) {
 				assert.EqualError(err, "max replicas must be greater than 0")
 			},
 		},
+		{
+			name:   "upload authorization requires tls and tlsVerify",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Upload.Authorization.Enable = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "upload authorization requires tls and tlsVerify")
+			},
+		},
+		{
+			name:   "upload authorization requires caCert, cert and key",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Upload.Authorization.Enable = true
+				cfg.Upload.Security.Insecure = false
+				cfg.Upload.Security.TLSVerify = true
+				cfg.Upload.Security.Cert = "cert"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "upload authorization requires caCert, cert and key")
+			},
+		},
+		{
+			name:   "compression min ratio must be greater than or equal to 1",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Upload.Compression.Enable = true
+				cfg.Upload.Compression.MinRatio = 0.5
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "compression min ratio must be greater than or equal to 1")
+			},
+		},
+		{
+			name:   "compression max cpu percent must be in (0, 100]",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Upload.Compression.Enable = true
+				cfg.Upload.Compression.MaxCPUPercent = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "compression max cpu percent must be in (0, 100]")
+			},
+		},
+		{
+			name:   "peer exchange static discovery requires static addresses",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.Discovery.Type = "static"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peer exchange static discovery requires static addresses")
+			},
+		},
+		{
+			name:   "peer exchange dns discovery requires name",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.Discovery.Type = "dns"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peer exchange dns discovery requires name")
+			},
+		},
+		{
+			name:   "peer exchange kubernetes discovery requires service",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.Discovery.Type = "kubernetes"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peer exchange kubernetes discovery requires service")
+			},
+		},
+		{
+			name:   "peer exchange discovery type is not supported",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.Discovery.Type = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peer exchange discovery type foo is not supported")
+			},
+		},
+		{
+			name:   "host label key is invalid",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Host.Labels = map[string]string{"foo=bar": "baz"}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "host label key \"foo=bar\" is invalid")
+			},
+		},
+		{
+			name:   "decentralized mode requires peer exchange",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Scheduler.Decentralized.Enable = true
+				cfg.PeerExchange.Enable = false
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "decentralized mode requires peer exchange")
+			},
+		},
+		{
+			name:   "decentralized interval must be greater than 0",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Scheduler.Decentralized.Enable = true
+				cfg.PeerExchange.Enable = true
+				cfg.Scheduler.Decentralized.Interval.Duration = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "decentralized interval must be greater than 0")
+			},
+		},
+		{
+			name:   "bandwidth window time is invalid",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Bandwidth.Windows = []*BandwidthWindow{{Start: "9am", End: "18:00"}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, `bandwidth window time "9am" is invalid, expect 15:04 format`)
+			},
+		},
+		{
+			name:   "bandwidth window start is equal to end",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Bandwidth.Windows = []*BandwidthWindow{{Start: "09:00", End: "09:00"}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "bandwidth window start must be different from end")
+			},
+		},
+		{
+			name:   "application bandwidth without application and priority",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Bandwidth.Applications = []*ApplicationBandwidth{{Upload: util.RateLimit{Limit: 1024}}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "application bandwidth requires application or priority")
+			},
+		},
+		{
+			name:   "application bandwidth priority is invalid",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Bandwidth.Applications = []*ApplicationBandwidth{{Priority: "LEVEL9"}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "application bandwidth priority LEVEL9 is invalid")
+			},
+		},
+		{
+			name:   "application download bandwidth without sampling traffic shaper",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.TrafficShaperType = "plain"
+				cfg.Bandwidth.Applications = []*ApplicationBandwidth{{Application: "foo", Download: util.RateLimit{Limit: 1024}}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "application download bandwidth requires sampling traffic shaper")
+			},
+		},
+		{
+			name:   "piece size of rule is invalid",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.PieceSize.Rules = []*PieceSizeRule{{Application: "foo"}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "piece size of rule must be in (0, 4GiB)")
+			},
+		},
+		{
+			name:   "adaptive piece size target duration is invalid",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.PieceSize.Adaptive = true
+				cfg.Download.PieceSize.TargetDuration = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "adaptive piece size target duration must be greater than 0")
+			},
+		},
+		{
+			name:   "adaptive piece size min is greater than max",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.PieceSize.Adaptive = true
+				cfg.Download.PieceSize.Min = 128 * unit.MB
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "adaptive piece size requires 0 < min <= max < 4GiB")
+			},
+		},
+		{
+			name:   "integrity signature without integrity enabled",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.Integrity.RequireSignature = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "integrity signature requires integrity enabled")
+			},
+		},
+		{
+			name:   "integrity signature without upload caCert",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.Integrity.Enable = true
+				cfg.Download.Integrity.RequireSignature = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "integrity signature requires upload caCert")
+			},
+		},
+		{
+			name:   "encryption key file is not specified",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Encryption.Enable = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "encryption key file is not specified")
+			},
+		},
+		{
+			name:   "split running tasks is not supported when encryption is enabled",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Encryption.Enable = true
+				cfg.Storage.Encryption.KeyFile = "/etc/dragonfly/key"
+				cfg.Download.SplitRunningTasks = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "split running tasks is not supported when encryption is enabled")
+			},
+		},
 		{
 			name:   "reload interval too short, must great than 1 second",
 			config: NewDaemonConfig(),
@@ -29,6 +29,7 @@ import (
 	"github.com/shirou/gopsutil/v3/mem"
 	gopsutilnet "github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
+	"google.golang.org/grpc/metadata"
 
 	managerv1 "d7y.io/api/v2/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/v2/pkg/apis/scheduler/v1"
@@ -129,7 +130,7 @@ func (a *announcer) announceToScheduler() error {
 		return err
 	}
 
-	if err := a.schedulerClient.AnnounceHost(context.Background(), req); err != nil {
+	if err := a.schedulerClient.AnnounceHost(a.newAnnounceHostContext(), req); err != nil {
 		logger.Errorf("announce for the first time failed: %s", err.Error())
 	}
 
@@ -144,7 +145,7 @@ func (a *announcer) announceToScheduler() error {
 				break
 			}
 
-			if err := a.schedulerClient.AnnounceHost(context.Background(), req); err != nil {
+			if err := a.schedulerClient.AnnounceHost(a.newAnnounceHostContext(), req); err != nil {
 				logger.Error(err)
 				break
 			}
@@ -154,6 +155,17 @@ func (a *announcer) announceToScheduler() error {
 	}
 }
 
+// newAnnounceHostContext returns the context of announce host request,
+// the labels of host are announced by grpc metadata.
+func (a *announcer) newAnnounceHostContext() context.Context {
+	ctx := context.Background()
+	for _, label := range types.FormatHostLabels(a.config.Host.Labels) {
+		ctx = metadata.AppendToOutgoingContext(ctx, types.HostLabelsMetadataKey, label)
+	}
+
+	return ctx
+}
+
 // newAnnounceHostRequest returns announce host request.
 func (a *announcer) newAnnounceHostRequest() (*schedulerv1.AnnounceHostRequest, error) {
 	hostType := types.HostTypeNormalName
@@ -21,11 +21,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/metadata"
 
 	"d7y.io/dragonfly/v2/client/config"
 	configmocks "d7y.io/dragonfly/v2/client/config/mocks"
 	managerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
 	schedulerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client/mocks"
+	"d7y.io/dragonfly/v2/pkg/types"
 )
 
 func TestAnnouncer_New(t *testing.T) {
@@ -84,3 +86,39 @@ func TestAnnouncer_New(t *testing.T) {
 		})
 	}
 }
+
+func TestAnnouncer_newAnnounceHostContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		expect func(t *testing.T, md metadata.MD)
+	}{
+		{
+			name:   "announce host without labels",
+			labels: nil,
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Empty(md.Get(types.HostLabelsMetadataKey))
+			},
+		},
+		{
+			name: "announce host with labels",
+			labels: map[string]string{
+				"lifecycle": "spot",
+				"disk":      "ssd",
+			},
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Equal(md.Get(types.HostLabelsMetadataKey), []string{"disk=ssd", "lifecycle=spot"})
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &announcer{config: &config.DaemonOption{Host: config.HostOption{Labels: tc.labels}}}
+			md, _ := metadata.FromOutgoingContext(a.newAnnounceHostContext())
+			tc.expect(t, md)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bandwidth
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// DefaultScheduleInterval is the interval to check bandwidth windows.
+const DefaultScheduleInterval = 30 * time.Second
+
+// Scheduler is the interface used for scheduling bandwidth by time windows.
+type Scheduler interface {
+	// Start starts to apply rate limits of bandwidth windows.
+	Start()
+
+	// Stop stops scheduling.
+	Stop()
+}
+
+// scheduler applies the total download and upload rate limits of the current bandwidth window.
+type scheduler struct {
+	windows  []*config.BandwidthWindow
+	download rate.Limit
+	upload   rate.Limit
+	interval time.Duration
+
+	setDownload func(rate.Limit)
+	setUpload   func(rate.Limit)
+
+	// current rate limits applied
+	currentDownload rate.Limit
+	currentUpload   rate.Limit
+
+	now  func() time.Time
+	done chan struct{}
+}
+
+// Option is a functional option for configuring the scheduler.
+type Option func(s *scheduler)
+
+// WithInterval sets the interval to check bandwidth windows.
+func WithInterval(interval time.Duration) Option {
+	return func(s *scheduler) {
+		s.interval = interval
+	}
+}
+
+// New returns a new Scheduler interface, download and upload are the rate limits out of windows,
+// setDownload and setUpload are called when the rate limits change.
+func New(windows []*config.BandwidthWindow, download, upload rate.Limit,
+	setDownload, setUpload func(rate.Limit), options ...Option) Scheduler {
+	s := &scheduler{
+		windows:         windows,
+		download:        download,
+		upload:          upload,
+		interval:        DefaultScheduleInterval,
+		setDownload:     setDownload,
+		setUpload:       setUpload,
+		currentDownload: download,
+		currentUpload:   upload,
+		now:             time.Now,
+		done:            make(chan struct{}),
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+// Start starts to apply rate limits of bandwidth windows.
+func (s *scheduler) Start() {
+	s.apply()
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.apply()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops scheduling.
+func (s *scheduler) Stop() {
+	close(s.done)
+}
+
+// apply sets the rate limits of the current window when they change.
+func (s *scheduler) apply() {
+	download, upload := s.limits(s.now())
+	if download != s.currentDownload {
+		logger.Infof("bandwidth window changes total download rate limit from %.0f to %.0f", s.currentDownload, download)
+		s.currentDownload = download
+		s.setDownload(download)
+	}
+
+	if upload != s.currentUpload {
+		logger.Infof("bandwidth window changes upload rate limit from %.0f to %.0f", s.currentUpload, upload)
+		s.currentUpload = upload
+		s.setUpload(upload)
+	}
+}
+
+// limits returns the download and upload rate limits at the time.
+func (s *scheduler) limits(t time.Time) (rate.Limit, rate.Limit) {
+	download, upload := s.download, s.upload
+	for _, window := range s.windows {
+		if !window.Contains(t) {
+			continue
+		}
+
+		if window.Download.Limit > 0 {
+			download = window.Download.Limit
+		}
+
+		if window.Upload.Limit > 0 {
+			upload = window.Upload.Limit
+		}
+
+		break
+	}
+
+	return download, upload
+}
+
+// WaitN blocks until limiter permits n bytes, n is split by the burst of limiter,
+// so the budgets smaller than one piece still work.
+func WaitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	burst := limiter.Burst()
+	if burst <= 0 {
+		return limiter.WaitN(ctx, n)
+	}
+
+	for n > 0 {
+		size := n
+		if size > burst {
+			size = burst
+		}
+
+		if err := limiter.WaitN(ctx, size); err != nil {
+			return err
+		}
+
+		n -= size
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bandwidth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/util"
+)
+
+func TestScheduler_Limits(t *testing.T) {
+	windows := []*config.BandwidthWindow{
+		{
+			Start:  "09:00",
+			End:    "18:00",
+			Upload: util.RateLimit{Limit: 10},
+		},
+		{
+			Start:    "22:00",
+			End:      "06:00",
+			Download: util.RateLimit{Limit: 300},
+			Upload:   util.RateLimit{Limit: 300},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		download rate.Limit
+		upload   rate.Limit
+	}{
+		{
+			name:     "in business hours",
+			now:      time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local),
+			download: 100,
+			upload:   10,
+		},
+		{
+			name:     "window end is exclusive",
+			now:      time.Date(2025, 1, 1, 18, 0, 0, 0, time.Local),
+			download: 100,
+			upload:   100,
+		},
+		{
+			name:     "window crosses midnight before midnight",
+			now:      time.Date(2025, 1, 1, 23, 30, 0, 0, time.Local),
+			download: 300,
+			upload:   300,
+		},
+		{
+			name:     "window crosses midnight after midnight",
+			now:      time.Date(2025, 1, 1, 5, 59, 59, 0, time.Local),
+			download: 300,
+			upload:   300,
+		},
+		{
+			name:     "out of windows",
+			now:      time.Date(2025, 1, 1, 20, 0, 0, 0, time.Local),
+			download: 100,
+			upload:   100,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(windows, 100, 100, func(rate.Limit) {}, func(rate.Limit) {}).(*scheduler)
+			download, upload := s.limits(tc.now)
+			assert := assert.New(t)
+			assert.Equal(tc.download, download)
+			assert.Equal(tc.upload, upload)
+		})
+	}
+}
+
+func TestScheduler_Apply(t *testing.T) {
+	assert := assert.New(t)
+	var downloads, uploads []rate.Limit
+	s := New([]*config.BandwidthWindow{
+		{
+			Start:  "09:00",
+			End:    "18:00",
+			Upload: util.RateLimit{Limit: 10},
+		},
+	}, 100, 100, func(limit rate.Limit) {
+		downloads = append(downloads, limit)
+	}, func(limit rate.Limit) {
+		uploads = append(uploads, limit)
+	}).(*scheduler)
+
+	s.now = func() time.Time { return time.Date(2025, 1, 1, 8, 0, 0, 0, time.Local) }
+	s.apply()
+	assert.Empty(downloads)
+	assert.Empty(uploads)
+
+	s.now = func() time.Time { return time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local) }
+	s.apply()
+	s.apply()
+	assert.Empty(downloads)
+	assert.Equal([]rate.Limit{10}, uploads)
+
+	s.now = func() time.Time { return time.Date(2025, 1, 1, 19, 0, 0, 0, time.Local) }
+	s.apply()
+	assert.Empty(downloads)
+	assert.Equal([]rate.Limit{10, 100}, uploads)
+}
+
+func TestWaitN(t *testing.T) {
+	assert := assert.New(t)
+	limiter := rate.NewLimiter(1000, 1000)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// n is greater than burst, it waits for about 1 second.
+	start := time.Now()
+	assert.NoError(WaitN(ctx, limiter, 2000))
+	assert.GreaterOrEqual(time.Since(start), 900*time.Millisecond)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(WaitN(canceled, limiter, 2000))
+}
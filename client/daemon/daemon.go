@@ -44,7 +44,9 @@ import (
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/announcer"
+	"d7y.io/dragonfly/v2/client/daemon/bandwidth"
 	"d7y.io/dragonfly/v2/client/daemon/gc"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/objectstorage"
 	"d7y.io/dragonfly/v2/client/daemon/peer"
@@ -94,6 +96,8 @@ type clientDaemon struct {
 	GCManager      gc.Manager
 	pexServer      pex.PeerExchangeServer
 
+	bandwidthScheduler bandwidth.Scheduler
+
 	PeerTaskManager peer.TaskManager
 	PieceManager    peer.PieceManager
 
@@ -182,23 +186,22 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	)
 
 	if opt.IsSupportPeerExchange() {
+		lister, err := newPeerExchangeMemberLister(opt, dynconfig)
+		if err != nil {
+			return nil, err
+		}
+
 		peerExchange, err = pex.NewPeerExchange(
 			func(task, peer string) error {
 				return reclaimFunc(task, peer)
 			},
-			pex.NewSeedPeerMemberLister(func() ([]*managerv1.SeedPeer, error) {
-				peers, err := dynconfig.GetSeedPeers()
-				if err == nil {
-					return peers, nil
-				}
-				_ = dynconfig.Refresh()
-				return dynconfig.GetSeedPeers()
-			}),
+			lister,
 			opt.Download.GRPCDialTimeout, []grpc.DialOption{
 				grpc.WithTransportCredentials(rpc.NewInsecureCredentials()),
 			},
 			pex.WithInitialRetryInterval(opt.PeerExchange.InitialInterval),
 			pex.WithReSyncInterval(opt.PeerExchange.ReSyncInterval),
+			pex.WithResolveInterval(opt.PeerExchange.Discovery.ResolveInterval),
 			pex.WithReplicaThreshold(opt.PeerExchange.ReplicaThreshold),
 			pex.WithReplicaCleanPercentage(opt.PeerExchange.ReplicaCleanPercentage))
 		if err != nil {
@@ -241,11 +244,23 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 			})
 	}
 
+	downloadLimiter := rate.NewLimiter(opt.Download.TotalRateLimit.Limit, int(opt.Download.TotalRateLimit.Limit))
 	pmOpts := []peer.PieceManagerOption{
-		peer.WithLimiter(rate.NewLimiter(opt.Download.TotalRateLimit.Limit, int(opt.Download.TotalRateLimit.Limit))),
+		peer.WithLimiter(downloadLimiter),
 		peer.WithCalculateDigest(opt.Download.CalculateDigest),
 		peer.WithTransportOption(opt.Download.Transport),
 		peer.WithConcurrentOption(opt.Download.Concurrent),
+		peer.WithQUIC(opt.Download.QUIC),
+		peer.WithCompression(opt.Download.Compression),
+		peer.WithPieceSizeOption(opt.Download.PieceSize),
+		peer.WithIntegrity(opt.Download.Integrity, string(opt.Upload.Security.CACert)),
+	}
+
+	// Present the peer certificate to parents which authorize piece uploads.
+	if opt.Upload.Authorization.Enable {
+		pmOpts = append(pmOpts,
+			peer.WithSyncPieceViaHTTPS(string(opt.Upload.Security.CACert)),
+			peer.WithPeerCertificate(string(opt.Upload.Security.Cert), string(opt.Upload.Security.Key)))
 	}
 
 	pieceManager, err := peer.NewPieceManager(opt.Download.PieceDownloadTimeout, pmOpts...)
@@ -264,11 +279,13 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 			GRPCCredentials:    rpc.NewInsecureCredentials(),
 			GRPCDialTimeout:    opt.Download.GRPCDialTimeout,
 			CancelIdlePeerTask: opt.Download.CancelIdlePeerTask,
+			Integrity:          opt.Download.Integrity,
 		},
 		SchedulerClient:       schedulerClient,
 		PerPeerRateLimit:      opt.Download.PerPeerRateLimit.Limit,
 		TotalRateLimit:        opt.Download.TotalRateLimit.Limit,
 		TrafficShaperType:     opt.Download.TrafficShaperType,
+		ApplicationBandwidth:  opt.Bandwidth.Applications,
 		Multiplex:             opt.Storage.Multiplex,
 		Prefetch:              opt.Download.Prefetch,
 		GetPiecesMaxRetry:     opt.Download.GetPiecesMaxRetry,
@@ -282,7 +299,7 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 
 	rpcManager, err := rpcserver.New(host, peerTaskManager, storageManager, peerExchangeRPC, schedulerClient,
 		opt.Download.RecursiveConcurrent.GoroutineCount, opt.Download.SeedConcurrent,
-		opt.Download.CacheRecursiveMetadata, []grpc.ServerOption{grpc.Creds(rpc.NewInsecureCredentials())}, []grpc.ServerOption{grpc.Creds(rpc.NewInsecureCredentials())})
+		opt.Download.CacheRecursiveMetadata, opt.Upload.QUIC.Enable, []grpc.ServerOption{grpc.Creds(rpc.NewInsecureCredentials())}, []grpc.ServerOption{grpc.Creds(rpc.NewInsecureCredentials())})
 	if err != nil {
 		return nil, err
 	}
@@ -294,8 +311,19 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		return nil, err
 	}
 
+	uploadLimiter := rate.NewLimiter(opt.Upload.RateLimit.Limit, int(opt.Upload.RateLimit.Limit))
 	uploadOpts := []upload.Option{
-		upload.WithLimiter(rate.NewLimiter(opt.Upload.RateLimit.Limit, int(opt.Upload.RateLimit.Limit))),
+		upload.WithLimiter(uploadLimiter),
+	}
+
+	// Seed peers sign merkle trees of tasks, peers trust the trees signed by them.
+	if opt.Download.Integrity.Enable && opt.Scheduler.Manager.SeedPeer.Enable && opt.Upload.Security.Cert != "" {
+		signer, err := integrity.NewSigner(string(opt.Upload.Security.Cert), string(opt.Upload.Security.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid merkle tree signer: %w", err)
+		}
+
+		uploadOpts = append(uploadOpts, upload.WithSigner(signer))
 	}
 
 	uploadManager, err := upload.NewUploadManager(opt, storageManager, d.LogDir(), uploadOpts...)
@@ -303,6 +331,18 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		return nil, err
 	}
 
+	// Bandwidth windows override the total download and upload rate limits during periods of the day.
+	var bandwidthScheduler bandwidth.Scheduler
+	if len(opt.Bandwidth.Windows) > 0 {
+		bandwidthScheduler = bandwidth.New(opt.Bandwidth.Windows,
+			opt.Download.TotalRateLimit.Limit, opt.Upload.RateLimit.Limit,
+			func(limit rate.Limit) {
+				downloadLimiter.SetLimit(limit)
+				peerTaskManager.GetTrafficShaper().SetTotalRateLimit(limit)
+			},
+			uploadLimiter.SetLimit)
+	}
+
 	var objectStorage objectstorage.ObjectStorage
 	if opt.ObjectStorage.Enable {
 		objectStorage, err = objectstorage.New(opt, dynconfig, peerTaskManager, storageManager, d.LogDir())
@@ -330,9 +370,33 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		managerClient:   managerClient,
 		schedulerClient: schedulerClient,
 		certifyClient:   certifyClient,
+
+		bandwidthScheduler: bandwidthScheduler,
 	}, nil
 }
 
+// newPeerExchangeMemberLister returns the lister of initial gossip members by discovery type.
+func newPeerExchangeMemberLister(opt *config.DaemonOption, dynconfig config.Dynconfig) (pex.InitialMemberLister, error) {
+	discovery := opt.PeerExchange.Discovery
+	switch discovery.Type {
+	case config.PeerExchangeDiscoveryStatic:
+		return pex.NewStaticAddrMemberLister(discovery.Static)
+	case config.PeerExchangeDiscoveryDNS:
+		return pex.NewDNSMemberLister(discovery.DNS.Service, discovery.DNS.Proto, discovery.DNS.Name), nil
+	case config.PeerExchangeDiscoveryKubernetes:
+		return pex.NewKubernetesMemberLister(discovery.Kubernetes.Namespace, discovery.Kubernetes.Service, discovery.Kubernetes.Port)
+	default:
+		return pex.NewSeedPeerMemberLister(func() ([]*managerv1.SeedPeer, error) {
+			peers, err := dynconfig.GetSeedPeers()
+			if err == nil {
+				return peers, nil
+			}
+			_ = dynconfig.Refresh()
+			return dynconfig.GetSeedPeers()
+		}), nil
+	}
+}
+
 func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (net.Listener, int, error) {
 	if len(opt.TCPListen.Namespace) > 0 {
 		runtime.LockOSThread()
@@ -411,6 +475,10 @@ func (cd *clientDaemon) Serve() error {
 	}
 
 	cd.GCManager.Start()
+	if cd.bandwidthScheduler != nil {
+		cd.bandwidthScheduler.Start()
+	}
+
 	// prepare download service listen
 	if cd.Option.Download.DownloadGRPC.UnixListen == nil {
 		return errors.New("download grpc unix listen option is empty")
@@ -447,6 +515,16 @@ func (cd *clientDaemon) Serve() error {
 	}
 	cd.schedPeerHost.DownPort = int32(uploadPort)
 
+	// prepare upload quic service listen, it uses the udp port with the same number as upload service
+	var uploadQUICConn net.PacketConn
+	if cd.Option.Upload.QUIC.Enable {
+		uploadQUICConn, err = net.ListenPacket("udp", uploadListener.Addr().String())
+		if err != nil {
+			logger.Errorf("failed to listen for upload quic service: %v", err)
+			return err
+		}
+	}
+
 	// prepare object storage service listen
 	var (
 		objectStorageListener net.Listener
@@ -573,6 +651,21 @@ func (cd *clientDaemon) Serve() error {
 		return nil
 	})
 
+	// serve upload quic service
+	if cd.Option.Upload.QUIC.Enable {
+		g.Go(func() error {
+			defer uploadQUICConn.Close()
+			logger.Infof("serve upload quic service at %s://%s", uploadQUICConn.LocalAddr().Network(), uploadQUICConn.LocalAddr().String())
+			if err := cd.UploadManager.ServeQUIC(uploadQUICConn); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("failed to serve for upload quic service: %v", err)
+				return err
+			} else if err == http.ErrServerClosed {
+				logger.Infof("upload quic service closed")
+			}
+			return nil
+		})
+	}
+
 	// serve object storage service
 	if cd.Option.ObjectStorage.Enable {
 		g.Go(func() error {
@@ -735,6 +828,10 @@ func (cd *clientDaemon) Stop() {
 		}
 
 		cd.GCManager.Stop()
+		if cd.bandwidthScheduler != nil {
+			cd.bandwidthScheduler.Stop()
+		}
+
 		cd.RPCManager.Stop()
 		if err := cd.UploadManager.Stop(); err != nil {
 			logger.Errorf("upload manager stop failed %s", err)
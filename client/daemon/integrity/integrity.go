@@ -0,0 +1,226 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integrity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+// SeedPeerURI is the uri SAN of seed peer certificates, only merkle trees signed by them are trusted.
+const SeedPeerURI = "dragonfly://seed-peer"
+
+var (
+	ErrRootMismatch  = errors.New("merkle root does not match leaves")
+	ErrUnsigned      = errors.New("merkle tree is not signed")
+	ErrNotSeedPeer   = errors.New("merkle tree is not signed by seed peer")
+	ErrLeafNotFound  = errors.New("merkle leaf not found")
+	ErrInvalidSigner = errors.New("signer certificate is not a seed peer certificate")
+)
+
+// MerkleTree is the merkle tree over sha256 digests of task pieces,
+// it is generated by the peer back-sourced the task and signed by seed peer.
+type MerkleTree struct {
+	// Root is the merkle root of leaves.
+	Root string `json:"root"`
+
+	// Leaves are the sha256 digests of pieces, ordered by piece number.
+	Leaves []string `json:"leaves"`
+
+	// Signature is the signature of task id and root.
+	Signature []byte `json:"signature,omitempty"`
+
+	// Certificates is the DER encoded certificate chain of the signer.
+	Certificates [][]byte `json:"certificates,omitempty"`
+
+	// BackSourced indicates all pieces of the local task are downloaded from source,
+	// only such trees are signed by seed peer. It is not transferred to other peers.
+	BackSourced bool `json:"-"`
+}
+
+// NewMerkleTree returns a unsigned merkle tree of leaves.
+func NewMerkleTree(leaves []string) *MerkleTree {
+	return &MerkleTree{
+		Root:   digest.MerkleRoot(leaves...),
+		Leaves: leaves,
+	}
+}
+
+// Leaf returns the sha256 digest of the piece.
+func (t *MerkleTree) Leaf(num int32) (string, error) {
+	if num < 0 || int(num) >= len(t.Leaves) {
+		return "", ErrLeafNotFound
+	}
+
+	return t.Leaves[num], nil
+}
+
+// Signed returns whether the tree is signed.
+func (t *MerkleTree) Signed() bool {
+	return len(t.Signature) > 0
+}
+
+// Signer signs merkle trees with seed peer certificate.
+type Signer interface {
+	// Sign signs the root of tree and attaches the certificate chain.
+	Sign(taskID string, tree *MerkleTree) error
+}
+
+// signer implements Signer.
+type signer struct {
+	cert tls.Certificate
+	key  crypto.Signer
+}
+
+// NewSigner returns a new Signer with the PEM encoded certificate and key,
+// the certificate must carry the seed peer uri.
+func NewSigner(certPEM, keyPEM string) (Signer, error) {
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSeedPeer(leaf) {
+		return nil, ErrInvalidSigner
+	}
+
+	key, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", cert.PrivateKey)
+	}
+
+	return &signer{cert: cert, key: key}, nil
+}
+
+// Sign signs the root of tree and attaches the certificate chain.
+func (s *signer) Sign(taskID string, tree *MerkleTree) error {
+	message := signedMessage(taskID, tree.Root)
+
+	var (
+		signature []byte
+		err       error
+	)
+	switch s.key.(type) {
+	case ed25519.PrivateKey:
+		signature, err = s.key.Sign(rand.Reader, message, crypto.Hash(0))
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		sum := sha256.Sum256(message)
+		signature, err = s.key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	default:
+		return fmt.Errorf("unsupported private key type %T", s.key)
+	}
+	if err != nil {
+		return err
+	}
+
+	tree.Signature = signature
+	tree.Certificates = s.cert.Certificate
+	return nil
+}
+
+// Verify verifies the root matches leaves, and the signature is signed by
+// seed peer certificate issued by roots. Unsigned tree is accepted
+// only when signature is not required.
+func Verify(taskID string, tree *MerkleTree, roots *x509.CertPool, requireSignature bool) error {
+	if tree.Root == "" || digest.MerkleRoot(tree.Leaves...) != tree.Root {
+		return ErrRootMismatch
+	}
+
+	if !tree.Signed() {
+		if requireSignature {
+			return ErrUnsigned
+		}
+
+		return nil
+	}
+
+	if len(tree.Certificates) == 0 {
+		return ErrNotSeedPeer
+	}
+
+	leaf, err := x509.ParseCertificate(tree.Certificates[0])
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, der := range tree.Certificates[1:] {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+
+		intermediates.AddCert(cert)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return err
+	}
+
+	if !isSeedPeer(leaf) {
+		return ErrNotSeedPeer
+	}
+
+	var algorithm x509.SignatureAlgorithm
+	switch leaf.PublicKeyAlgorithm {
+	case x509.Ed25519:
+		algorithm = x509.PureEd25519
+	case x509.ECDSA:
+		algorithm = x509.ECDSAWithSHA256
+	case x509.RSA:
+		algorithm = x509.SHA256WithRSA
+	default:
+		return fmt.Errorf("unsupported public key algorithm %s", leaf.PublicKeyAlgorithm)
+	}
+
+	return leaf.CheckSignature(algorithm, signedMessage(taskID, tree.Root), tree.Signature)
+}
+
+// signedMessage binds the root to the task, then the signature can not be replayed for other tasks.
+func signedMessage(taskID, root string) []byte {
+	return []byte(taskID + ":" + root)
+}
+
+// isSeedPeer returns whether the certificate carries the seed peer uri.
+func isSeedPeer(cert *x509.Certificate) bool {
+	for _, uri := range cert.URIs {
+		if uri.String() == SeedPeerURI {
+			return true
+		}
+	}
+
+	return false
+}
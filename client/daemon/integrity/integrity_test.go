@@ -0,0 +1,270 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integrity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+type mockCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newMockCA(t *testing.T) *mockCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &mockCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns PEM encoded certificate and key issued by ca.
+func (ca *mockCA) issue(t *testing.T, uris ...string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	for _, u := range uris {
+		uri, err := url.Parse(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = append(template.URIs, uri)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func mockLeaves() []string {
+	return []string{
+		digest.SHA256FromBytes([]byte("piece-0")),
+		digest.SHA256FromBytes([]byte("piece-1")),
+		digest.SHA256FromBytes([]byte("piece-2")),
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	ca := newMockCA(t)
+
+	cert, key := ca.issue(t, SeedPeerURI)
+	_, err := NewSigner(cert, key)
+	assert.NoError(t, err)
+
+	cert, key = ca.issue(t, "dragonfly://peer")
+	_, err = NewSigner(cert, key)
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+}
+
+func TestMerkleTree_Leaf(t *testing.T) {
+	tree := NewMerkleTree(mockLeaves())
+
+	leaf, err := tree.Leaf(1)
+	assert.NoError(t, err)
+	assert.Equal(t, mockLeaves()[1], leaf)
+
+	_, err = tree.Leaf(3)
+	assert.ErrorIs(t, err, ErrLeafNotFound)
+
+	_, err = tree.Leaf(-1)
+	assert.ErrorIs(t, err, ErrLeafNotFound)
+}
+
+func TestVerify(t *testing.T) {
+	ca := newMockCA(t)
+	seedCert, seedKey := ca.issue(t, SeedPeerURI)
+	seedSigner, err := NewSigner(seedCert, seedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		tree             func(t *testing.T) *MerkleTree
+		roots            *x509.CertPool
+		requireSignature bool
+		expect           func(t *testing.T, err error)
+	}{
+		{
+			name: "signed by seed peer",
+			tree: func(t *testing.T) *MerkleTree {
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, seedSigner.Sign("foo", tree))
+				return tree
+			},
+			roots:            ca.pool,
+			requireSignature: true,
+			expect: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "unsigned tree is accepted without requiring signature",
+			tree: func(t *testing.T) *MerkleTree {
+				return NewMerkleTree(mockLeaves())
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "unsigned tree is rejected when requiring signature",
+			tree: func(t *testing.T) *MerkleTree {
+				return NewMerkleTree(mockLeaves())
+			},
+			roots:            ca.pool,
+			requireSignature: true,
+			expect: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnsigned)
+			},
+		},
+		{
+			name: "tampered leaf",
+			tree: func(t *testing.T) *MerkleTree {
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, seedSigner.Sign("foo", tree))
+				tree.Leaves[1] = digest.SHA256FromBytes([]byte("bar"))
+				return tree
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrRootMismatch)
+			},
+		},
+		{
+			name: "tampered root",
+			tree: func(t *testing.T) *MerkleTree {
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, seedSigner.Sign("foo", tree))
+				tree.Leaves[1] = digest.SHA256FromBytes([]byte("bar"))
+				tree.Root = digest.MerkleRoot(tree.Leaves...)
+				return tree
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "signature of other task",
+			tree: func(t *testing.T) *MerkleTree {
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, seedSigner.Sign("bar", tree))
+				return tree
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "signed by untrusted ca",
+			tree: func(t *testing.T) *MerkleTree {
+				cert, key := newMockCA(t).issue(t, SeedPeerURI)
+				signer, err := NewSigner(cert, key)
+				assert.NoError(t, err)
+
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, signer.Sign("foo", tree))
+				return tree
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "signed by normal peer",
+			tree: func(t *testing.T) *MerkleTree {
+				tree := NewMerkleTree(mockLeaves())
+				assert.NoError(t, seedSigner.Sign("foo", tree))
+
+				// Replace the seed peer certificate with a normal peer certificate.
+				cert, _ := ca.issue(t, "dragonfly://peer")
+				block, _ := pem.Decode([]byte(cert))
+				tree.Certificates = [][]byte{block.Bytes}
+				return tree
+			},
+			roots: ca.pool,
+			expect: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrNotSeedPeer)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, Verify("foo", tc.tree(t), tc.roots, tc.requireSignature))
+		})
+	}
+}
@@ -42,6 +42,27 @@ const (
 
 	// SeedPeerDownload type is back-to-source
 	SeedPeerDownloadTypeBackToSource = "back_to_source"
+
+	// Upload path is sendfile, piece data is sent by sendfile or splice syscall
+	UploadPathSendfile = "sendfile"
+
+	// Upload path is copy, piece data is copied in user space
+	UploadPathCopy = "copy"
+
+	// Upload compression skipped reason is poor compression ratio of task
+	UploadCompressionSkippedReasonRatio = "ratio"
+
+	// Upload compression skipped reason is cpu saturated
+	UploadCompressionSkippedReasonCPU = "cpu"
+
+	// Upload denied reason is no verified client certificate
+	UploadDeniedReasonUnverified = "unverified"
+
+	// Upload denied reason is requester in other scheduler cluster
+	UploadDeniedReasonSchedulerCluster = "scheduler_cluster"
+
+	// Upload denied reason is requester not allowed for the application of task
+	UploadDeniedReasonApplication = "application"
 )
 
 // Variables declared for metrics.
@@ -95,6 +116,13 @@ var (
 		Help:      "Counter of the total peer tasks.",
 	})
 
+	DecentralizedPeerTaskCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "decentralized_peer_task_total",
+		Help:      "Counter of the total peer tasks downloaded in decentralized mode.",
+	})
+
 	PeerTaskFailedCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: types.MetricsNamespace,
 		Subsystem: types.DfdaemonMetricsName,
@@ -179,6 +207,34 @@ var (
 		Help:      "Total bytes of back source.",
 	})
 
+	UploadTraffic = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "upload_traffic",
+		Help:      "Counter of the number of upload traffic.",
+	}, []string{"path"})
+
+	UploadCompressionSavedTraffic = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "upload_compression_saved_traffic",
+		Help:      "Counter of the number of upload traffic saved by compression.",
+	})
+
+	UploadCompressionSkippedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "upload_compression_skipped_total",
+		Help:      "Counter of the number of upload pieces skipped compression.",
+	}, []string{"reason"})
+
+	UploadDeniedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "upload_denied_total",
+		Help:      "Counter of the number of denied upload request.",
+	}, []string{"reason"})
+
 	VersionGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: types.MetricsNamespace,
 		Subsystem: types.DfdaemonMetricsName,
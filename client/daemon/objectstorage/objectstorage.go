@@ -422,7 +422,7 @@ func (o *objectStorage) putObject(ctx *gin.Context) {
 
 	// Import object to local storage.
 	log.Infof("import object %s to local storage", objectKey)
-	if err := o.importObjectToLocalStorage(ctx, taskID, peerID, fileHeader); err != nil {
+	if err := o.importObjectToLocalStorage(ctx, taskID, peerID, signURL, urlMeta, fileHeader); err != nil {
 		log.Error(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
 		return
@@ -597,7 +597,7 @@ func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, o
 }
 
 // importObjectToSeedPeers uses to import object to local storage.
-func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID, peerID string, fileHeader *multipart.FileHeader) (err error) {
+func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID, peerID, url string, urlMeta *commonv1.UrlMeta, fileHeader *multipart.FileHeader) (err error) {
 	f, err := fileHeader.Open()
 	if err != nil {
 		return nil
@@ -622,7 +622,19 @@ func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID,
 	}
 
 	// Import task data to dfdaemon.
-	return o.peerTaskManager.GetPieceManager().Import(ctx, meta, tsd, fileHeader.Size, f)
+	if err := o.peerTaskManager.GetPieceManager().Import(ctx, meta, tsd, url, urlMeta, fileHeader.Size, f); err != nil {
+		// Unregister the partially imported task, the request context may be canceled already.
+		if uerr := o.storageManager.UnregisterTask(context.Background(), storage.CommonTaskRequest{
+			PeerID: peerID,
+			TaskID: taskID,
+		}); uerr != nil {
+			err = errors.Join(err, uerr)
+		}
+
+		return err
+	}
+
+	return nil
 }
 
 // importObjectToSeedPeers uses to import object to available seed peers.
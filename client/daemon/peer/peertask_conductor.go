@@ -44,6 +44,7 @@ import (
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	"d7y.io/dragonfly/v2/internal/dferrors"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/internal/util"
 	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
@@ -102,6 +103,7 @@ type peerTaskConductor struct {
 	totalPiece      *atomic.Int32
 	digest          *atomic.String
 	contentLength   *atomic.Int64
+	pieceSize       *atomic.Uint32
 	completedLength *atomic.Int64
 	usedTraffic     *atomic.Uint64
 	header          atomic.Value
@@ -152,6 +154,11 @@ type peerTaskConductor struct {
 	// limiter will be used when enable per peer task rate limit
 	limiter *rate.Limiter
 
+	// merkleTrees caches the verified merkle trees used to verify pieces
+	merkleTrees merkleTrees
+	// blockedParents stands parents serving corrupt data, key is peer id
+	blockedParents sync.Map
+
 	startTime time.Time
 
 	// subtask only
@@ -175,6 +182,8 @@ type TaskOption struct {
 	// WatchdogTimeout > 0 indicates to start watch dog for every single peer task
 	WatchdogTimeout    time.Duration
 	CancelIdlePeerTask bool
+	// Integrity indicates to verify pieces from parents with merkle tree
+	Integrity config.IntegrityOption
 }
 
 func (ptm *peerTaskManager) newPeerTaskConductor(
@@ -237,6 +246,7 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		failedReason:        failedReasonNotSet,
 		failedCode:          commonv1.Code_UnknownError,
 		contentLength:       atomic.NewInt64(-1),
+		pieceSize:           atomic.NewUint32(0),
 		totalPiece:          atomic.NewInt32(-1),
 		digest:              atomic.NewString(""),
 		trafficShaper:       ptm.trafficShaper,
@@ -283,6 +293,10 @@ func (pt *peerTaskConductor) register() error {
 			pt.Errorf("scheduler did not response in %s", pt.SchedulerOption.ScheduleTimeout.Duration)
 		}
 		pt.Errorf("step 1: peer %s register failed: %s", pt.request.PeerId, err)
+		if pt.registerDecentralized(err) {
+			return nil
+		}
+
 		// can not detect source or scheduler error, create a new dummy scheduler client
 		pt.schedulerClient = &dummySchedulerClient{}
 		// when peer register failed, some actions need to do with peerPacketStream
@@ -411,6 +425,14 @@ func (pt *peerTaskConductor) SetContentLength(i int64) {
 	pt.contentLength.Store(i)
 }
 
+func (pt *peerTaskConductor) GetPieceSize() uint32 {
+	return pt.pieceSize.Load()
+}
+
+func (pt *peerTaskConductor) SetPieceSize(size uint32) {
+	pt.pieceSize.Store(size)
+}
+
 func (pt *peerTaskConductor) AddTraffic(n uint64) {
 	pt.usedTraffic.Add(n)
 }
@@ -965,6 +987,32 @@ func (pt *peerTaskConductor) pullSinglePiece() {
 	return
 }
 
+// checkPieceLayout returns error when the pieces from parent do not match the piece size of the task.
+func (pt *peerTaskConductor) checkPieceLayout(piecePacket *commonv1.PiecePacket) error {
+	pieceSize := pt.GetPieceSize()
+	if pieceSize == 0 {
+		return nil
+	}
+
+	if contentLength := pt.GetContentLength(); contentLength > 0 && piecePacket.TotalPiece > 0 &&
+		piecePacket.TotalPiece != util.ComputePieceCount(contentLength, pieceSize) {
+		return fmt.Errorf("total piece count %d did not match piece size %d", piecePacket.TotalPiece, pieceSize)
+	}
+
+	for _, piece := range piecePacket.PieceInfos {
+		if piece.RangeStart != uint64(piece.PieceNum)*uint64(pieceSize) {
+			return fmt.Errorf("piece %d range start %d did not match piece size %d", piece.PieceNum, piece.RangeStart, pieceSize)
+		}
+
+		if piece.RangeSize > pieceSize ||
+			(piecePacket.TotalPiece > 0 && piece.PieceNum < piecePacket.TotalPiece-1 && piece.RangeSize != pieceSize) {
+			return fmt.Errorf("piece %d range size %d did not match piece size %d", piece.PieceNum, piece.RangeSize, pieceSize)
+		}
+	}
+
+	return nil
+}
+
 func (pt *peerTaskConductor) updateMetadata(piecePacket *commonv1.PiecePacket) {
 	// update total piece
 	var metadataChanged bool
@@ -996,6 +1044,18 @@ func (pt *peerTaskConductor) updateMetadata(piecePacket *commonv1.PiecePacket) {
 		return
 	}
 
+	// update piece size, all pieces except the last one have the same size
+	if pt.GetPieceSize() == 0 {
+		for _, piece := range piecePacket.PieceInfos {
+			if piecePacket.TotalPiece > 0 && piece.PieceNum < piecePacket.TotalPiece-1 && piece.RangeSize > 0 {
+				metadataChanged = true
+				pt.SetPieceSize(piece.RangeSize)
+				pt.Debugf("update piece size: %d, dst peer %s", piece.RangeSize, piecePacket.DstPid)
+				break
+			}
+		}
+	}
+
 	if piecePacket.ExtendAttribute != nil && len(piecePacket.ExtendAttribute.Header) > 0 && pt.GetHeader() == nil {
 		metadataChanged = true
 		pt.SetHeader(piecePacket.ExtendAttribute.Header)
@@ -1061,6 +1121,10 @@ func (pt *peerTaskConductor) downloadPieceWorker(id int32, requests PieceDispatc
 			continue
 		}
 		pt.readyPiecesLock.RUnlock()
+		if pt.isBlockedParent(request.DstPid) {
+			pt.Log().Debugf("piece %d is from blocklisted parent %s, skip", request.piece.PieceNum, request.DstPid)
+			continue
+		}
 		result := pt.downloadPiece(id, request)
 		if result != nil {
 			requests.Report(result)
@@ -1119,8 +1183,11 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 		workerID, request.DstPid, request.piece.PieceNum, request.piece.RangeStart, request.piece.RangeSize)
 	// download piece
 	// result is always not nil, PieceManager will report begin and end time
-	result, err := pt.PieceManager.DownloadPiece(ctx, request)
+	result, err := pt.downloadVerifiedPiece(ctx, request)
 	if err != nil {
+		if isIntegrityError(err) {
+			pt.blockParent(request.DstPid, err)
+		}
 		pt.ReportPieceResult(request, result, err)
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
 		span.End()
@@ -1151,6 +1218,30 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	return result
 }
 
+// downloadVerifiedPiece downloads the piece, which is verified with merkle tree when integrity is enabled.
+func (pt *peerTaskConductor) downloadVerifiedPiece(ctx context.Context, request *DownloadPieceRequest) (*DownloadPieceResult, error) {
+	if !pt.Integrity.Enable {
+		return pt.PieceManager.DownloadPiece(ctx, request)
+	}
+
+	sha256, err := pt.pieceSha256(ctx, request)
+	if err != nil {
+		pt.Errorf("get sha256 of piece %d from %s failed: %s", request.piece.PieceNum, request.DstPid, err)
+		now := time.Now().UnixNano()
+		return &DownloadPieceResult{
+			Size:       -1,
+			BeginTime:  now,
+			FinishTime: now,
+			DstPeerID:  request.DstPid,
+			Fail:       true,
+			pieceInfo:  request.piece,
+		}, err
+	}
+
+	request.Sha256 = sha256
+	return pt.PieceManager.DownloadPiece(ctx, request)
+}
+
 func (pt *peerTaskConductor) waitLimit(ctx context.Context, request *DownloadPieceRequest) bool {
 	_, waitSpan := tracer.Start(ctx, config.SpanWaitPieceLimit)
 	pt.trafficShaper.Record(pt.peerTaskManager.getRunningTaskKey(request.TaskID, request.PeerID), int(request.piece.RangeSize))
@@ -1326,6 +1417,8 @@ func (pt *peerTaskConductor) registerStorage(desiredLocation string) (err error)
 					PeerID: pt.GetPeerID(),
 					TaskID: pt.GetTaskID(),
 				},
+				Application:     pt.request.UrlMeta.GetApplication(),
+				Priority:        pt.request.UrlMeta.GetPriority(),
 				DesiredLocation: desiredLocation,
 				ContentLength:   pt.GetContentLength(),
 				TotalPieces:     pt.GetTotalPieces(),
@@ -1366,6 +1459,7 @@ func (pt *peerTaskConductor) UpdateStorage() error {
 			TotalPieces:   pt.GetTotalPieces(),
 			PieceMd5Sign:  pt.GetPieceMd5Sign(),
 			Header:        pt.GetHeader(),
+			PieceSize:     pt.GetPieceSize(),
 		})
 	if err != nil {
 		pt.Log().Errorf("update task to storage manager failed: %s", err)
@@ -0,0 +1,207 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"time"
+
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/v2/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	"d7y.io/dragonfly/v2/client/daemon/pex"
+	"d7y.io/dragonfly/v2/internal/dferrors"
+	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
+)
+
+// registerDecentralized downloads from the peers found by peer exchange when schedulers are down,
+// it returns false when decentralized mode is disabled or no peer has the task.
+func (pt *peerTaskConductor) registerDecentralized(err error) bool {
+	peerSearcher := pt.peerTaskManager.PeerSearchBroadcaster
+	if !pt.SchedulerOption.Decentralized.Enable || peerSearcher == nil || !isSchedulerDown(err) {
+		return false
+	}
+
+	if len(peerSearcher.FindPeers(pt.taskID)) == 0 {
+		pt.Infof("no peer found by peer exchange, skip decentralized mode")
+		return false
+	}
+
+	pt.Warnf("schedulers are unavailable, download from the peers found by peer exchange")
+	pt.span.AddEvent("decentralized mode")
+	pt.schedulerClient = &dummySchedulerClient{}
+	pt.peerPacketStream = &decentralizedPeerPacketStream{
+		ctx:                   pt.ctx,
+		taskID:                pt.taskID,
+		peerSearcher:          peerSearcher,
+		schedulerClient:       pt.peerTaskManager.SchedulerClient,
+		interval:              pt.SchedulerOption.Decentralized.Interval.Duration,
+		backSourceTimeout:     pt.SchedulerOption.ScheduleTimeout.Duration,
+		disableAutoBackSource: pt.SchedulerOption.DisableAutoBackSource,
+		completedLength:       pt.completedLength.Load,
+	}
+	pt.sizeScope = commonv1.SizeScope_NORMAL
+	pt.needBackSource = atomic.NewBool(false)
+	metrics.DecentralizedPeerTaskCount.Add(1)
+	return true
+}
+
+// isSchedulerDown returns whether the error indicates no scheduler is reachable.
+func isSchedulerDown(err error) bool {
+	return isSchedulerUnavailable(err) ||
+		status.Code(err) == codes.DeadlineExceeded ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// decentralizedPeerPacketStream schedules parents with the peers found by peer exchange when schedulers
+// are unavailable. Piece bitmaps of parents are synchronized by pieceTaskSyncManager, and pieces are
+// dispatched by PieceDispatcher like scheduler-driven mode. Schedulers are probed every interval,
+// it returns Code_SchedReregister error to switch back to scheduler-driven mode once a scheduler is available.
+// There is no scheduler to decide back-to-source, so it returns Code_SchedNeedBackSource error when the parents
+// do not deliver any piece within backSourceTimeout.
+type decentralizedPeerPacketStream struct {
+	grpc.ClientStream
+	ctx                   context.Context
+	taskID                string
+	peerSearcher          pex.PeerSearchBroadcaster
+	schedulerClient       schedulerclient.V1
+	interval              time.Duration
+	backSourceTimeout     time.Duration
+	disableAutoBackSource bool
+	completedLength       func() int64
+
+	// sent indicates the first peer packet is sent.
+	sent bool
+	// lastCompletedLength and lastProgressAt record the last progress of peer task.
+	lastCompletedLength int64
+	lastProgressAt      time.Time
+}
+
+func (d *decentralizedPeerPacketStream) Recv() (*schedulerv1.PeerPacket, error) {
+	if !d.sent {
+		d.sent = true
+		d.lastProgressAt = time.Now()
+		if peerPacket := d.peerPacket(); peerPacket != nil {
+			return peerPacket, nil
+		}
+	}
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return nil, io.EOF
+		case <-time.After(d.interval):
+		}
+
+		if d.schedulerAvailable() {
+			return nil, dferrors.New(commonv1.Code_SchedReregister, "scheduler is available")
+		}
+
+		if err := d.checkProgress(); err != nil {
+			return nil, err
+		}
+
+		// Send parents again, the failed synchronizers are re-initialized and the new parents are added.
+		if peerPacket := d.peerPacket(); peerPacket != nil {
+			return peerPacket, nil
+		}
+	}
+}
+
+func (d *decentralizedPeerPacketStream) Send(pr *schedulerv1.PieceResult) error {
+	return nil
+}
+
+func (d *decentralizedPeerPacketStream) CloseSend() error {
+	return nil
+}
+
+// peerPacket returns the peer packet of the peers which have the task, it returns nil when no peer is found.
+func (d *decentralizedPeerPacketStream) peerPacket() *schedulerv1.PeerPacket {
+	var parents []*schedulerv1.PeerPacket_DestPeer
+	for _, peer := range d.peerSearcher.FindPeers(d.taskID) {
+		parents = append(parents, &schedulerv1.PeerPacket_DestPeer{
+			Ip:      peer.IP,
+			RpcPort: peer.RPCPort,
+			PeerId:  peer.PeerID,
+		})
+	}
+
+	if len(parents) == 0 {
+		return nil
+	}
+
+	// Shuffle parents to spread the load of main peer among the peers.
+	rand.Shuffle(len(parents), func(i, j int) {
+		parents[i], parents[j] = parents[j], parents[i]
+	})
+
+	return &schedulerv1.PeerPacket{
+		TaskId:         d.taskID,
+		MainPeer:       parents[0],
+		CandidatePeers: parents[1:],
+		Code:           commonv1.Code_Success,
+	}
+}
+
+// checkProgress returns error when no piece is downloaded from the parents within backSourceTimeout,
+// Code_SchedNeedBackSource error makes peer task back-to-source.
+func (d *decentralizedPeerPacketStream) checkProgress() error {
+	if d.backSourceTimeout <= 0 || d.completedLength == nil {
+		return nil
+	}
+
+	if completedLength := d.completedLength(); completedLength != d.lastCompletedLength {
+		d.lastCompletedLength = completedLength
+		d.lastProgressAt = time.Now()
+		return nil
+	}
+
+	if time.Since(d.lastProgressAt) < d.backSourceTimeout {
+		return nil
+	}
+
+	if d.disableAutoBackSource {
+		return dferrors.Newf(commonv1.Code_ClientScheduleTimeout,
+			"no piece downloaded from peers found by peer exchange in %s, auto back source disabled", d.backSourceTimeout)
+	}
+
+	return dferrors.Newf(commonv1.Code_SchedNeedBackSource,
+		"no piece downloaded from peers found by peer exchange in %s", d.backSourceTimeout)
+}
+
+// schedulerAvailable probes schedulers with StatTask, which does not change the state of schedulers.
+func (d *decentralizedPeerPacketStream) schedulerAvailable() bool {
+	ctx, cancel := context.WithTimeout(d.ctx, d.interval)
+	defer cancel()
+
+	_, err := d.schedulerClient.StatTask(ctx, &schedulerv1.StatTaskRequest{TaskId: d.taskID})
+	if d.ctx.Err() != nil {
+		return false
+	}
+
+	return !isSchedulerDown(err)
+}
@@ -0,0 +1,357 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
+	schedulerv1 "d7y.io/api/v2/pkg/apis/scheduler/v1"
+	schedulerv1mocks "d7y.io/api/v2/pkg/apis/scheduler/v1/mocks"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/pex"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/internal/dferrors"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	schedulerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client/mocks"
+	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
+	sourcemocks "d7y.io/dragonfly/v2/pkg/source/mocks"
+)
+
+type mockPeerSearcher struct {
+	peers []*pex.DestPeer
+}
+
+func (m *mockPeerSearcher) SearchPeer(task string) pex.SearchPeerResult {
+	return pex.SearchPeerResult{Type: pex.SearchPeerResultTypeRemote, Peers: m.peers}
+}
+
+func (m *mockPeerSearcher) FindPeers(task string) []*pex.DestPeer {
+	return m.peers
+}
+
+func (m *mockPeerSearcher) BroadcastPeer(data *dfdaemonv1.PeerMetadata) {}
+
+func (m *mockPeerSearcher) BroadcastPeers(data *dfdaemonv1.PeerExchangeData) {}
+
+func TestIsSchedulerDown(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(isSchedulerDown(status.Error(codes.Unavailable, "")))
+	assert.True(isSchedulerDown(status.Error(codes.DeadlineExceeded, "")))
+	assert.True(isSchedulerDown(context.DeadlineExceeded))
+	assert.False(isSchedulerDown(nil))
+	assert.False(isSchedulerDown(status.Error(codes.NotFound, "")))
+	assert.False(isSchedulerDown(errors.New("foo")))
+}
+
+func TestDecentralizedPeerPacketStream_Recv(t *testing.T) {
+	peerSearcher := &mockPeerSearcher{
+		peers: []*pex.DestPeer{
+			{MemberMeta: &pex.MemberMeta{HostID: "host-1", IP: "127.0.0.1", RPCPort: 65000}, PeerID: "peer-1"},
+			{MemberMeta: &pex.MemberMeta{HostID: "host-2", IP: "127.0.0.2", RPCPort: 65000}, PeerID: "peer-2"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mock   func(m *schedulerclientmocks.MockV1MockRecorder)
+		expect func(t *testing.T, stream *decentralizedPeerPacketStream)
+	}{
+		{
+			name: "send parents found by peer exchange",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {
+				m.StatTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).AnyTimes()
+			},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				for i := 0; i < 2; i++ {
+					peerPacket, err := stream.Recv()
+					assert.Nil(err)
+					assert.Equal(commonv1.Code_Success, peerPacket.Code)
+					assert.Len(append(peerPacket.CandidatePeers, peerPacket.MainPeer), 2)
+					assert.Contains([]string{"peer-1", "peer-2"}, peerPacket.MainPeer.PeerId)
+				}
+			},
+		},
+		{
+			name: "switch back when scheduler is available",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {
+				m.StatTask(gomock.Any(), gomock.Any()).Return(&schedulerv1.Task{}, nil).Times(1)
+			},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				_, err := stream.Recv()
+				assert.Nil(err)
+
+				_, err = stream.Recv()
+				de, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(commonv1.Code_SchedReregister, de.Code)
+			},
+		},
+		{
+			name: "back source when parents do not deliver any piece",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {
+				m.StatTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).AnyTimes()
+			},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				stream.backSourceTimeout = 50 * time.Millisecond
+				stream.completedLength = func() int64 { return 0 }
+
+				var err error
+				for err == nil {
+					_, err = stream.Recv()
+				}
+				de, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(commonv1.Code_SchedNeedBackSource, de.Code)
+			},
+		},
+		{
+			name: "fail when parents do not deliver any piece and auto back source disabled",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {
+				m.StatTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).AnyTimes()
+			},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				stream.backSourceTimeout = 50 * time.Millisecond
+				stream.disableAutoBackSource = true
+				stream.completedLength = func() int64 { return 0 }
+
+				var err error
+				for err == nil {
+					_, err = stream.Recv()
+				}
+				de, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(commonv1.Code_ClientScheduleTimeout, de.Code)
+			},
+		},
+		{
+			name: "keep parents when pieces are delivered",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {
+				m.StatTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).AnyTimes()
+			},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				var completedLength int64
+				stream.backSourceTimeout = 50 * time.Millisecond
+				stream.completedLength = func() int64 {
+					completedLength++
+					return completedLength
+				}
+
+				for i := 0; i < 10; i++ {
+					_, err := stream.Recv()
+					assert.Nil(err)
+				}
+			},
+		},
+		{
+			name: "stream closed with context",
+			mock: func(m *schedulerclientmocks.MockV1MockRecorder) {},
+			expect: func(t *testing.T, stream *decentralizedPeerPacketStream) {
+				assert := assert.New(t)
+				_, err := stream.Recv()
+				assert.Nil(err)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				stream.ctx = ctx
+				_, err = stream.Recv()
+				assert.Equal(io.EOF, err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			schedulerClient := schedulerclientmocks.NewMockV1(ctrl)
+			tc.mock(schedulerClient.EXPECT())
+			tc.expect(t, &decentralizedPeerPacketStream{
+				ctx:             context.Background(),
+				taskID:          "task-1",
+				peerSearcher:    peerSearcher,
+				schedulerClient: schedulerClient,
+				interval:        10 * time.Millisecond,
+			})
+		})
+	}
+}
+
+func TestPeerTaskConductor_Decentralized(t *testing.T) {
+	content := []byte("hello dragonfly decentralized mode")
+	url := "http://localhost/test/decentralized"
+
+	tests := []struct {
+		name   string
+		mock   func(sched *schedulerclientmocks.MockV1MockRecorder, pps *schedulerv1mocks.MockScheduler_ReportPieceResultClient)
+		expect func(t *testing.T, ptc *peerTaskConductor, sched *schedulerclientmocks.MockV1)
+	}{
+		{
+			name: "back source when parents do not deliver any piece",
+			mock: func(sched *schedulerclientmocks.MockV1MockRecorder, pps *schedulerv1mocks.MockScheduler_ReportPieceResultClient) {
+				sched.RegisterPeerTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).Times(1)
+				sched.StatTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).AnyTimes()
+			},
+			expect: func(t *testing.T, ptc *peerTaskConductor, sched *schedulerclientmocks.MockV1) {
+				assert := assert.New(t)
+				assert.True(ptc.needBackSource.Load())
+				assert.IsType(&dummySchedulerClient{}, ptc.schedulerClient)
+			},
+		},
+		{
+			name: "reregister when scheduler is available",
+			mock: func(sched *schedulerclientmocks.MockV1MockRecorder, pps *schedulerv1mocks.MockScheduler_ReportPieceResultClient) {
+				gomock.InOrder(
+					sched.RegisterPeerTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "")).Times(1),
+					sched.RegisterPeerTask(gomock.Any(), gomock.Any()).Return(&schedulerv1.RegisterResult{SizeScope: commonv1.SizeScope_NORMAL}, nil).Times(1),
+				)
+				sched.StatTask(gomock.Any(), gomock.Any()).Return(&schedulerv1.Task{}, nil).AnyTimes()
+				sched.ReportPieceResult(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, ptr *schedulerv1.PeerTaskRequest, opts ...grpc.CallOption) (schedulerv1.Scheduler_ReportPieceResultClient, error) {
+						return pps, nil
+					}).Times(1)
+				sched.ReportPeerResult(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+				pps.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+				pps.EXPECT().Recv().Return(nil, dferrors.New(commonv1.Code_SchedNeedBackSource, "back source")).AnyTimes()
+				pps.EXPECT().CloseSend().Return(nil).AnyTimes()
+			},
+			expect: func(t *testing.T, ptc *peerTaskConductor, sched *schedulerclientmocks.MockV1) {
+				assert := assert.New(t)
+				assert.True(ptc.needBackSource.Load())
+				assert.Equal(sched, ptc.schedulerClient)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			require := require.New(t)
+
+			sourceClient := sourcemocks.NewMockResourceClient(ctrl)
+			sourceClient.EXPECT().GetContentLength(source.RequestEq(url)).Return(int64(len(content)), nil).AnyTimes()
+			sourceClient.EXPECT().Download(source.RequestEq(url)).DoAndReturn(
+				func(request *source.Request) (*source.Response, error) {
+					return source.NewResponse(io.NopCloser(bytes.NewBuffer(content))), nil
+				}).AnyTimes()
+			source.UnRegister("http")
+			require.Nil(source.Register("http", sourceClient, httpprotocol.Adapter))
+			defer func() {
+				source.UnRegister("http")
+				require.Nil(source.Register("http", httpprotocol.NewHTTPSourceClient(), httpprotocol.Adapter))
+			}()
+
+			sched := schedulerclientmocks.NewMockV1(ctrl)
+			pps := schedulerv1mocks.NewMockScheduler_ReportPieceResultClient(ctrl)
+			tc.mock(sched.EXPECT(), pps)
+
+			tempDir, err := os.MkdirTemp("", "d7y-test-*")
+			require.Nil(err)
+			defer os.RemoveAll(tempDir)
+			storageManager, err := storage.NewStorageManager(
+				config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath:       tempDir,
+					TaskExpireTime: util.Duration{Duration: -1 * time.Second},
+				}, func(request storage.CommonTaskRequest) {}, os.FileMode(0700))
+			require.Nil(err)
+			defer storageManager.CleanUp()
+
+			// No daemon listens on the port, so the parent found by peer exchange never delivers any piece.
+			ptm := &peerTaskManager{
+				conductorLock:    &sync.Mutex{},
+				runningPeerTasks: sync.Map{},
+				trafficShaper:    NewTrafficShaper("plain", 0, nil),
+				TaskManagerOption: TaskManagerOption{
+					SchedulerClient: sched,
+					PeerSearchBroadcaster: &mockPeerSearcher{
+						peers: []*pex.DestPeer{
+							{MemberMeta: &pex.MemberMeta{HostID: "host-1", IP: "127.0.0.1", RPCPort: int32(freeport.GetPort())}, PeerID: "peer-1"},
+						},
+					},
+					TaskOption: TaskOption{
+						PeerHost: &schedulerv1.PeerHost{Ip: "127.0.0.1"},
+						PieceManager: &pieceManager{
+							computePieceSize: func(contentLength int64) uint32 {
+								return 16
+							},
+						},
+						StorageManager: storageManager,
+						SchedulerOption: config.SchedulerOption{
+							ScheduleTimeout: util.Duration{Duration: 200 * time.Millisecond},
+							Decentralized: config.DecentralizedOption{
+								Enable:   true,
+								Interval: util.Duration{Duration: 20 * time.Millisecond},
+							},
+						},
+						GRPCDialTimeout: time.Second,
+						GRPCCredentials: insecure.NewCredentials(),
+					},
+				},
+			}
+
+			urlMeta := &commonv1.UrlMeta{}
+			ptc, created, err := ptm.getOrCreatePeerTaskConductor(context.Background(), idgen.TaskIDV1(url, urlMeta),
+				&schedulerv1.PeerTaskRequest{
+					Url:      url,
+					UrlMeta:  urlMeta,
+					PeerId:   "decentralized-peer",
+					PeerHost: &schedulerv1.PeerHost{},
+				}, rate.Inf, nil, nil, "", false)
+			require.Nil(err)
+			require.True(created)
+			require.Nil(ptc.start())
+
+			select {
+			case <-ptc.successCh:
+			case <-ptc.failCh:
+				t.Fatalf("peer task failed: %s", ptc.failedReason)
+			case <-time.After(10 * time.Second):
+				t.Fatal("peer task timeout")
+			}
+			tc.expect(t, ptc, sched)
+		})
+	}
+}
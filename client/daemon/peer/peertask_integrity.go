@@ -0,0 +1,164 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+)
+
+// merkleTreeRetryInterval is the interval to fetch merkle tree again from the parent without it,
+// parent generates the tree after all pieces are downloaded.
+const merkleTreeRetryInterval = 5 * time.Second
+
+var (
+	// errMerkleTreeNotFound indicates the parent does not have the merkle tree of task yet.
+	errMerkleTreeNotFound = errors.New("merkle tree not found")
+
+	// errInvalidMerkleTree indicates the merkle tree of parent fails verification.
+	errInvalidMerkleTree = errors.New("invalid merkle tree")
+)
+
+// merkleTrees caches the verified merkle trees of parents.
+type merkleTrees struct {
+	sync.Mutex
+	// signed is the tree signed by seed peer, it is trusted for pieces of all parents.
+	signed *integrity.MerkleTree
+	// parents are the unsigned trees, pieces of a parent are verified with its own tree.
+	// They only detect pieces corrupted in transfer or storage, a malicious parent serves a tree
+	// matching its own corrupt pieces, RequireSignature is needed against malicious parents.
+	parents map[string]*integrity.MerkleTree
+	// missing records the last time of parents without tree.
+	missing map[string]time.Time
+}
+
+// isIntegrityError returns whether the parent serves corrupt pieces or merkle tree.
+func isIntegrityError(err error) bool {
+	return errors.Is(err, storage.ErrPieceDigestMismatch) || errors.Is(err, errInvalidMerkleTree)
+}
+
+// pieceSha256 returns the sha256 of piece in the merkle tree, the tree is fetched from the parent
+// at its first piece. Empty sha256 means the piece is not verified, when the parent has no tree
+// and signature is not required.
+func (pt *peerTaskConductor) pieceSha256(ctx context.Context, request *DownloadPieceRequest) (string, error) {
+	tree, err := pt.merkleTree(ctx, request)
+	if err != nil {
+		if !pt.Integrity.RequireSignature &&
+			(errors.Is(err, errMerkleTreeNotFound) || errors.Is(err, integrity.ErrUnsigned)) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if total := pt.GetTotalPieces(); total > 0 && int(total) != len(tree.Leaves) {
+		return "", fmt.Errorf("%w: %d leaves, but %d pieces", errInvalidMerkleTree, len(tree.Leaves), total)
+	}
+
+	leaf, err := tree.Leaf(request.piece.PieceNum)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidMerkleTree, err)
+	}
+
+	return leaf, nil
+}
+
+// merkleTree returns the cached tree for the parent, or fetches it from the parent.
+func (pt *peerTaskConductor) merkleTree(ctx context.Context, request *DownloadPieceRequest) (*integrity.MerkleTree, error) {
+	pt.merkleTrees.Lock()
+	if pt.merkleTrees.signed != nil {
+		pt.merkleTrees.Unlock()
+		return pt.merkleTrees.signed, nil
+	}
+
+	if tree, ok := pt.merkleTrees.parents[request.DstPid]; ok {
+		pt.merkleTrees.Unlock()
+		return tree, nil
+	}
+
+	if last, ok := pt.merkleTrees.missing[request.DstPid]; ok && time.Since(last) < merkleTreeRetryInterval {
+		pt.merkleTrees.Unlock()
+		return nil, errMerkleTreeNotFound
+	}
+	pt.merkleTrees.Unlock()
+
+	tree, err := pt.PieceManager.GetMerkleTree(ctx, request)
+
+	pt.merkleTrees.Lock()
+	defer pt.merkleTrees.Unlock()
+	if err != nil {
+		if errors.Is(err, errMerkleTreeNotFound) || errors.Is(err, integrity.ErrUnsigned) {
+			if pt.merkleTrees.missing == nil {
+				pt.merkleTrees.missing = map[string]time.Time{}
+			}
+			pt.merkleTrees.missing[request.DstPid] = time.Now()
+		}
+
+		return nil, err
+	}
+
+	if !tree.Signed() {
+		if pt.merkleTrees.parents == nil {
+			pt.merkleTrees.parents = map[string]*integrity.MerkleTree{}
+		}
+		pt.merkleTrees.parents[request.DstPid] = tree
+		return tree, nil
+	}
+
+	if pt.merkleTrees.signed == nil {
+		pt.merkleTrees.signed = tree
+		pt.Infof("verified merkle tree signed by seed peer from %s, root: %s", request.DstPid, tree.Root)
+
+		// Store the signed tree, then children of current peer can verify pieces with it.
+		if err := pt.GetStorage().UpdateTask(ctx, &storage.UpdateTaskRequest{
+			PeerTaskMetadata: storage.PeerTaskMetadata{
+				PeerID: pt.GetPeerID(),
+				TaskID: pt.GetTaskID(),
+			},
+			Merkle: tree,
+		}); err != nil {
+			pt.Warnf("store merkle tree failed: %s", err)
+		}
+	}
+
+	return pt.merkleTrees.signed, nil
+}
+
+// blockParent blocklists the parent serving corrupt data or pieces with other layout,
+// pieces are not downloaded from it anymore.
+func (pt *peerTaskConductor) blockParent(peerID string, err error) {
+	if _, loaded := pt.blockedParents.LoadOrStore(peerID, struct{}{}); loaded {
+		return
+	}
+
+	pt.Errorf("parent %s is blocklisted: %s", peerID, err)
+	if pt.pieceTaskSyncManager != nil {
+		pt.pieceTaskSyncManager.closePeer(peerID)
+	}
+}
+
+// isBlockedParent returns whether the parent is blocklisted.
+func (pt *peerTaskConductor) isBlockedParent(peerID string) bool {
+	_, ok := pt.blockedParents.Load(peerID)
+	return ok
+}
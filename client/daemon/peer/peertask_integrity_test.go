@@ -0,0 +1,165 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"go.uber.org/mock/gomock"
+
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+func TestPeerTaskConductor_pieceSha256(t *testing.T) {
+	leaves := []string{
+		digest.SHA256FromBytes([]byte("piece-0")),
+		digest.SHA256FromBytes([]byte("piece-1")),
+	}
+
+	tests := []struct {
+		name             string
+		requireSignature bool
+		totalPieces      int32
+		mock             func(pm *MockPieceManagerMockRecorder)
+		expect           func(t *testing.T, pt *peerTaskConductor)
+	}{
+		{
+			name:        "verify pieces with tree of parent",
+			totalPieces: 2,
+			mock: func(pm *MockPieceManagerMockRecorder) {
+				pm.GetMerkleTree(gomock.Any(), gomock.Any()).Return(integrity.NewMerkleTree(leaves), nil).Times(1)
+			},
+			expect: func(t *testing.T, pt *peerTaskConductor) {
+				assert := testifyassert.New(t)
+				for i, leaf := range leaves {
+					sha256, err := pt.pieceSha256(context.Background(), mockIntegrityRequest("parent", int32(i)))
+					assert.Nil(err)
+					assert.Equal(leaf, sha256)
+				}
+			},
+		},
+		{
+			name:        "parent without tree",
+			totalPieces: 2,
+			mock: func(pm *MockPieceManagerMockRecorder) {
+				pm.GetMerkleTree(gomock.Any(), gomock.Any()).Return(nil, errMerkleTreeNotFound).Times(1)
+			},
+			expect: func(t *testing.T, pt *peerTaskConductor) {
+				assert := testifyassert.New(t)
+				for i := range leaves {
+					sha256, err := pt.pieceSha256(context.Background(), mockIntegrityRequest("parent", int32(i)))
+					assert.Nil(err)
+					assert.Empty(sha256)
+				}
+			},
+		},
+		{
+			name:             "parent without tree when requiring signature",
+			requireSignature: true,
+			totalPieces:      2,
+			mock: func(pm *MockPieceManagerMockRecorder) {
+				pm.GetMerkleTree(gomock.Any(), gomock.Any()).Return(nil, integrity.ErrUnsigned).Times(1)
+			},
+			expect: func(t *testing.T, pt *peerTaskConductor) {
+				assert := testifyassert.New(t)
+				_, err := pt.pieceSha256(context.Background(), mockIntegrityRequest("parent", 0))
+				assert.ErrorIs(err, integrity.ErrUnsigned)
+				assert.False(isIntegrityError(err))
+			},
+		},
+		{
+			name:        "leaves do not match total pieces",
+			totalPieces: 3,
+			mock: func(pm *MockPieceManagerMockRecorder) {
+				pm.GetMerkleTree(gomock.Any(), gomock.Any()).Return(integrity.NewMerkleTree(leaves), nil).Times(1)
+			},
+			expect: func(t *testing.T, pt *peerTaskConductor) {
+				assert := testifyassert.New(t)
+				_, err := pt.pieceSha256(context.Background(), mockIntegrityRequest("parent", 0))
+				assert.True(isIntegrityError(err))
+			},
+		},
+		{
+			name:        "trees of parents are cached separately",
+			totalPieces: 2,
+			mock: func(pm *MockPieceManagerMockRecorder) {
+				pm.GetMerkleTree(gomock.Any(), gomock.Any()).Return(integrity.NewMerkleTree(leaves), nil).Times(2)
+			},
+			expect: func(t *testing.T, pt *peerTaskConductor) {
+				assert := testifyassert.New(t)
+				for _, parent := range []string{"parent-0", "parent-1", "parent-0"} {
+					sha256, err := pt.pieceSha256(context.Background(), mockIntegrityRequest(parent, 1))
+					assert.Nil(err)
+					assert.Equal(leaves[1], sha256)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pieceManager := NewMockPieceManager(ctrl)
+			tc.mock(pieceManager.EXPECT())
+			pt := &peerTaskConductor{
+				TaskOption: TaskOption{
+					PieceManager: pieceManager,
+					Integrity: config.IntegrityOption{
+						Enable:           true,
+						RequireSignature: tc.requireSignature,
+					},
+				},
+				totalPiece:          atomic.NewInt32(tc.totalPieces),
+				SugaredLoggerOnWith: logger.With("test", "test"),
+			}
+			tc.expect(t, pt)
+		})
+	}
+}
+
+func TestPeerTaskConductor_blockParent(t *testing.T) {
+	assert := testifyassert.New(t)
+	pt := &peerTaskConductor{
+		SugaredLoggerOnWith: logger.With("test", "test"),
+	}
+
+	assert.False(pt.isBlockedParent("parent"))
+	pt.blockParent("parent", storage.ErrPieceDigestMismatch)
+	assert.True(pt.isBlockedParent("parent"))
+	assert.False(pt.isBlockedParent("other"))
+}
+
+func mockIntegrityRequest(parent string, num int32) *DownloadPieceRequest {
+	return &DownloadPieceRequest{
+		piece: &commonv1.PieceInfo{
+			PieceNum: num,
+		},
+		TaskID: "task-0",
+		DstPid: parent,
+	}
+}
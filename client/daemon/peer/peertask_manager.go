@@ -35,6 +35,7 @@ import (
 	dfdaemonv1 "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
 	schedulerv1 "d7y.io/api/v2/pkg/apis/scheduler/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/pex"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
@@ -71,6 +72,9 @@ type TaskManager interface {
 
 	GetPieceManager() PieceManager
 
+	// GetTrafficShaper returns the traffic shaper which allocates bandwidth for running tasks
+	GetTrafficShaper() TrafficShaper
+
 	// Stop stops the PeerTaskManager
 	Stop(ctx context.Context) error
 }
@@ -92,6 +96,9 @@ type Task interface {
 	GetContentLength() int64
 	SetContentLength(int64)
 
+	GetPieceSize() uint32
+	SetPieceSize(uint32)
+
 	AddTraffic(uint64)
 	GetTraffic() uint64
 
@@ -133,6 +140,8 @@ type TaskManagerOption struct {
 	PerPeerRateLimit  rate.Limit
 	TotalRateLimit    rate.Limit
 	TrafficShaperType string
+	// ApplicationBandwidth is the download budgets of applications, enforced by the sampling traffic shaper
+	ApplicationBandwidth []*config.ApplicationBandwidth
 	// Multiplex indicates to reuse the data of completed peer tasks
 	Multiplex bool
 	// Prefetch indicates to prefetch the whole files of ranged requests
@@ -148,7 +157,8 @@ func NewPeerTaskManager(opt *TaskManagerOption) (TaskManager, error) {
 		TaskManagerOption: *opt,
 		runningPeerTasks:  sync.Map{},
 		conductorLock:     &sync.Mutex{},
-		trafficShaper:     NewTrafficShaper(opt.TrafficShaperType, opt.TotalRateLimit, util.ComputePieceSize),
+		trafficShaper: NewTrafficShaper(opt.TrafficShaperType, opt.TotalRateLimit, util.ComputePieceSize,
+			WithApplicationBandwidth(opt.ApplicationBandwidth)),
 	}
 	ptm.trafficShaper.Start()
 	return ptm, nil
@@ -485,6 +495,10 @@ func (ptm *peerTaskManager) StatTask(ctx context.Context, taskID string) (*sched
 	return ptm.SchedulerClient.StatTask(ctx, req)
 }
 
+func (ptm *peerTaskManager) GetTrafficShaper() TrafficShaper {
+	return ptm.trafficShaper
+}
+
 func (ptm *peerTaskManager) GetPieceManager() PieceManager {
 	return ptm.PieceManager
 }
@@ -74,6 +74,20 @@ func (mr *MockTaskManagerMockRecorder) GetPieceManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPieceManager", reflect.TypeOf((*MockTaskManager)(nil).GetPieceManager))
 }
 
+// GetTrafficShaper mocks base method.
+func (m *MockTaskManager) GetTrafficShaper() TrafficShaper {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrafficShaper")
+	ret0, _ := ret[0].(TrafficShaper)
+	return ret0
+}
+
+// GetTrafficShaper indicates an expected call of GetTrafficShaper.
+func (mr *MockTaskManagerMockRecorder) GetTrafficShaper() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrafficShaper", reflect.TypeOf((*MockTaskManager)(nil).GetTrafficShaper))
+}
+
 // IsPeerTaskRunning mocks base method.
 func (m *MockTaskManager) IsPeerTaskRunning(taskID, peerID string) (Task, bool) {
 	m.ctrl.T.Helper()
@@ -272,6 +286,20 @@ func (mr *MockTaskMockRecorder) GetPieceMd5Sign() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPieceMd5Sign", reflect.TypeOf((*MockTask)(nil).GetPieceMd5Sign))
 }
 
+// GetPieceSize mocks base method.
+func (m *MockTask) GetPieceSize() uint32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPieceSize")
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// GetPieceSize indicates an expected call of GetPieceSize.
+func (mr *MockTaskMockRecorder) GetPieceSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPieceSize", reflect.TypeOf((*MockTask)(nil).GetPieceSize))
+}
+
 // GetStorage mocks base method.
 func (m *MockTask) GetStorage() storage.TaskStorageDriver {
 	m.ctrl.T.Helper()
@@ -390,6 +418,18 @@ func (mr *MockTaskMockRecorder) SetPieceMd5Sign(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPieceMd5Sign", reflect.TypeOf((*MockTask)(nil).SetPieceMd5Sign), arg0)
 }
 
+// SetPieceSize mocks base method.
+func (m *MockTask) SetPieceSize(arg0 uint32) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPieceSize", arg0)
+}
+
+// SetPieceSize indicates an expected call of SetPieceSize.
+func (mr *MockTaskMockRecorder) SetPieceSize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPieceSize", reflect.TypeOf((*MockTask)(nil).SetPieceSize), arg0)
+}
+
 // SetTotalPieces mocks base method.
 func (m *MockTask) SetTotalPieces(arg0 int32) {
 	m.ctrl.T.Helper()
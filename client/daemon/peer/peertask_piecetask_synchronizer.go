@@ -21,11 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
+	"golang.org/x/exp/slices"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -40,6 +42,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	"d7y.io/dragonfly/v2/pkg/types"
 )
 
 type pieceTaskSyncManager struct {
@@ -65,6 +68,9 @@ type pieceTaskSynchronizer struct {
 	grpcInitError     atomic.Value
 	peerTaskConductor *peerTaskConductor
 	pieceRequestQueue PieceDispatcher
+	// quicPort is the udp port of HTTP/3 upload service announced by the dest peer,
+	// empty means the dest peer does not serve pieces via QUIC.
+	quicPort string
 }
 
 type synchronizerWatchdog struct {
@@ -79,9 +85,14 @@ type pieceTaskSynchronizerError struct {
 
 // FIXME for compatibility, sync will be called after the dfdaemonclient.GetPieceTasks deprecated and the pieceTaskPoller removed
 func (s *pieceTaskSyncManager) syncPeers(destPeers []*schedulerv1.PeerPacket_DestPeer, desiredPiece int32) {
+	// skip parents serving corrupt data
+	destPeers = slices.DeleteFunc(slices.Clone(destPeers), func(peer *schedulerv1.PeerPacket_DestPeer) bool {
+		return s.peerTaskConductor.isBlockedParent(peer.PeerId)
+	})
+
 	s.Lock()
 	defer func() {
-		if s.peerTaskConductor.WatchdogTimeout > 0 {
+		if s.peerTaskConductor.WatchdogTimeout > 0 && len(destPeers) > 0 {
 			s.resetWatchdog(destPeers[0])
 		}
 		s.Unlock()
@@ -209,6 +220,16 @@ func (s *pieceTaskSyncManager) reportInvalidPeer(destPeer *schedulerv1.PeerPacke
 	}
 }
 
+// closePeer closes the synchronizer of the peer.
+func (s *pieceTaskSyncManager) closePeer(peerID string) {
+	s.Lock()
+	defer s.Unlock()
+	if worker, ok := s.workers[peerID]; ok {
+		worker.close()
+		delete(s.workers, peerID)
+	}
+}
+
 // acquire send the target piece to other peers
 func (s *pieceTaskSyncManager) acquire(request *commonv1.PieceTaskRequest) (attempt int, success int) {
 	s.RLock()
@@ -339,6 +360,11 @@ func (s *pieceTaskSynchronizer) closeGRPC() {
 }
 
 func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.PiecePacket) {
+	// parents downloading from source may select different piece sizes, pieces with other layout corrupt the content
+	if err := s.peerTaskConductor.checkPieceLayout(piecePacket); err != nil {
+		s.peerTaskConductor.blockParent(piecePacket.DstPid, err)
+		return
+	}
 	s.peerTaskConductor.updateMetadata(piecePacket)
 
 	pieceCount := len(piecePacket.PieceInfos)
@@ -351,6 +377,18 @@ func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.Piece
 		}
 		return
 	}
+	if s.peerTaskConductor.isBlockedParent(piecePacket.DstPid) {
+		s.Warnf("dest peer %s is blocklisted, skip dispatch piece request", piecePacket.DstPid)
+		return
+	}
+
+	var quicAddr string
+	if s.quicPort != "" {
+		if host, _, err := net.SplitHostPort(piecePacket.DstAddr); err == nil {
+			quicAddr = net.JoinHostPort(host, s.quicPort)
+		}
+	}
+
 	for _, piece := range piecePacket.PieceInfos {
 		s.Infof("got piece %d from %s/%s, digest: %s, start: %d, size: %d",
 			piece.PieceNum, piecePacket.DstAddr, piecePacket.DstPid, piece.PieceMd5, piece.RangeStart, piece.RangeSize)
@@ -361,13 +399,14 @@ func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.Piece
 		}
 		s.peerTaskConductor.requestedPiecesLock.Unlock()
 		req := &DownloadPieceRequest{
-			storage: s.peerTaskConductor.GetStorage(),
-			piece:   piece,
-			log:     s.peerTaskConductor.Log(),
-			TaskID:  s.peerTaskConductor.GetTaskID(),
-			PeerID:  s.peerTaskConductor.GetPeerID(),
-			DstPid:  piecePacket.DstPid,
-			DstAddr: piecePacket.DstAddr,
+			storage:  s.peerTaskConductor.GetStorage(),
+			piece:    piece,
+			log:      s.peerTaskConductor.Log(),
+			TaskID:   s.peerTaskConductor.GetTaskID(),
+			PeerID:   s.peerTaskConductor.GetPeerID(),
+			DstPid:   piecePacket.DstPid,
+			DstAddr:  piecePacket.DstAddr,
+			QUICAddr: quicAddr,
 		}
 
 		s.pieceRequestQueue.Put(req)
@@ -388,6 +427,14 @@ func (s *pieceTaskSynchronizer) receive() {
 		piecePacket *commonv1.PiecePacket
 		err         error
 	)
+
+	// The dest peer announces its QUIC port with the header of stream.
+	if md, err := s.syncPiecesStream.Header(); err == nil {
+		if values := md.Get(types.HostQUICPortMetadataKey); len(values) > 0 {
+			s.quicPort = values[0]
+		}
+	}
+
 	for {
 		piecePacket, err = s.syncPiecesStream.Recv()
 		if err != nil {
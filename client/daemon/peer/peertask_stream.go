@@ -334,7 +334,10 @@ func (s *resumeStreamTask) Start(ctx context.Context) (io.ReadCloser, map[string
 	attr[config.HeaderDragonflyTask] = s.peerTaskConductor.taskID
 	attr[config.HeaderDragonflyPeer] = s.peerTaskConductor.peerID
 
-	pieceSize := s.computePieceSize(s.peerTaskConductor.GetContentLength())
+	pieceSize := s.peerTaskConductor.GetPieceSize()
+	if pieceSize == 0 {
+		pieceSize = s.computePieceSize(s.peerTaskConductor.GetContentLength())
+	}
 	nextPiece := int32(s.skipBytes / int64(pieceSize))
 	skipBytesInNextPiece := s.skipBytes % int64(pieceSize)
 
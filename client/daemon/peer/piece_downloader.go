@@ -22,22 +22,30 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/go-http-utils/headers"
+	"github.com/klauspost/compress/zstd"
+	"github.com/quic-go/quic-go/http3"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/digest"
+	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
 	"d7y.io/dragonfly/v2/pkg/source"
 )
 
@@ -50,6 +58,11 @@ type DownloadPieceRequest struct {
 	DstPid     string
 	DstAddr    string
 	CalcDigest bool
+	// Sha256 is the sha256 of piece in merkle tree, empty means the piece is not verified with merkle tree
+	Sha256 string
+	// QUICAddr is the HTTP/3 address of parent announced by its host info,
+	// empty means the parent does not serve pieces via QUIC
+	QUICAddr string
 }
 
 type DownloadPieceResult struct {
@@ -66,6 +79,7 @@ type DownloadPieceResult struct {
 
 type PieceDownloader interface {
 	DownloadPiece(context.Context, *DownloadPieceRequest) (io.Reader, io.Closer, error)
+	DownloadMerkleTree(context.Context, *DownloadPieceRequest) (*integrity.MerkleTree, error)
 }
 
 type PieceDownloaderOption func(*pieceDownloader) error
@@ -73,6 +87,14 @@ type PieceDownloaderOption func(*pieceDownloader) error
 type pieceDownloader struct {
 	scheme     string
 	httpClient *http.Client
+
+	// quicClient downloads pieces via HTTP/3, it is nil when QUIC is disabled.
+	quicClient *http.Client
+	// quicFailures maps the parent upload address to the time of the last QUIC connection failure.
+	quicFailures sync.Map
+
+	// compression indicates to accept zstd compressed pieces.
+	compression bool
 }
 
 type pieceDownloadError struct {
@@ -128,6 +150,9 @@ func (e *backSourceError) Error() string {
 
 var _ PieceDownloader = (*pieceDownloader)(nil)
 
+// quicRetryInterval is the interval to retry QUIC after the QUIC connection with parent failed.
+const quicRetryInterval = 5 * time.Minute
+
 var defaultTransport http.RoundTripper = &http.Transport{
 	Proxy: http.ProxyFromEnvironment,
 	DialContext: (&net.Dialer{
@@ -142,7 +167,7 @@ var defaultTransport http.RoundTripper = &http.Transport{
 	ExpectContinueTimeout: 2 * time.Second,
 }
 
-func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool) PieceDownloader {
+func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool, opts ...PieceDownloaderOption) PieceDownloader {
 	pd := &pieceDownloader{
 		scheme: "http",
 		httpClient: &http.Client{
@@ -159,15 +184,80 @@ func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool) PieceD
 		}
 	}
 
+	for _, opt := range opts {
+		if err := opt(pd); err != nil {
+			logger.Errorf("apply piece downloader option failed: %s", err)
+		}
+	}
+
 	return pd
 }
 
+// WithClientCertificate presents the certificate to parents when downloading pieces via https,
+// it must be applied before WithQUICTransport.
+func WithClientCertificate(cert tls.Certificate) PieceDownloaderOption {
+	return func(pd *pieceDownloader) error {
+		if pd.scheme != "https" {
+			return errors.New("client certificate requires https")
+		}
+
+		defaultTransport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{cert}
+		return nil
+	}
+}
+
+// WithZstdCompression accepts zstd compressed pieces from parents to save bandwidth,
+// pieces are decompressed before digest verification.
+func WithZstdCompression() PieceDownloaderOption {
+	return func(pd *pieceDownloader) error {
+		pd.compression = true
+		return nil
+	}
+}
+
+// WithQUICTransport downloads pieces via QUIC from parents which announce HTTP/3 support,
+// piece requests to the same parent are multiplexed over one QUIC connection.
+func WithQUICTransport() PieceDownloaderOption {
+	return func(pd *pieceDownloader) error {
+		// Upload servers without certify use self-signed certificates,
+		// skip verification as plain HTTP does not verify parents either.
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		if pd.scheme == "https" {
+			tlsConfig = defaultTransport.(*http.Transport).TLSClientConfig.Clone()
+		}
+
+		pd.quicClient = &http.Client{
+			Transport: &http3.Transport{
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: pd.httpClient.Timeout,
+		}
+		return nil
+	}
+}
+
 func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceRequest) (io.Reader, io.Closer, error) {
-	httpRequest, err := p.buildDownloadPieceHTTPRequest(ctx, req)
+	if p.quicClient != nil && req.QUICAddr != "" && !p.isQUICFailed(req.DstAddr) {
+		reader, closer, err := p.downloadPiece(ctx, req, p.quicClient, "https", req.QUICAddr)
+		if !isConnectionError(err) {
+			return reader, closer, err
+		}
+
+		// Parent is unreachable via QUIC, e.g. udp is blocked, fall back to HTTP.
+		logger.Warnf("task id: %s, piece num: %d, dst: %s, download piece via quic failed, fall back to http: %s",
+			req.TaskID, req.piece.PieceNum, req.DstAddr, err)
+		p.quicFailures.Store(req.DstAddr, time.Now())
+	}
+
+	return p.downloadPiece(ctx, req, p.httpClient, p.scheme, req.DstAddr)
+}
+
+func (p *pieceDownloader) downloadPiece(ctx context.Context, req *DownloadPieceRequest, client *http.Client, scheme, host string) (io.Reader, io.Closer, error) {
+	httpRequest, err := p.buildDownloadPieceHTTPRequest(ctx, req, scheme, host)
 	if err != nil {
 		return nil, nil, err
 	}
-	resp, err := p.httpClient.Do(httpRequest)
+	resp, err := client.Do(httpRequest)
 	if err != nil {
 		logger.Errorf("task id: %s, piece num: %d, dst: %s, download piece failed: %s",
 			req.TaskID, req.piece.PieceNum, req.DstAddr, err)
@@ -189,9 +279,20 @@ func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceR
 		}
 	}
 	reader, closer := resp.Body.(io.Reader), resp.Body.(io.Closer)
+	if resp.Header.Get(headers.ContentEncoding) == nethttp.EncodingZstd {
+		decoder, err := zstd.NewReader(resp.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			_ = resp.Body.Close()
+			req.log.Errorf("init zstd decoder error: %s", err.Error())
+			return nil, nil, err
+		}
+
+		reader, closer = decoder, &zstdCloser{decoder: decoder, body: resp.Body}
+	}
+
 	if req.CalcDigest {
 		req.log.Debugf("calculate digest for piece %d, digest: %s", req.piece.PieceNum, req.piece.PieceMd5)
-		reader, err = digest.NewReader(digest.AlgorithmMD5, io.LimitReader(resp.Body, int64(req.piece.RangeSize)), digest.WithEncoded(req.piece.PieceMd5), digest.WithLogger(req.log))
+		reader, err = digest.NewReader(digest.AlgorithmMD5, io.LimitReader(reader, int64(req.piece.RangeSize)), digest.WithEncoded(req.piece.PieceMd5), digest.WithLogger(req.log))
 		if err != nil {
 			_ = closer.Close()
 			req.log.Errorf("init digest reader error: %s", err.Error())
@@ -201,14 +302,92 @@ func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceR
 	return reader, closer, nil
 }
 
-func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest) (*http.Request, error) {
+// DownloadMerkleTree downloads the merkle tree of task from the parent.
+func (p *pieceDownloader) DownloadMerkleTree(ctx context.Context, req *DownloadPieceRequest) (*integrity.MerkleTree, error) {
+	if len(req.TaskID) <= 3 {
+		return nil, fmt.Errorf("invalid task id")
+	}
+
+	targetURL := url.URL{
+		Scheme:   p.scheme,
+		Host:     req.DstAddr,
+		Path:     fmt.Sprintf("merkle/%s/%s", req.TaskID[:3], req.TaskID),
+		RawQuery: fmt.Sprintf("peerId=%s", req.DstPid),
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, &pieceDownloadError{
+			target:          targetURL.String(),
+			err:             err,
+			connectionError: true,
+		}
+	}
+	defer resp.Body.Close()
+
+	// Parents without merkle tree or of old versions respond not found.
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, errMerkleTreeNotFound
+	}
+
+	if resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, &pieceDownloadError{
+			target:     targetURL.String(),
+			status:     resp.Status,
+			statusCode: resp.StatusCode,
+		}
+	}
+
+	var tree integrity.MerkleTree
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+// zstdCloser closes the zstd decoder and the response body of compressed piece.
+type zstdCloser struct {
+	decoder *zstd.Decoder
+	body    io.Closer
+}
+
+func (c *zstdCloser) Close() error {
+	c.decoder.Close()
+	return c.body.Close()
+}
+
+// isQUICFailed returns whether the QUIC connection with parent failed in the retry interval,
+// the pieces of parent are downloaded via HTTP until the retry interval elapsed.
+func (p *pieceDownloader) isQUICFailed(dstAddr string) bool {
+	failedAt, ok := p.quicFailures.Load(dstAddr)
+	if !ok {
+		return false
+	}
+
+	if time.Since(failedAt.(time.Time)) < quicRetryInterval {
+		return true
+	}
+
+	p.quicFailures.Delete(dstAddr)
+	return false
+}
+
+func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest, scheme, host string) (*http.Request, error) {
 	if len(d.TaskID) <= 3 {
 		return nil, fmt.Errorf("invalid task id")
 	}
 	// FIXME switch to https when tls enabled
 	targetURL := url.URL{
-		Scheme:   p.scheme,
-		Host:     d.DstAddr,
+		Scheme:   scheme,
+		Host:     host,
 		Path:     fmt.Sprintf("download/%s/%s", d.TaskID[:3], d.TaskID),
 		RawQuery: fmt.Sprintf("peerId=%s", d.DstPid),
 	}
@@ -220,6 +399,11 @@ func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *
 	req.Header.Add("Range", fmt.Sprintf("bytes=%d-%d",
 		d.piece.RangeStart, d.piece.RangeStart+uint64(d.piece.RangeSize)-1))
 
+	// Parents compress the piece when it is worth, the range is still of the raw data.
+	if p.compression {
+		req.Header.Set(headers.AcceptEncoding, nethttp.EncodingZstd)
+	}
+
 	// inject trace id into request header
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 	return req, nil
@@ -14,6 +14,7 @@ import (
 	io "io"
 	reflect "reflect"
 
+	integrity "d7y.io/dragonfly/v2/client/daemon/integrity"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +42,21 @@ func (m *MockPieceDownloader) EXPECT() *MockPieceDownloaderMockRecorder {
 	return m.recorder
 }
 
+// DownloadMerkleTree mocks base method.
+func (m *MockPieceDownloader) DownloadMerkleTree(arg0 context.Context, arg1 *DownloadPieceRequest) (*integrity.MerkleTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadMerkleTree", arg0, arg1)
+	ret0, _ := ret[0].(*integrity.MerkleTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadMerkleTree indicates an expected call of DownloadMerkleTree.
+func (mr *MockPieceDownloaderMockRecorder) DownloadMerkleTree(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadMerkleTree", reflect.TypeOf((*MockPieceDownloader)(nil).DownloadMerkleTree), arg0, arg1)
+}
+
 // DownloadPiece mocks base method.
 func (m *MockPieceDownloader) DownloadPiece(arg0 context.Context, arg1 *DownloadPieceRequest) (io.Reader, io.Closer, error) {
 	m.ctrl.T.Helper()
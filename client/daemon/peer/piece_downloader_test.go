@@ -17,6 +17,7 @@
 package peer
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
@@ -24,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -32,6 +34,7 @@ import (
 	"time"
 
 	"github.com/go-http-utils/headers"
+	"github.com/klauspost/compress/zstd"
 	testifyassert "github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/status"
@@ -305,3 +308,97 @@ func TestPieceDownloader_DownloadPiece(t *testing.T) {
 		server.Close()
 	}
 }
+
+func TestPieceDownloader_DownloadPieceFallbackFromQUIC(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := []byte("test test ")
+
+	// Announce a QUIC port which no one listens on.
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	_, quicPort, err := net.SplitHostPort(udpConn.LocalAddr().String())
+	require.Nil(t, err)
+	require.Nil(t, udpConn.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+
+	pd := NewPieceDownloader(30*time.Second, nil, WithQUICTransport()).(*pieceDownloader)
+	pd.quicClient.Timeout = 500 * time.Millisecond
+
+	download := func() {
+		r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+			TaskID:   "task-0",
+			DstAddr:  addr.Host,
+			QUICAddr: net.JoinHostPort("127.0.0.1", quicPort),
+			piece: &commonv1.PieceInfo{
+				RangeStart: 0,
+				RangeSize:  uint32(len(data)),
+			},
+			log: logger.With("test", "test"),
+		})
+		require.Nil(t, err)
+		defer c.Close()
+
+		result, err := io.ReadAll(r)
+		assert.Nil(err)
+		assert.Equal(data, result)
+	}
+
+	// The first piece fails via QUIC and falls back to HTTP.
+	download()
+	assert.True(pd.isQUICFailed(addr.Host))
+
+	// The following pieces are downloaded via HTTP until the retry interval elapsed.
+	download()
+	assert.True(pd.isQUICFailed(addr.Host))
+
+	pd.quicFailures.Store(addr.Host, time.Now().Add(-quicRetryInterval))
+	assert.False(pd.isQUICFailed(addr.Host))
+}
+
+func TestPieceDownloader_DownloadPieceCompressed(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := bytes.Repeat([]byte("test test "), 1024)
+	hash := md5.Sum(data)
+
+	encoder, err := zstd.NewWriter(nil)
+	require.Nil(t, err)
+	compressed := encoder.EncodeAll(data, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(nethttp.EncodingZstd, r.Header.Get(headers.AcceptEncoding))
+		w.Header().Set(headers.ContentEncoding, nethttp.EncodingZstd)
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(compressed)))
+		if _, err := w.Write(compressed); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+
+	pd := NewPieceDownloader(30*time.Second, nil, WithZstdCompression())
+	r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+		TaskID:     "task-0",
+		DstAddr:    addr.Host,
+		CalcDigest: true,
+		piece: &commonv1.PieceInfo{
+			RangeStart: 0,
+			RangeSize:  uint32(len(data)),
+			PieceMd5:   hex.EncodeToString(hash[:]),
+		},
+		log: logger.With("test", "test"),
+	})
+	require.Nil(t, err)
+	defer c.Close()
+
+	result, err := io.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal(data, result)
+}
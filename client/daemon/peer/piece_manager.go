@@ -20,6 +20,7 @@ package peer
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -44,6 +45,7 @@ import (
 	schedulerv1 "d7y.io/api/v2/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
@@ -57,8 +59,9 @@ import (
 type PieceManager interface {
 	DownloadSource(ctx context.Context, pt Task, request *schedulerv1.PeerTaskRequest, parsedRange *nethttp.Range) error
 	DownloadPiece(ctx context.Context, request *DownloadPieceRequest) (*DownloadPieceResult, error)
+	GetMerkleTree(ctx context.Context, request *DownloadPieceRequest) (*integrity.MerkleTree, error)
 	ImportFile(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, req *dfdaemonv1.ImportTaskRequest) error
-	Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, contentLength int64, reader io.Reader) error
+	Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, url string, urlMeta *commonv1.UrlMeta, contentLength int64, reader io.Reader) error
 }
 
 type pieceManager struct {
@@ -69,6 +72,14 @@ type pieceManager struct {
 	concurrentOption  *config.ConcurrentOption
 	syncPieceViaHTTPS bool
 	certPool          *x509.CertPool
+	certificate       *tls.Certificate
+	quic              bool
+	compression       bool
+	pieceSizeSelector *pieceSizeSelector
+	// integrity calculates sha256 of pieces, and verifies merkle trees of parents with integrityRoots
+	integrity       config.IntegrityOption
+	integrityCACert string
+	integrityRoots  *x509.CertPool
 }
 
 type PieceManagerOption func(*pieceManager)
@@ -83,7 +94,28 @@ func NewPieceManager(pieceDownloadTimeout time.Duration, opts ...PieceManagerOpt
 		opt(pm)
 	}
 
-	pm.pieceDownloader = NewPieceDownloader(pieceDownloadTimeout, pm.certPool)
+	if pm.integrityCACert != "" {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(pm.integrityCACert)) {
+			return nil, errors.New("invalid integrity ca cert pem")
+		}
+		pm.integrityRoots = certPool
+	}
+
+	var pdOpts []PieceDownloaderOption
+	if pm.certificate != nil {
+		pdOpts = append(pdOpts, WithClientCertificate(*pm.certificate))
+	}
+
+	if pm.quic {
+		pdOpts = append(pdOpts, WithQUICTransport())
+	}
+
+	if pm.compression {
+		pdOpts = append(pdOpts, WithZstdCompression())
+	}
+
+	pm.pieceDownloader = NewPieceDownloader(pieceDownloadTimeout, pm.certPool, pdOpts...)
 
 	return pm, nil
 }
@@ -156,6 +188,33 @@ func WithConcurrentOption(opt *config.ConcurrentOption) func(*pieceManager) {
 	}
 }
 
+// WithQUIC enables downloading pieces via QUIC from parents which support it.
+func WithQUIC(enable bool) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		logger.Infof("set quic to %t for piece manager", enable)
+		pm.quic = enable
+	}
+}
+
+// WithCompression enables accepting zstd compressed pieces from parents,
+// the pieces are decompressed before verification and storing.
+func WithCompression(enable bool) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		logger.Infof("set compression to %t for piece manager", enable)
+		pm.compression = enable
+	}
+}
+
+func WithPieceSizeOption(opt config.PieceSizeOption) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		if len(opt.Rules) == 0 && !opt.Adaptive {
+			return
+		}
+		logger.Infof("set piece size rules %d, adaptive %t for piece manager", len(opt.Rules), opt.Adaptive)
+		pm.pieceSizeSelector = newPieceSizeSelector(opt)
+	}
+}
+
 func WithSyncPieceViaHTTPS(caCertPEM string) func(*pieceManager) {
 	return func(pm *pieceManager) {
 		logger.Infof("enable syncPieceViaHTTPS for piece manager")
@@ -168,6 +227,46 @@ func WithSyncPieceViaHTTPS(caCertPEM string) func(*pieceManager) {
 	}
 }
 
+// WithPeerCertificate sets the client certificate presented to parents when syncing pieces via https,
+// parents authorize piece requests with the identity of certificate.
+func WithPeerCertificate(certPEM, keyPEM string) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		logger.Infof("set peer certificate for piece manager")
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			logger.Fatalf("invalid peer certificate: %s", err)
+		}
+		pm.certificate = &cert
+	}
+}
+
+// WithIntegrity sets the integrity option, the signer of merkle trees is verified with the ca cert.
+func WithIntegrity(opt config.IntegrityOption, caCertPEM string) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		logger.Infof("set integrity option for piece manager, enable: %t, require signature: %t", opt.Enable, opt.RequireSignature)
+		pm.integrity = opt
+		pm.integrityCACert = caCertPEM
+	}
+}
+
+// GetMerkleTree downloads the merkle tree of task from the parent and verifies it.
+func (pm *pieceManager) GetMerkleTree(ctx context.Context, request *DownloadPieceRequest) (*integrity.MerkleTree, error) {
+	tree, err := pm.pieceDownloader.DownloadMerkleTree(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := integrity.Verify(request.TaskID, tree, pm.integrityRoots, pm.integrity.RequireSignature); err != nil {
+		if errors.Is(err, integrity.ErrUnsigned) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %w", errInvalidMerkleTree, err)
+	}
+
+	return tree, nil
+}
+
 func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPieceRequest) (*DownloadPieceResult, error) {
 	var result = &DownloadPieceResult{
 		Size:       -1,
@@ -208,7 +307,8 @@ func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPiec
 
 	// 2. save to storage
 	writePieceRequest := &storage.WritePieceRequest{
-		Reader: r,
+		Reader:     r,
+		CalcSha256: pm.integrity.Enable,
 		PeerTaskMetadata: storage.PeerTaskMetadata{
 			PeerID: request.PeerID,
 			TaskID: request.TaskID,
@@ -216,6 +316,7 @@ func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPiec
 		PieceMetadata: storage.PieceMetadata{
 			Num:    request.piece.PieceNum,
 			Md5:    request.piece.PieceMd5,
+			Sha256: request.Sha256,
 			Offset: request.piece.PieceOffset,
 			Range: nethttp.Range{
 				Start:  int64(request.piece.RangeStart),
@@ -260,6 +361,11 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 			return
 		}
 	}
+	var timedReader *sourceReader
+	if pm.pieceSizeSelector != nil {
+		timedReader = &sourceReader{Reader: reader}
+		reader = timedReader
+	}
 	if pm.calculateDigest {
 		pt.Log().Debugf("piece %d calculate digest", pieceNum)
 		reader, _ = digest.NewReader(digest.AlgorithmMD5, reader, digest.WithLogger(pt.Log()))
@@ -284,6 +390,8 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 				},
 			},
 			Reader:          reader,
+			CalcSha256:      pm.integrity.Enable,
+			FromSource:      true,
 			NeedGenMetadata: isLastPiece,
 		})
 
@@ -295,6 +403,9 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 		pt.Log().Errorf("put piece to storage failed, piece num: %d, wrote: %d, error: %s", pieceNum, result.Size, err)
 		return
 	}
+	if pm.pieceSizeSelector != nil {
+		pm.pieceSizeSelector.Observe(result.Size, timedReader.cost)
+	}
 	if pm.calculateDigest {
 		md5 = reader.(digest.Reader).Encoded()
 	}
@@ -416,7 +527,7 @@ singleDownload:
 	}
 	contentLength := response.ContentLength
 	// we must calculate piece size
-	pieceSize := pm.computePieceSize(contentLength)
+	pieceSize := pm.selectPieceSize(pt, peerTaskRequest, contentLength)
 	if contentLength < 0 {
 		log.Warnf("can not get content length for %s", peerTaskRequest.Url)
 	} else {
@@ -434,6 +545,7 @@ singleDownload:
 				ContentLength: contentLength,
 				TotalPieces:   pt.GetTotalPieces(),
 				Header:        &response.Header,
+				PieceSize:     pieceSize,
 			})
 		if err != nil {
 			return err
@@ -624,7 +736,7 @@ func detectBackSourceError(err error) error {
 
 func (pm *pieceManager) processPieceFromFile(ctx context.Context, ptm storage.PeerTaskMetadata,
 	tsd storage.TaskStorageDriver, r io.Reader, pieceNum int32, pieceOffset uint64,
-	pieceSize uint32, isLastPiece func(n int64) (int32, int64, bool)) (int64, error) {
+	pieceSize uint32, unknownLength bool, isLastPiece func(n int64) (int32, int64, bool)) (int64, error) {
 	var (
 		n      int64
 		reader = r
@@ -637,7 +749,7 @@ func (pm *pieceManager) processPieceFromFile(ctx context.Context, ptm storage.Pe
 	}
 	n, err := tsd.WritePiece(ctx,
 		&storage.WritePieceRequest{
-			UnknownLength:    false,
+			UnknownLength:    unknownLength,
 			PeerTaskMetadata: ptm,
 			PieceMetadata: storage.PieceMetadata{
 				Num: pieceNum,
@@ -650,6 +762,8 @@ func (pm *pieceManager) processPieceFromFile(ctx context.Context, ptm storage.Pe
 				},
 			},
 			Reader:          reader,
+			CalcSha256:      pm.integrity.Enable,
+			FromSource:      true,
 			NeedGenMetadata: isLastPiece,
 		})
 	if err != nil {
@@ -668,8 +782,26 @@ func (pm *pieceManager) ImportFile(ctx context.Context, ptm storage.PeerTaskMeta
 		log.Error(msg)
 		return errors.New(msg)
 	}
+
+	// The named pipe is streamed by dfcache, e.g. import from stdin, its content length is unknown.
+	if stat.Mode()&os.ModeNamedPipe != 0 {
+		pipe, err := os.Open(req.Path)
+		if err != nil {
+			msg := fmt.Sprintf("open named pipe %s failed: %s", req.Path, err)
+			log.Error(msg)
+			return errors.New(msg)
+		}
+		defer func() {
+			if cerr := pipe.Close(); cerr != nil {
+				err = errors.Join(err, cerr)
+			}
+		}()
+
+		return pm.Import(ctx, ptm, tsd, req.Url, req.UrlMeta, -1, pipe)
+	}
+
 	contentLength := stat.Size()
-	pieceSize := pm.computePieceSize(contentLength)
+	pieceSize := pm.importPieceSize(req.Url, req.UrlMeta, contentLength)
 	maxPieceNum := util.ComputePieceCount(contentLength, pieceSize)
 
 	file, err := os.Open(req.Path)
@@ -695,7 +827,7 @@ func (pm *pieceManager) ImportFile(ctx context.Context, ptm storage.PeerTaskMeta
 		}
 
 		log.Debugf("import piece %d", pieceNum)
-		n, er := pm.processPieceFromFile(ctx, ptm, tsd, reader, pieceNum, offset, size, isLastPiece)
+		n, er := pm.processPieceFromFile(ctx, ptm, tsd, reader, pieceNum, offset, size, false, isLastPiece)
 		if er != nil {
 			log.Errorf("import piece %d of task %s error: %s", pieceNum, ptm.TaskID, er)
 			return er
@@ -711,6 +843,7 @@ func (pm *pieceManager) ImportFile(ctx context.Context, ptm storage.PeerTaskMeta
 		PeerTaskMetadata: ptm,
 		ContentLength:    contentLength,
 		TotalPieces:      maxPieceNum,
+		PieceSize:        pieceSize,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("update task(%s) failed: %s", ptm.TaskID, err)
@@ -736,40 +869,61 @@ func (pm *pieceManager) ImportFile(ctx context.Context, ptm storage.PeerTaskMeta
 	return nil
 }
 
-func (pm *pieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, contentLength int64, reader io.Reader) error {
+// Import imports the content of the reader as the task, the content length is -1 if it is unknown,
+// then the reader is imported until EOF.
+func (pm *pieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, url string, urlMeta *commonv1.UrlMeta, contentLength int64, reader io.Reader) error {
 	log := logger.WithTaskAndPeerID(ptm.TaskID, ptm.PeerID)
-	pieceSize := pm.computePieceSize(contentLength)
+	pieceSize := pm.importPieceSize(url, urlMeta, contentLength)
 	maxPieceNum := util.ComputePieceCount(contentLength, pieceSize)
+	if contentLength < 0 {
+		var err error
+		if contentLength, maxPieceNum, err = pm.importUnknownLength(ctx, ptm, tsd, pieceSize, reader); err != nil {
+			return err
+		}
+	} else {
+		for pieceNum := int32(0); pieceNum < maxPieceNum; pieceNum++ {
+			size := pieceSize
+			offset := uint64(pieceNum) * uint64(pieceSize)
 
-	for pieceNum := int32(0); pieceNum < maxPieceNum; pieceNum++ {
-		size := pieceSize
-		offset := uint64(pieceNum) * uint64(pieceSize)
+			// Calculate piece size for last piece.
+			if contentLength > 0 && int64(offset)+int64(size) > contentLength {
+				size = uint32(contentLength - int64(offset))
+			}
 
-		// Calculate piece size for last piece.
-		if contentLength > 0 && int64(offset)+int64(size) > contentLength {
-			size = uint32(contentLength - int64(offset))
-		}
+			log.Debugf("import piece %d", pieceNum)
+			n, err := pm.processPieceFromFile(ctx, ptm, tsd, reader, pieceNum, offset, size, false, func(int64) (int32, int64, bool) {
+				return maxPieceNum, contentLength, pieceNum == maxPieceNum-1
+			})
+			if err != nil {
+				log.Errorf("import piece %d error: %s", pieceNum, err)
+				return err
+			}
 
-		log.Debugf("import piece %d", pieceNum)
-		n, err := pm.processPieceFromFile(ctx, ptm, tsd, reader, pieceNum, offset, size, func(int64) (int32, int64, bool) {
-			return maxPieceNum, contentLength, pieceNum == maxPieceNum-1
-		})
-		if err != nil {
-			log.Errorf("import piece %d error: %s", pieceNum, err)
-			return err
-		}
+			if n != int64(size) {
+				log.Errorf("import piece %d size not match, desired: %d, actual: %d", pieceNum, size, n)
+				return storage.ErrShortRead
+			}
 
-		if n != int64(size) {
-			log.Errorf("import piece %d size not match, desired: %d, actual: %d", pieceNum, size, n)
-			return storage.ErrShortRead
+			if err := ctx.Err(); err != nil {
+				log.Errorf("import piece %d canceled: %s", pieceNum, err)
+				return err
+			}
 		}
 	}
 
+	// The reader may be closed early when the caller is canceled, the content is truncated
+	// and must not be stored as a completed task.
+	if err := ctx.Err(); err != nil {
+		log.Errorf("import canceled: %s", err)
+		return err
+	}
+
 	// Update task with length and piece count.
 	if err := tsd.UpdateTask(ctx, &storage.UpdateTaskRequest{
 		PeerTaskMetadata: ptm,
 		ContentLength:    contentLength,
 		TotalPieces:      maxPieceNum,
+		PieceSize:        pieceSize,
 	}); err != nil {
 		msg := fmt.Sprintf("update task failed: %s", err)
 		log.Error(msg)
@@ -793,9 +947,82 @@ func (pm *pieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata
 	return nil
 }
 
+// importPieceSize returns the piece size of the imported task, the piece size chosen by
+// the piece size selector takes precedence over the one computed by content length.
+func (pm *pieceManager) importPieceSize(url string, urlMeta *commonv1.UrlMeta, contentLength int64) uint32 {
+	if pm.pieceSizeSelector != nil {
+		if size, ok := pm.pieceSizeSelector.Select(url, urlMeta); ok {
+			return size
+		}
+	}
+
+	return pm.computePieceSize(contentLength)
+}
+
+// importUnknownLength imports the reader until EOF, and returns the content length and piece count.
+func (pm *pieceManager) importUnknownLength(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, pieceSize uint32, reader io.Reader) (int64, int32, error) {
+	var (
+		contentLength int64 = -1
+		totalPieces   int32 = -1
+	)
+	log := logger.WithTaskAndPeerID(ptm.TaskID, ptm.PeerID)
+	for pieceNum := int32(0); ; pieceNum++ {
+		offset := uint64(pieceNum) * uint64(pieceSize)
+		log.Debugf("import piece %d", pieceNum)
+		n, err := pm.processPieceFromFile(ctx, ptm, tsd, reader, pieceNum, offset, pieceSize, true, func(n int64) (int32, int64, bool) {
+			if n >= int64(pieceSize) {
+				return -1, -1, false
+			}
+
+			// When n == 0, content length is aligned at piece size, the current piece is ignored.
+			contentLength = int64(pieceSize)*int64(pieceNum) + n
+			if n == 0 {
+				totalPieces = pieceNum
+			} else {
+				totalPieces = pieceNum + 1
+			}
+
+			return totalPieces, contentLength, true
+		})
+		if err != nil {
+			log.Errorf("import piece %d error: %s", pieceNum, err)
+			return -1, -1, err
+		}
+
+		if err := ctx.Err(); err != nil {
+			log.Errorf("import piece %d canceled: %s", pieceNum, err)
+			return -1, -1, err
+		}
+
+		if n < int64(pieceSize) {
+			log.Debugf("final piece is %d, content length: %d", totalPieces-1, contentLength)
+			return contentLength, totalPieces, nil
+		}
+	}
+}
+
+// selectPieceSize returns the piece size of the task, a piece size already chosen for the task
+// is kept to make sure the resumed downloading uses the same piece layout.
+func (pm *pieceManager) selectPieceSize(pt Task, peerTaskRequest *schedulerv1.PeerTaskRequest, contentLength int64) uint32 {
+	if pieceSize := pt.GetPieceSize(); pieceSize > 0 {
+		return pieceSize
+	}
+
+	pieceSize := pm.computePieceSize(contentLength)
+	if pm.pieceSizeSelector != nil {
+		if size, ok := pm.pieceSizeSelector.Select(peerTaskRequest.Url, peerTaskRequest.UrlMeta); ok {
+			pieceSize = size
+		}
+	}
+
+	pt.Log().Infof("select piece size %d", pieceSize)
+	pt.SetPieceSize(pieceSize)
+	return pieceSize
+}
+
 func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, peerTaskRequest *schedulerv1.PeerTaskRequest, parsedRange *nethttp.Range, continuePieceNum int32) error {
 	// parsedRange is always exist
-	pieceSize := pm.computePieceSize(parsedRange.Length)
+	pieceSize := pm.selectPieceSize(pt, peerTaskRequest, parsedRange.Length)
 	pieceCount := util.ComputePieceCount(parsedRange.Length, pieceSize)
 
 	pt.SetContentLength(parsedRange.Length)
@@ -14,8 +14,10 @@ import (
 	io "io"
 	reflect "reflect"
 
+	common "d7y.io/api/v2/pkg/apis/common/v1"
 	dfdaemon "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
 	scheduler "d7y.io/api/v2/pkg/apis/scheduler/v1"
+	integrity "d7y.io/dragonfly/v2/client/daemon/integrity"
 	storage "d7y.io/dragonfly/v2/client/daemon/storage"
 	http "d7y.io/dragonfly/v2/pkg/net/http"
 	gomock "go.uber.org/mock/gomock"
@@ -74,18 +76,33 @@ func (mr *MockPieceManagerMockRecorder) DownloadSource(ctx, pt, request, parsedR
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadSource", reflect.TypeOf((*MockPieceManager)(nil).DownloadSource), ctx, pt, request, parsedRange)
 }
 
+// GetMerkleTree mocks base method.
+func (m *MockPieceManager) GetMerkleTree(ctx context.Context, request *DownloadPieceRequest) (*integrity.MerkleTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerkleTree", ctx, request)
+	ret0, _ := ret[0].(*integrity.MerkleTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerkleTree indicates an expected call of GetMerkleTree.
+func (mr *MockPieceManagerMockRecorder) GetMerkleTree(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleTree", reflect.TypeOf((*MockPieceManager)(nil).GetMerkleTree), ctx, request)
+}
+
 // Import mocks base method.
-func (m *MockPieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, contentLength int64, reader io.Reader) error {
+func (m *MockPieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, url string, urlMeta *common.UrlMeta, contentLength int64, reader io.Reader) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Import", ctx, ptm, tsd, contentLength, reader)
+	ret := m.ctrl.Call(m, "Import", ctx, ptm, tsd, url, urlMeta, contentLength, reader)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Import indicates an expected call of Import.
-func (mr *MockPieceManagerMockRecorder) Import(ctx, ptm, tsd, contentLength, reader any) *gomock.Call {
+func (mr *MockPieceManagerMockRecorder) Import(ctx, ptm, tsd, url, urlMeta, contentLength, reader any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockPieceManager)(nil).Import), ctx, ptm, tsd, contentLength, reader)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockPieceManager)(nil).Import), ctx, ptm, tsd, url, urlMeta, contentLength, reader)
 }
 
 // ImportFile mocks base method.
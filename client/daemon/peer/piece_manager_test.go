@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -332,6 +333,7 @@ func TestPieceManager_DownloadSource(t *testing.T) {
 			mockPeerTask := NewMockTask(ctrl)
 			var (
 				totalPieces = &atomic.Int32{}
+				pieceSize   = &atomic.Uint32{}
 				taskStorage storage.TaskStorageDriver
 			)
 			mockPeerTask.EXPECT().SetContentLength(gomock.Any()).AnyTimes().DoAndReturn(
@@ -346,6 +348,14 @@ func TestPieceManager_DownloadSource(t *testing.T) {
 				func() int32 {
 					return totalPieces.Load()
 				})
+			mockPeerTask.EXPECT().SetPieceSize(gomock.Any()).AnyTimes().DoAndReturn(
+				func(arg0 uint32) {
+					pieceSize.Store(arg0)
+				})
+			mockPeerTask.EXPECT().GetPieceSize().AnyTimes().DoAndReturn(
+				func() uint32 {
+					return pieceSize.Load()
+				})
 			mockPeerTask.EXPECT().GetPeerID().AnyTimes().DoAndReturn(
 				func() string {
 					return peerID
@@ -489,6 +499,198 @@ func TestPieceManager_DownloadSource(t *testing.T) {
 	}
 }
 
+func TestPieceManager_Import(t *testing.T) {
+	testBytes, err := os.ReadFile(test.File)
+	require.Nil(t, err, "load test file")
+
+	tests := []struct {
+		name          string
+		pieceSize     uint32
+		pieceSizeRule *config.PieceSizeRule
+		contentLength int64
+	}{
+		{
+			name:          "import with content length",
+			pieceSize:     1024,
+			contentLength: int64(len(testBytes)),
+		},
+		{
+			name:          "import with content length and piece size rule",
+			pieceSize:     1024,
+			pieceSizeRule: &config.PieceSizeRule{Application: "model", PieceSize: 2048},
+			contentLength: int64(len(testBytes)),
+		},
+		{
+			name:          "import without content length and with piece size rule",
+			pieceSize:     1024,
+			pieceSizeRule: &config.PieceSizeRule{Application: "model", PieceSize: 2048},
+			contentLength: -1,
+		},
+		{
+			name:          "import without content length",
+			pieceSize:     1024,
+			contentLength: -1,
+		},
+		{
+			name:          "import without content length, content length is aligned at piece size",
+			pieceSize:     uint32(len(testBytes)),
+			contentLength: -1,
+		},
+		{
+			name:          "import without content length, one piece",
+			pieceSize:     uint32(len(testBytes)) + 1,
+			contentLength: -1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			storageManager, err := storage.NewStorageManager(
+				config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath: t.TempDir(),
+					TaskExpireTime: clientutil.Duration{
+						Duration: -1 * time.Second,
+					},
+				}, func(request storage.CommonTaskRequest) {}, os.FileMode(0700))
+			assert.Nil(err)
+			defer storageManager.CleanUp()
+
+			ptm := storage.PeerTaskMetadata{
+				PeerID: "peer0",
+				TaskID: "task0",
+			}
+			tsd, err := storageManager.RegisterTask(context.Background(), &storage.RegisterTaskRequest{
+				PeerTaskMetadata: ptm,
+			})
+			assert.Nil(err)
+
+			var (
+				opts      []PieceManagerOption
+				pieceSize = tc.pieceSize
+			)
+			if tc.pieceSizeRule != nil {
+				opts = append(opts, WithPieceSizeOption(config.PieceSizeOption{Rules: []*config.PieceSizeRule{tc.pieceSizeRule}}))
+				pieceSize = uint32(tc.pieceSizeRule.PieceSize)
+			}
+
+			pm, err := NewPieceManager(30*time.Second, opts...)
+			assert.Nil(err)
+			pm.(*pieceManager).computePieceSize = func(length int64) uint32 {
+				return tc.pieceSize
+			}
+
+			assert.Nil(pm.Import(context.Background(), ptm, tsd, "http://example.com/checkpoint", &commonv1.UrlMeta{Application: "model"}, tc.contentLength, bytes.NewReader(testBytes)))
+
+			output := filepath.Join(t.TempDir(), "output")
+			assert.Nil(storageManager.Store(context.Background(), &storage.StoreRequest{
+				CommonTaskRequest: storage.CommonTaskRequest{
+					PeerID:      ptm.PeerID,
+					TaskID:      ptm.TaskID,
+					Destination: output,
+				},
+			}))
+
+			outputBytes, err := os.ReadFile(output)
+			assert.Nil(err, "load output file")
+			assert.Equal(testBytes, outputBytes)
+
+			task := storageManager.FindCompletedTask(ptm.TaskID)
+			assert.NotNil(task)
+			assert.Equal(int64(len(testBytes)), task.ContentLength)
+			assert.Equal(util.ComputePieceCount(int64(len(testBytes)), pieceSize), task.TotalPieces)
+		})
+	}
+}
+
+// cancelReader cancels the caller when EOF is read, like a named pipe
+// which is closed by the canceled writer.
+type cancelReader struct {
+	reader io.Reader
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		r.cancel()
+	}
+
+	return n, err
+}
+
+func TestPieceManager_ImportWithCanceledContext(t *testing.T) {
+	testBytes, err := os.ReadFile(test.File)
+	require.Nil(t, err, "load test file")
+
+	tests := []struct {
+		name          string
+		pieceSize     uint32
+		contentLength int64
+	}{
+		{
+			name:          "import with content length",
+			pieceSize:     1024,
+			contentLength: int64(len(testBytes)),
+		},
+		{
+			name:          "import without content length",
+			pieceSize:     1024,
+			contentLength: -1,
+		},
+		{
+			name:          "import without content length, one piece",
+			pieceSize:     uint32(len(testBytes)) + 1,
+			contentLength: -1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			storageManager, err := storage.NewStorageManager(
+				config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath: t.TempDir(),
+					TaskExpireTime: clientutil.Duration{
+						Duration: -1 * time.Second,
+					},
+				}, func(request storage.CommonTaskRequest) {}, os.FileMode(0700))
+			assert.Nil(err)
+			defer storageManager.CleanUp()
+
+			ptm := storage.PeerTaskMetadata{
+				PeerID: "peer0",
+				TaskID: "task0",
+			}
+			tsd, err := storageManager.RegisterTask(context.Background(), &storage.RegisterTaskRequest{
+				PeerTaskMetadata: ptm,
+			})
+			assert.Nil(err)
+
+			pm, err := NewPieceManager(30 * time.Second)
+			assert.Nil(err)
+			pm.(*pieceManager).computePieceSize = func(length int64) uint32 {
+				return tc.pieceSize
+			}
+
+			// The caller is canceled before the whole content is read, the reader is truncated.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			reader := &cancelReader{reader: bytes.NewReader(testBytes[:len(testBytes)/2]), cancel: cancel}
+
+			err = pm.Import(ctx, ptm, tsd, "http://example.com/checkpoint", &commonv1.UrlMeta{}, tc.contentLength, reader)
+			if tc.contentLength < 0 {
+				assert.ErrorIs(err, context.Canceled)
+			} else {
+				assert.Error(err)
+			}
+			assert.Nil(storageManager.FindCompletedTask(ptm.TaskID))
+		})
+	}
+}
+
 func TestDetectBackSourceError(t *testing.T) {
 	assert := testifyassert.New(t)
 	testCases := []struct {
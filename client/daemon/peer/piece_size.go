@@ -0,0 +1,124 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/pkg/unit"
+)
+
+const (
+	// pieceSizeBandwidthWeight is the weight of the latest observation in the moving average of bandwidth
+	pieceSizeBandwidthWeight = 0.2
+
+	// pieceSizeMinObservation is the minimum piece size to be observed, tiny pieces make the bandwidth noisy
+	pieceSizeMinObservation = 256 * unit.KB
+)
+
+// pieceSizeSelector selects the piece size of tasks downloaded from source,
+// configured rules take precedence over the observed back source bandwidth.
+// Peers downloading the same task from source may select different piece sizes,
+// so children only download pieces from the parents with the same piece layout.
+type pieceSizeSelector struct {
+	rules          []*config.PieceSizeRule
+	adaptive       bool
+	targetDuration time.Duration
+	min            uint32
+	max            uint32
+
+	lock sync.RWMutex
+	// bandwidth is the moving average of back source bandwidth in bytes per second
+	bandwidth float64
+}
+
+func newPieceSizeSelector(opt config.PieceSizeOption) *pieceSizeSelector {
+	return &pieceSizeSelector{
+		rules:          opt.Rules,
+		adaptive:       opt.Adaptive,
+		targetDuration: opt.TargetDuration,
+		min:            uint32(opt.Min),
+		max:            uint32(opt.Max),
+	}
+}
+
+// Select returns the piece size for the task, ok is false when no rule matches
+// and there is no bandwidth observed yet.
+func (s *pieceSizeSelector) Select(url string, meta *commonv1.UrlMeta) (uint32, bool) {
+	for _, rule := range s.rules {
+		if rule.Match(meta.GetApplication(), url) {
+			return uint32(rule.PieceSize), true
+		}
+	}
+
+	if !s.adaptive {
+		return 0, false
+	}
+
+	s.lock.RLock()
+	bandwidth := s.bandwidth
+	s.lock.RUnlock()
+	if bandwidth <= 0 {
+		return 0, false
+	}
+
+	// align piece size to MB to keep piece boundaries friendly to storage
+	size := uint64(bandwidth*s.targetDuration.Seconds()) / uint64(unit.MB) * uint64(unit.MB)
+	switch {
+	case size < uint64(s.min):
+		return s.min, true
+	case size > uint64(s.max):
+		return s.max, true
+	default:
+		return uint32(size), true
+	}
+}
+
+// Observe records the cost of reading a piece from source.
+func (s *pieceSizeSelector) Observe(size int64, cost time.Duration) {
+	if !s.adaptive || size < int64(pieceSizeMinObservation) || cost <= 0 {
+		return
+	}
+
+	bandwidth := float64(size) / cost.Seconds()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.bandwidth == 0 {
+		s.bandwidth = bandwidth
+		return
+	}
+
+	s.bandwidth = pieceSizeBandwidthWeight*bandwidth + (1-pieceSizeBandwidthWeight)*s.bandwidth
+}
+
+// sourceReader records the time spent on reading from source, the cost of digest and storage is excluded.
+type sourceReader struct {
+	io.Reader
+	cost time.Duration
+}
+
+func (r *sourceReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := r.Reader.Read(p)
+	r.cost += time.Since(start)
+	return n, err
+}
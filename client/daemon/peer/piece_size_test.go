@@ -0,0 +1,278 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/pkg/unit"
+)
+
+func TestPieceSizeSelector_Select(t *testing.T) {
+	layerRule, err := config.NewRegexp("blobs/sha256.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opt     config.PieceSizeOption
+		observe func(s *pieceSizeSelector)
+		url     string
+		meta    *commonv1.UrlMeta
+		expect  func(t *testing.T, size uint32, ok bool)
+	}{
+		{
+			name: "match application rule",
+			opt: config.PieceSizeOption{
+				Rules: []*config.PieceSizeRule{
+					{Application: "model", PieceSize: 32 * unit.MB},
+				},
+			},
+			observe: func(s *pieceSizeSelector) {},
+			url:     "http://example.com/checkpoint",
+			meta:    &commonv1.UrlMeta{Application: "model"},
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(uint32(32*unit.MB), size)
+			},
+		},
+		{
+			name: "match url rule",
+			opt: config.PieceSizeOption{
+				Rules: []*config.PieceSizeRule{
+					{Application: "model", PieceSize: 32 * unit.MB},
+					{URL: layerRule, PieceSize: 1 * unit.MB},
+				},
+			},
+			observe: func(s *pieceSizeSelector) {},
+			url:     "http://example.com/v2/library/nginx/blobs/sha256:abc",
+			meta:    &commonv1.UrlMeta{},
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(uint32(1*unit.MB), size)
+			},
+		},
+		{
+			name: "rules do not match",
+			opt: config.PieceSizeOption{
+				Rules: []*config.PieceSizeRule{
+					{Application: "model", URL: layerRule, PieceSize: 32 * unit.MB},
+				},
+			},
+			observe: func(s *pieceSizeSelector) {},
+			url:     "http://example.com/checkpoint",
+			meta:    &commonv1.UrlMeta{Application: "model"},
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "adaptive without observation",
+			opt: config.PieceSizeOption{
+				Adaptive:       true,
+				TargetDuration: time.Second,
+				Min:            4 * unit.MB,
+				Max:            64 * unit.MB,
+			},
+			observe: func(s *pieceSizeSelector) {},
+			url:     "http://example.com/checkpoint",
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "adaptive by observed bandwidth",
+			opt: config.PieceSizeOption{
+				Adaptive:       true,
+				TargetDuration: time.Second,
+				Min:            4 * unit.MB,
+				Max:            64 * unit.MB,
+			},
+			observe: func(s *pieceSizeSelector) {
+				s.Observe(int64(16*unit.MB), time.Second)
+				// tiny pieces are ignored
+				s.Observe(int64(unit.KB), time.Second)
+			},
+			url: "http://example.com/checkpoint",
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(uint32(16*unit.MB), size)
+			},
+		},
+		{
+			name: "adaptive limited by max",
+			opt: config.PieceSizeOption{
+				Adaptive:       true,
+				TargetDuration: time.Second,
+				Min:            4 * unit.MB,
+				Max:            64 * unit.MB,
+			},
+			observe: func(s *pieceSizeSelector) {
+				s.Observe(int64(64*unit.MB), 100*time.Millisecond)
+			},
+			url: "http://example.com/checkpoint",
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(uint32(64*unit.MB), size)
+			},
+		},
+		{
+			name: "adaptive limited by min",
+			opt: config.PieceSizeOption{
+				Adaptive:       true,
+				TargetDuration: time.Second,
+				Min:            4 * unit.MB,
+				Max:            64 * unit.MB,
+			},
+			observe: func(s *pieceSizeSelector) {
+				s.Observe(int64(unit.MB), time.Second)
+			},
+			url: "http://example.com/checkpoint",
+			expect: func(t *testing.T, size uint32, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(uint32(4*unit.MB), size)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newPieceSizeSelector(tc.opt)
+			tc.observe(s)
+			size, ok := s.Select(tc.url, tc.meta)
+			tc.expect(t, size, ok)
+		})
+	}
+}
+
+func TestPeerTaskConductor_checkPieceLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		pieceSize     uint32
+		contentLength int64
+		piecePacket   *commonv1.PiecePacket
+		expect        func(t *testing.T, err error)
+	}{
+		{
+			name:          "piece size is unknown",
+			pieceSize:     0,
+			contentLength: -1,
+			piecePacket: &commonv1.PiecePacket{
+				TotalPiece: 3,
+				PieceInfos: []*commonv1.PieceInfo{{PieceNum: 1, RangeStart: 8, RangeSize: 8}},
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:          "same piece layout",
+			pieceSize:     4,
+			contentLength: 10,
+			piecePacket: &commonv1.PiecePacket{
+				TotalPiece: 3,
+				PieceInfos: []*commonv1.PieceInfo{
+					{PieceNum: 1, RangeStart: 4, RangeSize: 4},
+					{PieceNum: 2, RangeStart: 8, RangeSize: 2},
+				},
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:          "total piece count did not match",
+			pieceSize:     4,
+			contentLength: 10,
+			piecePacket: &commonv1.PiecePacket{
+				TotalPiece: 2,
+			},
+			expect: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "total piece count 2 did not match piece size 4")
+			},
+		},
+		{
+			name:          "range start did not match",
+			pieceSize:     4,
+			contentLength: -1,
+			piecePacket: &commonv1.PiecePacket{
+				TotalPiece: -1,
+				PieceInfos: []*commonv1.PieceInfo{{PieceNum: 1, RangeStart: 8, RangeSize: 8}},
+			},
+			expect: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "piece 1 range start 8 did not match piece size 4")
+			},
+		},
+		{
+			name:          "range size did not match",
+			pieceSize:     4,
+			contentLength: 10,
+			piecePacket: &commonv1.PiecePacket{
+				TotalPiece: 3,
+				PieceInfos: []*commonv1.PieceInfo{{PieceNum: 0, RangeStart: 0, RangeSize: 2}},
+			},
+			expect: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "piece 0 range size 2 did not match piece size 4")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pt := &peerTaskConductor{
+				pieceSize:     atomic.NewUint32(tc.pieceSize),
+				contentLength: atomic.NewInt64(tc.contentLength),
+			}
+			tc.expect(t, pt.checkPieceLayout(tc.piecePacket))
+		})
+	}
+}
+
+func TestSourceReader(t *testing.T) {
+	assert := assert.New(t)
+	r := &sourceReader{Reader: io.MultiReader(bytes.NewBufferString("foo"), &slowReader{delay: 10 * time.Millisecond})}
+	data, err := io.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal("foo", string(data))
+	assert.GreaterOrEqual(r.cost, 10*time.Millisecond)
+}
+
+type slowReader struct {
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return 0, io.EOF
+}
@@ -23,15 +23,11 @@ import (
 	"go.uber.org/atomic"
 	"golang.org/x/time/rate"
 
+	"d7y.io/dragonfly/v2/client/config"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/math"
 )
 
-const (
-	TypePlainTrafficShaper    = "plain"
-	TypeSamplingTrafficShaper = "sampling"
-)
-
 // TrafficShaper allocates bandwidth for running tasks dynamically
 type TrafficShaper interface {
 	// Start starts the TrafficShaper
@@ -46,14 +42,26 @@ type TrafficShaper interface {
 	Record(taskID string, n int)
 	// GetBandwidth gets the total download bandwidth in the past second
 	GetBandwidth() int64
+	// SetTotalRateLimit updates the total download rate limit
+	SetTotalRateLimit(limit rate.Limit)
+}
+
+// TrafficShaperOption is a functional option for configuring the sampling traffic shaper.
+type TrafficShaperOption func(ts *samplingTrafficShaper)
+
+// WithApplicationBandwidth sets the download budgets shared by tasks of the matched application or priority.
+func WithApplicationBandwidth(budgets []*config.ApplicationBandwidth) TrafficShaperOption {
+	return func(ts *samplingTrafficShaper) {
+		ts.budgets = budgets
+	}
 }
 
-func NewTrafficShaper(trafficShaperType string, totalRateLimit rate.Limit, computePieceSize func(int64) uint32) TrafficShaper {
+func NewTrafficShaper(trafficShaperType string, totalRateLimit rate.Limit, computePieceSize func(int64) uint32, opts ...TrafficShaperOption) TrafficShaper {
 	var ts TrafficShaper
 	switch trafficShaperType {
-	case TypeSamplingTrafficShaper:
-		ts = NewSamplingTrafficShaper(totalRateLimit, computePieceSize)
-	case TypePlainTrafficShaper:
+	case config.TrafficShaperTypeSampling:
+		ts = NewSamplingTrafficShaper(totalRateLimit, computePieceSize, opts...)
+	case config.TrafficShaperTypePlain:
 		ts = NewPlainTrafficShaper()
 	default:
 		logger.Warnf("type \"%s\" doesn't exist, use plain traffic shaper instead", trafficShaperType)
@@ -111,6 +119,9 @@ func (ts *plainTrafficShaper) GetBandwidth() int64 {
 	return ts.lastSecondBandwidth.Load()
 }
 
+func (ts *plainTrafficShaper) SetTotalRateLimit(_ rate.Limit) {
+}
+
 type taskEntry struct {
 	ptc       *peerTaskConductor
 	pieceSize uint32
@@ -120,6 +131,8 @@ type taskEntry struct {
 	needBandwidth int64
 	// indicates if the bandwidth need to be updated, tasks added within one second don't need to be updated
 	needUpdate bool
+	// index of the application download budget of the task, -1 means no budget
+	budget int
 }
 
 type samplingTrafficShaper struct {
@@ -132,12 +145,14 @@ type samplingTrafficShaper struct {
 	// total used bandwidth in the current second
 	usingBandWidth *atomic.Int64
 	tasks          map[string]*taskEntry
-	stopCh         chan struct{}
+	// download budgets of applications
+	budgets []*config.ApplicationBandwidth
+	stopCh  chan struct{}
 }
 
-func NewSamplingTrafficShaper(totalRateLimit rate.Limit, computePieceSize func(int64) uint32) TrafficShaper {
+func NewSamplingTrafficShaper(totalRateLimit rate.Limit, computePieceSize func(int64) uint32, opts ...TrafficShaperOption) TrafficShaper {
 	log := logger.With("component", "TrafficShaper")
-	return &samplingTrafficShaper{
+	ts := &samplingTrafficShaper{
 		SugaredLoggerOnWith: log,
 		computePieceSize:    computePieceSize,
 		totalRateLimit:      totalRateLimit,
@@ -146,6 +161,12 @@ func NewSamplingTrafficShaper(totalRateLimit rate.Limit, computePieceSize func(i
 		tasks:               make(map[string]*taskEntry),
 		stopCh:              make(chan struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(ts)
+	}
+
+	return ts
 }
 
 func (ts *samplingTrafficShaper) Start() {
@@ -205,6 +226,54 @@ func (ts *samplingTrafficShaper) updateLimit() {
 		te.ptc.limiter.SetLimit(rate.Limit(diffLimit + float64(te.pieceSize)))
 		ts.Debugf("period update limit, task %s, need bandwidth %d, diff rate limit %f", te.ptc.taskID, te.needBandwidth, diffLimit)
 	}
+
+	ts.limitApplications()
+}
+
+// limitApplications scales down the limits of tasks whose application exceeds its download budget,
+// tasks of the same application share the budget by their current limits.
+func (ts *samplingTrafficShaper) limitApplications() {
+	if len(ts.budgets) == 0 {
+		return
+	}
+
+	used := make([]rate.Limit, len(ts.budgets))
+	for _, te := range ts.tasks {
+		if te.budget >= 0 {
+			used[te.budget] += te.ptc.limiter.Limit()
+		}
+	}
+
+	for _, te := range ts.tasks {
+		if te.budget < 0 {
+			continue
+		}
+
+		budget := ts.budgets[te.budget].Download.Limit
+		if used[te.budget] <= budget {
+			continue
+		}
+
+		newLimit := te.ptc.limiter.Limit() * budget / used[te.budget]
+		te.ptc.limiter.SetLimit(newLimit)
+		ts.Debugf("application budget exceeded, task %s rate limit updated to %f", te.ptc.taskID, newLimit)
+	}
+}
+
+// matchBudget returns the index of the first application budget matched by the task, -1 means no budget.
+func (ts *samplingTrafficShaper) matchBudget(ptc *peerTaskConductor) int {
+	urlMeta := ptc.request.GetUrlMeta()
+	for i, budget := range ts.budgets {
+		if budget.Match(urlMeta.GetApplication(), urlMeta.GetPriority()) {
+			if budget.Download.Limit > 0 {
+				return i
+			}
+
+			return -1
+		}
+	}
+
+	return -1
 }
 
 func (ts *samplingTrafficShaper) AddTask(taskID string, ptc *peerTaskConductor) {
@@ -214,11 +283,14 @@ func (ts *samplingTrafficShaper) AddTask(taskID string, ptc *peerTaskConductor)
 	if nTasks == 0 {
 		nTasks++
 	}
-	pieceSize := ts.computePieceSize(ptc.contentLength.Load())
+	pieceSize := ptc.GetPieceSize()
+	if pieceSize == 0 {
+		pieceSize = ts.computePieceSize(ptc.contentLength.Load())
+	}
 	limit := rate.Limit(math.Max(float64(ts.totalRateLimit)/float64(nTasks), float64(pieceSize)))
 	// make sure bandwidth is not smaller than pieceSize
 	ptc.limiter.SetLimit(limit)
-	ts.tasks[taskID] = &taskEntry{ptc: ptc, lastSecondBandwidth: atomic.NewInt64(0), pieceSize: pieceSize}
+	ts.tasks[taskID] = &taskEntry{ptc: ptc, lastSecondBandwidth: atomic.NewInt64(0), pieceSize: pieceSize, budget: ts.matchBudget(ptc)}
 	var totalNeedRateLimit rate.Limit
 	for _, te := range ts.tasks {
 		totalNeedRateLimit += te.ptc.limiter.Limit()
@@ -231,6 +303,8 @@ func (ts *samplingTrafficShaper) AddTask(taskID string, ptc *peerTaskConductor)
 		te.ptc.limiter.SetLimit(newLimit)
 		ts.Debugf("a task added, task %s rate limit updated to %f", te.ptc.taskID, newLimit)
 	}
+
+	ts.limitApplications()
 }
 
 func (ts *samplingTrafficShaper) RemoveTask(taskID string) {
@@ -246,13 +320,18 @@ func (ts *samplingTrafficShaper) RemoveTask(taskID string) {
 	}
 
 	delete(ts.tasks, taskID)
-	ratio := ts.totalRateLimit / (ts.totalRateLimit - limit)
-	// increase all running tasks' bandwidth
-	for _, te := range ts.tasks {
-		newLimit := ratio * te.ptc.limiter.Limit()
-		te.ptc.limiter.SetLimit(newLimit)
-		ts.Debugf("a task removed, task %s rate limit updated to %f", te.ptc.taskID, newLimit)
+	// the total rate limit may be lowered by bandwidth windows, leave it to the next period update
+	if limit < ts.totalRateLimit {
+		ratio := ts.totalRateLimit / (ts.totalRateLimit - limit)
+		// increase all running tasks' bandwidth
+		for _, te := range ts.tasks {
+			newLimit := ratio * te.ptc.limiter.Limit()
+			te.ptc.limiter.SetLimit(newLimit)
+			ts.Debugf("a task removed, task %s rate limit updated to %f", te.ptc.taskID, newLimit)
+		}
 	}
+
+	ts.limitApplications()
 }
 
 func (ts *samplingTrafficShaper) Record(taskID string, n int) {
@@ -269,3 +348,30 @@ func (ts *samplingTrafficShaper) Record(taskID string, n int) {
 func (ts *samplingTrafficShaper) GetBandwidth() int64 {
 	return ts.lastSecondBandwidth.Load()
 }
+
+func (ts *samplingTrafficShaper) SetTotalRateLimit(limit rate.Limit) {
+	ts.Lock()
+	defer ts.Unlock()
+
+	var totalLimit rate.Limit
+	for _, te := range ts.tasks {
+		totalLimit += te.ptc.limiter.Limit()
+	}
+
+	ts.totalRateLimit = limit
+	ts.Infof("total rate limit updated to %f", limit)
+	if totalLimit == 0 {
+		return
+	}
+
+	// scale all running tasks' bandwidth to the new total rate limit
+	ratio := limit / totalLimit
+	for _, te := range ts.tasks {
+		// make sure bandwidth is not smaller than pieceSize
+		newLimit := math.Max(ratio*te.ptc.limiter.Limit(), rate.Limit(te.pieceSize))
+		te.ptc.limiter.SetLimit(newLimit)
+		ts.Debugf("total rate limit updated, task %s rate limit updated to %f", te.ptc.taskID, newLimit)
+	}
+
+	ts.limitApplications()
+}
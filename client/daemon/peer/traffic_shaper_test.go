@@ -31,6 +31,7 @@ import (
 	"github.com/phayes/freeport"
 	testifyassert "github.com/stretchr/testify/assert"
 	testifyrequire "github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
@@ -630,3 +631,39 @@ func (ts *trafficShaperTestSpec) run(assert *testifyassert.Assertions, require *
 		assert.True(success, "task should success")
 	}
 }
+
+func TestSamplingTrafficShaper_ApplicationBandwidth(t *testing.T) {
+	assert := testifyassert.New(t)
+	newConductor := func(taskID, application string) *peerTaskConductor {
+		return &peerTaskConductor{
+			taskID:        taskID,
+			contentLength: atomic.NewInt64(-1),
+			pieceSize:     atomic.NewUint32(0),
+			limiter:       rate.NewLimiter(1000, 1000),
+			request: &schedulerv1.PeerTaskRequest{
+				UrlMeta: &commonv1.UrlMeta{Application: application},
+			},
+		}
+	}
+
+	ts := NewSamplingTrafficShaper(1000, func(int64) uint32 { return 10 }, WithApplicationBandwidth([]*config.ApplicationBandwidth{
+		{Application: "model", Download: util.RateLimit{Limit: 200}},
+		{Application: "image", Upload: util.RateLimit{Limit: 200}},
+	})).(*samplingTrafficShaper)
+
+	model1, model2, image := newConductor("model-1", "model"), newConductor("model-2", "model"), newConductor("image", "image")
+	ts.AddTask(model1.taskID, model1)
+	ts.AddTask(model2.taskID, model2)
+	ts.AddTask(image.taskID, image)
+
+	// tasks of model share the download budget, image has no download budget.
+	assert.InDelta(200, float64(model1.limiter.Limit()+model2.limiter.Limit()), 1)
+	assert.Greater(float64(image.limiter.Limit()), float64(200))
+
+	// lower total rate limit scales all tasks.
+	ts.SetTotalRateLimit(100)
+	assert.Less(float64(image.limiter.Limit()), float64(100))
+
+	ts.RemoveTask(image.taskID)
+	assert.LessOrEqual(float64(model1.limiter.Limit()+model2.limiter.Limit()), float64(200)+1)
+}
@@ -0,0 +1,167 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pex
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// defaultLookupTimeout is the default timeout of dns lookup.
+const defaultLookupTimeout = 10 * time.Second
+
+// resolver resolves domain names, it is replaced in tests.
+type resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type staticAddrMemberLister struct {
+	addrs    []string
+	resolver resolver
+}
+
+// NewStaticAddrMemberLister returns a lister of static gossip addresses, like 10.0.0.1:7946 or
+// dragonfly-peer-0.dragonfly-peer:7946, the port is 7946 when omitted. Host names are re-resolved in every List.
+func NewStaticAddrMemberLister(addrs []string) (InitialMemberLister, error) {
+	for _, addr := range addrs {
+		if _, _, err := splitGossipAddr(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	return &staticAddrMemberLister{
+		addrs:    addrs,
+		resolver: net.DefaultResolver,
+	}, nil
+}
+
+func (s *staticAddrMemberLister) List() ([]*InitialMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultLookupTimeout)
+	defer cancel()
+
+	var members []*InitialMember
+	for _, addr := range s.addrs {
+		host, port, err := splitGossipAddr(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ms, err := resolveMembers(ctx, s.resolver, host, port)
+		if err != nil {
+			// One unresolvable address does not block joining the others.
+			logger.Warnf("failed to resolve static member %s: %s", addr, err)
+			continue
+		}
+		members = append(members, ms...)
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no member resolved from %s", strings.Join(s.addrs, ","))
+	}
+
+	return members, nil
+}
+
+type dnsMemberLister struct {
+	service  string
+	proto    string
+	name     string
+	resolver resolver
+}
+
+// NewDNSMemberLister returns a lister of the SRV records of _service._proto.name,
+// the records are looked up in every List.
+func NewDNSMemberLister(service, proto, name string) InitialMemberLister {
+	return &dnsMemberLister{
+		service:  service,
+		proto:    proto,
+		name:     name,
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (d *dnsMemberLister) List() ([]*InitialMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultLookupTimeout)
+	defer cancel()
+
+	_, records, err := d.resolver.LookupSRV(ctx, d.service, d.proto, d.name)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*InitialMember
+	for _, record := range records {
+		ms, err := resolveMembers(ctx, d.resolver, strings.TrimSuffix(record.Target, "."), int(record.Port))
+		if err != nil {
+			logger.Warnf("failed to resolve srv target %s: %s", record.Target, err)
+			continue
+		}
+		members = append(members, ms...)
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no member resolved from srv records of %s", d.name)
+	}
+
+	return members, nil
+}
+
+// splitGossipAddr splits the gossip address into host and port, the port is defaultGossipPort when omitted.
+func splitGossipAddr(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		// Address without port, like dragonfly-peer-0 or [::1].
+		host = strings.Trim(addr, "[]")
+		if net.ParseIP(host) != nil || !strings.Contains(addr, ":") {
+			return host, defaultGossipPort, nil
+		}
+		return "", 0, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port of address %s", addr)
+	}
+
+	return host, port, nil
+}
+
+// resolveMembers resolves the host into members, ip host is returned directly.
+func resolveMembers(ctx context.Context, r resolver, host string, port int) ([]*InitialMember, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []*InitialMember{{Addr: ip, Port: uint16(port)}}, nil
+	}
+
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*InitialMember
+	for _, addr := range addrs {
+		members = append(members, &InitialMember{Addr: addr.IP, Port: uint16(port)})
+	}
+
+	return members, nil
+}
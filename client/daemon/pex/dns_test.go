@@ -0,0 +1,140 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pex
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResolver struct {
+	hosts map[string][]string
+	srvs  map[string][]*net.SRV
+}
+
+func (m *mockResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := m.hosts[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func (m *mockResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	srvs, ok := m.srvs[name]
+	if !ok {
+		return "", nil, errors.New("no such host")
+	}
+	return name, srvs, nil
+}
+
+func TestStaticAddrMemberLister(t *testing.T) {
+	testCases := []struct {
+		name    string
+		addrs   []string
+		members []*InitialMember
+		err     bool
+	}{
+		{
+			name:  "ip addresses",
+			addrs: []string{"127.0.0.1:8000", "127.0.0.2", "[::1]"},
+			members: []*InitialMember{
+				{Addr: net.ParseIP("127.0.0.1"), Port: 8000},
+				{Addr: net.ParseIP("127.0.0.2"), Port: defaultGossipPort},
+				{Addr: net.ParseIP("::1"), Port: defaultGossipPort},
+			},
+		},
+		{
+			name:  "resolve host names",
+			addrs: []string{"peer-0:8000", "peer-1", "unknown"},
+			members: []*InitialMember{
+				{Addr: net.ParseIP("10.0.0.1"), Port: 8000},
+				{Addr: net.ParseIP("10.0.0.2"), Port: defaultGossipPort},
+				{Addr: net.ParseIP("10.0.0.3"), Port: defaultGossipPort},
+			},
+		},
+		{
+			name:  "no member resolved",
+			addrs: []string{"unknown"},
+			err:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			lister, err := NewStaticAddrMemberLister(tc.addrs)
+			assert.Nil(err)
+			lister.(*staticAddrMemberLister).resolver = &mockResolver{
+				hosts: map[string][]string{
+					"peer-0": {"10.0.0.1"},
+					"peer-1": {"10.0.0.2", "10.0.0.3"},
+				},
+			}
+
+			members, err := lister.List()
+			if tc.err {
+				assert.Error(err)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.members, members)
+		})
+	}
+}
+
+func TestNewStaticAddrMemberLister_InvalidAddr(t *testing.T) {
+	_, err := NewStaticAddrMemberLister([]string{"127.0.0.1:foo"})
+	assert.Error(t, err)
+}
+
+func TestDNSMemberLister(t *testing.T) {
+	assert := assert.New(t)
+	lister := NewDNSMemberLister("gossip", "udp", "dragonfly-peer.svc")
+	lister.(*dnsMemberLister).resolver = &mockResolver{
+		hosts: map[string][]string{
+			"peer-0.dragonfly-peer.svc": {"10.0.0.1"},
+			"peer-1.dragonfly-peer.svc": {"10.0.0.2"},
+		},
+		srvs: map[string][]*net.SRV{
+			"dragonfly-peer.svc": {
+				{Target: "peer-0.dragonfly-peer.svc.", Port: 7946},
+				{Target: "peer-1.dragonfly-peer.svc.", Port: 7947},
+				{Target: "unknown.", Port: 7946},
+			},
+		},
+	}
+
+	members, err := lister.List()
+	assert.Nil(err)
+	assert.Equal([]*InitialMember{
+		{Addr: net.ParseIP("10.0.0.1"), Port: 7946},
+		{Addr: net.ParseIP("10.0.0.2"), Port: 7947},
+	}, members)
+
+	_, err = NewDNSMemberLister("gossip", "udp", "unknown").List()
+	assert.Error(err)
+}
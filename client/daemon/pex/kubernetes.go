@@ -0,0 +1,340 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pex
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// kubernetesServiceAccountDir is the directory of service account mounted in pod.
+	kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// kubernetesServiceNameLabel is the label of EndpointSlice which indicates the service.
+	kubernetesServiceNameLabel = "kubernetes.io/service-name"
+
+	// kubernetesRequestTimeout is the timeout of list request.
+	kubernetesRequestTimeout = 30 * time.Second
+
+	// kubernetesWatchRetryInterval is the interval to re-list and watch after failures.
+	kubernetesWatchRetryInterval = 5 * time.Second
+)
+
+// endpointSliceList is the subset of discovery.k8s.io/v1 EndpointSliceList used by lister.
+type endpointSliceList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []endpointSlice `json:"items"`
+}
+
+// endpointSlice is the subset of discovery.k8s.io/v1 EndpointSlice used by lister.
+type endpointSlice struct {
+	Metadata struct {
+		Name            string `json:"name"`
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Endpoints []struct {
+		Addresses  []string `json:"addresses"`
+		Conditions struct {
+			Ready *bool `json:"ready"`
+		} `json:"conditions"`
+	} `json:"endpoints"`
+}
+
+// endpointSliceEvent is the watch event of EndpointSlice.
+type endpointSliceEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+type kubernetesMemberLister struct {
+	apiServer string
+	client    *http.Client
+	tokenFile string
+	namespace string
+	service   string
+	port      int
+
+	// slices maps the EndpointSlice name to its ready members.
+	slices  map[string][]*InitialMember
+	synced  bool
+	mu      sync.RWMutex
+	runOnce sync.Once
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewKubernetesMemberLister returns a lister which watches the EndpointSlices of service with the
+// in-cluster service account, the ready endpoints are the initial members.
+func NewKubernetesMemberLister(namespace, service string, port int) (InitialMemberLister, error) {
+	host, hostPort := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || hostPort == "" {
+		return nil, errors.New("kubernetes discovery requires running in cluster")
+	}
+
+	caCert, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("invalid kubernetes ca cert")
+	}
+
+	if namespace == "" {
+		data, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "namespace"))
+		if err != nil {
+			return nil, err
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: caCertPool},
+		},
+	}
+
+	return newKubernetesMemberLister("https://"+net.JoinHostPort(host, hostPort), client,
+		filepath.Join(kubernetesServiceAccountDir, "token"), namespace, service, port), nil
+}
+
+func newKubernetesMemberLister(apiServer string, client *http.Client, tokenFile, namespace, service string, port int) *kubernetesMemberLister {
+	if port <= 0 {
+		port = defaultGossipPort
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &kubernetesMemberLister{
+		apiServer: apiServer,
+		client:    client,
+		tokenFile: tokenFile,
+		namespace: namespace,
+		service:   service,
+		port:      port,
+		slices:    map[string][]*InitialMember{},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// List returns the ready endpoints of service, the watcher is started in the first List.
+func (k *kubernetesMemberLister) List() ([]*InitialMember, error) {
+	k.runOnce.Do(func() {
+		go k.run()
+	})
+
+	k.mu.RLock()
+	synced := k.synced
+	k.mu.RUnlock()
+
+	// Watcher is not synced yet, list directly.
+	if !synced {
+		if _, err := k.list(k.ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var members []*InitialMember
+	for _, ms := range k.slices {
+		members = append(members, ms...)
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no ready endpoints of service %s/%s", k.namespace, k.service)
+	}
+
+	return members, nil
+}
+
+// Stop stops the watcher.
+func (k *kubernetesMemberLister) Stop() {
+	k.cancel()
+}
+
+// run lists and watches EndpointSlices until stopped.
+func (k *kubernetesMemberLister) run() {
+	for {
+		resourceVersion, err := k.list(k.ctx)
+		if err == nil {
+			err = k.watch(k.ctx, resourceVersion)
+		}
+
+		if err != nil {
+			logger.Warnf("failed to watch endpointslices of service %s/%s: %s", k.namespace, k.service, err)
+			select {
+			case <-k.ctx.Done():
+				return
+			case <-time.After(kubernetesWatchRetryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-k.ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// list lists EndpointSlices of service and replaces the cached members, it returns the resource version of list.
+func (k *kubernetesMemberLister) list(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+
+	resp, err := k.do(ctx, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var list endpointSliceList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return "", err
+	}
+
+	slices := map[string][]*InitialMember{}
+	for _, slice := range list.Items {
+		slices[slice.Metadata.Name] = k.readyMembers(&slice)
+	}
+
+	k.mu.Lock()
+	k.slices = slices
+	k.synced = true
+	k.mu.Unlock()
+
+	return list.Metadata.ResourceVersion, nil
+}
+
+// watch watches EndpointSlices of service from the resource version and updates the cached members,
+// it returns nil when the watch is closed by api server.
+func (k *kubernetesMemberLister) watch(ctx context.Context, resourceVersion string) error {
+	resp, err := k.do(ctx, url.Values{
+		"watch":               []string{"true"},
+		"resourceVersion":     []string{resourceVersion},
+		"allowWatchBookmarks": []string{"true"},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event endpointSliceEvent
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Watch is closed by api server.
+			return nil
+		}
+
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+		case "ERROR":
+			// Resource version is too old, re-list.
+			return fmt.Errorf("watch error: %s", event.Object)
+		default:
+			continue
+		}
+
+		var slice endpointSlice
+		if err := json.Unmarshal(event.Object, &slice); err != nil {
+			return err
+		}
+
+		k.mu.Lock()
+		if event.Type == "DELETED" {
+			delete(k.slices, slice.Metadata.Name)
+		} else {
+			k.slices[slice.Metadata.Name] = k.readyMembers(&slice)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// do sends the request of EndpointSlices of service to api server.
+func (k *kubernetesMemberLister) do(ctx context.Context, query url.Values) (*http.Response, error) {
+	query.Set("labelSelector", fmt.Sprintf("%s=%s", kubernetesServiceNameLabel, k.service))
+	u := fmt.Sprintf("%s/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices?%s", k.apiServer, k.namespace, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Service account token is rotated, read it in every request.
+	if k.tokenFile != "" {
+		token, err := os.ReadFile(k.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+// readyMembers returns the members of ready endpoints in EndpointSlice.
+func (k *kubernetesMemberLister) readyMembers(slice *endpointSlice) []*InitialMember {
+	var members []*InitialMember
+	for _, endpoint := range slice.Endpoints {
+		// Nil ready condition is interpreted as ready.
+		if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+			continue
+		}
+
+		for _, addr := range endpoint.Addresses {
+			if ip := net.ParseIP(addr); ip != nil {
+				members = append(members, &InitialMember{Addr: ip, Port: uint16(k.port)})
+			}
+		}
+	}
+
+	return members
+}
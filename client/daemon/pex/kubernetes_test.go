@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEndpointSlice returns EndpointSlice json of addresses.
+func fakeEndpointSlice(name string, ready bool, addrs ...string) map[string]any {
+	return map[string]any{
+		"metadata": map[string]any{"name": name},
+		"endpoints": []map[string]any{
+			{
+				"addresses":  addrs,
+				"conditions": map[string]any{"ready": ready},
+			},
+		},
+	}
+}
+
+func TestKubernetesMemberLister(t *testing.T) {
+	assert := assert.New(t)
+	events := make(chan map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/apis/discovery.k8s.io/v1/namespaces/dragonfly-system/endpointslices", r.URL.Path)
+		assert.Equal("kubernetes.io/service-name=dragonfly-peer", r.URL.Query().Get("labelSelector"))
+
+		if r.URL.Query().Get("watch") != "true" {
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"metadata": map[string]any{"resourceVersion": "1"},
+				"items": []map[string]any{
+					fakeEndpointSlice("slice-0", true, "10.0.0.1", "10.0.0.2"),
+					fakeEndpointSlice("slice-1", false, "10.0.0.3"),
+				},
+			}); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		assert.Equal("1", r.URL.Query().Get("resourceVersion"))
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				if err := json.NewEncoder(w).Encode(event); err != nil {
+					t.Error(err)
+				}
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer server.Close()
+
+	lister := newKubernetesMemberLister(server.URL, server.Client(), "", "dragonfly-system", "dragonfly-peer", 0)
+	defer lister.Stop()
+
+	list := func() []string {
+		members, err := lister.List()
+		require.Nil(t, err)
+
+		var addrs []string
+		for _, member := range members {
+			addrs = append(addrs, member.Address())
+		}
+		sort.Strings(addrs)
+		return addrs
+	}
+
+	// Only ready endpoints are listed.
+	assert.Equal([]string{
+		net.JoinHostPort("10.0.0.1", fmt.Sprint(defaultGossipPort)),
+		net.JoinHostPort("10.0.0.2", fmt.Sprint(defaultGossipPort)),
+	}, list())
+
+	events <- map[string]any{"type": "MODIFIED", "object": fakeEndpointSlice("slice-1", true, "10.0.0.3")}
+	events <- map[string]any{"type": "DELETED", "object": fakeEndpointSlice("slice-0", true)}
+	assert.Eventually(func() bool {
+		addrs := list()
+		return len(addrs) == 1 && addrs[0] == net.JoinHostPort("10.0.0.3", fmt.Sprint(defaultGossipPort))
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKubernetesMemberLister_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	lister := newKubernetesMemberLister(server.URL, server.Client(), "", "dragonfly-system", "dragonfly-peer", 7946)
+	defer lister.Stop()
+
+	_, err := lister.List()
+	assert.EqualError(t, err, "unexpected status code 403")
+}
@@ -45,6 +45,7 @@ type peerExchange struct {
 type peerExchangeConfig struct {
 	initialRetryInterval   time.Duration
 	reSyncInterval         time.Duration
+	resolveInterval        time.Duration
 	replicaThreshold       int
 	replicaCleanPercentage int32
 }
@@ -95,6 +96,15 @@ func WithReSyncInterval(interval time.Duration) func(*memberlist.Config, *peerEx
 	}
 }
 
+// WithResolveInterval sets the interval to re-resolve initial members and join the new ones.
+func WithResolveInterval(interval time.Duration) func(*memberlist.Config, *peerExchangeConfig) {
+	return func(memberConfig *memberlist.Config, pexConfig *peerExchangeConfig) {
+		if interval > 0 {
+			pexConfig.resolveInterval = interval
+		}
+	}
+}
+
 func WithReplicaThreshold(threshold int) func(*memberlist.Config, *peerExchangeConfig) {
 	return func(memberConfig *memberlist.Config, pexConfig *peerExchangeConfig) {
 		if threshold > 0 {
@@ -138,6 +148,7 @@ func NewPeerExchange(
 
 	logger.Infof("peer exchange initial retry interval: %s", pexConfig.initialRetryInterval)
 	logger.Infof("peer exchange re-sync interval: %s", pexConfig.reSyncInterval)
+	logger.Infof("peer exchange resolve interval: %s", pexConfig.resolveInterval)
 	logger.Infof("peer exchange replica threshold: %d", pexConfig.replicaThreshold)
 	logger.Infof("peer exchange replica clean percentage: %d", pexConfig.replicaCleanPercentage)
 
@@ -193,6 +204,16 @@ func (p *peerExchange) SearchPeer(task string) SearchPeerResult {
 	return searchPeerResult
 }
 
+func (p *peerExchange) FindPeers(task string) []*DestPeer {
+	var peers []*DestPeer
+	for _, peer := range p.memberManager.peerPool.Search(task).Peers {
+		if !peer.isLocal {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
 func (p *peerExchange) tryReclaim(task string, searchPeerResult SearchPeerResult) bool {
 	if p.config.replicaCleanPercentage == 0 {
 		return false
@@ -246,6 +267,10 @@ func (p *peerExchange) Serve(localMember *MemberMeta) error {
 
 	p.serve()
 
+	if p.config.resolveInterval > 0 {
+		go p.reJoinMember()
+	}
+
 	<-p.stopCh
 	return nil
 }
@@ -295,8 +320,54 @@ func (p *peerExchange) listAndJoin() error {
 	return err
 }
 
+// reJoinMember re-resolves initial members periodically and joins the ones not in cluster,
+// then new members are found even when the first join only reached part of them.
+func (p *peerExchange) reJoinMember() {
+	ticker := time.NewTicker(p.config.resolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		members, err := p.lister.List()
+		if err != nil {
+			logger.Warnf("failed to re-resolve initial member: %s", err)
+			continue
+		}
+
+		joined := map[string]struct{}{}
+		for _, m := range p.memberlist.Members() {
+			joined[m.Address()] = struct{}{}
+		}
+
+		var addrs []string
+		for _, member := range members {
+			addr := member.Address()
+			if _, ok := joined[addr]; !ok {
+				addrs = append(addrs, addr)
+			}
+		}
+
+		if len(addrs) == 0 {
+			continue
+		}
+
+		logger.Infof("join re-resolved members: %s", addrs)
+		if _, err := p.memberlist.Join(addrs); err != nil {
+			logger.Warnf("failed to join re-resolved members: %s, error: %s", addrs, err)
+		}
+	}
+}
+
 func (p *peerExchange) Stop() error {
 	close(p.stopCh)
+	if stopper, ok := p.lister.(interface{ Stop() }); ok {
+		stopper.Stop()
+	}
 	return p.memberlist.Leave(10 * time.Second)
 }
 
@@ -70,6 +70,8 @@ type ReclaimFunc func(task, peer string) error
 
 type PeerSearchBroadcaster interface {
 	SearchPeer(task string) SearchPeerResult
+	// FindPeers returns the remote peers of task, unlike SearchPeer, it never reclaims local replica.
+	FindPeers(task string) []*DestPeer
 	BroadcastPeer(data *dfdaemonv1.PeerMetadata)
 	BroadcastPeers(data *dfdaemonv1.PeerExchangeData)
 }
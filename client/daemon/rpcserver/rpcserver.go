@@ -30,6 +30,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -45,6 +46,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	grpcpeer "google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -68,6 +70,7 @@ import (
 	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 	"d7y.io/dragonfly/v2/pkg/safe"
 	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/types"
 	resource "d7y.io/dragonfly/v2/scheduler/resource/standard"
 )
 
@@ -91,6 +94,9 @@ type server struct {
 	downloadServer *grpc.Server
 	peerServer     *grpc.Server
 	uploadAddr     string
+	// uploadQUIC indicates the upload service serves pieces via QUIC on the udp port
+	// with the same number as upload port, it is announced to peers when syncing pieces.
+	uploadQUIC bool
 
 	recursiveConcurrent    int
 	cacheRecursiveMetadata time.Duration
@@ -104,7 +110,7 @@ func init() {
 
 func New(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskManager,
 	storageManager storage.Manager, peerExchanger pex.PeerExchangeRPC, schedulerClient schedulerclient.V1,
-	recursiveConcurrent int, seedConcurrent int64, cacheRecursiveMetadata time.Duration,
+	recursiveConcurrent int, seedConcurrent int64, cacheRecursiveMetadata time.Duration, uploadQUIC bool,
 	downloadOpts []grpc.ServerOption, peerOpts []grpc.ServerOption) (Server, error) {
 	s := &server{
 		KeepAlive:       util.NewKeepAlive("rpc server"),
@@ -116,6 +122,7 @@ func New(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskManager,
 
 		recursiveConcurrent:    recursiveConcurrent,
 		cacheRecursiveMetadata: cacheRecursiveMetadata,
+		uploadQUIC:             uploadQUIC,
 
 		healthServer: health.NewServer(),
 	}
@@ -287,6 +294,13 @@ func (s *server) SyncPieceTasks(sync dfdaemonv1.Daemon_SyncPieceTasksServer) err
 	log := logger.With("taskID", request.TaskId,
 		"localPeerID", request.DstPid, "remotePeerID", request.SrcPid)
 
+	// Announce the QUIC port of upload service, then peers download pieces via QUIC.
+	if s.uploadQUIC {
+		if err := sync.SendHeader(metadata.Pairs(types.HostQUICPortMetadataKey, strconv.Itoa(int(s.peerHost.DownPort)))); err != nil {
+			log.Warnf("send quic port header error: %s", err)
+		}
+	}
+
 	skipPieceCount := request.StartNum
 	var (
 		sentMap       = make(map[int32]struct{})
@@ -933,6 +947,15 @@ func (s *server) ImportTask(ctx context.Context, req *dfdaemonv1.ImportTaskReque
 	if err := pieceManager.ImportFile(ctx, ptm, tsd, req); err != nil {
 		msg := fmt.Sprintf("import file failed: %v", err)
 		log.Error(msg)
+
+		// Unregister the partially imported task, the request context may be canceled already.
+		if err := s.storageManager.UnregisterTask(context.Background(), storage.CommonTaskRequest{
+			PeerID: ptm.PeerID,
+			TaskID: ptm.TaskID,
+		}); err != nil {
+			log.Errorf("unregister task failed: %s", err)
+		}
+
 		return nil, errors.New(msg)
 	}
 	log.Info("import file succeeded")
@@ -76,7 +76,7 @@ func TestServer_New(t *testing.T) {
 			mockSchedulerClient := schedulerclientmocks.NewMockV1(ctrl)
 			var mockdownloadOpts []grpc.ServerOption
 			var mockpeerOpts []grpc.ServerOption
-			_, err := New(mockpeerHost, mockpeerTaskManager, mockStorageManger, nil, mockSchedulerClient, 16, 0, 0, mockdownloadOpts, mockpeerOpts)
+			_, err := New(mockpeerHost, mockpeerTaskManager, mockStorageManger, nil, mockSchedulerClient, 16, 0, 0, false, mockdownloadOpts, mockpeerOpts)
 			tc.expect(t, err)
 		})
 	}
@@ -366,6 +366,24 @@ func TestServer_ImportTask(t *testing.T) {
 				mockStorageManger.RegisterTask(gomock.Any(), gomock.Any()).Return(mocktsd, nil)
 				mockTaskManager.GetPieceManager().Return(mockPieceManager)
 				mockPieceManager.EXPECT().ImportFile(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dferrors.ErrInvalidArgument)
+				mockStorageManger.UnregisterTask(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, r *dfdaemonv1.ImportTaskRequest, err error) {
+				assert := testifyassert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "2. Import task file with err and unregister task with err",
+			r: &dfdaemonv1.ImportTaskRequest{
+				UrlMeta: &commonv1.UrlMeta{},
+			},
+			mock: func(mockStorageManger *mocks.MockManagerMockRecorder, mockTaskManager *peer.MockTaskManagerMockRecorder, mocktsd *mocks.MockTaskStorageDriver, mockPieceManager *peer.MockPieceManager) {
+				mockStorageManger.FindCompletedTask(gomock.Any()).Return(nil)
+				mockStorageManger.RegisterTask(gomock.Any(), gomock.Any()).Return(mocktsd, nil)
+				mockTaskManager.GetPieceManager().Return(mockPieceManager)
+				mockPieceManager.EXPECT().ImportFile(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(context.Canceled)
+				mockStorageManger.UnregisterTask(gomock.Any(), gomock.Any()).Return(storage.ErrTaskNotFound).Times(1)
 			},
 			expect: func(t *testing.T, r *dfdaemonv1.ImportTaskRequest, err error) {
 				assert := testifyassert.New(t)
@@ -25,6 +25,13 @@ const (
 	taskData     = "data"
 	taskMetadata = "metadata"
 
+	// TaskMetaApplication is the key of application in task meta
+	TaskMetaApplication = "application"
+	// TaskMetaPriority is the key of priority in task meta
+	TaskMetaPriority = "priority"
+	// TaskMetaPieceSize is the key of piece size in task meta, all peers of a task share the same piece size
+	TaskMetaPieceSize = "pieceSize"
+
 	defaultFileMode      = os.FileMode(0644)
 	defaultDirectoryMode = os.FileMode(0700) // used unless overridden in config
 )
@@ -0,0 +1,282 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	// dataKeySize is the size of task data key, uses AES-256.
+	dataKeySize = 32
+)
+
+// KMS is the key management service which wraps and unwraps the data keys of tasks.
+type KMS interface {
+	// WrapKey encrypts the data key of task.
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+
+	// UnwrapKey decrypts the wrapped data key of task.
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// keyFileKMS wraps data keys with the master key in the local key file.
+type keyFileKMS struct {
+	aead cipher.AEAD
+}
+
+// NewKeyFileKMS returns a KMS with the master key in the local key file,
+// the key file contains a 32 bytes key encoded in hex.
+func NewKeyFileKMS(keyFile string) (KMS, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", keyFile, err)
+	}
+
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("invalid key file %s: key size must be %d bytes", keyFile, dataKeySize)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyFileKMS{aead: aead}, nil
+}
+
+// WrapKey encrypts the data key of task with the master key.
+func (k *keyFileKMS) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return k.aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// UnwrapKey decrypts the wrapped data key of task with the master key.
+func (k *keyFileKMS) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(wrappedKey) < nonceSize {
+		return nil, errors.New("invalid wrapped key")
+	}
+
+	return k.aead.Open(nil, wrappedKey[:nonceSize], wrappedKey[nonceSize:], nil)
+}
+
+// newAEAD returns AES-GCM cipher with the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// newDataKey generates a data key for task and wraps it with kms.
+func newDataKey(ctx context.Context, kms KMS) (cipher.AEAD, []byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey, err := kms.WrapKey(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aead, wrappedKey, nil
+}
+
+// loadDataKey unwraps the data key of task with kms.
+func loadDataKey(ctx context.Context, kms KMS, wrappedKey []byte) (cipher.AEAD, error) {
+	if kms == nil {
+		return nil, ErrKMSNotSet
+	}
+
+	key, err := kms.UnwrapKey(ctx, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAEAD(key)
+}
+
+// pieceOverhead returns the size added to every sealed piece, the sealed piece is
+// prefixed by its random nonce and followed by the authentication tag.
+func pieceOverhead(aead cipher.AEAD) int64 {
+	return int64(aead.NonceSize() + aead.Overhead())
+}
+
+// encryptedPieceOffset returns the offset of the sealed piece in the task data file.
+func encryptedPieceOffset(aead cipher.AEAD, piece PieceMetadata) int64 {
+	return piece.Range.Start + int64(piece.Num)*pieceOverhead(aead)
+}
+
+// sealPiece encrypts the piece data and writes it to the task data file. The nonce is
+// generated randomly on every write, because a piece rejected by the length or digest
+// check is written again with the data from another parent under the same data key.
+func sealPiece(aead cipher.AEAD, file *os.File, piece PieceMetadata, reader io.Reader) (int64, error) {
+	nonceSize := aead.NonceSize()
+	buf := make([]byte, int64(nonceSize)+piece.Range.Length)
+	n, err := io.ReadFull(reader, buf[nonceSize:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return int64(n), err
+	}
+
+	if n == 0 {
+		return 0, nil
+	}
+
+	nonce := buf[:nonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, err
+	}
+
+	sealed := aead.Seal(buf[:nonceSize], nonce, buf[nonceSize:nonceSize+n], nil)
+	if _, err := file.WriteAt(sealed, encryptedPieceOffset(aead, piece)); err != nil {
+		return 0, err
+	}
+
+	return int64(n), nil
+}
+
+// openPieces returns the pieces which overlap with the range [start, start+length), sorted by range start.
+func openPieces(pieces map[int32]PieceMetadata, start, length int64) ([]PieceMetadata, error) {
+	var result []PieceMetadata
+	for _, piece := range pieces {
+		if piece.Range.Start+piece.Range.Length > start && piece.Range.Start < start+length {
+			result = append(result, piece)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Range.Start < result[j].Range.Start
+	})
+
+	// Check pieces cover the whole range.
+	next := start
+	for _, piece := range result {
+		if piece.Range.Start > next {
+			return nil, ErrPieceNotFound
+		}
+		next = piece.Range.Start + piece.Range.Length
+	}
+
+	if next < start+length {
+		return nil, ErrPieceNotFound
+	}
+
+	return result, nil
+}
+
+// decryptReader decrypts the sealed pieces of task data file piece by piece.
+type decryptReader struct {
+	file   *os.File
+	aead   cipher.AEAD
+	pieces []PieceMetadata
+	// skip is the length to skip in the first piece.
+	skip int64
+	// remain is the length to read.
+	remain int64
+	buf    []byte
+}
+
+// newDecryptReader returns a reader of the range [start, start+length) in the encrypted task data file.
+func newDecryptReader(file *os.File, aead cipher.AEAD, pieces map[int32]PieceMetadata, start, length int64) (*decryptReader, error) {
+	overlapped, err := openPieces(pieces, start, length)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &decryptReader{
+		file:   file,
+		aead:   aead,
+		pieces: overlapped,
+		remain: length,
+	}
+
+	if len(overlapped) > 0 {
+		r.skip = start - overlapped[0].Range.Start
+	}
+
+	return r, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, io.EOF
+	}
+
+	if len(r.buf) == 0 {
+		if len(r.pieces) == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		piece := r.pieces[0]
+		r.pieces = r.pieces[1:]
+
+		sealed := make([]byte, piece.Range.Length+pieceOverhead(r.aead))
+		if _, err := r.file.ReadAt(sealed, encryptedPieceOffset(r.aead, piece)); err != nil {
+			return 0, err
+		}
+
+		nonce, ciphertext := sealed[:r.aead.NonceSize()], sealed[r.aead.NonceSize():]
+		plaintext, err := r.aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+		if err != nil {
+			return 0, fmt.Errorf("decrypt piece %d: %w", piece.Num, err)
+		}
+
+		r.buf = plaintext[r.skip:]
+		r.skip = 0
+	}
+
+	if int64(len(r.buf)) > r.remain {
+		r.buf = r.buf[:r.remain]
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.remain -= int64(n)
+	return n, nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.file.Close()
+}
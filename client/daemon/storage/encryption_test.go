@@ -0,0 +1,262 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/test"
+	clientutil "d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/pkg/net/http"
+)
+
+func writeKeyFile(t *testing.T, dir string) string {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.Nil(t, err)
+
+	keyFile := path.Join(dir, "key")
+	require.Nil(t, os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0600))
+	return keyFile
+}
+
+func TestKeyFileKMS(t *testing.T) {
+	assert := testifyassert.New(t)
+	dir := t.TempDir()
+
+	kms, err := NewKeyFileKMS(writeKeyFile(t, dir))
+	require.Nil(t, err)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	wrappedKey, err := kms.WrapKey(context.Background(), key)
+	assert.Nil(err)
+	assert.NotContains(string(wrappedKey), string(key))
+
+	unwrappedKey, err := kms.UnwrapKey(context.Background(), wrappedKey)
+	assert.Nil(err)
+	assert.Equal(key, unwrappedKey)
+
+	_, err = kms.UnwrapKey(context.Background(), wrappedKey[:4])
+	assert.Error(err)
+
+	invalidKeyFile := path.Join(dir, "invalid")
+	require.Nil(t, os.WriteFile(invalidKeyFile, []byte("abcd"), 0600))
+	_, err = NewKeyFileKMS(invalidKeyFile)
+	assert.Error(err)
+
+	_, err = NewKeyFileKMS(path.Join(dir, "not-exist"))
+	assert.Error(err)
+}
+
+func TestLocalTaskStore_Encryption(t *testing.T) {
+	assert := testifyassert.New(t)
+	testBytes, err := os.ReadFile(test.File)
+	require.Nil(t, err, "load test file")
+
+	var (
+		dir       = t.TempDir()
+		taskID    = "task-d4bb1c273a9889fea14abd4651994fe8"
+		peerID    = "peer-d4bb1c273a9889fea14abd4651994fe8"
+		pieceSize = 512
+		opt       = &config.StorageOption{
+			DataPath: path.Join(dir, "storage"),
+			TaskExpireTime: clientutil.Duration{
+				Duration: time.Minute,
+			},
+			Encryption: config.EncryptionOption{
+				Enable:  true,
+				KeyFile: writeKeyFile(t, dir),
+			},
+		}
+	)
+
+	sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, opt, func(request CommonTaskRequest) {}, defaultDirectoryMode)
+	require.Nil(t, err)
+
+	ts, err := sm.(*storageManager).CreateTask(&RegisterTaskRequest{
+		PeerTaskMetadata: PeerTaskMetadata{
+			PeerID: peerID,
+			TaskID: taskID,
+		},
+		DesiredLocation: path.Join(dir, "output"),
+		ContentLength:   int64(len(testBytes)),
+	})
+	require.Nil(t, err)
+
+	// write pieces in reverse order
+	var totalPieces int32
+	for i := (len(testBytes) - 1) / pieceSize; i >= 0; i-- {
+		start := i * pieceSize
+		end := min(start+pieceSize, len(testBytes))
+		n, err := ts.WritePiece(context.Background(), &WritePieceRequest{
+			PieceMetadata: PieceMetadata{
+				Num:    int32(i),
+				Md5:    calcPieceMd5(testBytes[start:end]),
+				Offset: uint64(start),
+				Range: http.Range{
+					Start:  int64(start),
+					Length: int64(end - start),
+				},
+			},
+			Reader: bytes.NewBuffer(testBytes[start:end]),
+		})
+		assert.Nil(err)
+		assert.Equal(int64(end-start), n)
+		totalPieces++
+	}
+
+	assert.Nil(ts.UpdateTask(context.Background(), &UpdateTaskRequest{
+		ContentLength: int64(len(testBytes)),
+		TotalPieces:   totalPieces,
+	}))
+
+	// task data is not stored in plaintext
+	data, err := os.ReadFile(ts.(*localTaskStore).DataFilePath)
+	assert.Nil(err)
+	assert.Equal(len(testBytes)+int(totalPieces)*(12+16), len(data))
+	assert.False(bytes.Contains(data, testBytes[:pieceSize]))
+
+	readPiece := func(ts TaskStorageDriver, rg http.Range, unverified bool) ([]byte, error) {
+		r, c, err := ts.ReadPiece(context.Background(), &ReadPieceRequest{
+			PieceMetadata: PieceMetadata{
+				Num:   -1,
+				Range: rg,
+			},
+			Unverified: unverified,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		return io.ReadAll(r)
+	}
+
+	readAll := func(ts TaskStorageDriver, rg *http.Range) []byte {
+		rc, err := ts.ReadAllPieces(context.Background(), &ReadAllPiecesRequest{Range: rg})
+		require.Nil(t, err)
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		assert.Nil(err)
+		return data
+	}
+
+	// read a piece
+	data, err = readPiece(ts, http.Range{Start: int64(pieceSize), Length: int64(pieceSize)}, false)
+	assert.Nil(err)
+	assert.Equal(testBytes[pieceSize:2*pieceSize], data)
+
+	// read range across pieces
+	data, err = readPiece(ts, http.Range{Start: 100, Length: int64(3 * pieceSize)}, false)
+	assert.Nil(err)
+	assert.Equal(testBytes[100:100+3*pieceSize], data)
+
+	// unverified peers can not read encrypted pieces
+	_, err = readPiece(ts, http.Range{Start: 0, Length: int64(pieceSize)}, true)
+	assert.ErrorIs(err, ErrUnauthorized)
+
+	// read all pieces
+	assert.Equal(testBytes, readAll(ts, nil))
+	assert.Equal(testBytes[1000:3000], readAll(ts, &http.Range{Start: 1000, Length: 2000}))
+
+	// store decrypted data to destination
+	dst := path.Join(dir, "output")
+	assert.Nil(ts.Store(context.Background(), &StoreRequest{
+		CommonTaskRequest: CommonTaskRequest{
+			PeerID:      peerID,
+			TaskID:      taskID,
+			Destination: dst,
+		},
+	}))
+	data, err = os.ReadFile(dst)
+	assert.Nil(err)
+	assert.Equal(testBytes, data)
+
+	assert.ErrorIs(ts.Store(context.Background(), &StoreRequest{
+		CommonTaskRequest: CommonTaskRequest{
+			PeerID:      peerID,
+			TaskID:      taskID,
+			Destination: dst,
+		},
+		StoreDataOnly:  true,
+		OriginalOffset: true,
+	}), ErrNotSupported)
+
+	// reload encrypted task from disk
+	sm, err = NewStorageManager(config.SimpleLocalTaskStoreStrategy, opt, func(request CommonTaskRequest) {}, defaultDirectoryMode)
+	require.Nil(t, err)
+
+	reloaded, ok := sm.(*storageManager).LoadTask(PeerTaskMetadata{
+		PeerID: peerID,
+		TaskID: taskID,
+	})
+	require.True(t, ok)
+	assert.Equal(testBytes, readAll(reloaded, nil))
+}
+
+func TestSealPiece(t *testing.T) {
+	assert := testifyassert.New(t)
+	aead, err := newAEAD(bytes.Repeat([]byte{1}, dataKeySize))
+	require.Nil(t, err)
+
+	file, err := os.Create(path.Join(t.TempDir(), "data"))
+	require.Nil(t, err)
+	defer file.Close()
+
+	piece := PieceMetadata{
+		Num:   1,
+		Range: http.Range{Start: 4, Length: 4},
+	}
+
+	readSealed := func() []byte {
+		sealed := make([]byte, piece.Range.Length+pieceOverhead(aead))
+		_, err := file.ReadAt(sealed, encryptedPieceOffset(aead, piece))
+		assert.Nil(err)
+		return sealed
+	}
+
+	// the piece rejected by digest check is written again with the data from another parent
+	n, err := sealPiece(aead, file, piece, bytes.NewBufferString("evil"))
+	assert.Nil(err)
+	assert.Equal(int64(4), n)
+	rejected := readSealed()
+
+	n, err = sealPiece(aead, file, piece, bytes.NewBufferString("good"))
+	assert.Nil(err)
+	assert.Equal(int64(4), n)
+	sealed := readSealed()
+
+	// the nonce is not reused under the same data key
+	assert.NotEqual(rejected[:aead.NonceSize()], sealed[:aead.NonceSize()])
+
+	r, err := newDecryptReader(file, aead, map[int32]PieceMetadata{piece.Num: piece}, piece.Range.Start, piece.Range.Length)
+	require.Nil(t, err)
+	data, err := io.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal("good", string(data))
+}
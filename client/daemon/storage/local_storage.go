@@ -18,13 +18,18 @@ package storage
 
 import (
 	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 	"os"
 	"path"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -35,6 +40,7 @@ import (
 
 	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/internal/util"
 	"d7y.io/dragonfly/v2/pkg/digest"
@@ -64,6 +70,9 @@ type localTaskStore struct {
 	// content stores tiny file which length less than 128 bytes
 	content []byte
 
+	// aead encrypts task data with the data key, it is nil when task is not encrypted
+	aead cipher.AEAD
+
 	subtasks map[PeerTaskMetadata]*localSubTaskStore
 }
 
@@ -135,11 +144,23 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 		}
 	}()
 
-	if _, err = file.Seek(req.Range.Start, io.SeekStart); err != nil {
-		return 0, err
+	// md5 is still read from req.Reader, sha256 is calculated along with writing
+	reader := req.Reader
+	var sha256Hash hash.Hash
+	if req.CalcSha256 {
+		sha256Hash = sha256.New()
+		reader = io.TeeReader(req.Reader, sha256Hash)
 	}
 
-	n, err = tryWriteWithBuffer(file, req.Reader, req.Range.Length)
+	if t.aead != nil {
+		n, err = sealPiece(t.aead, file, req.PieceMetadata, reader)
+	} else {
+		if _, err = file.Seek(req.Range.Start, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		n, err = tryWriteWithBuffer(file, reader, req.Range.Length)
+	}
 	if err != nil {
 		return n, err
 	}
@@ -168,6 +189,17 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 		}
 	}
 
+	// verify piece with the leaf of merkle tree before it is visible to other peers
+	if sha256Hash != nil {
+		encoded := hex.EncodeToString(sha256Hash.Sum(nil))
+		if req.PieceMetadata.Sha256 != "" && req.PieceMetadata.Sha256 != encoded {
+			t.Warnf("piece %d sha256 not match, desired: %s, actual: %s", req.Num, req.PieceMetadata.Sha256, encoded)
+			return n, ErrPieceDigestMismatch
+		}
+
+		req.PieceMetadata.Sha256 = encoded
+	}
+
 	// when Md5 is empty, try to get md5 from reader, it's useful for back source
 	if req.PieceMetadata.Md5 == "" {
 		t.Debugf("piece %d md5 not found in metadata, read from reader", req.PieceMetadata.Num)
@@ -189,7 +221,11 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 	}
 	req.PieceMetadata.Cost = uint64(time.Now().UnixNano() - start)
 	t.Pieces[req.Num] = req.PieceMetadata
+	if !req.FromSource {
+		t.ParentPieces = true
+	}
 	t.genMetadata(n, req)
+	t.genMerkleTree()
 	return n, nil
 }
 
@@ -214,6 +250,28 @@ func (t *localTaskStore) genMetadata(n int64, req *WritePieceRequest) {
 	digest := digest.SHA256FromStrings(pieceDigests...)
 	t.PieceMd5Sign = digest
 	t.Infof("generated digest: %s, total pieces: %d, content length: %d", digest, t.TotalPieces, t.ContentLength)
+	t.genMerkleTree()
+}
+
+// genMerkleTree generates the merkle tree when sha256 of all pieces are calculated,
+// the tree received from parent is kept.
+func (t *localTaskStore) genMerkleTree() {
+	if t.Merkle != nil || t.TotalPieces <= 0 || len(t.Pieces) != int(t.TotalPieces) {
+		return
+	}
+
+	leaves := make([]string, 0, t.TotalPieces)
+	for i := int32(0); i < t.TotalPieces; i++ {
+		piece, ok := t.Pieces[i]
+		if !ok || piece.Sha256 == "" {
+			return
+		}
+
+		leaves = append(leaves, piece.Sha256)
+	}
+
+	t.Merkle = integrity.NewMerkleTree(leaves)
+	t.Infof("generated merkle root: %s", t.Merkle.Root)
 }
 
 func (t *localTaskStore) UpdateTask(ctx context.Context, req *UpdateTaskRequest) error {
@@ -241,9 +299,32 @@ func (t *localTaskStore) UpdateTask(ctx context.Context, req *UpdateTaskRequest)
 		t.Header = req.Header
 		t.Debugf("update header: %#v", t.Header)
 	}
+	if req.PieceSize > 0 && t.TaskMeta[TaskMetaPieceSize] == "" {
+		if t.TaskMeta == nil {
+			t.TaskMeta = map[string]string{}
+		}
+		t.TaskMeta[TaskMetaPieceSize] = strconv.FormatUint(uint64(req.PieceSize), 10)
+		t.Debugf("update piece size: %d", req.PieceSize)
+	}
+	// signed tree replaces the unsigned one with the same root
+	if req.Merkle != nil && (t.Merkle == nil || t.Merkle.Root == req.Merkle.Root) {
+		t.Merkle = req.Merkle
+		t.Debugf("update merkle root: %s, signed: %t", t.Merkle.Root, t.Merkle.Signed())
+	}
+	t.genMerkleTree()
 	return nil
 }
 
+// computePieceSize returns the piece size recorded in task meta,
+// falls back to compute it from content length for tasks without the record.
+func (t *localTaskStore) computePieceSize(length int64) uint32 {
+	if size, err := strconv.ParseUint(t.TaskMeta[TaskMetaPieceSize], 10, 32); err == nil && size > 0 {
+		return uint32(size)
+	}
+
+	return util.ComputePieceSize(length)
+}
+
 func (t *localTaskStore) ValidateDigest(*PeerTaskMetadata) error {
 	t.Lock()
 	defer t.Unlock()
@@ -286,6 +367,11 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 		return nil, nil, ErrInvalidDigest
 	}
 
+	if t.aead != nil && req.Unverified {
+		t.Warnf("unverified peer, refuse to read encrypted pieces")
+		return nil, nil, ErrUnauthorized
+	}
+
 	t.touch()
 	file, err := os.Open(t.DataFilePath)
 	if err != nil {
@@ -306,6 +392,18 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 		}
 	}
 
+	if t.aead != nil {
+		t.RLock()
+		reader, err := newDecryptReader(file, t.aead, t.Pieces, req.Range.Start, req.Range.Length)
+		t.RUnlock()
+		if err != nil {
+			file.Close()
+			t.Errorf("open encrypted pieces failed: %v", err)
+			return nil, nil, err
+		}
+		return reader, reader, nil
+	}
+
 	if _, err = file.Seek(req.Range.Start, io.SeekStart); err != nil {
 		file.Close()
 		t.Errorf("file seek failed: %v", err)
@@ -329,6 +427,10 @@ func (t *localTaskStore) ReadAllPieces(ctx context.Context, req *ReadAllPiecesRe
 		return nil, err
 	}
 
+	if t.aead != nil {
+		return t.readAllEncryptedPieces(file, req.Range)
+	}
+
 	if req.Range == nil {
 		// by jim: for some corner case, avoid the io.Copy call superfluous sendfile syscall
 		// then increase network latency
@@ -350,6 +452,26 @@ func (t *localTaskStore) ReadAllPieces(ctx context.Context, req *ReadAllPiecesRe
 	}, nil
 }
 
+// readAllEncryptedPieces returns a reader which decrypts the pieces in range, the whole task data when range is nil.
+func (t *localTaskStore) readAllEncryptedPieces(file *os.File, rg *http.Range) (io.ReadCloser, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	start, length := int64(0), t.ContentLength
+	if rg != nil {
+		start, length = rg.Start, rg.Length
+	}
+
+	reader, err := newDecryptReader(file, t.aead, t.Pieces, start, length)
+	if err != nil {
+		file.Close()
+		t.Errorf("open encrypted pieces failed: %v", err)
+		return nil, err
+	}
+
+	return reader, nil
+}
+
 func (t *localTaskStore) Store(ctx context.Context, req *StoreRequest) (err error) {
 	// Store is called in callback.Done, mark local task store done, for fast search
 	t.Done = true
@@ -376,6 +498,9 @@ func (t *localTaskStore) Store(ctx context.Context, req *StoreRequest) (err erro
 	defer globalFSWriteLock.UnlockKey(req.Destination)
 
 	if req.OriginalOffset {
+		if t.aead != nil {
+			return ErrNotSupported
+		}
 		return hardlink(t.SugaredLoggerOnWith, req.Destination, t.DataFilePath)
 	}
 
@@ -390,6 +515,11 @@ func (t *localTaskStore) Store(ctx context.Context, req *StoreRequest) (err erro
 			return err
 		}
 	}
+
+	// encrypted task data can not be linked, decrypt it to destination
+	if t.aead != nil {
+		return t.storeDecrypted(ctx, req.Destination)
+	}
 	// 1. try to link
 	err = os.Link(t.DataFilePath, req.Destination)
 	if err == nil {
@@ -431,6 +561,34 @@ func (t *localTaskStore) Store(ctx context.Context, req *StoreRequest) (err erro
 	return err
 }
 
+// storeDecrypted decrypts the task data to the destination.
+func (t *localTaskStore) storeDecrypted(ctx context.Context, destination string) (err error) {
+	reader, err := t.ReadAllPieces(ctx, &ReadAllPiecesRequest{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := reader.Close(); cerr != nil {
+			err = errors.Join(err, cerr)
+		}
+	}()
+
+	dstFile, err := os.OpenFile(destination, os.O_CREATE|os.O_RDWR|os.O_TRUNC, defaultFileMode)
+	if err != nil {
+		t.Errorf("open tasks destination file error: %s", err)
+		return err
+	}
+	defer func() {
+		if cerr := dstFile.Close(); cerr != nil {
+			err = errors.Join(err, cerr)
+		}
+	}()
+
+	n, err := io.Copy(dstFile, reader)
+	t.Debugf("decrypted tasks data %d bytes to %s", n, destination)
+	return err
+}
+
 func (t *localTaskStore) GetPieces(ctx context.Context, req *commonv1.PieceTaskRequest) (*commonv1.PiecePacket, error) {
 	if req == nil {
 		return nil, ErrBadRequest
@@ -685,7 +843,7 @@ func (t *localTaskStore) partialCompleted(rg *http.Range) bool {
 		realRange.Length = t.ContentLength - realRange.Start
 	}
 
-	start, end := computePiecePosition(t.ContentLength, realRange, util.ComputePieceSize)
+	start, end := computePiecePosition(t.ContentLength, realRange, t.computePieceSize)
 	// fix int overflow
 	if start < 0 || end < 0 {
 		t.Warnf("wrong start and end piece num, %d, %d", start, end)
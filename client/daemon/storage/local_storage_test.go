@@ -582,3 +582,94 @@ func TestLocalTaskStore_CanReclaim(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalTaskStore_MerkleTree(t *testing.T) {
+	assert := testifyassert.New(t)
+	testBytes, err := os.ReadFile(test.File)
+	assert.Nil(err, "load test file")
+
+	var (
+		dir       = t.TempDir()
+		taskID    = "task-d4bb1c273a9889fea14abd4651994fe9"
+		peerID    = "peer-d4bb1c273a9889fea14abd4651994fe9"
+		pieceSize = 1024
+		opt       = &config.StorageOption{
+			DataPath: path.Join(dir, "storage"),
+			TaskExpireTime: clientutil.Duration{
+				Duration: time.Minute,
+			},
+		}
+	)
+
+	sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, opt, func(request CommonTaskRequest) {}, defaultDirectoryMode)
+	assert.Nil(err)
+
+	ts, err := sm.(*storageManager).CreateTask(&RegisterTaskRequest{
+		PeerTaskMetadata: PeerTaskMetadata{
+			PeerID: peerID,
+			TaskID: taskID,
+		},
+		DesiredLocation: path.Join(dir, "output"),
+		ContentLength:   int64(len(testBytes)),
+	})
+	assert.Nil(err)
+
+	writePiece := func(num int, sha256 string, data []byte) error {
+		start := num * pieceSize
+		_, err := ts.WritePiece(context.Background(), &WritePieceRequest{
+			PieceMetadata: PieceMetadata{
+				Num:    int32(num),
+				Md5:    calcPieceMd5(data),
+				Sha256: sha256,
+				Offset: uint64(start),
+				Range: http.Range{
+					Start:  int64(start),
+					Length: int64(len(data)),
+				},
+			},
+			Reader:     bytes.NewBuffer(data),
+			CalcSha256: true,
+		})
+		return err
+	}
+
+	var leaves []string
+	for i := 0; i*pieceSize < len(testBytes); i++ {
+		data := testBytes[i*pieceSize : min((i+1)*pieceSize, len(testBytes))]
+		leaves = append(leaves, digest.SHA256FromBytes(data))
+	}
+
+	// corrupt piece is rejected
+	corrupt := bytes.Repeat([]byte{'x'}, pieceSize)
+	assert.ErrorIs(writePiece(0, leaves[0], corrupt), ErrPieceDigestMismatch)
+	_, ok := ts.(*localTaskStore).Pieces[0]
+	assert.False(ok)
+
+	for i, leaf := range leaves {
+		data := testBytes[i*pieceSize : min((i+1)*pieceSize, len(testBytes))]
+		assert.Nil(writePiece(i, leaf, data))
+	}
+
+	// merkle tree is generated after total pieces is known
+	_, err = sm.GetMerkleTree(&PeerTaskMetadata{PeerID: peerID, TaskID: taskID})
+	assert.ErrorIs(err, ErrMerkleNotSet)
+
+	assert.Nil(ts.UpdateTask(context.Background(), &UpdateTaskRequest{
+		ContentLength: int64(len(testBytes)),
+		TotalPieces:   int32(len(leaves)),
+	}))
+
+	tree, err := sm.GetMerkleTree(&PeerTaskMetadata{PeerID: peerID, TaskID: taskID})
+	assert.Nil(err)
+	assert.Equal(digest.MerkleRoot(leaves...), tree.Root)
+	assert.Equal(leaves, tree.Leaves)
+	// pieces are not downloaded from source, the tree must not be signed by seed peer
+	assert.False(tree.BackSourced)
+
+	// signed tree replaces the unsigned one with the same root
+	tree.Signature = []byte("signature")
+	assert.Nil(ts.UpdateTask(context.Background(), &UpdateTaskRequest{Merkle: tree}))
+	tree, err = sm.GetMerkleTree(&PeerTaskMetadata{PeerID: peerID, TaskID: taskID})
+	assert.Nil(err)
+	assert.True(tree.Signed())
+}
@@ -21,6 +21,7 @@ import (
 
 	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	"d7y.io/dragonfly/v2/pkg/net/http"
 	"d7y.io/dragonfly/v2/pkg/source"
 )
@@ -37,6 +38,12 @@ type persistentMetadata struct {
 	DataFilePath  string                  `json:"dataFilePath"`
 	Done          bool                    `json:"done"`
 	Header        *source.Header          `json:"header"`
+	// EncryptedKey is the data key of encrypted task wrapped by kms
+	EncryptedKey []byte `json:"encryptedKey,omitempty"`
+	// Merkle is the merkle tree over sha256 digests of pieces
+	Merkle *integrity.MerkleTree `json:"merkle,omitempty"`
+	// ParentPieces indicates some pieces are not downloaded from source, they are written by parents
+	ParentPieces bool `json:"parentPieces,omitempty"`
 }
 
 type PeerTaskMetadata struct {
@@ -47,6 +54,7 @@ type PeerTaskMetadata struct {
 type PieceMetadata struct {
 	Num    int32               `json:"num,omitempty"`
 	Md5    string              `json:"md5,omitempty"`
+	Sha256 string              `json:"sha256,omitempty"`
 	Offset uint64              `json:"offset,omitempty"`
 	Range  http.Range          `json:"range,omitempty"`
 	Style  commonv1.PieceStyle `json:"style,omitempty"`
@@ -62,6 +70,8 @@ type CommonTaskRequest struct {
 
 type RegisterTaskRequest struct {
 	PeerTaskMetadata
+	Application     string
+	Priority        commonv1.Priority
 	DesiredLocation string
 	ContentLength   int64
 	TotalPieces     int32
@@ -73,6 +83,11 @@ type WritePieceRequest struct {
 	PieceMetadata
 	UnknownLength bool
 	Reader        io.Reader
+	// CalcSha256 calculates sha256 of piece data for merkle tree,
+	// the piece is rejected when it does not match PieceMetadata.Sha256
+	CalcSha256 bool
+	// FromSource indicates the piece is downloaded from source or imported from local file
+	FromSource bool
 	// NeedGenMetadata is used after the last piece in back source case
 	NeedGenMetadata func(n int64) (total int32, contentLength int64, gen bool)
 }
@@ -90,6 +105,9 @@ type StoreRequest struct {
 type ReadPieceRequest struct {
 	PeerTaskMetadata
 	PieceMetadata
+	// Unverified indicates the piece is read by a remote peer without verified identity,
+	// the data of encrypted task is not decrypted for it.
+	Unverified bool
 }
 
 type ReadAllPiecesRequest struct {
@@ -109,6 +127,10 @@ type UpdateTaskRequest struct {
 	TotalPieces   int32
 	PieceMd5Sign  string
 	Header        *source.Header
+	// PieceSize is the size of all pieces except the last one, 0 means unknown
+	PieceSize uint32
+	// Merkle is the verified merkle tree of task
+	Merkle *integrity.MerkleTree
 }
 
 type ReusePeerTask struct {
@@ -17,6 +17,7 @@ import (
 
 	common "d7y.io/api/v2/pkg/apis/common/v1"
 	dfdaemon "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
+	integrity "d7y.io/dragonfly/v2/client/daemon/integrity"
 	storage "d7y.io/dragonfly/v2/client/daemon/storage"
 	http "d7y.io/dragonfly/v2/pkg/net/http"
 	gomock "go.uber.org/mock/gomock"
@@ -365,6 +366,21 @@ func (mr *MockManagerMockRecorder) GetExtendAttribute(ctx, req any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExtendAttribute", reflect.TypeOf((*MockManager)(nil).GetExtendAttribute), ctx, req)
 }
 
+// GetMerkleTree mocks base method.
+func (m *MockManager) GetMerkleTree(req *storage.PeerTaskMetadata) (*integrity.MerkleTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerkleTree", req)
+	ret0, _ := ret[0].(*integrity.MerkleTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerkleTree indicates an expected call of GetMerkleTree.
+func (mr *MockManagerMockRecorder) GetMerkleTree(req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleTree", reflect.TypeOf((*MockManager)(nil).GetMerkleTree), req)
+}
+
 // GetPieces mocks base method.
 func (m *MockManager) GetPieces(ctx context.Context, req *common.PieceTaskRequest) (*common.PiecePacket, error) {
 	m.ctrl.T.Helper()
@@ -380,6 +396,21 @@ func (mr *MockManagerMockRecorder) GetPieces(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPieces", reflect.TypeOf((*MockManager)(nil).GetPieces), ctx, req)
 }
 
+// GetTaskMeta mocks base method.
+func (m *MockManager) GetTaskMeta(req *storage.PeerTaskMetadata) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskMeta", req)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskMeta indicates an expected call of GetTaskMeta.
+func (mr *MockManagerMockRecorder) GetTaskMeta(req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskMeta", reflect.TypeOf((*MockManager)(nil).GetTaskMeta), req)
+}
+
 // GetTotalPieces mocks base method.
 func (m *MockManager) GetTotalPieces(ctx context.Context, req *storage.PeerTaskMetadata) (int32, error) {
 	m.ctrl.T.Helper()
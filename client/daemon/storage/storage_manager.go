@@ -45,6 +45,7 @@ import (
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/gc"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
 	"d7y.io/dragonfly/v2/client/daemon/pex"
 	"d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
@@ -110,15 +111,24 @@ type Manager interface {
 	CleanUp()
 	// ListAllPeers return all peers info
 	ListAllPeers(perGroupCount int) [][]*dfdaemonv1.PeerMetadata
+	// GetTaskMeta returns the meta of task, like the application of task
+	GetTaskMeta(req *PeerTaskMetadata) (map[string]string, error)
+	// GetMerkleTree returns the merkle tree over piece digests of task
+	GetMerkleTree(req *PeerTaskMetadata) (*integrity.MerkleTree, error)
 }
 
 var (
-	ErrTaskNotFound     = errors.New("task not found")
-	ErrPieceNotFound    = errors.New("piece not found")
-	ErrPieceCountNotSet = errors.New("total piece count not set")
-	ErrDigestNotSet     = errors.New("digest not set")
-	ErrInvalidDigest    = errors.New("invalid digest")
-	ErrBadRequest       = errors.New("bad request")
+	ErrTaskNotFound        = errors.New("task not found")
+	ErrPieceNotFound       = errors.New("piece not found")
+	ErrPieceCountNotSet    = errors.New("total piece count not set")
+	ErrDigestNotSet        = errors.New("digest not set")
+	ErrInvalidDigest       = errors.New("invalid digest")
+	ErrBadRequest          = errors.New("bad request")
+	ErrUnauthorized        = errors.New("unauthorized to read encrypted task")
+	ErrKMSNotSet           = errors.New("kms not set for encrypted task")
+	ErrNotSupported        = errors.New("not supported for encrypted task")
+	ErrMerkleNotSet        = errors.New("merkle tree not set")
+	ErrPieceDigestMismatch = errors.New("piece digest mismatch")
 )
 
 const (
@@ -153,6 +163,9 @@ type storageManager struct {
 	subIndexTask2PeerTask map[string][]*localSubTaskStore // key: task id, value: slice of localSubTaskStore
 
 	peerSearchBroadcaster pex.PeerSearchBroadcaster
+
+	// kms wraps and unwraps the data keys of encrypted tasks
+	kms KMS
 }
 
 var _ gc.GC = (*storageManager)(nil)
@@ -209,6 +222,14 @@ func NewStorageManager(storeStrategy config.StoreStrategy, opt *config.StorageOp
 		}
 	}
 
+	if s.storeOption.Encryption.Enable && s.kms == nil {
+		kms, err := NewKeyFileKMS(s.storeOption.Encryption.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.kms = kms
+	}
+
 	if s.storeOption.ReloadGoroutineCount <= 0 {
 		s.storeOption.ReloadGoroutineCount = 64
 	}
@@ -250,6 +271,15 @@ func WithPeerSearchBroadcaster(peerSearchBroadcaster pex.PeerSearchBroadcaster)
 	}
 }
 
+// WithKMS sets the kms which wraps and unwraps the data keys of encrypted tasks,
+// the key file kms is used by default.
+func WithKMS(kms KMS) func(*storageManager) error {
+	return func(manager *storageManager) error {
+		manager.kms = kms
+		return nil
+	}
+}
+
 func (s *storageManager) RegisterTask(ctx context.Context, req *RegisterTaskRequest) (TaskStorageDriver, error) {
 	ts, ok := s.LoadTask(
 		PeerTaskMetadata{
@@ -288,6 +318,10 @@ func (s *storageManager) RegisterSubTask(ctx context.Context, req *RegisterSubTa
 		return nil, fmt.Errorf("task %s not found", req.Parent.TaskID)
 	}
 
+	if t.(*localTaskStore).aead != nil {
+		return nil, fmt.Errorf("subtask of task %s: %w", req.Parent.TaskID, ErrNotSupported)
+	}
+
 	subtask := t.(*localTaskStore).SubTask(req)
 	s.subIndexRWMutex.Lock()
 	if ts, ok := s.subIndexTask2PeerTask[req.SubTask.TaskID]; ok {
@@ -413,6 +447,65 @@ func (s *storageManager) LoadAndDeleteTask(meta PeerTaskMetadata) (TaskStorageDr
 	return d.(TaskStorageDriver), ok
 }
 
+func (s *storageManager) GetTaskMeta(req *PeerTaskMetadata) (map[string]string, error) {
+	t, ok := s.LoadTask(
+		PeerTaskMetadata{
+			TaskID: req.TaskID,
+			PeerID: req.PeerID,
+		})
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	var ts *localTaskStore
+	switch t := t.(type) {
+	case *localTaskStore:
+		ts = t
+	case *localSubTaskStore:
+		// subtask shares the task meta of parent
+		ts = t.parent
+	default:
+		return nil, ErrTaskNotFound
+	}
+
+	ts.RLock()
+	defer ts.RUnlock()
+	meta := make(map[string]string, len(ts.TaskMeta))
+	for k, v := range ts.TaskMeta {
+		meta[k] = v
+	}
+
+	return meta, nil
+}
+
+func (s *storageManager) GetMerkleTree(req *PeerTaskMetadata) (*integrity.MerkleTree, error) {
+	t, ok := s.LoadTask(
+		PeerTaskMetadata{
+			TaskID: req.TaskID,
+			PeerID: req.PeerID,
+		})
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	// subtask has different pieces from the merkle tree of parent
+	ts, ok := t.(*localTaskStore)
+	if !ok {
+		return nil, ErrMerkleNotSet
+	}
+
+	ts.RLock()
+	defer ts.RUnlock()
+	if ts.Merkle == nil {
+		return nil, ErrMerkleNotSet
+	}
+
+	// return a copy, the tree may be signed by caller
+	tree := *ts.Merkle
+	tree.BackSourced = !ts.ParentPieces
+	return &tree, nil
+}
+
 func (s *storageManager) UpdateTask(ctx context.Context, req *UpdateTaskRequest) error {
 	t, ok := s.LoadTask(
 		PeerTaskMetadata{
@@ -450,6 +543,14 @@ func (s *storageManager) CreateTask(req *RegisterTaskRequest) (TaskStorageDriver
 		SugaredLoggerOnWith: logger.With("task", req.TaskID, "peer", req.PeerID, "component", "localTaskStore"),
 	}
 
+	if req.Application != "" {
+		t.TaskMeta[TaskMetaApplication] = req.Application
+	}
+
+	if req.Priority != commonv1.Priority_LEVEL0 {
+		t.TaskMeta[TaskMetaPriority] = req.Priority.String()
+	}
+
 	dataDirMode := defaultDirectoryMode
 	// If dirMode isn't in config, use default
 	if s.dataDirMode != os.FileMode(0) {
@@ -465,6 +566,18 @@ func (s *storageManager) CreateTask(req *RegisterTaskRequest) (TaskStorageDriver
 	if req.DesiredLocation == "" {
 		t.StoreStrategy = string(config.SimpleLocalTaskStoreStrategy)
 	}
+
+	// encrypted task data is kept in data dir, and decrypted to the desired location when storing
+	if s.storeOption.Encryption.Enable {
+		aead, encryptedKey, err := newDataKey(context.Background(), s.kms)
+		if err != nil {
+			return nil, err
+		}
+
+		t.aead = aead
+		t.EncryptedKey = encryptedKey
+		t.StoreStrategy = string(config.SimpleLocalTaskStoreStrategy)
+	}
 	data := path.Join(dataDir, taskData)
 	switch t.StoreStrategy {
 	case string(config.SimpleLocalTaskStoreStrategy):
@@ -812,6 +925,14 @@ func (s *storageManager) reloadPersistentTaskByPeerDir(gcCallback GCCallback, ta
 			Warnf("load task from disk error: %s, data base64 encode: %s", err, base64.StdEncoding.EncodeToString(bytes))
 		return err
 	}
+	if len(t.EncryptedKey) > 0 {
+		if t.aead, err = loadDataKey(context.Background(), s.kms, t.EncryptedKey); err != nil {
+			logger.With("action", "reload", "stage", "load data key", "taskID", taskID, "peerID", peerID).
+				Warnf("load task data key error: %s", err)
+			return err
+		}
+	}
+
 	logger.Debugf("load task %s/%s from disk, metadata %s, last access: %v, expire time: %s",
 		t.persistentMetadata.TaskID, t.persistentMetadata.PeerID, t.metadataFilePath, time.Unix(0, t.lastAccess.Load()), t.expireTime)
 	s.tasks.Store(PeerTaskMetadata{
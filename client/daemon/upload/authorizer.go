@@ -0,0 +1,152 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+const (
+	// peerIdentityScheme is the scheme of peer identity in the URI SANs of certificate.
+	peerIdentityScheme = "dragonfly"
+
+	// peerIdentitySchedulerCluster is the host of scheduler cluster identity,
+	// like dragonfly://scheduler-cluster/1.
+	peerIdentitySchedulerCluster = "scheduler-cluster"
+
+	// peerIdentityApplication is the host of application identity,
+	// like dragonfly://application/foo.
+	peerIdentityApplication = "application"
+)
+
+// PeerIdentity is the identity of peer carried by the URI SANs of certificate.
+type PeerIdentity struct {
+	// SchedulerClusterID is the scheduler cluster of peer.
+	SchedulerClusterID uint64
+
+	// Applications are the applications allowed to peer.
+	Applications []string
+}
+
+// ParsePeerIdentity parses the peer identity from the URI SANs of certificate.
+func ParsePeerIdentity(cert *x509.Certificate) (*PeerIdentity, error) {
+	var (
+		identity         = &PeerIdentity{}
+		schedulerCluster bool
+	)
+	for _, uri := range cert.URIs {
+		if uri.Scheme != peerIdentityScheme {
+			continue
+		}
+
+		value := strings.TrimPrefix(uri.Path, "/")
+		switch uri.Host {
+		case peerIdentitySchedulerCluster:
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid scheduler cluster %q: %w", value, err)
+			}
+
+			if schedulerCluster && id != identity.SchedulerClusterID {
+				return nil, errors.New("multiple scheduler clusters in certificate")
+			}
+
+			identity.SchedulerClusterID = id
+			schedulerCluster = true
+		case peerIdentityApplication:
+			if value != "" {
+				identity.Applications = append(identity.Applications, value)
+			}
+		}
+	}
+
+	if !schedulerCluster {
+		return nil, errors.New("scheduler cluster not found in certificate")
+	}
+
+	return identity, nil
+}
+
+// authorizer authorizes upload requests with the verified client certificate of peer.
+type authorizer struct {
+	// identity is the identity of local peer.
+	identity *PeerIdentity
+
+	// checkApplication indicates whether the requester must be allowed for the application of task.
+	checkApplication bool
+}
+
+// newAuthorizer returns a new authorizer, the identity of local peer is parsed from certPEM.
+func newAuthorizer(certPEM string, checkApplication bool) (*authorizer, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("invalid certificate pem")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := ParsePeerIdentity(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorizer{
+		identity:         identity,
+		checkApplication: checkApplication,
+	}, nil
+}
+
+// authorize authorizes the request for the task of application,
+// it returns the denied reason for metrics when the request is denied.
+func (a *authorizer) authorize(req *http.Request, application string) (string, error) {
+	if !isVerifiedPeer(req) {
+		return metrics.UploadDeniedReasonUnverified, errors.New("no verified client certificate")
+	}
+
+	identity, err := ParsePeerIdentity(req.TLS.VerifiedChains[0][0])
+	if err != nil {
+		return metrics.UploadDeniedReasonUnverified, err
+	}
+
+	if identity.SchedulerClusterID != a.identity.SchedulerClusterID {
+		return metrics.UploadDeniedReasonSchedulerCluster,
+			fmt.Errorf("scheduler cluster %d does not match %d", identity.SchedulerClusterID, a.identity.SchedulerClusterID)
+	}
+
+	if !a.checkApplication || application == "" {
+		return "", nil
+	}
+
+	for _, app := range identity.Applications {
+		if app == application {
+			return "", nil
+		}
+	}
+
+	return metrics.UploadDeniedReasonApplication, fmt.Errorf("application %s is not allowed", application)
+}
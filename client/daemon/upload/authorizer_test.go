@@ -0,0 +1,197 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+func mockPeerCertificate(t *testing.T, uris ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	for _, u := range uris {
+		uri, err := url.Parse(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = append(template.URIs, uri)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func TestParsePeerIdentity(t *testing.T) {
+	tests := []struct {
+		name   string
+		uris   []string
+		expect func(t *testing.T, identity *PeerIdentity, err error)
+	}{
+		{
+			name: "parse scheduler cluster and applications",
+			uris: []string{"dragonfly://scheduler-cluster/1", "dragonfly://application/foo", "dragonfly://application/bar", "spiffe://example.org/peer"},
+			expect: func(t *testing.T, identity *PeerIdentity, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(uint64(1), identity.SchedulerClusterID)
+				assert.Equal([]string{"foo", "bar"}, identity.Applications)
+			},
+		},
+		{
+			name: "scheduler cluster not found",
+			uris: []string{"dragonfly://application/foo"},
+			expect: func(t *testing.T, identity *PeerIdentity, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler cluster not found in certificate")
+			},
+		},
+		{
+			name: "invalid scheduler cluster",
+			uris: []string{"dragonfly://scheduler-cluster/foo"},
+			expect: func(t *testing.T, identity *PeerIdentity, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "multiple scheduler clusters",
+			uris: []string{"dragonfly://scheduler-cluster/1", "dragonfly://scheduler-cluster/2"},
+			expect: func(t *testing.T, identity *PeerIdentity, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "multiple scheduler clusters in certificate")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := ParsePeerIdentity(mockPeerCertificate(t, tc.uris...))
+			tc.expect(t, identity, err)
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	local := mockPeerCertificate(t, "dragonfly://scheduler-cluster/1")
+	a, err := newAuthorizer(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: local.Raw})), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		req         func(t *testing.T) *http.Request
+		application string
+		reason      string
+		ok          bool
+	}{
+		{
+			name: "no tls",
+			req: func(t *testing.T) *http.Request {
+				return &http.Request{}
+			},
+			reason: metrics.UploadDeniedReasonUnverified,
+		},
+		{
+			name: "no verified chains",
+			req: func(t *testing.T) *http.Request {
+				return &http.Request{TLS: &tls.ConnectionState{}}
+			},
+			reason: metrics.UploadDeniedReasonUnverified,
+		},
+		{
+			name: "scheduler cluster mismatch",
+			req: func(t *testing.T) *http.Request {
+				return mockVerifiedRequest(mockPeerCertificate(t, "dragonfly://scheduler-cluster/2"))
+			},
+			reason: metrics.UploadDeniedReasonSchedulerCluster,
+		},
+		{
+			name: "task without application",
+			req: func(t *testing.T) *http.Request {
+				return mockVerifiedRequest(mockPeerCertificate(t, "dragonfly://scheduler-cluster/1"))
+			},
+			ok: true,
+		},
+		{
+			name: "application allowed",
+			req: func(t *testing.T) *http.Request {
+				return mockVerifiedRequest(mockPeerCertificate(t, "dragonfly://scheduler-cluster/1", "dragonfly://application/foo"))
+			},
+			application: "foo",
+			ok:          true,
+		},
+		{
+			name: "application denied",
+			req: func(t *testing.T) *http.Request {
+				return mockVerifiedRequest(mockPeerCertificate(t, "dragonfly://scheduler-cluster/1", "dragonfly://application/bar"))
+			},
+			application: "foo",
+			reason:      metrics.UploadDeniedReasonApplication,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			reason, err := a.authorize(tc.req(t), tc.application)
+			assert.Equal(tc.ok, err == nil)
+			assert.Equal(tc.reason, reason)
+		})
+	}
+}
+
+func mockVerifiedRequest(cert *x509.Certificate) *http.Request {
+	return &http.Request{
+		TLS: &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		},
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/klauspost/compress/zstd"
+	"github.com/shirou/gopsutil/v3/cpu"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/cache"
+	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+)
+
+const (
+	// poorRatioTTL is the duration to send pieces of task raw after a piece is poorly compressed.
+	poorRatioTTL = 10 * time.Minute
+
+	// cpuSampleInterval is the minimal interval to sample cpu usage.
+	cpuSampleInterval = time.Second
+)
+
+// compressor compresses pieces with zstd, it skips compression when the sample piece
+// of task is poorly compressed or the cpu is saturated.
+type compressor struct {
+	encoder       *zstd.Encoder
+	minRatio      float64
+	maxCPUPercent float64
+
+	// poorRatioTasks records the tasks whose pieces are poorly compressed.
+	poorRatioTasks cache.Cache
+
+	cpuLock        sync.Mutex
+	cpuPercent     float64
+	cpuSampledAt   time.Time
+	cpuPercentFunc func() (float64, error)
+}
+
+// newCompressor returns a new compressor.
+func newCompressor(opt config.CompressionOption) (*compressor, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	if err != nil {
+		return nil, err
+	}
+
+	return &compressor{
+		encoder:        encoder,
+		minRatio:       opt.MinRatio,
+		maxCPUPercent:  opt.MaxCPUPercent,
+		poorRatioTasks: cache.New(poorRatioTTL, poorRatioTTL),
+		cpuPercentFunc: func() (float64, error) {
+			percents, err := cpu.Percent(0, false)
+			if err != nil || len(percents) == 0 {
+				return 0, err
+			}
+			return percents[0], nil
+		},
+	}, nil
+}
+
+// compress reads the piece and compresses it, it returns the original reader and length
+// when compression is skipped, otherwise the compressed data and true.
+func (c *compressor) compress(taskID string, reader io.Reader, length int64) (io.Reader, int64, bool, error) {
+	if _, ok := c.poorRatioTasks.Get(taskID); ok {
+		metrics.UploadCompressionSkippedCount.WithLabelValues(metrics.UploadCompressionSkippedReasonRatio).Inc()
+		return reader, length, false, nil
+	}
+
+	if c.cpuSaturated() {
+		metrics.UploadCompressionSkippedCount.WithLabelValues(metrics.UploadCompressionSkippedReasonCPU).Inc()
+		return reader, length, false, nil
+	}
+
+	raw := make([]byte, length)
+	if _, err := io.ReadFull(reader, raw); err != nil {
+		return nil, 0, false, err
+	}
+
+	data := c.encoder.EncodeAll(raw, make([]byte, 0, len(raw)/2))
+	if float64(len(raw)) < float64(len(data))*c.minRatio {
+		logger.Debugf("pieces of task %s are poorly compressed, %d bytes to %d bytes", taskID, len(raw), len(data))
+		c.poorRatioTasks.SetDefault(taskID, struct{}{})
+		metrics.UploadCompressionSkippedCount.WithLabelValues(metrics.UploadCompressionSkippedReasonRatio).Inc()
+		return bytes.NewReader(raw), length, false, nil
+	}
+
+	metrics.UploadCompressionSavedTraffic.Add(float64(len(raw) - len(data)))
+	return bytes.NewReader(data), int64(len(data)), true, nil
+}
+
+// cpuSaturated returns whether the cpu usage exceeds the limit, cpu usage is sampled
+// at most once per cpuSampleInterval.
+func (c *compressor) cpuSaturated() bool {
+	c.cpuLock.Lock()
+	defer c.cpuLock.Unlock()
+
+	if time.Since(c.cpuSampledAt) >= cpuSampleInterval {
+		percent, err := c.cpuPercentFunc()
+		if err != nil {
+			logger.Warnf("get cpu percent error: %s", err)
+		} else {
+			c.cpuPercent = percent
+		}
+		c.cpuSampledAt = time.Now()
+	}
+
+	return c.cpuPercent > c.maxCPUPercent
+}
+
+// acceptZstd returns whether the peer accepts zstd compressed pieces.
+func acceptZstd(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get(headers.AcceptEncoding), ",") {
+		encoding, _, _ = strings.Cut(encoding, ";")
+		if strings.TrimSpace(encoding) == nethttp.EncodingZstd {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-http-utils/headers"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+func TestCompressor_Compress(t *testing.T) {
+	compressible := bytes.Repeat([]byte("test test "), 1024)
+	incompressible := make([]byte, 10240)
+	_, err := rand.Read(incompressible)
+	require.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		cpuPercent float64
+		expect     func(t *testing.T, c *compressor, reader io.Reader, length int64, encoded bool)
+	}{
+		{
+			name:       "compress piece",
+			data:       compressible,
+			cpuPercent: 10,
+			expect: func(t *testing.T, c *compressor, reader io.Reader, length int64, encoded bool) {
+				assert := assert.New(t)
+				assert.True(encoded)
+				assert.Less(length, int64(len(compressible)))
+
+				decoder, err := zstd.NewReader(reader)
+				require.Nil(t, err)
+				defer decoder.Close()
+				data, err := io.ReadAll(decoder)
+				assert.Nil(err)
+				assert.Equal(compressible, data)
+			},
+		},
+		{
+			name:       "piece is poorly compressed",
+			data:       incompressible,
+			cpuPercent: 10,
+			expect: func(t *testing.T, c *compressor, reader io.Reader, length int64, encoded bool) {
+				assert := assert.New(t)
+				assert.False(encoded)
+				assert.Equal(int64(len(incompressible)), length)
+				data, err := io.ReadAll(reader)
+				assert.Nil(err)
+				assert.Equal(incompressible, data)
+
+				// The following pieces of task are sent raw.
+				_, ok := c.poorRatioTasks.Get("task")
+				assert.True(ok)
+				reader, length, encoded, err = c.compress("task", bytes.NewReader(compressible), int64(len(compressible)))
+				assert.Nil(err)
+				assert.False(encoded)
+				assert.Equal(int64(len(compressible)), length)
+			},
+		},
+		{
+			name:       "cpu is saturated",
+			data:       compressible,
+			cpuPercent: 90,
+			expect: func(t *testing.T, c *compressor, reader io.Reader, length int64, encoded bool) {
+				assert := assert.New(t)
+				assert.False(encoded)
+				assert.Equal(int64(len(compressible)), length)
+				data, err := io.ReadAll(reader)
+				assert.Nil(err)
+				assert.Equal(compressible, data)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := newCompressor(config.CompressionOption{
+				Enable:        true,
+				MinRatio:      config.DefaultUploadCompressionMinRatio,
+				MaxCPUPercent: config.DefaultUploadCompressionMaxCPUPercent,
+			})
+			require.Nil(t, err)
+			c.cpuPercentFunc = func() (float64, error) {
+				return tc.cpuPercent, nil
+			}
+
+			reader, length, encoded, err := c.compress("task", bytes.NewReader(tc.data), int64(len(tc.data)))
+			require.Nil(t, err)
+			tc.expect(t, c, reader, length, encoded)
+		})
+	}
+}
+
+func TestAcceptZstd(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expect         bool
+	}{
+		{acceptEncoding: "", expect: false},
+		{acceptEncoding: "gzip", expect: false},
+		{acceptEncoding: "zstd", expect: true},
+		{acceptEncoding: "gzip, zstd;q=0.9", expect: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.acceptEncoding, func(t *testing.T) {
+			req := &http.Request{Header: http.Header{}}
+			req.Header.Set(headers.AcceptEncoding, tc.acceptEncoding)
+			assert.Equal(t, tc.expect, acceptZstd(req))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// sendfile sends piece data straight from the task data file to the response,
+// the net/http server uses sendfile or splice syscall when the source is a file
+// and the connection is a plain tcp connection. It returns false when the reader
+// or the response does not support the fast path.
+func sendfile(w http.ResponseWriter, reader io.Reader) (int64, bool, error) {
+	lr, ok := reader.(*io.LimitedReader)
+	if !ok {
+		return 0, false, nil
+	}
+
+	if _, ok := lr.R.(*os.File); !ok {
+		return 0, false, nil
+	}
+
+	// Unwrap the middleware response writers to the net/http response.
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+
+	rf, ok := w.(io.ReaderFrom)
+	if !ok {
+		return 0, false, nil
+	}
+
+	n, err := rf.ReadFrom(lr)
+	return n, true, err
+}
@@ -21,6 +21,8 @@ package upload
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -34,11 +36,17 @@ import (
 	"github.com/go-http-utils/headers"
 	"github.com/johanbrandhorst/certify"
 	ginprometheus "github.com/mcuadros/go-gin-prometheus"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/soheilhy/cmux"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"golang.org/x/time/rate"
 
+	commonv1 "d7y.io/api/v2/pkg/apis/common/v1"
+
 	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/bandwidth"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
@@ -51,6 +59,7 @@ const (
 
 const (
 	RouterGroupDownload = "/download"
+	RouterGroupMerkle   = "/merkle"
 )
 
 var GinLogFileName = "gin-upload.log"
@@ -60,6 +69,9 @@ type Manager interface {
 	// Started upload manager server.
 	Serve(lis net.Listener) error
 
+	// ServeQUIC started upload manager HTTP/3 server.
+	ServeQUIC(conn net.PacketConn) error
+
 	// Stop upload manager server.
 	Stop() error
 }
@@ -68,8 +80,20 @@ type Manager interface {
 type uploadManager struct {
 	*http.Server
 	*rate.Limiter
+	quicServer     *http3.Server
 	storageManager storage.Manager
 	certify        *certify.Certify
+	security       config.SecurityOption
+	authorizer     *authorizer
+	compressor     *compressor
+
+	// signer signs merkle trees of tasks, it is set for seed peers only.
+	signer integrity.Signer
+
+	// budgets are the upload budgets of applications, budgetLimiters holds
+	// the limiter shared by tasks of every budget, nil means unlimited.
+	budgets        []*config.ApplicationBandwidth
+	budgetLimiters []*rate.Limiter
 }
 
 // Option is a functional option for configuring the upload manager.
@@ -88,10 +112,46 @@ func WithCertify(ct *certify.Certify) func(manager *uploadManager) {
 	}
 }
 
+// WithSigner sets the signer of merkle trees, seed peers sign trees when serving them.
+func WithSigner(signer integrity.Signer) func(*uploadManager) {
+	return func(manager *uploadManager) {
+		manager.signer = signer
+	}
+}
+
 // New returns a new Manager instance.
 func NewUploadManager(cfg *config.DaemonOption, storageManager storage.Manager, logDir string, opts ...Option) (Manager, error) {
 	um := &uploadManager{
 		storageManager: storageManager,
+		security:       cfg.Upload.Security,
+	}
+
+	if cfg.Upload.Authorization.Enable {
+		authorizer, err := newAuthorizer(string(cfg.Upload.Security.Cert), cfg.Upload.Authorization.CheckApplication)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upload authorization: %w", err)
+		}
+
+		um.authorizer = authorizer
+	}
+
+	if cfg.Upload.Compression.Enable {
+		compressor, err := newCompressor(cfg.Upload.Compression)
+		if err != nil {
+			return nil, err
+		}
+
+		um.compressor = compressor
+	}
+
+	for _, budget := range cfg.Bandwidth.Applications {
+		var limiter *rate.Limiter
+		if budget.Upload.Limit > 0 {
+			limiter = rate.NewLimiter(budget.Upload.Limit, int(budget.Upload.Limit))
+		}
+
+		um.budgets = append(um.budgets, budget)
+		um.budgetLimiters = append(um.budgetLimiters, limiter)
 	}
 
 	router := um.initRouter(cfg, logDir)
@@ -99,6 +159,12 @@ func NewUploadManager(cfg *config.DaemonOption, storageManager storage.Manager,
 		Handler: router,
 	}
 
+	if cfg.Upload.QUIC.Enable {
+		um.quicServer = &http3.Server{
+			Handler: router,
+		}
+	}
+
 	for _, opt := range opts {
 		opt(um)
 	}
@@ -124,15 +190,7 @@ func (um *uploadManager) Serve(listener net.Listener) error {
 	}()
 
 	go func() {
-		tlsConfig := &tls.Config{
-			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-				// FIXME peers need pure ip cert, certify checks the ServerName, so workaround here
-				hello.ServerName = "peer"
-				return um.certify.GetCertificate(hello)
-			},
-		}
-
-		tlsListener = tls.NewListener(tlsListener, tlsConfig)
+		tlsListener = tls.NewListener(tlsListener, um.tlsConfig())
 		if err := um.Server.Serve(tlsListener); err != nil {
 			logger.Debugf("upload server exit: %s", err)
 		}
@@ -140,11 +198,84 @@ func (um *uploadManager) Serve(listener net.Listener) error {
 	return m.Serve()
 }
 
+// ServeQUIC started upload manager HTTP/3 server, pieces are served over
+// multiplexed streams of one QUIC connection per peer.
+func (um *uploadManager) ServeQUIC(conn net.PacketConn) error {
+	if um.quicServer == nil {
+		return errors.New("quic upload server is not enabled")
+	}
+
+	if um.certify != nil {
+		um.quicServer.TLSConfig = um.tlsConfig()
+	} else if !um.security.Insecure {
+		// Use the same certificate and client verification as the TLS upload listener.
+		tlsConfig, err := securityTLSConfig(um.security)
+		if err != nil {
+			return err
+		}
+
+		um.quicServer.TLSConfig = tlsConfig
+	} else {
+		// Without certify, QUIC still requires TLS, peers skip verification
+		// as they do for plain HTTP uploads.
+		cert, err := generateSelfSignedCertificate()
+		if err != nil {
+			return err
+		}
+
+		um.quicServer.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{*cert},
+		}
+	}
+
+	return um.quicServer.Serve(conn)
+}
+
 // Stop upload manager server.
 func (um *uploadManager) Stop() error {
+	if um.quicServer != nil {
+		if err := um.quicServer.Close(); err != nil {
+			logger.Warnf("stop quic upload server error: %s", err)
+		}
+	}
+
 	return um.Server.Shutdown(context.Background())
 }
 
+// tlsConfig returns tls config of upload server with certify.
+func (um *uploadManager) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			// FIXME peers need pure ip cert, certify checks the ServerName, so workaround here
+			hello.ServerName = "peer"
+			return um.certify.GetCertificate(hello)
+		},
+	}
+}
+
+// securityTLSConfig returns tls config of upload server with security option.
+func securityTLSConfig(opt config.SecurityOption) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair([]byte(opt.Cert), []byte(opt.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if opt.CACert != "" {
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM([]byte(opt.CACert))
+		tlsConfig.ClientCAs = caCertPool
+		if opt.TLSVerify {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return tlsConfig, nil
+}
+
 // Initialize router of gin.
 func (um *uploadManager) initRouter(cfg *config.DaemonOption, logDir string) *gin.Engine {
 	// Set mode
@@ -168,6 +299,10 @@ func (um *uploadManager) initRouter(cfg *config.DaemonOption, logDir string) *gi
 			return RouterGroupDownload
 		}
 
+		if strings.HasPrefix(c.Request.URL.Path, RouterGroupMerkle) {
+			return RouterGroupMerkle
+		}
+
 		return c.Request.URL.Path
 	}
 	p.Use(r)
@@ -184,6 +319,10 @@ func (um *uploadManager) initRouter(cfg *config.DaemonOption, logDir string) *gi
 	d := r.Group(RouterGroupDownload)
 	d.GET(":task_prefix/:task_id", um.getDownload)
 
+	// Merkle tree of task.
+	m := r.Group(RouterGroupMerkle)
+	m.GET(":task_prefix/:task_id", um.getMerkleTree)
+
 	return r
 }
 
@@ -224,6 +363,15 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 		return
 	}
 
+	if um.authorizer != nil {
+		if reason, err := um.authorize(ctx.Request, taskID, peerID); err != nil {
+			log.Warnf("upload request from %s denied: %s", ctx.Request.RemoteAddr, err)
+			metrics.UploadDeniedCount.WithLabelValues(reason).Inc()
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": err.Error()})
+			return
+		}
+	}
+
 	reader, closer, err := um.storageManager.ReadPiece(ctx,
 		&storage.ReadPieceRequest{
 			PeerTaskMetadata: storage.PeerTaskMetadata{
@@ -234,37 +382,194 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 				Num:   -1,
 				Range: rg[0],
 			},
+			Unverified: !isVerifiedPeer(ctx.Request),
 		})
 	if err != nil {
 		log.Errorf("get task data failed: %s", err)
+		if errors.Is(err, storage.ErrUnauthorized) {
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": err.Error()})
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
 		return
 	}
 	defer closer.Close()
 
+	// Compress piece for peers which accept zstd, piece length is the compressed length then.
+	length := rg[0].Length
+	if um.compressor != nil && acceptZstd(ctx.Request) {
+		var encoded bool
+		reader, length, encoded, err = um.compressor.compress(taskID, reader, length)
+		if err != nil {
+			log.Errorf("compress piece failed: %s", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+			return
+		}
+
+		if encoded {
+			ctx.Header(headers.ContentEncoding, nethttp.EncodingZstd)
+		}
+	}
+
 	// Add header "Content-Length" to avoid chunked body in http client.
-	ctx.Header(headers.ContentLength, fmt.Sprintf("%d", rg[0].Length))
+	ctx.Header(headers.ContentLength, fmt.Sprintf("%d", length))
 
 	// write header immediately, prevent client disconnecting after limiter.Wait() due to response header timeout
 	ctx.Writer.WriteHeaderNow()
 	ctx.Writer.Flush()
 
-	if um.Limiter != nil {
-		if err = um.Limiter.WaitN(ctx, int(rg[0].Length)); err != nil {
+	// Rate limiting is in play when the limiter can not allow the piece immediately.
+	var limited bool
+	if um.Limiter != nil && !um.Limiter.AllowN(time.Now(), int(length)) {
+		limited = true
+		if err = um.Limiter.WaitN(ctx, int(length)); err != nil {
 			log.Errorf("get limit failed: %s", err)
 			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
 			return
 		}
 	}
 
-	// If w is a socket, golang will use sendfile or splice syscall for zero copy feature
-	// when start to transfer data, we could not call http.Error with header.
-	if n, err := io.Copy(ctx.Writer, reader); err != nil {
+	// Tasks of the application share the upload budget.
+	if limiter := um.budgetLimiter(taskID, peerID); limiter != nil && !limiter.AllowN(time.Now(), int(length)) {
+		limited = true
+		if err = bandwidth.WaitN(ctx, limiter, int(length)); err != nil {
+			log.Errorf("get application budget failed: %s", err)
+			return
+		}
+	}
+
+	// When start to transfer data, we could not call http.Error with header.
+	n, path, err := um.transfer(ctx, reader, limited)
+	metrics.UploadTraffic.WithLabelValues(path).Add(float64(n))
+	if err != nil {
 		log.Errorf("transfer data failed: %s", err)
 		return
-	} else if n != rg[0].Length {
+	} else if n != length {
 		log.Errorf("transferred data length not match request, request: %d, transferred: %d",
-			rg[0].Length, n)
+			length, n)
+		return
+	}
+}
+
+// getMerkleTree returns the merkle tree of task, peers verify pieces with it.
+func (um *uploadManager) getMerkleTree(ctx *gin.Context) {
+	var params DownloadParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query DownloadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	taskID := params.TaskID
+	peerID := query.PeerID
+
+	log := logger.WithTaskAndPeerID(taskID, peerID).With("component", "uploadManager")
+	if um.authorizer != nil {
+		if reason, err := um.authorize(ctx.Request, taskID, peerID); err != nil {
+			log.Warnf("merkle tree request from %s denied: %s", ctx.Request.RemoteAddr, err)
+			metrics.UploadDeniedCount.WithLabelValues(reason).Inc()
+			ctx.JSON(http.StatusForbidden, gin.H{"errors": err.Error()})
+			return
+		}
+	}
+
+	tree, err := um.storageManager.GetMerkleTree(&storage.PeerTaskMetadata{
+		TaskID: taskID,
+		PeerID: peerID,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrTaskNotFound) || errors.Is(err, storage.ErrMerkleNotSet) {
+			ctx.JSON(http.StatusNotFound, gin.H{"errors": err.Error()})
+			return
+		}
+
+		log.Errorf("get merkle tree failed: %s", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
 		return
 	}
+
+	// Seed peer signs the tree at the first request, then the signed tree is served.
+	// The tree over pieces from parents is not signed, a malicious parent may have served them.
+	if !tree.Signed() && tree.BackSourced && um.signer != nil {
+		if err := um.signer.Sign(taskID, tree); err != nil {
+			log.Errorf("sign merkle tree failed: %s", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+			return
+		}
+
+		if err := um.storageManager.UpdateTask(ctx, &storage.UpdateTaskRequest{
+			PeerTaskMetadata: storage.PeerTaskMetadata{
+				TaskID: taskID,
+				PeerID: peerID,
+			},
+			Merkle: tree,
+		}); err != nil {
+			log.Warnf("store signed merkle tree failed: %s", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, tree)
+}
+
+// authorize authorizes the request for the task with the application in task meta.
+func (um *uploadManager) authorize(req *http.Request, taskID, peerID string) (string, error) {
+	var application string
+	meta, err := um.storageManager.GetTaskMeta(&storage.PeerTaskMetadata{
+		TaskID: taskID,
+		PeerID: peerID,
+	})
+	if err == nil {
+		application = meta[storage.TaskMetaApplication]
+	}
+
+	return um.authorizer.authorize(req, application)
+}
+
+// budgetLimiter returns the limiter of the upload budget matched by the application and priority in task meta.
+func (um *uploadManager) budgetLimiter(taskID, peerID string) *rate.Limiter {
+	if len(um.budgets) == 0 {
+		return nil
+	}
+
+	meta, err := um.storageManager.GetTaskMeta(&storage.PeerTaskMetadata{
+		TaskID: taskID,
+		PeerID: peerID,
+	})
+	if err != nil {
+		return nil
+	}
+
+	priority := commonv1.Priority(commonv1.Priority_value[meta[storage.TaskMetaPriority]])
+	for i, budget := range um.budgets {
+		if budget.Match(meta[storage.TaskMetaApplication], priority) {
+			return um.budgetLimiters[i]
+		}
+	}
+
+	return nil
+}
+
+// isVerifiedPeer returns whether the peer presents a verified client certificate.
+func isVerifiedPeer(req *http.Request) bool {
+	return req.TLS != nil && len(req.TLS.VerifiedChains) > 0
+}
+
+// transfer writes piece data to the response, it sends data straight from the task data file
+// with sendfile or splice syscall when no tls or rate limiting is in play, otherwise
+// copies data in user space. It returns the transferred length and the upload path.
+func (um *uploadManager) transfer(ctx *gin.Context, reader io.Reader, limited bool) (int64, string, error) {
+	if !limited && ctx.Request.TLS == nil && ctx.Request.ProtoMajor < 3 {
+		if n, ok, err := sendfile(ctx.Writer, reader); ok {
+			return n, metrics.UploadPathSendfile, err
+		}
+	}
+
+	n, err := io.Copy(ctx.Writer, reader)
+	return n, metrics.UploadPathCopy, err
 }
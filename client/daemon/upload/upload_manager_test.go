@@ -19,21 +19,27 @@ package upload
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os"
+	"runtime"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	testifyassert "github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/time/rate"
 
 	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/integrity"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
 	"d7y.io/dragonfly/v2/client/daemon/test"
+	"d7y.io/dragonfly/v2/pkg/digest"
 	_ "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 )
 
@@ -104,3 +110,140 @@ func TestUploadManager_Serve(t *testing.T) {
 		assert.Equal(tt.targetPieceData, data)
 	}
 }
+
+func TestUploadManager_ServeSendfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert := testifyassert.New(t)
+	testData, err := os.ReadFile(test.File)
+	assert.Nil(err, "load test file")
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().ReadPiece(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+			file, err := os.Open(test.File)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if _, err := file.Seek(req.Range.Start, io.SeekStart); err != nil {
+				file.Close()
+				return nil, nil, err
+			}
+
+			return io.LimitReader(file, req.Range.Length), file, nil
+		})
+
+	um, err := NewUploadManager(config.NewDaemonConfig(), mockStorageManager, os.TempDir(), WithLimiter(rate.NewLimiter(rate.Inf, 0)))
+	assert.Nil(err, "NewUploadManager")
+
+	listen, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(err, "Listen")
+	addr := listen.Addr().String()
+
+	go func() {
+		if err := um.Serve(listen); err != nil && err != http.ErrServerClosed {
+			t.Error(err)
+		}
+	}()
+	defer um.Stop()
+
+	sendfileTraffic := testutil.ToFloat64(metrics.UploadTraffic.WithLabelValues(metrics.UploadPathSendfile))
+	copyTraffic := testutil.ToFloat64(metrics.UploadTraffic.WithLabelValues(metrics.UploadPathCopy))
+
+	req, _ := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "download", "666", "task-0", "peer-0"), nil)
+	req.Header.Add("Range", "bytes=512-4095")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(err, "get piece data")
+
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(testData[512:4096], data)
+
+	if runtime.GOOS == "linux" {
+		assert.Equal(sendfileTraffic+float64(len(data)), testutil.ToFloat64(metrics.UploadTraffic.WithLabelValues(metrics.UploadPathSendfile)))
+		assert.Equal(copyTraffic, testutil.ToFloat64(metrics.UploadTraffic.WithLabelValues(metrics.UploadPathCopy)))
+	} else {
+		assert.Equal(copyTraffic+float64(len(data)), testutil.ToFloat64(metrics.UploadTraffic.WithLabelValues(metrics.UploadPathCopy)))
+	}
+}
+
+func TestUploadManager_GetMerkleTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert := testifyassert.New(t)
+	tree := integrity.NewMerkleTree([]string{
+		digest.SHA256FromBytes([]byte("piece-0")),
+		digest.SHA256FromBytes([]byte("piece-1")),
+	})
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().GetMerkleTree(gomock.Any()).AnyTimes().
+		DoAndReturn(func(req *storage.PeerTaskMetadata) (*integrity.MerkleTree, error) {
+			switch req.TaskID {
+			case "task-0":
+				// pieces are downloaded from parents
+				tree := *tree
+				return &tree, nil
+			case "task-2":
+				tree := *tree
+				tree.BackSourced = true
+				return &tree, nil
+			}
+
+			return nil, storage.ErrMerkleNotSet
+		})
+	mockStorageManager.EXPECT().UpdateTask(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	um, err := NewUploadManager(config.NewDaemonConfig(), mockStorageManager, os.TempDir(), WithSigner(&mockSigner{}))
+	assert.Nil(err, "NewUploadManager")
+
+	listen, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(err, "Listen")
+	addr := listen.Addr().String()
+
+	go func() {
+		if err := um.Serve(listen); err != nil && err != http.ErrServerClosed {
+			t.Error(err)
+		}
+	}()
+	defer um.Stop()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "merkle", "tas", "task-0", "peer-0"))
+	assert.Nil(err, "get merkle tree")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	var got integrity.MerkleTree
+	assert.Nil(json.NewDecoder(resp.Body).Decode(&got))
+	resp.Body.Close()
+	assert.Equal(tree.Root, got.Root)
+	assert.Equal(tree.Leaves, got.Leaves)
+	assert.False(got.Signed())
+
+	// tree of back-sourced task is signed by seed peer
+	resp, err = http.Get(fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "merkle", "tas", "task-2", "peer-2"))
+	assert.Nil(err, "get merkle tree")
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	got = integrity.MerkleTree{}
+	assert.Nil(json.NewDecoder(resp.Body).Decode(&got))
+	resp.Body.Close()
+	assert.Equal(tree.Root, got.Root)
+	assert.True(got.Signed())
+
+	resp, err = http.Get(fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "merkle", "tas", "task-1", "peer-1"))
+	assert.Nil(err, "get merkle tree")
+	resp.Body.Close()
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+type mockSigner struct{}
+
+func (s *mockSigner) Sign(taskID string, tree *integrity.MerkleTree) error {
+	tree.Signature = []byte(taskID)
+	return nil
+}
@@ -148,7 +148,15 @@ func importTask(ctx context.Context, client dfdaemonclient.V1, cfg *config.Dfcac
 	}
 
 	start := time.Now()
-	importError := client.ImportTask(ctx, newImportRequest(cfg))
+	var importError error
+	if cfg.Path == config.DfcacheStdioPath {
+		importError = importStream(ctx, client, cfg, os.Stdin)
+	} else if stat, err := os.Stat(cfg.Path); err == nil && stat.IsDir() {
+		importError = importDirectory(ctx, client, cfg, wLog)
+	} else {
+		importError = client.ImportTask(ctx, newImportRequest(cfg))
+	}
+
 	if importError != nil {
 		wLog.Errorf("daemon import file error: %s", importError)
 		return importError
@@ -210,7 +218,16 @@ func exportTask(ctx context.Context, client dfdaemonclient.V1, cfg *config.Dfcac
 	}
 
 	start := time.Now()
-	exportError := client.ExportTask(ctx, newExportRequest(cfg))
+	var exportError error
+	switch {
+	case cfg.Recursive:
+		exportError = exportDirectory(ctx, client, cfg, wLog)
+	case cfg.Output == config.DfcacheStdioPath:
+		exportError = exportStream(ctx, client, cfg, os.Stdout)
+	default:
+		exportError = client.ExportTask(ctx, newExportRequest(cfg))
+	}
+
 	if exportError == nil {
 		wLog.Infof("task exported successfully in %.6f s", time.Since(start).Seconds())
 		return nil
@@ -0,0 +1,186 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+)
+
+// ManifestMediaType is the media type of the manifest of the imported directory.
+const ManifestMediaType = "application/vnd.dragonfly.dfcache.manifest.v1+json"
+
+// Manifest is the manifest of the imported directory. The files of the directory are imported
+// as child tasks, and the manifest is imported as the task of the logical cid.
+type Manifest struct {
+	// MediaType is the media type of the manifest.
+	MediaType string `json:"mediaType"`
+
+	// Entries are the files and directories of the imported directory, a directory is
+	// always listed before its children.
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is a file or directory of the imported directory.
+type ManifestEntry struct {
+	// Path is the slash-separated path relative to the imported directory.
+	Path string `json:"path"`
+
+	// Cid is the cid of the child task of the file, it is empty for the directory.
+	Cid string `json:"cid,omitempty"`
+
+	// Mode is the permission bits of the file or directory.
+	Mode fs.FileMode `json:"mode"`
+
+	// Size is the size of the file.
+	Size int64 `json:"size,omitempty"`
+}
+
+// newChildCid returns the cid of the child task of the file in the imported directory.
+func newChildCid(cid, path string) string {
+	return cid + "/" + path
+}
+
+// importDirectory imports the files of the directory as child tasks, and then imports
+// the manifest of the directory as the task of the logical cid.
+func importDirectory(ctx context.Context, client dfdaemonclient.V1, cfg *config.DfcacheConfig, wLog *logger.SugaredLoggerOnWith) error {
+	manifest := Manifest{MediaType: ManifestMediaType}
+	if err := filepath.WalkDir(cfg.Path, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filePath == cfg.Path {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(cfg.Path, filePath)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		entry := ManifestEntry{
+			Path: filepath.ToSlash(relPath),
+			Mode: info.Mode().Perm(),
+		}
+
+		switch {
+		case d.IsDir():
+		case d.Type().IsRegular():
+			entry.Cid = newChildCid(cfg.Cid, entry.Path)
+			entry.Size = info.Size()
+
+			req := newImportRequest(cfg)
+			req.Url = newCid(entry.Cid)
+			req.Path = filePath
+			if err := client.ImportTask(ctx, req); err != nil {
+				return fmt.Errorf("import file %s: %w", filePath, err)
+			}
+
+			wLog.Debugf("import file %s as cid %s", filePath, entry.Cid)
+		default:
+			wLog.Warnf("skip %s, only regular files and directories are imported", filePath)
+			return nil
+		}
+
+		manifest.Entries = append(manifest.Entries, entry)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	wLog.Infof("import manifest of %d entries", len(manifest.Entries))
+	return importStream(ctx, client, cfg, bytes.NewReader(data))
+}
+
+// exportDirectory exports the manifest of the logical cid, and then exports the child tasks
+// to restore the directory tree in output.
+func exportDirectory(ctx context.Context, client dfdaemonclient.V1, cfg *config.DfcacheConfig, wLog *logger.SugaredLoggerOnWith) error {
+	var buf bytes.Buffer
+	if err := exportStream(ctx, client, cfg, &buf); err != nil {
+		return err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(buf.Bytes(), &manifest); err != nil || manifest.MediaType != ManifestMediaType {
+		return fmt.Errorf("cid %s is not an imported directory", cfg.Cid)
+	}
+
+	if err := os.MkdirAll(cfg.Output, 0700); err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Entries {
+		if !filepath.IsLocal(filepath.FromSlash(entry.Path)) || path.Clean(entry.Path) != entry.Path {
+			return fmt.Errorf("invalid path %s in manifest", entry.Path)
+		}
+
+		output := filepath.Join(cfg.Output, filepath.FromSlash(entry.Path))
+		if entry.Cid == "" {
+			if err := os.MkdirAll(output, 0700); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0700); err != nil {
+			return err
+		}
+
+		req := newExportRequest(cfg)
+		req.Url = newCid(entry.Cid)
+		req.Output = output
+		if err := client.ExportTask(ctx, req); err != nil {
+			return fmt.Errorf("export file %s: %w", entry.Path, err)
+		}
+
+		wLog.Debugf("export cid %s to file %s", entry.Cid, output)
+	}
+
+	// Restore the permission bits from the deepest entries, so a read-only directory
+	// does not prevent restoring its children.
+	for i := len(manifest.Entries) - 1; i >= 0; i-- {
+		entry := manifest.Entries[i]
+		if err := os.Chmod(filepath.Join(cfg.Output, filepath.FromSlash(entry.Path)), entry.Mode); err != nil {
+			return err
+		}
+	}
+
+	wLog.Infof("export %d entries to directory %s", len(manifest.Entries), cfg.Output)
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfcache
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	dfdaemonv1 "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client/mocks"
+)
+
+func TestImportDirectory(t *testing.T) {
+	dir := t.TempDir()
+	assert := assert.New(t)
+	assert.NoError(os.WriteFile(filepath.Join(dir, "a"), []byte("foo"), 0600))
+	assert.NoError(os.Mkdir(filepath.Join(dir, "b"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "b", "c"), []byte("bar"), 0400))
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client := mocks.NewMockV1(ctl)
+
+	var manifest Manifest
+	cfg := &config.DfcacheConfig{Cid: "foo", Path: dir}
+	client.EXPECT().ImportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ImportTaskRequest, opts ...grpc.CallOption) error {
+		switch req.Url {
+		case newCid(newChildCid(cfg.Cid, "a")):
+			assert.Equal(filepath.Join(dir, "a"), req.Path)
+		case newCid(newChildCid(cfg.Cid, "b/c")):
+			assert.Equal(filepath.Join(dir, "b", "c"), req.Path)
+		case newCid(cfg.Cid):
+			pipe, err := os.Open(req.Path)
+			if err != nil {
+				return err
+			}
+			defer pipe.Close()
+
+			return json.NewDecoder(pipe).Decode(&manifest)
+		default:
+			t.Fatalf("unexpected url %s", req.Url)
+		}
+
+		return nil
+	}).Times(3)
+
+	assert.NoError(importDirectory(context.Background(), client, cfg, logger.With("Cid", cfg.Cid)))
+	assert.Equal(Manifest{
+		MediaType: ManifestMediaType,
+		Entries: []ManifestEntry{
+			{Path: "a", Cid: newChildCid(cfg.Cid, "a"), Mode: 0600, Size: 3},
+			{Path: "b", Mode: 0700},
+			{Path: "b/c", Cid: newChildCid(cfg.Cid, "b/c"), Mode: 0400, Size: 3},
+		},
+	}, manifest)
+}
+
+func TestExportDirectory(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest any
+		expect   func(t *testing.T, output string, err error)
+	}{
+		{
+			name: "restore read-only directory",
+			manifest: Manifest{
+				MediaType: ManifestMediaType,
+				Entries: []ManifestEntry{
+					{Path: "a", Mode: 0600},
+					{Path: "a/b", Cid: "foo/a/b", Mode: 0400},
+				},
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				// The directory is restored after its children, otherwise the
+				// permission of the children can not be restored without search bit.
+				info, err := os.Stat(filepath.Join(output, "a"))
+				assert.NoError(err)
+				assert.Equal(fs.FileMode(0600), info.Mode().Perm())
+
+				assert.NoError(os.Chmod(filepath.Join(output, "a"), 0700))
+				info, err = os.Stat(filepath.Join(output, "a", "b"))
+				assert.NoError(err)
+				assert.Equal(fs.FileMode(0400), info.Mode().Perm())
+
+				data, err := os.ReadFile(filepath.Join(output, "a", "b"))
+				assert.NoError(err)
+				assert.Equal("bar", string(data))
+			},
+		},
+		{
+			name: "path is outside the directory",
+			manifest: Manifest{
+				MediaType: ManifestMediaType,
+				Entries:   []ManifestEntry{{Path: "../a", Cid: "foo/a", Mode: 0600}},
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.EqualError(t, err, "invalid path ../a in manifest")
+			},
+		},
+		{
+			name: "path is absolute",
+			manifest: Manifest{
+				MediaType: ManifestMediaType,
+				Entries:   []ManifestEntry{{Path: "/a", Cid: "foo/a", Mode: 0600}},
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.EqualError(t, err, "invalid path /a in manifest")
+			},
+		},
+		{
+			name: "path is not clean",
+			manifest: Manifest{
+				MediaType: ManifestMediaType,
+				Entries:   []ManifestEntry{{Path: "a/../b", Cid: "foo/b", Mode: 0600}},
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.EqualError(t, err, "invalid path a/../b in manifest")
+			},
+		},
+		{
+			name:     "cid is not an imported directory",
+			manifest: "foo",
+			expect: func(t *testing.T, output string, err error) {
+				assert.EqualError(t, err, "cid foo is not an imported directory")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			client := mocks.NewMockV1(ctl)
+
+			data, err := json.Marshal(tc.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.DfcacheConfig{Cid: "foo", Output: filepath.Join(t.TempDir(), "output")}
+			t.Cleanup(func() {
+				// Let the temporary directory be removed.
+				filepath.WalkDir(cfg.Output, func(path string, d fs.DirEntry, err error) error { // nolint: errcheck
+					if err == nil && d.IsDir() {
+						os.Chmod(path, 0700) // nolint: errcheck
+					}
+
+					return nil
+				})
+			})
+
+			client.EXPECT().ExportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ExportTaskRequest, opts ...grpc.CallOption) error {
+				if req.Url == newCid(cfg.Cid) {
+					return os.WriteFile(req.Output, data, 0600)
+				}
+
+				return os.WriteFile(req.Output, []byte("bar"), 0400)
+			}).AnyTimes()
+
+			tc.expect(t, cfg.Output, exportDirectory(context.Background(), client, cfg, logger.With("Cid", cfg.Cid)))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"d7y.io/dragonfly/v2/client/config"
+	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+)
+
+// importStream imports the content of the reader whose length is unknown. The reader is
+// streamed to dfdaemon through a named pipe, so it is never buffered to a temporary file.
+func importStream(ctx context.Context, client dfdaemonclient.V1, cfg *config.DfcacheConfig, reader io.Reader) error {
+	dir, err := os.MkdirTemp("", "dfcache-import-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	pipePath := filepath.Join(dir, "stream")
+	if err := syscall.Mkfifo(pipePath, 0600); err != nil {
+		return fmt.Errorf("create named pipe %s: %w", pipePath, err)
+	}
+
+	// The named pipe is opened for both reading and writing, so opening it does not block
+	// even if dfdaemon never reads it, e.g. the task already exists.
+	pipe, err := os.OpenFile(pipePath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open named pipe %s: %w", pipePath, err)
+	}
+
+	importCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	copyErrCh := make(chan error, 1)
+	go func() {
+		// dfdaemon reads the named pipe until EOF, which is sent by closing it. If the reader
+		// fails, the import is canceled and the named pipe is kept open until ImportTask returns,
+		// so dfdaemon never takes the truncated content as complete.
+		if _, err := io.Copy(pipe, reader); err != nil {
+			cancel()
+			copyErrCh <- err
+			return
+		}
+
+		copyErrCh <- pipe.Close()
+	}()
+
+	req := newImportRequest(cfg)
+	req.Path = pipePath
+	importErr := client.ImportTask(importCtx, req)
+
+	// Close the named pipe to release the writer if dfdaemon does not read it to the end.
+	_ = pipe.Close()
+
+	// The reader may block forever when the caller is canceled, e.g. stdin is never closed,
+	// dfdaemon sees the canceled request and unregisters the truncated task by itself.
+	var copyErr error
+	select {
+	case copyErr = <-copyErrCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// The closed named pipe is expected if dfdaemon skips reading it, otherwise
+	// the reader fails and the imported task is truncated.
+	if copyErr != nil && !errors.Is(copyErr, os.ErrClosed) {
+		if err := client.DeleteTask(ctx, newDeleteRequest(cfg)); err != nil {
+			return errors.Join(copyErr, err)
+		}
+
+		return copyErr
+	}
+
+	return importErr
+}
+
+// exportStream exports the task to the writer. dfdaemon only exports the task to
+// a path, so the task is exported to a temporary file and copied to the writer.
+func exportStream(ctx context.Context, client dfdaemonclient.V1, cfg *config.DfcacheConfig, writer io.Writer) error {
+	dir, err := os.MkdirTemp("", "dfcache-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	req := newExportRequest(cfg)
+	req.Output = filepath.Join(dir, "stream")
+	if err := client.ExportTask(ctx, req); err != nil {
+		return err
+	}
+
+	file, err := os.Open(req.Output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
@@ -0,0 +1,179 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	dfdaemonv1 "d7y.io/api/v2/pkg/apis/dfdaemon/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client/mocks"
+)
+
+// readPipe reads the named pipe like dfdaemon, the content is sent to the returned channel
+// after EOF, and the import succeeds only if EOF is read before the context is done.
+func readPipe(ctx context.Context, path string, contentCh chan<- string) error {
+	pipe, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	eofCh := make(chan struct{})
+	go func() {
+		defer pipe.Close()
+		data, _ := io.ReadAll(pipe)
+		contentCh <- string(data)
+		close(eofCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-eofCh:
+		return nil
+	}
+}
+
+func TestImportStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		reader io.Reader
+		mock   func(mv *mocks.MockV1MockRecorder, contentCh chan string, importErrCh chan error)
+		expect func(t *testing.T, err error, contentCh chan string, importErrCh chan error)
+	}{
+		{
+			name:   "import stream",
+			reader: strings.NewReader("foo"),
+			mock: func(mv *mocks.MockV1MockRecorder, contentCh chan string, importErrCh chan error) {
+				mv.ImportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ImportTaskRequest, opts ...grpc.CallOption) error {
+					err := readPipe(ctx, req.Path, contentCh)
+					importErrCh <- err
+					return err
+				}).Times(1)
+			},
+			expect: func(t *testing.T, err error, contentCh chan string, importErrCh chan error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NoError(<-importErrCh)
+				assert.Equal("foo", <-contentCh)
+			},
+		},
+		{
+			name:   "dfdaemon skips reading stream",
+			reader: strings.NewReader("foo"),
+			mock: func(mv *mocks.MockV1MockRecorder, contentCh chan string, importErrCh chan error) {
+				mv.ImportTask(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, err error, contentCh chan string, importErrCh chan error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "truncated stream is canceled and deleted",
+			reader: io.MultiReader(strings.NewReader("foo"), iotest.ErrReader(errors.New("bar"))),
+			mock: func(mv *mocks.MockV1MockRecorder, contentCh chan string, importErrCh chan error) {
+				gomock.InOrder(
+					mv.ImportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ImportTaskRequest, opts ...grpc.CallOption) error {
+						err := readPipe(ctx, req.Path, contentCh)
+						importErrCh <- err
+						return err
+					}).Times(1),
+					mv.DeleteTask(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error, contentCh chan string, importErrCh chan error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "bar")
+				assert.ErrorIs(<-importErrCh, context.Canceled)
+			},
+		},
+		{
+			name:   "delete truncated stream failed",
+			reader: iotest.ErrReader(errors.New("bar")),
+			mock: func(mv *mocks.MockV1MockRecorder, contentCh chan string, importErrCh chan error) {
+				gomock.InOrder(
+					mv.ImportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ImportTaskRequest, opts ...grpc.CallOption) error {
+						return readPipe(ctx, req.Path, contentCh)
+					}).Times(1),
+					mv.DeleteTask(gomock.Any(), gomock.Any()).Return(errors.New("baz")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error, contentCh chan string, importErrCh chan error) {
+				assert.EqualError(t, err, "bar\nbaz")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			client := mocks.NewMockV1(ctl)
+			contentCh := make(chan string, 1)
+			importErrCh := make(chan error, 1)
+			tc.mock(client.EXPECT(), contentCh, importErrCh)
+
+			err := importStream(context.Background(), client, &config.DfcacheConfig{Cid: "foo"}, tc.reader)
+			tc.expect(t, err, contentCh, importErrCh)
+		})
+	}
+}
+
+func TestImportStreamWithCanceledContext(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client := mocks.NewMockV1(ctl)
+
+	// The reader blocks until the test ends, like stdin which is never closed.
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.EXPECT().ImportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ImportTaskRequest, opts ...grpc.CallOption) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}).Times(1)
+
+	assert.ErrorIs(t, importStream(ctx, client, &config.DfcacheConfig{Cid: "foo"}, reader), context.Canceled)
+}
+
+func TestExportStream(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client := mocks.NewMockV1(ctl)
+	client.EXPECT().ExportTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *dfdaemonv1.ExportTaskRequest, opts ...grpc.CallOption) error {
+		return os.WriteFile(req.Output, []byte("foo"), 0600)
+	}).Times(1)
+
+	var buf strings.Builder
+	assert := assert.New(t)
+	assert.NoError(exportStream(context.Background(), client, &config.DfcacheConfig{Cid: "foo"}, &buf))
+	assert.Equal("foo", buf.String())
+}
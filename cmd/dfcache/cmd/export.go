@@ -27,7 +27,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
 )
 
-const exportDesc = "export file from P2P cache system"
+const exportDesc = "export file or directory from P2P cache system"
 
 // exportCmd represents the cache export command
 var exportCmd = &cobra.Command{
@@ -48,7 +48,8 @@ func initExport() {
 	rootCmd.AddCommand(exportCmd)
 
 	flags := exportCmd.Flags()
-	flags.StringVarP(&dfcacheConfig.Output, "output", "O", "", "export file path")
+	flags.StringVarP(&dfcacheConfig.Output, "output", "O", "", "export file path, - exports to stdout")
+	flags.BoolVarP(&dfcacheConfig.Recursive, "recursive", "r", false, "export the imported directory recursively to output")
 	flags.BoolVarP(&dfcacheConfig.LocalOnly, "local", "l", false, "only export file from local cache")
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind cache export flags to viper: %w", err))
@@ -27,7 +27,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
 )
 
-const importDesc = "import file into P2P cache system"
+const importDesc = "import file, directory or stdin into P2P cache system"
 
 // importCmd represents the cache import command
 var importCmd = &cobra.Command{
@@ -48,7 +48,7 @@ func initImport() {
 	rootCmd.AddCommand(importCmd)
 
 	flags := importCmd.Flags()
-	flags.StringVarP(&dfcacheConfig.Path, "input", "I", "", "import the given file into P2P network")
+	flags.StringVarP(&dfcacheConfig.Path, "input", "I", "", "import the given file or directory into P2P network, - imports from stdin")
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind cache import flags to viper: %w", err))
 	}
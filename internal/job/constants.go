@@ -35,6 +35,9 @@ const (
 
 	// DeleteTaskJob is the name of deleting task job.
 	DeleteTaskJob = "delete_task"
+
+	// ListPersistentCacheTasksJob is the name of listing persistent cache tasks job.
+	ListPersistentCacheTasksJob = "list_persistent_cache_tasks"
 )
 
 // Machinery server configuration.
@@ -46,3 +49,19 @@ const (
 	DefaultRedisWriteTimeout   = 60
 	DefaultRedisConnectTimeout = 60
 )
+
+// Listing persistent cache tasks configuration, the tasks are returned by page
+// because the result of the job is stored in the result backend.
+const (
+	// DefaultListPersistentCacheTasksPage is the default page of listing persistent cache tasks.
+	DefaultListPersistentCacheTasksPage = 1
+
+	// DefaultListPersistentCacheTasksPerPage is the default task count per page of listing persistent cache tasks.
+	DefaultListPersistentCacheTasksPerPage = 1000
+
+	// MaxListPersistentCacheTasksPerPage is the max task count per page of listing persistent cache tasks.
+	MaxListPersistentCacheTasksPerPage = 10000
+)
+
+// EmbeddedBroker is the machinery scheme of the in-process broker, result backend and lock.
+const EmbeddedBroker = "eager"
@@ -44,6 +44,10 @@ type Config struct {
 	SentinelPassword string
 	BrokerDB         int
 	BackendDB        int
+
+	// Embedded uses the in-process broker and result backend instead of redis,
+	// tasks are executed by the workers registered in the same process.
+	Embedded bool
 }
 
 type Job struct {
@@ -56,6 +60,10 @@ func New(cfg *Config, queue Queue) (*Job, error) {
 	// Set logger
 	machineryv1log.Set(&MachineryLogger{})
 
+	if cfg.Embedded {
+		return newEmbedded(queue)
+	}
+
 	if err := ping(&redis.UniversalOptions{
 		Addrs:            cfg.Addrs,
 		MasterName:       cfg.MasterName,
@@ -92,6 +100,25 @@ func New(cfg *Config, queue Queue) (*Job, error) {
 	}, nil
 }
 
+// newEmbedded creates a job with the in-process broker, result backend and lock.
+func newEmbedded(queue Queue) (*Job, error) {
+	server, err := machinery.NewServer(&machineryv1config.Config{
+		Broker:          EmbeddedBroker,
+		DefaultQueue:    queue.String(),
+		ResultBackend:   EmbeddedBroker,
+		Lock:            EmbeddedBroker,
+		ResultsExpireIn: DefaultResultsExpireIn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		Server: server,
+		Queue:  queue,
+	}, nil
+}
+
 func ping(options *redis.UniversalOptions) error {
 	return redis.NewUniversalClient(options).Ping(context.Background()).Err()
 }
@@ -379,3 +379,32 @@ func TestJob_UnmarshalRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestJob_NewEmbedded(t *testing.T) {
+	assert := assert.New(t)
+	job, err := New(&Config{Embedded: true}, GlobalQueue)
+	assert.NoError(err)
+	assert.Equal(GlobalQueue, job.Queue)
+
+	assert.NoError(job.RegisterJob(map[string]any{
+		PreheatJob: func(req string) (string, error) {
+			return req, nil
+		},
+	}))
+
+	args, err := MarshalRequest(PreheatRequest{URL: "http://example.com"})
+	assert.NoError(err)
+
+	result, err := job.Server.SendTask(&machineryv1tasks.Signature{
+		Name: PreheatJob,
+		Args: args,
+	})
+	assert.NoError(err)
+
+	values, err := result.Get(0)
+	assert.NoError(err)
+
+	var req PreheatRequest
+	assert.NoError(UnmarshalResponse(values, &req))
+	assert.Equal("http://example.com", req.URL)
+}
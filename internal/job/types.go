@@ -106,3 +106,39 @@ type DeleteFailureTask struct {
 	HostType    string `json:"host_type"`
 	Description string `json:"description"`
 }
+
+// ListPersistentCacheTasksRequest defines the request parameters for listing persistent cache tasks.
+type ListPersistentCacheTasksRequest struct {
+	Application string        `json:"application" validate:"omitempty"`
+	Tag         string        `json:"tag" validate:"omitempty"`
+	State       string        `json:"state" validate:"omitempty,oneof=Pending Uploading Succeeded Failed"`
+	MinAge      time.Duration `json:"min_age" validate:"omitempty,gte=0"`
+	MaxAge      time.Duration `json:"max_age" validate:"omitempty,gte=0"`
+	Page        int           `json:"page" validate:"omitempty,gte=1"`
+	PerPage     int           `json:"per_page" validate:"omitempty,gte=1,lte=10000"`
+	Timeout     time.Duration `json:"timeout" validate:"omitempty"`
+}
+
+// ListPersistentCacheTasksResponse defines the response parameters for listing persistent cache tasks.
+type ListPersistentCacheTasksResponse struct {
+	PersistentCacheTasks []*PersistentCacheTask `json:"persistent_cache_tasks"`
+	TotalCount           int                    `json:"total_count"`
+	TotalContentLength   uint64                 `json:"total_content_length"`
+	SchedulerClusterID   uint                   `json:"scheduler_cluster_id"`
+}
+
+// PersistentCacheTask represents the persistent cache task information.
+type PersistentCacheTask struct {
+	ID                            string        `json:"id"`
+	Tag                           string        `json:"tag"`
+	Application                   string        `json:"application"`
+	State                         string        `json:"state"`
+	PersistentReplicaCount        uint64        `json:"persistent_replica_count"`
+	CurrentPersistentReplicaCount uint64        `json:"current_persistent_replica_count"`
+	CurrentReplicaCount           uint64        `json:"current_replica_count"`
+	ContentLength                 uint64        `json:"content_length"`
+	TTL                           time.Duration `json:"ttl"`
+	Pinned                        bool          `json:"pinned"`
+	CreatedAt                     time.Time     `json:"created_at"`
+	UpdatedAt                     time.Time     `json:"updated_at"`
+}
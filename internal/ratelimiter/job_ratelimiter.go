@@ -165,9 +165,16 @@ func (j *jobRateLimiter) refresh(ctx context.Context) error {
 			jobRateLimit = int(schedulerClusterConfig.JobRateLimit)
 		}
 
+		// Use the local rate limiter if redis is embedded.
+		var rateLimiter DistributedRateLimiter
+		if j.database.RDB == nil {
+			rateLimiter = NewLocalRateLimiter()
+		} else {
+			rateLimiter = NewDistributedRateLimiter(j.database.RDB, j.key(schedulerCluster.ID))
+		}
+
 		logger.Debugf("create job rate limiter for scheduler cluster %d with rate limit %d", schedulerCluster.ID, jobRateLimit)
-		j.clusters.Store(schedulerCluster.ID,
-			NewDistributedRateLimiter(j.database.RDB, j.key(schedulerCluster.ID)).TokenBucket(ctx, int64(jobRateLimit), time.Second))
+		j.clusters.Store(schedulerCluster.ID, rateLimiter.TokenBucket(ctx, int64(jobRateLimit), time.Second))
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ratelimiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/mennanov/limiters"
+)
+
+// localRateLimiter is an implementation of DistributedRateLimiter in process memory,
+// it is used when the manager runs with embedded redis.
+type localRateLimiter struct{}
+
+// NewLocalRateLimiter creates a new instance of local rate limiter.
+func NewLocalRateLimiter() DistributedRateLimiter {
+	return &localRateLimiter{}
+}
+
+// TokenBucket returns a token bucket rate limiter.
+func (l *localRateLimiter) TokenBucket(ctx context.Context, capacity int64, per time.Duration) *limiters.TokenBucket {
+	return limiters.NewTokenBucket(capacity, per, limiters.NewLockNoop(), limiters.NewTokenBucketInMemory(), limiters.NewSystemClock(), limiters.NewStdLogger())
+}
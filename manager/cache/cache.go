@@ -37,6 +37,16 @@ func New(cfg *config.Config) (*Cache, error) {
 	var localCache *cache.TinyLFU
 	localCache = cache.NewTinyLFU(cfg.Cache.Local.Size, cfg.Cache.Local.TTL)
 
+	// Only the local cache is used when redis is embedded.
+	if cfg.Database.Redis.Embedded {
+		return &Cache{
+			Cache: cache.New(&cache.Options{
+				LocalCache: localCache,
+			}),
+			TTL: cfg.Cache.Local.TTL,
+		}, nil
+	}
+
 	rdb, err := pkgredis.NewRedis(&redis.UniversalOptions{
 		Addrs:            cfg.Database.Redis.Addrs,
 		MasterName:       cfg.Database.Redis.MasterName,
@@ -116,6 +116,9 @@ type DatabaseConfig struct {
 	// Postgres configuration.
 	Postgres PostgresConfig `yaml:"postgres" mapstructure:"postgres"`
 
+	// SQLite configuration.
+	SQLite SQLiteConfig `yaml:"sqlite" mapstructure:"sqlite"`
+
 	// Redis configuration.
 	Redis RedisConfig `yaml:"redis" mapstructure:"redis"`
 }
@@ -190,7 +193,24 @@ type PostgresConfig struct {
 	Migrate bool `yaml:"migrate" mapstructure:"migrate"`
 }
 
+type SQLiteConfig struct {
+	// Path is the file path of the database, default is manager.db in the data directory.
+	Path string `yaml:"path" mapstructure:"path"`
+
+	// BusyTimeout is the timeout of waiting for the database lock.
+	BusyTimeout time.Duration `yaml:"busyTimeout" mapstructure:"busyTimeout"`
+
+	// Enable migration.
+	Migrate bool `yaml:"migrate" mapstructure:"migrate"`
+}
+
 type RedisConfig struct {
+	// Embedded replaces redis with the in-process machinery broker, result backend and
+	// local cache, it is designed for the single-binary manager of edge sites and local
+	// development. Jobs dispatched to schedulers, such as preheat and sync peers, are
+	// unavailable in embedded mode, because schedulers can not consume the in-process queue.
+	Embedded bool `yaml:"embedded" mapstructure:"embedded"`
+
 	// DEPRECATED: Please use the `addrs` field instead.
 	Host string `yaml:"host" mapstructure:"host"`
 
@@ -428,6 +448,10 @@ func New() *Config {
 				Timezone:             DefaultPostgresTimezone,
 				Migrate:              true,
 			},
+			SQLite: SQLiteConfig{
+				BusyTimeout: DefaultSQLiteBusyTimeout,
+				Migrate:     true,
+			},
 			Redis: RedisConfig{
 				DB:        DefaultRedisDB,
 				BrokerDB:  DefaultRedisBrokerDB,
@@ -597,24 +621,32 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
-	if len(cfg.Database.Redis.Addrs) == 0 {
-		return errors.New("redis requires parameter addrs")
+	if cfg.Database.Type == DatabaseTypeSQLite {
+		if cfg.Database.SQLite.BusyTimeout <= 0 {
+			return errors.New("sqlite requires parameter busyTimeout")
+		}
 	}
 
-	if cfg.Database.Redis.DB < 0 {
-		return errors.New("redis requires parameter db")
-	}
+	if !cfg.Database.Redis.Embedded {
+		if len(cfg.Database.Redis.Addrs) == 0 {
+			return errors.New("redis requires parameter addrs")
+		}
 
-	if cfg.Database.Redis.BrokerDB < 0 {
-		return errors.New("redis requires parameter brokerDB")
-	}
+		if cfg.Database.Redis.DB < 0 {
+			return errors.New("redis requires parameter db")
+		}
 
-	if cfg.Database.Redis.BackendDB < 0 {
-		return errors.New("redis requires parameter backendDB")
-	}
+		if cfg.Database.Redis.BrokerDB < 0 {
+			return errors.New("redis requires parameter brokerDB")
+		}
+
+		if cfg.Database.Redis.BackendDB < 0 {
+			return errors.New("redis requires parameter backendDB")
+		}
 
-	if cfg.Cache.Redis.TTL == 0 {
-		return errors.New("redis requires parameter ttl")
+		if cfg.Cache.Redis.TTL == 0 {
+			return errors.New("redis requires parameter ttl")
+		}
 	}
 
 	if cfg.Cache.Local.Size == 0 {
@@ -65,6 +65,12 @@ var (
 		Migrate:              true,
 	}
 
+	mockSQLiteConfig = SQLiteConfig{
+		Path:        "manager.db",
+		BusyTimeout: DefaultSQLiteBusyTimeout,
+		Migrate:     true,
+	}
+
 	mockRedisConfig = RedisConfig{
 		Addrs:      []string{"127.0.0.0:6379"},
 		MasterName: "master",
@@ -158,7 +164,13 @@ func TestConfig_Load(t *testing.T) {
 				Timezone:             "UTC",
 				Migrate:              true,
 			},
+			SQLite: SQLiteConfig{
+				Path:        "foo",
+				BusyTimeout: 10 * time.Second,
+				Migrate:     true,
+			},
 			Redis: RedisConfig{
+				Embedded:   true,
 				Password:   "bar",
 				Addrs:      []string{"foo", "bar"},
 				MasterName: "baz",
@@ -631,6 +643,34 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "postgres requires parameter timezone")
 			},
 		},
+		{
+			name:   "sqlite requires parameter busyTimeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeSQLite
+				cfg.Database.SQLite = mockSQLiteConfig
+				cfg.Database.SQLite.BusyTimeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "sqlite requires parameter busyTimeout")
+			},
+		},
+		{
+			name:   "valid config with sqlite and embedded redis",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeSQLite
+				cfg.Database.SQLite = mockSQLiteConfig
+				cfg.Database.Redis.Embedded = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
 		{
 			name:   "redis requires parameter addrs",
 			config: New(),
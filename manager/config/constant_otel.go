@@ -19,18 +19,20 @@ package config
 import "go.opentelemetry.io/otel/attribute"
 
 const (
-	AttributeID           = attribute.Key("d7y.manager.id")
-	AttributePreheatType  = attribute.Key("d7y.manager.preheat.type")
-	AttributePreheatURL   = attribute.Key("d7y.manager.preheat.url")
-	AttributeDeleteTaskID = attribute.Key("d7y.manager.delete_task.id")
-	AttributeGetTaskID    = attribute.Key("d7y.manager.get_task.id")
+	AttributeID                                  = attribute.Key("d7y.manager.id")
+	AttributePreheatType                         = attribute.Key("d7y.manager.preheat.type")
+	AttributePreheatURL                          = attribute.Key("d7y.manager.preheat.url")
+	AttributeDeleteTaskID                        = attribute.Key("d7y.manager.delete_task.id")
+	AttributeGetTaskID                           = attribute.Key("d7y.manager.get_task.id")
+	AttributeListPersistentCacheTasksApplication = attribute.Key("d7y.manager.list_persistent_cache_tasks.application")
 )
 
 const (
-	SpanPreheat          = "preheat"
-	SpanSyncPeers        = "sync-peers"
-	SpanGetLayers        = "get-layers"
-	SpanAuthWithRegistry = "auth-with-registry"
-	SpanDeleteTask       = "delete-task"
-	SpanGetTask          = "get-task"
+	SpanPreheat                  = "preheat"
+	SpanSyncPeers                = "sync-peers"
+	SpanGetLayers                = "get-layers"
+	SpanAuthWithRegistry         = "auth-with-registry"
+	SpanDeleteTask               = "delete-task"
+	SpanGetTask                  = "get-task"
+	SpanListPersistentCacheTasks = "list-persistent-cache-tasks"
 )
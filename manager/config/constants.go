@@ -32,6 +32,9 @@ const (
 
 	// DatabaseTypePostgres is database type of postgres.
 	DatabaseTypePostgres = "postgres"
+
+	// DatabaseTypeSQLite is database type of sqlite.
+	DatabaseTypeSQLite = "sqlite"
 )
 
 const (
@@ -133,6 +136,15 @@ const (
 	DefaultPostgresTimezone = "UTC"
 )
 
+const (
+	// DefaultSQLiteDBName is default db file name for sqlite, it is created in the data directory
+	// when the path is not specified.
+	DefaultSQLiteDBName = "manager.db"
+
+	// DefaultSQLiteBusyTimeout is default timeout of waiting for the database lock in sqlite.
+	DefaultSQLiteBusyTimeout = 5 * time.Second
+)
+
 const (
 	// DefaultMetricsAddr is default address for metrics server.
 	DefaultMetricsAddr = ":8000"
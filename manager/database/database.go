@@ -38,7 +38,9 @@ const (
 )
 
 type Database struct {
-	DB  *gorm.DB
+	DB *gorm.DB
+
+	// RDB is nil when redis is embedded.
 	RDB redis.UniversalClient
 }
 
@@ -60,6 +62,12 @@ func New(cfg *config.Config) (*Database, error) {
 			logger.Errorf("postgres: %s", err.Error())
 			return nil, err
 		}
+	case config.DatabaseTypeSQLite:
+		db, err = newSQLite(cfg)
+		if err != nil {
+			logger.Errorf("sqlite: %s", err.Error())
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("invalid database type %s", cfg.Database.Type)
 	}
@@ -79,6 +87,13 @@ func New(cfg *config.Config) (*Database, error) {
 		return nil, err
 	}
 
+	// Redis is replaced by the in-process implementations in embedded mode.
+	if cfg.Database.Redis.Embedded {
+		return &Database{
+			DB: db,
+		}, nil
+	}
+
 	rdb, err := pkgredis.NewRedis(&redis.UniversalOptions{
 		Addrs:            cfg.Database.Redis.Addrs,
 		MasterName:       cfg.Database.Redis.MasterName,
@@ -105,6 +120,7 @@ func migrate(db *gorm.DB) error {
 		&models.SeedPeerCluster{},
 		&models.SeedPeer{},
 		&models.SchedulerCluster{},
+		&models.SchedulerClusterConfigVersion{},
 		&models.Scheduler{},
 		&models.User{},
 		&models.Oauth{},
@@ -112,6 +128,7 @@ func migrate(db *gorm.DB) error {
 		&models.Application{},
 		&models.PersonalAccessToken{},
 		&models.Peer{},
+		&models.PeerVersion{},
 	)
 }
 
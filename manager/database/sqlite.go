@@ -0,0 +1,84 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"moul.io/zapgorm2"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/config"
+)
+
+func newSQLite(cfg *config.Config) (*gorm.DB, error) {
+	sqliteCfg := &cfg.Database.SQLite
+
+	// Initialize gorm logger.
+	logLevel := gormlogger.Info
+	if !cfg.Verbose {
+		logLevel = gormlogger.Warn
+	}
+	gormLogger := zapgorm2.New(logger.CoreLogger.Desugar()).LogMode(logLevel)
+
+	// Connect to sqlite, the driver is implemented in pure go,
+	// so the manager can be built without cgo.
+	db, err := gorm.Open(sqlite.Open(formatSQLiteDSN(sqliteCfg)), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   gormLogger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sqlite only allows one writer at a time, limit the connection
+	// to avoid the database is locked error.
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	// Run migration.
+	if sqliteCfg.Migrate {
+		if err := migrate(db); err != nil {
+			return nil, err
+		}
+	}
+
+	// Run seed.
+	if err := seed(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func formatSQLiteDSN(cfg *config.SQLiteConfig) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode(WAL)")
+	return fmt.Sprintf("file:%s?%s", cfg.Path, params.Encode())
+}
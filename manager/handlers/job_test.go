@@ -57,6 +57,16 @@ var (
 				"task_id": "04a29122b0c4d0affde2d577fb36bb956caa3da10e9130375623c24a5f865a49"
 			}
 		}`
+	mockListPersistentCacheTasksJobReqBody = `
+		{
+			"type": "list_persistent_cache_tasks",
+			"user_id": 4,
+			"bio": "bio",
+			"args": {
+				"application": "foo",
+				"state": "Succeeded"
+			}
+		}`
 	mockOtherJobReqBody = `
 		{
 			"type": "others",
@@ -82,6 +92,15 @@ var (
 			TaskID: "04a29122b0c4d0affde2d577fb36bb956caa3da10e9130375623c24a5f865a49",
 		},
 	}
+	mockCreateListPersistentCacheTasksJobRequest = types.CreateListPersistentCacheTasksJobRequest{
+		UserID: 4,
+		Type:   "list_persistent_cache_tasks",
+		BIO:    "bio",
+		Args: types.ListPersistentCacheTasksArgs{
+			Application: "foo",
+			State:       "Succeeded",
+		},
+	}
 	mockUpdateJobRequest = types.UpdateJobRequest{
 		UserID: 4,
 		BIO:    "bio",
@@ -107,6 +126,13 @@ var (
 		BIO:       "bio",
 		TaskID:    "04a29122b0c4d0affde2d577fb36bb956caa3da10e9130375623c24a5f865a49",
 	}
+	mockListPersistentCacheTasksJobModel = &models.Job{
+		BaseModel: mockBaseModel,
+		UserID:    4,
+		Type:      "list_persistent_cache_tasks",
+		BIO:       "bio",
+		TaskID:    "group_8d3b4ac1-3f0e-4e36-a4e5-7c2b3b5a7e0d",
+	}
 )
 
 func mockJobRouter(h *Handlers) *gin.Engine {
@@ -191,6 +217,21 @@ func TestHandlers_CreateJob(t *testing.T) {
 				assert.Equal(mockDeleteTaskJobModel, &job)
 			},
 		},
+		{
+			name: "create list persistent cache tasks job success",
+			req:  httptest.NewRequest(http.MethodPost, "/oapi/v1/jobs", strings.NewReader(mockListPersistentCacheTasksJobReqBody)),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.CreateListPersistentCacheTasksJob(gomock.Any(), gomock.Eq(mockCreateListPersistentCacheTasksJobRequest)).Return(mockListPersistentCacheTasksJobModel, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				job := models.Job{}
+				err := json.Unmarshal(w.Body.Bytes(), &job)
+				assert.NoError(err)
+				assert.Equal(mockListPersistentCacheTasksJobModel, &job)
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
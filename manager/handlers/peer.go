@@ -111,6 +111,12 @@ func (h *Handlers) GetPeer(ctx *gin.Context) {
 // @Tags Peer
 // @Accept json
 // @Produce json
+// @Param git_version query string false "git version"
+// @Param idc query string false "idc"
+// @Param location query string false "location"
+// @Param type query string false "host type"
+// @Param min_disk_free query int false "minimum free disk space in bytes"
+// @Param max_disk_free query int false "maximum free disk space in bytes"
 // @Param page query int true "current page" default(0)
 // @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
 // @Success 200 {object} []models.Peer
@@ -135,3 +141,40 @@ func (h *Handlers) GetPeers(ctx *gin.Context) {
 	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
 	ctx.JSON(http.StatusOK, peers)
 }
+
+// @Summary Get Peer Versions
+// @Description Get version changes of Peer, the latest change is first
+// @Tags Peer
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []models.PeerVersion
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /peers/{id}/versions [get]
+func (h *Handlers) GetPeerVersions(ctx *gin.Context) {
+	var params types.PeerParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query types.GetPeerVersionsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	versions, count, err := h.service.GetPeerVersions(ctx.Request.Context(), params.ID, query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, versions)
+}
@@ -59,6 +59,14 @@ var (
 		DownloadPort:       8001,
 		SchedulerClusterID: 2,
 	}
+	mockPeerVersionModel = &models.PeerVersion{
+		BaseModel:          mockBaseModel,
+		Hostname:           "foo",
+		IP:                 "127.0.0.1",
+		PreviousGitVersion: "v2.1.0",
+		GitVersion:         "v2.1.1",
+		SchedulerClusterID: 2,
+	}
 )
 
 func mockPeerRouter(h *Handlers) *gin.Engine {
@@ -69,6 +77,7 @@ func mockPeerRouter(h *Handlers) *gin.Engine {
 	peer.DELETE(":id", h.DestroyPeer)
 	peer.GET(":id", h.GetPeer)
 	peer.GET("", h.GetPeers)
+	peer.GET(":id/versions", h.GetPeerVersions)
 	return r
 }
 
@@ -247,6 +256,83 @@ func TestHandlers_GetPeers(t *testing.T) {
 				assert.Equal(mockPeerModel, &peer)
 			},
 		},
+		{
+			name: "success with version and disk free",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/peers?git_version=v2.1.0&type=normal&max_disk_free=1024", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetPeers(gomock.Any(), gomock.Eq(types.GetPeersQuery{
+					Type:        "normal",
+					GitVersion:  "v2.1.0",
+					MaxDiskFree: 1024,
+					Page:        1,
+					PerPage:     10,
+				})).Return([]models.Peer{*mockPeerModel}, int64(1), nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			w := httptest.NewRecorder()
+			h := New(svc)
+			mockRouter := mockPeerRouter(h)
+
+			tc.mock(svc.EXPECT())
+			mockRouter.ServeHTTP(w, tc.req)
+			tc.expect(t, w)
+		})
+	}
+}
+
+func TestHandlers_GetPeerVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "unprocessable entity caused by uri",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/peers/test/versions", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "unprocessable entity caused by query",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/peers/2/versions?page=-1", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "success",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/peers/2/versions", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetPeerVersions(gomock.Any(), gomock.Eq(uint(2)), gomock.Eq(types.GetPeerVersionsQuery{
+					Page:    1,
+					PerPage: 10,
+				})).Return([]models.PeerVersion{*mockPeerVersionModel}, int64(1), nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				version := models.PeerVersion{}
+				err := json.Unmarshal(w.Body.Bytes()[1:w.Body.Len()-1], &version)
+				assert.NoError(err)
+				assert.Equal(mockPeerVersionModel, &version)
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
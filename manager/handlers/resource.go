@@ -0,0 +1,81 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Export Resources
+// @Description Export scheduler clusters, seed peer clusters, applications and configs as yaml
+// @Tags Resource
+// @Accept json
+// @Produce x-yaml
+// @Success 200 {object} types.Resources
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /resources/export [get]
+func (h *Handlers) ExportResources(ctx *gin.Context) {
+	resources, err := h.service.ExportResources(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.YAML(http.StatusOK, resources)
+}
+
+// @Summary Apply Resources
+// @Description Apply resources by yaml, returns the plan of create, update and delete actions
+// @Tags Resource
+// @Accept x-yaml
+// @Produce json
+// @Param Resources body types.Resources true "Resources"
+// @Param dry_run query bool false "only compute the plan"
+// @Param prune query bool false "delete resources which are not declared"
+// @Param user_id query int false "user id"
+// @Success 200 {object} types.ApplyResourcesResponse
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /resources/apply [post]
+func (h *Handlers) ApplyResources(ctx *gin.Context) {
+	var query types.ApplyResourcesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var yaml types.Resources
+	if err := ctx.ShouldBindYAML(&yaml); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ApplyResources(ctx.Request.Context(), yaml, query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
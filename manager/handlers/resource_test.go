@@ -0,0 +1,195 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
+
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+var (
+	mockResourcesReqBody = `
+seedPeerClusters:
+  - name: foo
+    config:
+      loadLimit: 300
+schedulerClusters:
+  - name: bar
+    config:
+      candidateParentLimit: 4
+      filterParentLimit: 40
+    clientConfig:
+      loadLimit: 50
+    isDefault: true
+    seedPeerCluster: foo
+configs:
+  - name: baz
+    value: value
+`
+	mockResources = types.Resources{
+		SeedPeerClusters: []types.SeedPeerClusterResource{
+			{
+				Name:   "foo",
+				Config: &types.SeedPeerClusterConfig{LoadLimit: 300},
+			},
+		},
+		SchedulerClusters: []types.SchedulerClusterResource{
+			{
+				Name: "bar",
+				Config: &types.SchedulerClusterConfig{
+					CandidateParentLimit: 4,
+					FilterParentLimit:    40,
+				},
+				ClientConfig:    &types.SchedulerClusterClientConfig{LoadLimit: 50},
+				IsDefault:       true,
+				SeedPeerCluster: "foo",
+			},
+		},
+		Configs: []types.ConfigResource{
+			{
+				Name:  "baz",
+				Value: "value",
+			},
+		},
+	}
+	mockApplyResourcesResponse = &types.ApplyResourcesResponse{
+		DryRun: true,
+		Plans: []types.ResourcePlan{
+			{Kind: types.ResourceKindSeedPeerCluster, Name: "foo", Action: types.ResourceActionCreate},
+			{Kind: types.ResourceKindSchedulerCluster, Name: "bar", Action: types.ResourceActionUpdate},
+		},
+	}
+)
+
+func mockResourceRouter(h *Handlers) *gin.Engine {
+	r := gin.Default()
+	apiv1 := r.Group("/api/v1")
+	rs := apiv1.Group("/resources")
+	rs.GET("export", h.ExportResources)
+	rs.POST("apply", h.ApplyResources)
+	return r
+}
+
+func TestHandlers_ExportResources(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "success",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/resources/export", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ExportResources(gomock.Any()).Return(&mockResources, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				resources := types.Resources{}
+				err := yaml.Unmarshal(w.Body.Bytes(), &resources)
+				assert.NoError(err)
+				assert.Equal(mockResources, resources)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			w := httptest.NewRecorder()
+			h := New(svc)
+			mockRouter := mockResourceRouter(h)
+
+			tc.mock(svc.EXPECT())
+			mockRouter.ServeHTTP(w, tc.req)
+			tc.expect(t, w)
+		})
+	}
+}
+
+func TestHandlers_ApplyResources(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "unprocessable entity caused by query",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/resources/apply?dry_run=foo", strings.NewReader(mockResourcesReqBody)),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "unprocessable entity caused by body",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/resources/apply", strings.NewReader("configs:\n  - name: foo\n")),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "success",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/resources/apply?dry_run=true&user_id=4", strings.NewReader(mockResourcesReqBody)),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ApplyResources(gomock.Any(), gomock.Eq(mockResources), gomock.Eq(types.ApplyResourcesQuery{
+					DryRun: true,
+					UserID: 4,
+				})).Return(mockApplyResourcesResponse, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				resp := &types.ApplyResourcesResponse{}
+				err := json.Unmarshal(w.Body.Bytes(), resp)
+				assert.NoError(err)
+				assert.Equal(mockApplyResourcesResponse, resp)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			w := httptest.NewRecorder()
+			h := New(svc)
+			mockRouter := mockResourceRouter(h)
+
+			tc.mock(svc.EXPECT())
+			mockRouter.ServeHTTP(w, tc.req)
+			tc.expect(t, w)
+		})
+	}
+}
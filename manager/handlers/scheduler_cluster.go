@@ -197,3 +197,75 @@ func (h *Handlers) AddSchedulerToSchedulerCluster(ctx *gin.Context) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// @Summary Get SchedulerCluster Config Versions
+// @Description Get config versions of SchedulerCluster, the latest version is first
+// @Tags SchedulerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []models.SchedulerClusterConfigVersion
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions [get]
+func (h *Handlers) GetSchedulerClusterConfigVersions(ctx *gin.Context) {
+	var params types.SchedulerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query types.GetSchedulerClusterConfigVersionsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	versions, count, err := h.service.GetSchedulerClusterConfigVersions(ctx.Request.Context(), params.ID, query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, versions)
+}
+
+// @Summary Rollback SchedulerCluster Config
+// @Description Rollback config and client config of SchedulerCluster to the version, and record the rollback as a new version
+// @Tags SchedulerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param version path string true "version"
+// @Param SchedulerCluster body types.RollbackSchedulerClusterConfigRequest true "SchedulerCluster"
+// @Success 200 {object} models.SchedulerCluster
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions/{version}/rollback [post]
+func (h *Handlers) RollbackSchedulerClusterConfig(ctx *gin.Context) {
+	var params types.SchedulerClusterConfigVersionParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.RollbackSchedulerClusterConfigRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	schedulerCluster, err := h.service.RollbackSchedulerClusterConfig(ctx.Request.Context(), params.ID, params.Version, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedulerCluster)
+}
@@ -63,6 +63,15 @@ var (
 		Config:       models.JSONMap{"CandidateParentLimit": float64(1), "FilterParentLimit": float64(10)},
 		ClientConfig: models.JSONMap{"LoadLimit": float64(1)},
 	}
+	mockSchedulerClusterConfigVersionModel = &models.SchedulerClusterConfigVersion{
+		BaseModel:          mockBaseModel,
+		Version:            2,
+		BIO:                "bio",
+		Config:             models.JSONMap{"CandidateParentLimit": float64(1)},
+		ClientConfig:       models.JSONMap{"LoadLimit": float64(1)},
+		Diff:               models.JSONMap{"client_config": map[string]any{"LoadLimit": map[string]any{"previous": float64(2), "current": float64(1)}}},
+		SchedulerClusterID: 2,
+	}
 )
 
 func mockSchedulerClusterRouter(h *Handlers) *gin.Engine {
@@ -75,6 +84,8 @@ func mockSchedulerClusterRouter(h *Handlers) *gin.Engine {
 	sc.GET(":id", h.GetSchedulerCluster)
 	sc.GET("", h.GetSchedulerClusters)
 	sc.PUT(":id/schedulers/:scheduler_id", h.AddSchedulerToSchedulerCluster)
+	sc.GET(":id/config-versions", h.GetSchedulerClusterConfigVersions)
+	sc.POST(":id/config-versions/:version/rollback", h.RollbackSchedulerClusterConfig)
 	return r
 }
 
@@ -369,3 +380,123 @@ func TestHandlers_AddSchedulerToSchedulerCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlers_GetSchedulerClusterConfigVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "unprocessable entity caused by uri",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/scheduler-clusters/test/config-versions", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "unprocessable entity caused by query",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/scheduler-clusters/2/config-versions?page=-1", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "success",
+			req:  httptest.NewRequest(http.MethodGet, "/api/v1/scheduler-clusters/2/config-versions", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetSchedulerClusterConfigVersions(gomock.Any(), gomock.Eq(uint(2)), gomock.Eq(types.GetSchedulerClusterConfigVersionsQuery{
+					Page:    1,
+					PerPage: 10,
+				})).Return([]models.SchedulerClusterConfigVersion{*mockSchedulerClusterConfigVersionModel}, int64(1), nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				version := models.SchedulerClusterConfigVersion{}
+				err := json.Unmarshal(w.Body.Bytes()[1:w.Body.Len()-1], &version)
+				assert.NoError(err)
+				assert.Equal(mockSchedulerClusterConfigVersionModel, &version)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			w := httptest.NewRecorder()
+			h := New(svc)
+			mockRouter := mockSchedulerClusterRouter(h)
+
+			tc.mock(svc.EXPECT())
+			mockRouter.ServeHTTP(w, tc.req)
+			tc.expect(t, w)
+		})
+	}
+}
+
+func TestHandlers_RollbackSchedulerClusterConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "unprocessable entity caused by uri",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/scheduler-clusters/2/config-versions/test/rollback", strings.NewReader(`{}`)),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "unprocessable entity caused by body",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/scheduler-clusters/2/config-versions/1/rollback", nil),
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "success",
+			req:  httptest.NewRequest(http.MethodPost, "/api/v1/scheduler-clusters/2/config-versions/1/rollback", strings.NewReader(`{"bio": "bio", "user_id": 1}`)),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.RollbackSchedulerClusterConfig(gomock.Any(), gomock.Eq(uint(2)), gomock.Eq(uint(1)), gomock.Eq(types.RollbackSchedulerClusterConfigRequest{
+					BIO:    "bio",
+					UserID: 1,
+				})).Return(mockSchedulerClusterModel, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				schedulerCluster := models.SchedulerCluster{}
+				err := json.Unmarshal(w.Body.Bytes(), &schedulerCluster)
+				assert.NoError(err)
+				assert.Equal(mockUnmarshalSchedulerClusterModel, &schedulerCluster)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			w := httptest.NewRecorder()
+			h := New(svc)
+			mockRouter := mockSchedulerClusterRouter(h)
+
+			tc.mock(svc.EXPECT())
+			mockRouter.ServeHTTP(w, tc.req)
+			tc.expect(t, w)
+		})
+	}
+}
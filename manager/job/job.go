@@ -55,6 +55,7 @@ func New(cfg *config.Config, gdb *gorm.DB) (*Job, error) {
 		SentinelPassword: cfg.Database.Redis.SentinelPassword,
 		BrokerDB:         cfg.Database.Redis.BrokerDB,
 		BackendDB:        cfg.Database.Redis.BackendDB,
+		Embedded:         cfg.Database.Redis.Embedded,
 	}, internaljob.GlobalQueue)
 	if err != nil {
 		return nil, err
@@ -72,3 +72,18 @@ func (mr *MockTaskMockRecorder) CreateGetTask(arg0, arg1, arg2 any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGetTask", reflect.TypeOf((*MockTask)(nil).CreateGetTask), arg0, arg1, arg2)
 }
+
+// CreateListPersistentCacheTasks mocks base method.
+func (m *MockTask) CreateListPersistentCacheTasks(arg0 context.Context, arg1 []models.Scheduler, arg2 types.ListPersistentCacheTasksArgs) (*job.GroupJobState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateListPersistentCacheTasks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*job.GroupJobState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateListPersistentCacheTasks indicates an expected call of CreateListPersistentCacheTasks.
+func (mr *MockTaskMockRecorder) CreateListPersistentCacheTasks(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListPersistentCacheTasks", reflect.TypeOf((*MockTask)(nil).CreateListPersistentCacheTasks), arg0, arg1, arg2)
+}
@@ -206,35 +206,36 @@ func (s *syncPeers) mergePeers(ctx context.Context, scheduler models.Scheduler,
 		syncPeers[result.ID] = result
 	}
 
+	// All of the sync peers are seen at the time of syncing.
+	lastSeenAt := time.Now()
+
 	oldPeers := make([]*models.Peer, 0, s.config.Job.SyncPeers.BatchSize)
 	if err := s.db.WithContext(ctx).Model(&models.Peer{}).Where("scheduler_cluster_id = ?", scheduler.SchedulerClusterID).FindInBatches(&oldPeers, s.config.Job.SyncPeers.BatchSize, func(tx *gorm.DB, batch int) error {
 		peers := make([]*models.Peer, 0, s.config.Job.SyncPeers.BatchSize)
+		var versions []*models.PeerVersion
 		for _, oldPeer := range oldPeers {
 			// If the peer exists in the sync peer results, update the peer data in the database with
 			// the sync peer results and delete the sync peer from the sync peers map.
 			isSeedPeer := pkgtypes.ParseHostType(oldPeer.Type) != pkgtypes.HostTypeNormal
 			id := idgen.HostIDV2(oldPeer.IP, oldPeer.Hostname, isSeedPeer)
 			if syncPeer, ok := syncPeers[id]; ok {
-				peers = append(peers, &models.Peer{
-					Hostname:           syncPeer.Hostname,
-					Type:               syncPeer.Type.Name(),
-					IDC:                syncPeer.Network.IDC,
-					Location:           syncPeer.Network.Location,
-					IP:                 syncPeer.IP,
-					Port:               syncPeer.Port,
-					DownloadPort:       syncPeer.DownloadPort,
-					ObjectStoragePort:  syncPeer.ObjectStoragePort,
-					State:              models.PeerStateActive,
-					OS:                 syncPeer.OS,
-					Platform:           syncPeer.Platform,
-					PlatformFamily:     syncPeer.PlatformFamily,
-					PlatformVersion:    syncPeer.PlatformVersion,
-					KernelVersion:      syncPeer.KernelVersion,
-					GitVersion:         syncPeer.Build.GitVersion,
-					GitCommit:          syncPeer.Build.GitCommit,
-					BuildPlatform:      syncPeer.Build.Platform,
-					SchedulerClusterID: uint(syncPeer.SchedulerClusterID),
-				})
+				peer := newPeer(syncPeer, lastSeenAt)
+				peer.ID = oldPeer.ID
+				peer.CreatedAt = oldPeer.CreatedAt
+				peers = append(peers, peer)
+
+				// Record the version change of the peer.
+				if oldPeer.GitVersion != peer.GitVersion || oldPeer.GitCommit != peer.GitCommit {
+					versions = append(versions, &models.PeerVersion{
+						Hostname:           peer.Hostname,
+						IP:                 peer.IP,
+						PreviousGitVersion: oldPeer.GitVersion,
+						PreviousGitCommit:  oldPeer.GitCommit,
+						GitVersion:         peer.GitVersion,
+						GitCommit:          peer.GitCommit,
+						SchedulerClusterID: peer.SchedulerClusterID,
+					})
+				}
 
 				// Delete the sync peer from the sync peers map.
 				delete(syncPeers, id)
@@ -248,7 +249,15 @@ func (s *syncPeers) mergePeers(ctx context.Context, scheduler models.Scheduler,
 
 		// Avoid save empty slice.
 		if len(peers) > 0 {
-			tx.Save(&peers)
+			if err := tx.Save(&peers).Error; err != nil {
+				log.Error(err)
+			}
+		}
+
+		if len(versions) > 0 {
+			if err := tx.Create(&versions).Error; err != nil {
+				log.Error(err)
+			}
 		}
 
 		return nil
@@ -257,28 +266,19 @@ func (s *syncPeers) mergePeers(ctx context.Context, scheduler models.Scheduler,
 		return
 	}
 
-	// Insert the sync peers that do not exist in the database into the peer table.
+	// Insert the sync peers that do not exist in the database into the peer table,
+	// and record the first seen version of the peers.
 	peers := make([]*models.Peer, 0, len(syncPeers))
+	versions := make([]*models.PeerVersion, 0, len(syncPeers))
 	for _, syncPeer := range syncPeers {
-		peers = append(peers, &models.Peer{
-			Hostname:           syncPeer.Hostname,
-			Type:               syncPeer.Type.Name(),
-			IDC:                syncPeer.Network.IDC,
-			Location:           syncPeer.Network.Location,
-			IP:                 syncPeer.IP,
-			Port:               syncPeer.Port,
-			DownloadPort:       syncPeer.DownloadPort,
-			ObjectStoragePort:  syncPeer.ObjectStoragePort,
-			State:              models.PeerStateActive,
-			OS:                 syncPeer.OS,
-			Platform:           syncPeer.Platform,
-			PlatformFamily:     syncPeer.PlatformFamily,
-			PlatformVersion:    syncPeer.PlatformVersion,
-			KernelVersion:      syncPeer.KernelVersion,
-			GitVersion:         syncPeer.Build.GitVersion,
-			GitCommit:          syncPeer.Build.GitCommit,
-			BuildPlatform:      syncPeer.Build.Platform,
-			SchedulerClusterID: uint(syncPeer.SchedulerClusterID),
+		peer := newPeer(syncPeer, lastSeenAt)
+		peers = append(peers, peer)
+		versions = append(versions, &models.PeerVersion{
+			Hostname:           peer.Hostname,
+			IP:                 peer.IP,
+			GitVersion:         peer.GitVersion,
+			GitCommit:          peer.GitCommit,
+			SchedulerClusterID: peer.SchedulerClusterID,
 		})
 	}
 
@@ -286,6 +286,72 @@ func (s *syncPeers) mergePeers(ctx context.Context, scheduler models.Scheduler,
 	if len(peers) > 0 {
 		if err := s.db.WithContext(ctx).CreateInBatches(peers, len(peers)).Error; err != nil {
 			log.Error(err)
+			return
 		}
+
+		if err := s.db.WithContext(ctx).CreateInBatches(versions, len(versions)).Error; err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// newPeer creates the peer model from the sync peer result.
+func newPeer(host *resource.Host, lastSeenAt time.Time) *models.Peer {
+	return &models.Peer{
+		Hostname:          host.Hostname,
+		Type:              host.Type.Name(),
+		IDC:               host.Network.IDC,
+		Location:          host.Network.Location,
+		IP:                host.IP,
+		Port:              host.Port,
+		DownloadPort:      host.DownloadPort,
+		ObjectStoragePort: host.ObjectStoragePort,
+		State:             models.PeerStateActive,
+		OS:                host.OS,
+		Platform:          host.Platform,
+		PlatformFamily:    host.PlatformFamily,
+		PlatformVersion:   host.PlatformVersion,
+		KernelVersion:     host.KernelVersion,
+		GitVersion:        host.Build.GitVersion,
+		GitCommit:         host.Build.GitCommit,
+		BuildPlatform:     host.Build.Platform,
+		GoVersion:         host.Build.GoVersion,
+		CPU: models.JSONMap{
+			"logical_count":   host.CPU.LogicalCount,
+			"physical_count":  host.CPU.PhysicalCount,
+			"percent":         host.CPU.Percent,
+			"process_percent": host.CPU.ProcessPercent,
+		},
+		Memory: models.JSONMap{
+			"total":                host.Memory.Total,
+			"available":            host.Memory.Available,
+			"used":                 host.Memory.Used,
+			"used_percent":         host.Memory.UsedPercent,
+			"process_used_percent": host.Memory.ProcessUsedPercent,
+			"free":                 host.Memory.Free,
+		},
+		Network: models.JSONMap{
+			"tcp_connection_count":        host.Network.TCPConnectionCount,
+			"upload_tcp_connection_count": host.Network.UploadTCPConnectionCount,
+			"download_rate":               host.Network.DownloadRate,
+			"download_rate_limit":         host.Network.DownloadRateLimit,
+			"upload_rate":                 host.Network.UploadRate,
+			"upload_rate_limit":           host.Network.UploadRateLimit,
+		},
+		Disk: models.JSONMap{
+			"total":               host.Disk.Total,
+			"free":                host.Disk.Free,
+			"used":                host.Disk.Used,
+			"used_percent":        host.Disk.UsedPercent,
+			"inodes_total":        host.Disk.InodesTotal,
+			"inodes_used":         host.Disk.InodesUsed,
+			"inodes_free":         host.Disk.InodesFree,
+			"inodes_used_percent": host.Disk.InodesUsedPercent,
+			"write_bandwidth":     host.Disk.WriteBandwidth,
+			"read_bandwidth":      host.Disk.ReadBandwidth,
+		},
+		DiskFree:           host.Disk.Free,
+		LastSeenAt:         lastSeenAt,
+		SchedulerClusterID: uint(host.SchedulerClusterID),
 	}
 }
@@ -42,6 +42,9 @@ type Task interface {
 
 	// CreateDeleteTask create a delete task job.
 	CreateDeleteTask(context.Context, []models.Scheduler, types.DeleteTaskArgs) (*internaljob.GroupJobState, error)
+
+	// CreateListPersistentCacheTasks create a list persistent cache tasks job.
+	CreateListPersistentCacheTasks(context.Context, []models.Scheduler, types.ListPersistentCacheTasksArgs) (*internaljob.GroupJobState, error)
 }
 
 // task is an implementation of Task.
@@ -170,3 +173,63 @@ func (t *task) CreateDeleteTask(ctx context.Context, schedulers []models.Schedul
 		CreatedAt: time.Now(),
 	}, nil
 }
+
+// CreateListPersistentCacheTasks create a list persistent cache tasks job.
+func (t *task) CreateListPersistentCacheTasks(ctx context.Context, schedulers []models.Scheduler, json types.ListPersistentCacheTasksArgs) (*internaljob.GroupJobState, error) {
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, config.SpanListPersistentCacheTasks, trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(config.AttributeListPersistentCacheTasksApplication.String(json.Application))
+	defer span.End()
+
+	args, err := internaljob.MarshalRequest(internaljob.ListPersistentCacheTasksRequest{
+		Application: json.Application,
+		Tag:         json.Tag,
+		State:       json.State,
+		MinAge:      json.MinAge,
+		MaxAge:      json.MaxAge,
+		Page:        json.Page,
+		PerPage:     json.PerPage,
+		Timeout:     json.Timeout,
+	})
+	if err != nil {
+		logger.Errorf("list persistent cache tasks marshal request: %v, error: %v", args, err)
+		return nil, err
+	}
+
+	queues, err := getSchedulerQueues(schedulers)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []*machineryv1tasks.Signature
+	for _, queue := range queues {
+		signatures = append(signatures, &machineryv1tasks.Signature{
+			UUID:       fmt.Sprintf("task_%s", uuid.New().String()),
+			Name:       internaljob.ListPersistentCacheTasksJob,
+			RoutingKey: queue.String(),
+			Args:       args,
+		})
+	}
+
+	group, err := machineryv1tasks.NewGroup(signatures...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []machineryv1tasks.Signature
+	for _, signature := range signatures {
+		tasks = append(tasks, *signature)
+	}
+
+	logger.Infof("create task group %s in queues %v, tasks: %#v", group.GroupUUID, queues, tasks)
+	if _, err := t.job.Server.SendGroupWithContext(ctx, group, 0); err != nil {
+		logger.Errorf("create list persistent cache tasks group %s failed: %s", group.GroupUUID, err)
+		return nil, err
+	}
+
+	return &internaljob.GroupJobState{
+		GroupUUID: group.GroupUUID,
+		State:     machineryv1tasks.StatePending,
+		CreatedAt: time.Now(),
+	}, nil
+}
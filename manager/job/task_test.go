@@ -130,3 +130,54 @@ func TestTask_CreateDeleteTask(t *testing.T) {
 		})
 	}
 }
+
+func TestTask_CreateListPersistentCacheTasks(t *testing.T) {
+	tk := newTask(&job.Job{Server: &machinery.Server{}})
+
+	tests := []struct {
+		name       string
+		schedulers []models.Scheduler
+		args       types.ListPersistentCacheTasksArgs
+		expect     func(t *testing.T, g *job.GroupJobState, e error)
+	}{
+		{
+			name: "queue retrieval error",
+			schedulers: []models.Scheduler{
+				{
+					SchedulerClusterID: 0,
+					Hostname:           "",
+				},
+			},
+			args: types.ListPersistentCacheTasksArgs{
+				Application: "foo",
+			},
+			expect: func(t *testing.T, g *job.GroupJobState, e error) {
+				assert := assert.New(t)
+				assert.Error(errors.New("empty cluster id config is not specified"), e)
+			},
+		},
+		{
+			name: "send group failure",
+			schedulers: []models.Scheduler{
+				{
+					SchedulerClusterID: 1,
+					Hostname:           "hostname",
+				},
+			},
+			args: types.ListPersistentCacheTasksArgs{
+				Application: "foo",
+			},
+			expect: func(t *testing.T, g *job.GroupJobState, e error) {
+				assert := assert.New(t)
+				assert.Error(errors.New("Result backend required"), e)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tk.CreateListPersistentCacheTasks(context.TODO(), tc.schedulers, tc.args)
+			tc.expect(t, res, err)
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"io/fs"
 	"net"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/gin-contrib/static"
@@ -107,6 +108,11 @@ type Server struct {
 func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	s := &Server{config: cfg}
 
+	// Initialize the sqlite database file in the data directory by default.
+	if cfg.Database.Type == config.DatabaseTypeSQLite && cfg.Database.SQLite.Path == "" {
+		cfg.Database.SQLite.Path = filepath.Join(d.DataDir(), config.DefaultSQLiteDBName)
+	}
+
 	// Initialize database.
 	db, err := database.New(cfg)
 	if err != nil {
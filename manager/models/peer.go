@@ -16,6 +16,8 @@
 
 package models
 
+import "time"
+
 const (
 	// PeerStateActive represents the peer whose state is active.
 	PeerStateActive = "active"
@@ -43,6 +45,13 @@ type Peer struct {
 	GitVersion         string           `gorm:"column:git_version;type:varchar(256);index:idx_peer_git_version;comment:git version" json:"git_version"`
 	GitCommit          string           `gorm:"column:git_commit;type:varchar(256);index:idx_peer_git_commit;comment:git commit" json:"git_commit"`
 	BuildPlatform      string           `gorm:"column:build_platform;type:varchar(256);comment:build platform" json:"build_platform"`
+	GoVersion          string           `gorm:"column:go_version;type:varchar(256);comment:go version" json:"go_version"`
+	CPU                JSONMap          `gorm:"column:cpu;comment:cpu information" json:"cpu"`
+	Memory             JSONMap          `gorm:"column:memory;comment:memory information" json:"memory"`
+	Network            JSONMap          `gorm:"column:network;comment:network information" json:"network"`
+	Disk               JSONMap          `gorm:"column:disk;comment:disk information" json:"disk"`
+	DiskFree           uint64           `gorm:"column:disk_free;index:idx_peer_disk_free;comment:free disk space in bytes" json:"disk_free"`
+	LastSeenAt         time.Time        `gorm:"column:last_seen_at;type:timestamp;default:current_timestamp;comment:last seen time" json:"last_seen_at"`
 	SchedulerClusterID uint             `gorm:"index:uk_peer,unique;not null;comment:scheduler cluster id" json:"scheduler_cluster_id"`
 	SchedulerCluster   SchedulerCluster `json:"scheduler_cluster"`
 }
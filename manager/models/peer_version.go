@@ -0,0 +1,30 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+// PeerVersion records the version change of the peer, the peer is identified by
+// hostname, ip and scheduler cluster id, so the history is kept after the peer is deleted.
+type PeerVersion struct {
+	BaseModel
+	Hostname           string `gorm:"column:host_name;type:varchar(256);index:idx_peer_version_host;not null;comment:hostname" json:"host_name"`
+	IP                 string `gorm:"column:ip;type:varchar(256);index:idx_peer_version_host;not null;comment:ip address" json:"ip"`
+	PreviousGitVersion string `gorm:"column:previous_git_version;type:varchar(256);comment:previous git version" json:"previous_git_version"`
+	PreviousGitCommit  string `gorm:"column:previous_git_commit;type:varchar(256);comment:previous git commit" json:"previous_git_commit"`
+	GitVersion         string `gorm:"column:git_version;type:varchar(256);comment:git version" json:"git_version"`
+	GitCommit          string `gorm:"column:git_commit;type:varchar(256);comment:git commit" json:"git_commit"`
+	SchedulerClusterID uint   `gorm:"index:idx_peer_version_host;not null;comment:scheduler cluster id" json:"scheduler_cluster_id"`
+}
@@ -16,6 +16,8 @@
 
 package models
 
+import "time"
+
 const (
 	// SchedulerStateActive represents the scheduler whose state is active.
 	SchedulerStateActive = "active"
@@ -33,6 +35,7 @@ type Scheduler struct {
 	Port               int32            `gorm:"column:port;not null;comment:grpc service listening port" json:"port"`
 	State              string           `gorm:"column:state;type:varchar(256);default:'inactive';comment:service state" json:"state"`
 	Features           Array            `gorm:"column:features;comment:feature flags" json:"features"`
+	LastKeepAliveAt    time.Time        `gorm:"column:last_keep_alive_at;type:timestamp;default:current_timestamp;comment:last keepalive time" json:"last_keep_alive_at"`
 	SchedulerClusterID uint             `gorm:"index:uk_scheduler,unique;not null;comment:scheduler cluster id"  json:"scheduler_cluster_id"`
 	SchedulerCluster   SchedulerCluster `json:"scheduler_cluster"`
 }
@@ -28,4 +28,8 @@ type SchedulerCluster struct {
 	Schedulers       []Scheduler       `json:"schedulers"`
 	Peers            []Peer            `json:"peers"`
 	Jobs             []Job             `gorm:"many2many:job_scheduler_cluster;" json:"jobs"`
+
+	// ActivePeerCount is the number of active peers in the scheduler cluster,
+	// it is not persisted and only populated when searching scheduler clusters.
+	ActivePeerCount int64 `gorm:"-" json:"-"`
 }
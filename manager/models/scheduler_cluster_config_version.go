@@ -0,0 +1,30 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+type SchedulerClusterConfigVersion struct {
+	BaseModel
+	Version            uint             `gorm:"column:version;index:uk_scheduler_cluster_config_version,unique;not null;comment:config version" json:"version"`
+	BIO                string           `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	Config             JSONMap          `gorm:"column:config;not null;comment:configuration" json:"config"`
+	ClientConfig       JSONMap          `gorm:"column:client_config;not null;comment:client configuration" json:"client_config"`
+	Diff               JSONMap          `gorm:"column:diff;comment:difference from the previous version" json:"diff"`
+	UserID             uint             `gorm:"comment:user id" json:"user_id"`
+	User               User             `json:"user"`
+	SchedulerClusterID uint             `gorm:"index:uk_scheduler_cluster_config_version,unique;not null;comment:scheduler cluster id" json:"scheduler_cluster_id"`
+	SchedulerCluster   SchedulerCluster `json:"-"`
+}
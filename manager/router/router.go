@@ -155,6 +155,8 @@ func Init(cfg *config.Config, logDir string, service service.Service, database *
 	sc.GET(":id", h.GetSchedulerCluster)
 	sc.GET("", h.GetSchedulerClusters)
 	sc.PUT(":id/schedulers/:scheduler_id", h.AddSchedulerToSchedulerCluster)
+	sc.GET(":id/config-versions", h.GetSchedulerClusterConfigVersions)
+	sc.POST(":id/config-versions/:version/rollback", h.RollbackSchedulerClusterConfig)
 
 	// Scheduler.
 	s := apiv1.Group("/schedulers", jwt.MiddlewareFunc(), rbac)
@@ -192,6 +194,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, database *
 	peer.DELETE(":id", h.DestroyPeer)
 	peer.GET(":id", h.GetPeer)
 	peer.GET("", h.GetPeers)
+	peer.GET(":id/versions", h.GetPeerVersions)
 
 	// Bucket.
 	bucket := apiv1.Group("/buckets", jwt.MiddlewareFunc(), rbac)
@@ -233,6 +236,11 @@ func Init(cfg *config.Config, logDir string, service service.Service, database *
 	pat.GET(":id", h.GetPersonalAccessToken)
 	pat.GET("", h.GetPersonalAccessTokens)
 
+	// Resource.
+	rs := apiv1.Group("/resources", jwt.MiddlewareFunc(), rbac)
+	rs.GET("export", h.ExportResources)
+	rs.POST("apply", h.ApplyResources)
+
 	// Open API router.
 	oapiv1 := r.Group("/oapi/v1")
 
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"time"
 
 	cachev9 "github.com/go-redis/cache/v9"
 	"github.com/redis/go-redis/v9"
@@ -551,6 +552,12 @@ func (s *managerServerV1) ListSchedulers(ctx context.Context, req *managerv1.Lis
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	// Count active peers of scheduler clusters for the load of scheduler clusters.
+	activePeerCounts, err := countActivePeers(ctx, s.db)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	// Remove schedulers which not have schedule feature. As OceanBase does not support JSON type,
 	// it is not possible to use datatypes.JSONQuery for filtering.
 	var tmpSchedulerClusters []models.SchedulerCluster
@@ -562,21 +569,18 @@ func (s *managerServerV1) ListSchedulers(ctx context.Context, req *managerv1.Lis
 			}
 		}
 
-		if len(tmpSchedulers) != 0 {
-			schedulerCluster.Schedulers = tmpSchedulers
-			tmpSchedulerClusters = append(tmpSchedulerClusters, schedulerCluster)
-		}
+		// Scheduler clusters without schedulers are kept, so the searcher
+		// can fail over from them.
+		schedulerCluster.Schedulers = tmpSchedulers
+		schedulerCluster.ActivePeerCount = activePeerCounts[schedulerCluster.ID]
+		tmpSchedulerClusters = append(tmpSchedulerClusters, schedulerCluster)
 	}
 	log.Debugf("list scheduler clusters %v with hostInfo %#v", getSchedulerClusterNames(tmpSchedulerClusters), req.HostInfo)
 
 	// Search optimal scheduler clusters.
 	// If searcher can not found candidate scheduler cluster,
 	// return all scheduler clusters.
-	var (
-		candidateSchedulerClusters []models.SchedulerCluster
-		err                        error
-	)
-	candidateSchedulerClusters, err = s.searcher.FindSchedulerClusters(ctx, tmpSchedulerClusters, req.Ip, req.Hostname, req.HostInfo, logger.CoreLogger)
+	candidateSchedulerClusters, err := s.searcher.FindSchedulerClusters(ctx, tmpSchedulerClusters, req.Ip, req.Hostname, req.HostInfo, logger.CoreLogger)
 	if err != nil {
 		log.Error(err)
 		metrics.SearchSchedulerClusterFailureCount.WithLabelValues(req.Version, req.Commit).Inc()
@@ -785,6 +789,7 @@ func (s *managerServerV1) KeepAlive(stream managerv1.Manager_KeepAliveServer) er
 	log.Info("keepalive for the first time")
 
 	// Initialize active scheduler.
+	lastKeepAliveAt := time.Now()
 	if sourceType == managerv1.SourceType_SCHEDULER_SOURCE {
 		scheduler := models.Scheduler{}
 		if err := s.db.First(&scheduler, models.Scheduler{
@@ -792,7 +797,8 @@ func (s *managerServerV1) KeepAlive(stream managerv1.Manager_KeepAliveServer) er
 			IP:                 ip,
 			SchedulerClusterID: clusterID,
 		}).Updates(models.Scheduler{
-			State: models.SchedulerStateActive,
+			State:           models.SchedulerStateActive,
+			LastKeepAliveAt: lastKeepAliveAt,
 		}).Error; err != nil {
 			return status.Error(codes.Internal, err.Error())
 		}
@@ -879,5 +885,18 @@ func (s *managerServerV1) KeepAlive(stream managerv1.Manager_KeepAliveServer) er
 			log.Errorf("keepalive failed: %s", err.Error())
 			return status.Error(codes.Unknown, err.Error())
 		}
+
+		// Record the last keepalive time of scheduler, the searcher skips the scheduler
+		// which has missed keepalives.
+		if sourceType == managerv1.SourceType_SCHEDULER_SOURCE && time.Since(lastKeepAliveAt) >= keepAliveRecordInterval {
+			lastKeepAliveAt = time.Now()
+			if err := s.db.Model(&models.Scheduler{}).Where(&models.Scheduler{
+				Hostname:           hostname,
+				IP:                 ip,
+				SchedulerClusterID: clusterID,
+			}).Update("last_keep_alive_at", lastKeepAliveAt).Error; err != nil {
+				log.Warnf("record keepalive time failed: %s", err.Error())
+			}
+		}
 	}
 }
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"time"
 
 	cachev9 "github.com/go-redis/cache/v9"
 	"github.com/redis/go-redis/v9"
@@ -358,7 +359,7 @@ func (s *managerServerV2) GetScheduler(ctx context.Context, req *managerv2.GetSc
 	}
 
 	// Marshal config of scheduler.
-	schedulerClusterConfig, err := scheduler.SchedulerCluster.Config.MarshalJSON()
+	schedulerClusterConfig, err := s.marshalSchedulerClusterConfig(ctx, &scheduler.SchedulerCluster)
 	if err != nil {
 		return nil, status.Error(codes.DataLoss, err.Error())
 	}
@@ -446,6 +447,48 @@ func (s *managerServerV2) GetScheduler(ctx context.Context, req *managerv2.GetSc
 	return &pbScheduler, nil
 }
 
+// marshalSchedulerClusterConfig marshals the config of the scheduler cluster, if the scheduler
+// cluster is federated, the active schedulers of the other federated clusters are filled in the config.
+func (s *managerServerV2) marshalSchedulerClusterConfig(ctx context.Context, schedulerCluster *models.SchedulerCluster) ([]byte, error) {
+	if federated, ok := schedulerCluster.Config["federated"].(bool); !ok || !federated {
+		return schedulerCluster.Config.MarshalJSON()
+	}
+
+	var schedulerClusters []models.SchedulerCluster
+	if err := s.db.WithContext(ctx).Preload("Schedulers", &models.Scheduler{
+		State: models.SchedulerStateActive,
+	}).Find(&schedulerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	federatedSchedulers := []types.SchedulerClusterFederatedScheduler{}
+	for _, federatedSchedulerCluster := range schedulerClusters {
+		if federatedSchedulerCluster.ID == schedulerCluster.ID {
+			continue
+		}
+
+		if federated, ok := federatedSchedulerCluster.Config["federated"].(bool); !ok || !federated {
+			continue
+		}
+
+		for _, scheduler := range federatedSchedulerCluster.Schedulers {
+			federatedSchedulers = append(federatedSchedulers, types.SchedulerClusterFederatedScheduler{
+				Hostname:           scheduler.Hostname,
+				IP:                 scheduler.IP,
+				SchedulerClusterID: scheduler.SchedulerClusterID,
+			})
+		}
+	}
+
+	config := make(models.JSONMap, len(schedulerCluster.Config)+1)
+	for key, value := range schedulerCluster.Config {
+		config[key] = value
+	}
+
+	config["federated_schedulers"] = federatedSchedulers
+	return config.MarshalJSON()
+}
+
 // Update scheduler configuration.
 func (s *managerServerV2) UpdateScheduler(ctx context.Context, req *managerv2.UpdateSchedulerRequest) (*managerv2.Scheduler, error) {
 	log := logger.WithHostnameAndIP(req.Hostname, req.Ip)
@@ -570,6 +613,12 @@ func (s *managerServerV2) ListSchedulers(ctx context.Context, req *managerv2.Lis
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	// Count active peers of scheduler clusters for the load of scheduler clusters.
+	activePeerCounts, err := countActivePeers(ctx, s.db)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	// Remove schedulers which not have schedule feature. As OceanBase does not support JSON type,
 	// it is not possible to use datatypes.JSONQuery for filtering.
 	var tmpSchedulerClusters []models.SchedulerCluster
@@ -581,21 +630,18 @@ func (s *managerServerV2) ListSchedulers(ctx context.Context, req *managerv2.Lis
 			}
 		}
 
-		if len(tmpSchedulers) != 0 {
-			schedulerCluster.Schedulers = tmpSchedulers
-			tmpSchedulerClusters = append(tmpSchedulerClusters, schedulerCluster)
-		}
+		// Scheduler clusters without schedulers are kept, so the searcher
+		// can fail over from them.
+		schedulerCluster.Schedulers = tmpSchedulers
+		schedulerCluster.ActivePeerCount = activePeerCounts[schedulerCluster.ID]
+		tmpSchedulerClusters = append(tmpSchedulerClusters, schedulerCluster)
 	}
 	log.Debugf("list scheduler clusters %v, idc is %s, location is %s", getSchedulerClusterNames(tmpSchedulerClusters), req.GetIdc(), req.GetLocation())
 
 	// Search optimal scheduler clusters.
 	// If searcher can not found candidate scheduler cluster,
 	// return all scheduler clusters.
-	var (
-		candidateSchedulerClusters []models.SchedulerCluster
-		err                        error
-	)
-	candidateSchedulerClusters, err = s.searcher.FindSchedulerClusters(ctx, tmpSchedulerClusters, req.Ip, req.Hostname,
+	candidateSchedulerClusters, err := s.searcher.FindSchedulerClusters(ctx, tmpSchedulerClusters, req.Ip, req.Hostname,
 		map[string]string{searcher.ConditionIDC: req.GetIdc(), searcher.ConditionLocation: req.GetLocation()}, logger.CoreLogger)
 	if err != nil {
 		log.Error(err)
@@ -774,6 +820,7 @@ func (s *managerServerV2) KeepAlive(stream managerv2.Manager_KeepAliveServer) er
 	log.Info("keepalive for the first time")
 
 	// Initialize active scheduler.
+	lastKeepAliveAt := time.Now()
 	if sourceType == managerv2.SourceType_SCHEDULER_SOURCE {
 		scheduler := models.Scheduler{}
 		if err := s.db.First(&scheduler, models.Scheduler{
@@ -781,7 +828,8 @@ func (s *managerServerV2) KeepAlive(stream managerv2.Manager_KeepAliveServer) er
 			IP:                 ip,
 			SchedulerClusterID: clusterID,
 		}).Updates(models.Scheduler{
-			State: models.SchedulerStateActive,
+			State:           models.SchedulerStateActive,
+			LastKeepAliveAt: lastKeepAliveAt,
 		}).Error; err != nil {
 			return status.Error(codes.Internal, err.Error())
 		}
@@ -868,5 +916,18 @@ func (s *managerServerV2) KeepAlive(stream managerv2.Manager_KeepAliveServer) er
 			log.Errorf("keepalive failed: %s", err.Error())
 			return status.Error(codes.Unknown, err.Error())
 		}
+
+		// Record the last keepalive time of scheduler, the searcher skips the scheduler
+		// which has missed keepalives.
+		if sourceType == managerv2.SourceType_SCHEDULER_SOURCE && time.Since(lastKeepAliveAt) >= keepAliveRecordInterval {
+			lastKeepAliveAt = time.Now()
+			if err := s.db.Model(&models.Scheduler{}).Where(&models.Scheduler{
+				Hostname:           hostname,
+				IP:                 ip,
+				SchedulerClusterID: clusterID,
+			}).Update("last_keep_alive_at", lastKeepAliveAt).Error; err != nil {
+				log.Warnf("record keepalive time failed: %s", err.Error())
+			}
+		}
 	}
 }
@@ -17,6 +17,9 @@
 package rpcserver
 
 import (
+	"context"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"gorm.io/gorm"
@@ -30,6 +33,9 @@ import (
 	managerserver "d7y.io/dragonfly/v2/pkg/rpc/manager/server"
 )
 
+// keepAliveRecordInterval is the interval of recording the last keepalive time of scheduler.
+const keepAliveRecordInterval = 30 * time.Second
+
 // Server is grpc server.
 type Server struct {
 	// Manager configuration.
@@ -79,3 +85,22 @@ func getSchedulerClusterNames(clusters []models.SchedulerCluster) []string {
 
 	return names
 }
+
+// countActivePeers counts the active peers of each scheduler cluster.
+func countActivePeers(ctx context.Context, db *gorm.DB) (map[uint]int64, error) {
+	var rows []struct {
+		SchedulerClusterID uint
+		Count              int64
+	}
+	if err := db.WithContext(ctx).Model(&models.Peer{}).Select("scheduler_cluster_id, count(*) as count").
+		Where("state = ?", models.PeerStateActive).Group("scheduler_cluster_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.SchedulerClusterID] = row.Count
+	}
+
+	return counts, nil
+}
@@ -22,11 +22,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	stdmath "math"
 	"net"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
+	xxhash "github.com/cespare/xxhash/v2"
 	"github.com/mitchellh/mapstructure"
 	"github.com/yl2chen/cidranger"
 	"go.uber.org/zap"
@@ -75,12 +78,32 @@ const (
 	maxElementLen = 5
 )
 
+const (
+	// SchedulerKeepAliveTimeout is the timeout of scheduler keepalive, the scheduler
+	// is considered unhealthy if it has not kept alive within the timeout.
+	SchedulerKeepAliveTimeout = 2 * time.Minute
+
+	// defaultWeight is the default traffic weight of scheduler cluster.
+	defaultWeight uint32 = 1
+)
+
 // Scheduler cluster scopes.
 type Scopes struct {
 	IDC       string   `mapstructure:"idc"`
 	Location  string   `mapstructure:"location"`
 	CIDRs     []string `mapstructure:"cidrs"`
 	Hostnames []string `mapstructure:"hostnames"`
+
+	// Failover is the names of scheduler clusters that hosts fail over to in order,
+	// when the scheduler cluster is unhealthy.
+	Failover []string `mapstructure:"failover"`
+
+	// Weight is the traffic weight among scheduler clusters with the same score.
+	Weight uint32 `mapstructure:"weight"`
+
+	// LoadLimit is the maximum number of active peers per healthy scheduler,
+	// the scheduler cluster is considered overloaded when it is exceeded.
+	LoadLimit uint32 `mapstructure:"load_limit"`
 }
 
 type Searcher interface {
@@ -102,7 +125,10 @@ func New(pluginDir string) Searcher {
 	return s
 }
 
-// FindSchedulerClusters finds scheduler clusters that best matches the evaluation.
+// FindSchedulerClusters finds scheduler clusters that best matches the evaluation. The healthy scheduler
+// clusters are returned in order, the first is the primary and the rest are the secondaries. The failover
+// targets declared by a scheduler cluster follow it, so hosts of an unhealthy scheduler cluster move
+// to its failover targets first and then to the next nearest scheduler clusters.
 func (s *searcher) FindSchedulerClusters(ctx context.Context, schedulerClusters []models.SchedulerCluster, ip, hostname string,
 	conditions map[string]string, log *zap.SugaredLogger) ([]models.SchedulerCluster, error) {
 	log = log.With("ip", ip, "hostname", hostname, "conditions", conditions)
@@ -111,28 +137,84 @@ func (s *searcher) FindSchedulerClusters(ctx context.Context, schedulerClusters
 		return nil, errors.New("empty scheduler clusters")
 	}
 
-	clusters := FilterSchedulerClusters(conditions, schedulerClusters)
-	if len(clusters) == 0 {
+	healthy := make(map[string]struct{}, len(schedulerClusters))
+	for _, cluster := range FilterSchedulerClusters(conditions, schedulerClusters) {
+		healthy[cluster.Name] = struct{}{}
+	}
+
+	if len(healthy) == 0 {
 		return nil, fmt.Errorf("conditions %#v does not match any scheduler cluster", conditions)
 	}
 
-	sort.Slice(
-		clusters,
-		func(i, j int) bool {
-			var si, sj Scopes
-			if err := mapstructure.Decode(clusters[i].Scopes, &si); err != nil {
-				log.Errorf("cluster %s decode scopes failed: %v", clusters[i].Name, err)
-				return false
-			}
+	type candidate struct {
+		cluster models.SchedulerCluster
+		scopes  Scopes
+		score   float64
+		key     float64
+	}
 
-			if err := mapstructure.Decode(clusters[j].Scopes, &sj); err != nil {
-				log.Errorf("cluster %s decode scopes failed: %v", clusters[i].Name, err)
-				return false
-			}
+	var weighted bool
+	candidates := make([]candidate, 0, len(schedulerClusters))
+	for _, cluster := range schedulerClusters {
+		var scopes Scopes
+		if err := mapstructure.Decode(cluster.Scopes, &scopes); err != nil {
+			log.Errorf("cluster %s decode scopes failed: %v", cluster.Name, err)
+		}
+
+		if scopes.Weight > 0 {
+			weighted = true
+		}
+
+		candidates = append(candidates, candidate{
+			cluster: cluster,
+			scopes:  scopes,
+			score:   Evaluate(ip, hostname, conditions, scopes, cluster, log),
+			key:     calculateWeightedKey(ip, hostname, cluster.Name, scopes.Weight),
+		})
+	}
+
+	// Scheduler clusters with the same score are ordered by weight only if any weight is declared,
+	// otherwise the given order is kept.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score || !weighted {
+			return candidates[i].score > candidates[j].score
+		}
+
+		return candidates[i].key < candidates[j].key
+	})
+
+	indexes := make(map[string]int, len(candidates))
+	for i, candidate := range candidates {
+		indexes[candidate.cluster.Name] = i
+	}
 
-			return Evaluate(ip, hostname, conditions, si, clusters[i], log) > Evaluate(ip, hostname, conditions, sj, clusters[j], log)
-		},
+	var (
+		clusters []models.SchedulerCluster
+		visit    func(i int)
 	)
+	visited := make([]bool, len(candidates))
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+
+		if _, ok := healthy[candidates[i].cluster.Name]; ok {
+			clusters = append(clusters, candidates[i].cluster)
+		} else {
+			log.Infof("cluster %s is unhealthy, fail over to %v", candidates[i].cluster.Name, candidates[i].scopes.Failover)
+		}
+
+		for _, name := range candidates[i].scopes.Failover {
+			if j, ok := indexes[name]; ok {
+				visit(j)
+			}
+		}
+	}
+
+	for i := range candidates {
+		visit(i)
+	}
 
 	return clusters, nil
 }
@@ -146,6 +228,25 @@ func FilterSchedulerClusters(conditions map[string]string, schedulerClusters []m
 			continue
 		}
 
+		// All schedulers in the scheduler cluster have missed keepalives.
+		var healthySchedulerCount int64
+		for _, scheduler := range schedulerCluster.Schedulers {
+			if scheduler.LastKeepAliveAt.IsZero() || time.Since(scheduler.LastKeepAliveAt) <= SchedulerKeepAliveTimeout {
+				healthySchedulerCount++
+			}
+		}
+
+		if healthySchedulerCount == 0 {
+			continue
+		}
+
+		// The active peers of the scheduler cluster exceed the load limit.
+		var scopes Scopes
+		if err := mapstructure.Decode(schedulerCluster.Scopes, &scopes); err == nil && scopes.LoadLimit > 0 &&
+			schedulerCluster.ActivePeerCount > healthySchedulerCount*int64(scopes.LoadLimit) {
+			continue
+		}
+
 		clusters = append(clusters, schedulerCluster)
 	}
 
@@ -161,6 +262,20 @@ func Evaluate(ip, hostname string, conditions map[string]string, scopes Scopes,
 		clusterTypeWeight*calculateClusterTypeScore(cluster)
 }
 
+// calculateWeightedKey calculates the key of weighted rendezvous hashing, smaller and better.
+// The key is stable for the same host, so the host is always assigned to the same scheduler cluster
+// among the scheduler clusters with the same score, and the traffic is split by weight.
+func calculateWeightedKey(ip, hostname, clusterName string, weight uint32) float64 {
+	if weight == 0 {
+		weight = defaultWeight
+	}
+
+	// Convert the hash to a uniform float in (0, 1).
+	h := xxhash.Sum64String(ip + hostname + clusterName)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -stdmath.Log(u) / float64(weight)
+}
+
 // calculateCIDRAffinityScore 0.0~1.0 larger and better.
 func calculateCIDRAffinityScore(ip string, cidrs []string, log *zap.SugaredLogger) float64 {
 	// Construct CIDR ranger.
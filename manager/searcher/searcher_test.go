@@ -18,7 +18,9 @@ package searcher
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -422,6 +424,172 @@ func TestSearcher_FindSchedulerClusters(t *testing.T) {
 				assert.Equal(len(data), 7)
 			},
 		},
+		{
+			name: "skip scheduler cluster whose schedulers missed keepalives",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname:        "foo",
+							State:           "active",
+							LastKeepAliveAt: time.Now().Add(-2 * SchedulerKeepAliveTimeout),
+						},
+					},
+					IsDefault: true,
+				},
+				{
+					Name: "bar",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname:        "bar",
+							State:           "active",
+							LastKeepAliveAt: time.Now(),
+						},
+					},
+				},
+			},
+			conditions: map[string]string{},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(data), 1)
+				assert.Equal(data[0].Name, "bar")
+			},
+		},
+		{
+			name: "skip scheduler cluster which exceeds load limit",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"load_limit": float64(10),
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "foo",
+							State:    "active",
+						},
+					},
+					ActivePeerCount: 11,
+					IsDefault:       true,
+				},
+				{
+					Name: "bar",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(data), 1)
+				assert.Equal(data[0].Name, "bar")
+			},
+		},
+		{
+			name: "fail over to the declared scheduler cluster",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"location": "location-1",
+						"failover": []any{"baz"},
+					},
+				},
+				{
+					Name: "bar",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+					IsDefault: true,
+				},
+				{
+					Name: "baz",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "baz",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"location": "location-1"},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(data), 2)
+				assert.Equal(data[0].Name, "baz")
+				assert.Equal(data[1].Name, "bar")
+			},
+		},
+		{
+			name: "fail over to the next nearest scheduler cluster",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"location": "location-1|location-2",
+					},
+				},
+				{
+					Name: "bar",
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "baz",
+					Scopes: map[string]any{
+						"location": "location-1",
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "baz",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"location": "location-1|location-2"},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(data), 2)
+				assert.Equal(data[0].Name, "baz")
+				assert.Equal(data[1].Name, "bar")
+			},
+		},
+		{
+			name: "all scheduler clusters are unhealthy",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"failover": []any{"bar"},
+					},
+				},
+				{
+					Name: "bar",
+				},
+			},
+			conditions: map[string]string{},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -432,3 +600,18 @@ func TestSearcher_FindSchedulerClusters(t *testing.T) {
 		})
 	}
 }
+
+func TestSearcher_calculateWeightedKey(t *testing.T) {
+	assert := assert.New(t)
+
+	var fooCount int
+	for i := 0; i < 10000; i++ {
+		hostname := fmt.Sprintf("host-%d", i)
+		if calculateWeightedKey("127.0.0.1", hostname, "foo", 3) < calculateWeightedKey("127.0.0.1", hostname, "bar", 1) {
+			fooCount++
+		}
+	}
+
+	assert.InDelta(0.75, float64(fooCount)/10000, 0.05)
+	assert.Equal(calculateWeightedKey("127.0.0.1", "foo", "bar", 0), calculateWeightedKey("127.0.0.1", "foo", "bar", defaultWeight))
+}
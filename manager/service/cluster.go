@@ -72,6 +72,11 @@ func (s *service) CreateCluster(ctx context.Context, json types.CreateClusterReq
 		return nil, err
 	}
 
+	if err := createSchedulerClusterConfigVersion(ctx, tx, nil, &schedulerCluster, 0, json.BIO); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	seedPeerCluster := models.SeedPeerCluster{
 		Name:   json.Name,
 		BIO:    json.BIO,
@@ -157,6 +162,13 @@ func (s *service) DestroyCluster(ctx context.Context, id uint) error {
 		return err
 	}
 
+	if err := tx.WithContext(ctx).Unscoped().Where(&models.SchedulerClusterConfigVersion{
+		SchedulerClusterID: id,
+	}).Delete(&models.SchedulerClusterConfigVersion{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -209,7 +221,13 @@ func (s *service) UpdateCluster(ctx context.Context, id uint, json types.UpdateC
 	}
 
 	schedulerCluster := models.SchedulerCluster{}
-	if err := tx.WithContext(ctx).Preload("SeedPeerClusters").First(&schedulerCluster, id).Updates(models.SchedulerCluster{
+	if err := lockSchedulerCluster(tx.WithContext(ctx)).Preload("SeedPeerClusters").First(&schedulerCluster, id).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	previous := schedulerCluster
+
+	if err := tx.WithContext(ctx).Model(&schedulerCluster).Updates(models.SchedulerCluster{
 		Name:         json.Name,
 		BIO:          json.BIO,
 		Config:       schedulerClusterConfig,
@@ -220,6 +238,11 @@ func (s *service) UpdateCluster(ctx context.Context, id uint, json types.UpdateC
 		return nil, err
 	}
 
+	if err := createSchedulerClusterConfigVersion(ctx, tx, &previous, &schedulerCluster, 0, json.BIO); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Updates does not accept bool as false.
 	// Refer to https://stackoverflow.com/questions/56653423/gorm-doesnt-update-boolean-field-to-false.
 	if json.IsDefault != schedulerCluster.IsDefault {
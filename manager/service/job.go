@@ -188,6 +188,59 @@ func (s *service) CreateDeleteTaskJob(ctx context.Context, json types.CreateDele
 	return &job, nil
 }
 
+func (s *service) CreateListPersistentCacheTasksJob(ctx context.Context, json types.CreateListPersistentCacheTasksJobRequest) (*models.Job, error) {
+	if json.Args.Timeout == 0 {
+		json.Args.Timeout = types.DefaultJobTimeout
+	}
+
+	if json.Args.Page == 0 {
+		json.Args.Page = internaljob.DefaultListPersistentCacheTasksPage
+	}
+
+	if json.Args.PerPage == 0 {
+		json.Args.PerPage = internaljob.DefaultListPersistentCacheTasksPerPage
+	}
+
+	args, err := structure.StructToMap(json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	// The schedulers in the same cluster share the persistent cache tasks in redis,
+	// so only one scheduler in each cluster is required.
+	schedulers, err := s.findSchedulerInClusters(ctx, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groupJobState, err := s.job.CreateListPersistentCacheTasks(ctx, schedulers, json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedulerClusters []models.SchedulerCluster
+	for _, scheduler := range schedulers {
+		schedulerClusters = append(schedulerClusters, scheduler.SchedulerCluster)
+	}
+
+	job := models.Job{
+		TaskID:            groupJobState.GroupUUID,
+		BIO:               json.BIO,
+		Type:              json.Type,
+		State:             groupJobState.State,
+		Args:              args,
+		UserID:            json.UserID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), internaljob.ListPersistentCacheTasksJob, job.ID, job.TaskID)
+	return &job, nil
+}
+
 func (s *service) findSchedulerInClusters(ctx context.Context, schedulerClusterIDs []uint) ([]models.Scheduler, error) {
 	var activeSchedulers []models.Scheduler
 	if len(schedulerClusterIDs) != 0 {
@@ -117,6 +117,21 @@ func (mr *MockServiceMockRecorder) AddSeedPeerToSeedPeerCluster(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSeedPeerToSeedPeerCluster", reflect.TypeOf((*MockService)(nil).AddSeedPeerToSeedPeerCluster), arg0, arg1, arg2)
 }
 
+// ApplyResources mocks base method.
+func (m *MockService) ApplyResources(arg0 context.Context, arg1 types.Resources, arg2 types.ApplyResourcesQuery) (*types.ApplyResourcesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyResources", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*types.ApplyResourcesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyResources indicates an expected call of ApplyResources.
+func (mr *MockServiceMockRecorder) ApplyResources(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyResources", reflect.TypeOf((*MockService)(nil).ApplyResources), arg0, arg1, arg2)
+}
+
 // CreateApplication mocks base method.
 func (m *MockService) CreateApplication(arg0 context.Context, arg1 types.CreateApplicationRequest) (*models.Application, error) {
 	m.ctrl.T.Helper()
@@ -206,6 +221,21 @@ func (mr *MockServiceMockRecorder) CreateGetTaskJob(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGetTaskJob", reflect.TypeOf((*MockService)(nil).CreateGetTaskJob), arg0, arg1)
 }
 
+// CreateListPersistentCacheTasksJob mocks base method.
+func (m *MockService) CreateListPersistentCacheTasksJob(arg0 context.Context, arg1 types.CreateListPersistentCacheTasksJobRequest) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateListPersistentCacheTasksJob", arg0, arg1)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateListPersistentCacheTasksJob indicates an expected call of CreateListPersistentCacheTasksJob.
+func (mr *MockServiceMockRecorder) CreateListPersistentCacheTasksJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListPersistentCacheTasksJob", reflect.TypeOf((*MockService)(nil).CreateListPersistentCacheTasksJob), arg0, arg1)
+}
+
 // CreateOauth mocks base method.
 func (m *MockService) CreateOauth(arg0 context.Context, arg1 types.CreateOauthRequest) (*models.Oauth, error) {
 	m.ctrl.T.Helper()
@@ -582,6 +612,21 @@ func (mr *MockServiceMockRecorder) DestroySeedPeerCluster(arg0, arg1 any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroySeedPeerCluster", reflect.TypeOf((*MockService)(nil).DestroySeedPeerCluster), arg0, arg1)
 }
 
+// ExportResources mocks base method.
+func (m *MockService) ExportResources(arg0 context.Context) (*types.Resources, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportResources", arg0)
+	ret0, _ := ret[0].(*types.Resources)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportResources indicates an expected call of ExportResources.
+func (mr *MockServiceMockRecorder) ExportResources(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportResources", reflect.TypeOf((*MockService)(nil).ExportResources), arg0)
+}
+
 // GetApplication mocks base method.
 func (m *MockService) GetApplication(arg0 context.Context, arg1 uint) (*models.Application, error) {
 	m.ctrl.T.Helper()
@@ -782,6 +827,22 @@ func (mr *MockServiceMockRecorder) GetPeer(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeer", reflect.TypeOf((*MockService)(nil).GetPeer), arg0, arg1)
 }
 
+// GetPeerVersions mocks base method.
+func (m *MockService) GetPeerVersions(arg0 context.Context, arg1 uint, arg2 types.GetPeerVersionsQuery) ([]models.PeerVersion, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeerVersions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.PeerVersion)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPeerVersions indicates an expected call of GetPeerVersions.
+func (mr *MockServiceMockRecorder) GetPeerVersions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeerVersions", reflect.TypeOf((*MockService)(nil).GetPeerVersions), arg0, arg1, arg2)
+}
+
 // GetPeers mocks base method.
 func (m *MockService) GetPeers(arg0 context.Context, arg1 types.GetPeersQuery) ([]models.Peer, int64, error) {
 	m.ctrl.T.Helper()
@@ -916,6 +977,22 @@ func (mr *MockServiceMockRecorder) GetSchedulerCluster(arg0, arg1 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerCluster", reflect.TypeOf((*MockService)(nil).GetSchedulerCluster), arg0, arg1)
 }
 
+// GetSchedulerClusterConfigVersions mocks base method.
+func (m *MockService) GetSchedulerClusterConfigVersions(arg0 context.Context, arg1 uint, arg2 types.GetSchedulerClusterConfigVersionsQuery) ([]models.SchedulerClusterConfigVersion, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedulerClusterConfigVersions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.SchedulerClusterConfigVersion)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSchedulerClusterConfigVersions indicates an expected call of GetSchedulerClusterConfigVersions.
+func (mr *MockServiceMockRecorder) GetSchedulerClusterConfigVersions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerClusterConfigVersions", reflect.TypeOf((*MockService)(nil).GetSchedulerClusterConfigVersions), arg0, arg1, arg2)
+}
+
 // GetSchedulerClusters mocks base method.
 func (m *MockService) GetSchedulerClusters(arg0 context.Context, arg1 types.GetSchedulerClustersQuery) ([]models.SchedulerCluster, int64, error) {
 	m.ctrl.T.Helper()
@@ -1114,6 +1191,21 @@ func (mr *MockServiceMockRecorder) ResetPassword(arg0, arg1, arg2 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), arg0, arg1, arg2)
 }
 
+// RollbackSchedulerClusterConfig mocks base method.
+func (m *MockService) RollbackSchedulerClusterConfig(arg0 context.Context, arg1, arg2 uint, arg3 types.RollbackSchedulerClusterConfigRequest) (*models.SchedulerCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackSchedulerClusterConfig", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.SchedulerCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackSchedulerClusterConfig indicates an expected call of RollbackSchedulerClusterConfig.
+func (mr *MockServiceMockRecorder) RollbackSchedulerClusterConfig(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackSchedulerClusterConfig", reflect.TypeOf((*MockService)(nil).RollbackSchedulerClusterConfig), arg0, arg1, arg2, arg3)
+}
+
 // SignIn mocks base method.
 func (m *MockService) SignIn(arg0 context.Context, arg1 types.SignInRequest) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -77,7 +77,16 @@ func (s *service) GetPeer(ctx context.Context, id uint) (*models.Peer, error) {
 func (s *service) GetPeers(ctx context.Context, q types.GetPeersQuery) ([]models.Peer, int64, error) {
 	var count int64
 	var peers []models.Peer
-	if err := s.db.WithContext(ctx).Preload("SchedulerCluster").Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Peer{
+	tx := s.db.WithContext(ctx)
+	if q.MinDiskFree > 0 {
+		tx = tx.Where("disk_free >= ?", q.MinDiskFree)
+	}
+
+	if q.MaxDiskFree > 0 {
+		tx = tx.Where("disk_free <= ?", q.MaxDiskFree)
+	}
+
+	if err := tx.Preload("SchedulerCluster").Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Peer{
 		Type:               q.Type,
 		Hostname:           q.Hostname,
 		IDC:                q.IDC,
@@ -102,3 +111,22 @@ func (s *service) GetPeers(ctx context.Context, q types.GetPeersQuery) ([]models
 
 	return peers, count, nil
 }
+
+func (s *service) GetPeerVersions(ctx context.Context, id uint, q types.GetPeerVersionsQuery) ([]models.PeerVersion, int64, error) {
+	peer := models.Peer{}
+	if err := s.db.WithContext(ctx).First(&peer, id).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	var versions []models.PeerVersion
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.PeerVersion{
+		Hostname:           peer.Hostname,
+		IP:                 peer.IP,
+		SchedulerClusterID: peer.SchedulerClusterID,
+	}).Order("id DESC").Find(&versions).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return versions, count, nil
+}
@@ -0,0 +1,470 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/structure"
+)
+
+func (s *service) ExportResources(ctx context.Context) (*types.Resources, error) {
+	var seedPeerClusters []models.SeedPeerCluster
+	if err := s.db.WithContext(ctx).Order("name").Find(&seedPeerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	var schedulerClusters []models.SchedulerCluster
+	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Order("name").Find(&schedulerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	var applications []models.Application
+	if err := s.db.WithContext(ctx).Order("name").Find(&applications).Error; err != nil {
+		return nil, err
+	}
+
+	var configs []models.Config
+	if err := s.db.WithContext(ctx).Order("name").Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	resources := types.Resources{}
+	for _, seedPeerCluster := range seedPeerClusters {
+		config := &types.SeedPeerClusterConfig{}
+		if err := structure.MapToStruct(seedPeerCluster.Config, config); err != nil {
+			return nil, err
+		}
+
+		resources.SeedPeerClusters = append(resources.SeedPeerClusters, types.SeedPeerClusterResource{
+			Name:   seedPeerCluster.Name,
+			BIO:    seedPeerCluster.BIO,
+			Config: config,
+		})
+	}
+
+	for _, schedulerCluster := range schedulerClusters {
+		config := &types.SchedulerClusterConfig{}
+		if err := structure.MapToStruct(schedulerCluster.Config, config); err != nil {
+			return nil, err
+		}
+
+		clientConfig := &types.SchedulerClusterClientConfig{}
+		if err := structure.MapToStruct(schedulerCluster.ClientConfig, clientConfig); err != nil {
+			return nil, err
+		}
+
+		var scopes *types.SchedulerClusterScopes
+		if len(schedulerCluster.Scopes) > 0 {
+			scopes = &types.SchedulerClusterScopes{}
+			if err := structure.MapToStruct(schedulerCluster.Scopes, scopes); err != nil {
+				return nil, err
+			}
+		}
+
+		var seedPeerCluster string
+		if len(schedulerCluster.SeedPeerClusters) > 0 {
+			seedPeerCluster = schedulerCluster.SeedPeerClusters[0].Name
+		}
+
+		resources.SchedulerClusters = append(resources.SchedulerClusters, types.SchedulerClusterResource{
+			Name:            schedulerCluster.Name,
+			BIO:             schedulerCluster.BIO,
+			Config:          config,
+			ClientConfig:    clientConfig,
+			Scopes:          scopes,
+			IsDefault:       schedulerCluster.IsDefault,
+			SeedPeerCluster: seedPeerCluster,
+		})
+	}
+
+	for _, application := range applications {
+		priority := &types.PriorityConfig{}
+		if err := structure.MapToStruct(application.Priority, priority); err != nil {
+			return nil, err
+		}
+
+		resources.Applications = append(resources.Applications, types.ApplicationResource{
+			Name:     application.Name,
+			URL:      application.URL,
+			BIO:      application.BIO,
+			Priority: priority,
+		})
+	}
+
+	for _, config := range configs {
+		resources.Configs = append(resources.Configs, types.ConfigResource{
+			Name:  config.Name,
+			Value: config.Value,
+			BIO:   config.BIO,
+		})
+	}
+
+	return &resources, nil
+}
+
+// ApplyResources computes the plan to converge the manager resources to the declared resources and
+// executes the plan if it is not a dry run. Resources not declared are deleted only when prune is set.
+// Resources are created and updated by dependency order, seed peer clusters first, and deleted by
+// the reverse order. Applying the same resources again produces an empty plan, so a partially
+// failed apply can be resumed by applying again.
+func (s *service) ApplyResources(ctx context.Context, json types.Resources, q types.ApplyResourcesQuery) (*types.ApplyResourcesResponse, error) {
+	var (
+		plans   []types.ResourcePlan
+		deletes []func() error
+	)
+
+	// Apply seed peer clusters.
+	var seedPeerClusters []models.SeedPeerCluster
+	if err := s.db.WithContext(ctx).Find(&seedPeerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	currentSeedPeerClusters := make(map[string]models.SeedPeerCluster, len(seedPeerClusters))
+	for _, seedPeerCluster := range seedPeerClusters {
+		currentSeedPeerClusters[seedPeerCluster.Name] = seedPeerCluster
+	}
+
+	desiredSeedPeerClusters := make(map[string]struct{}, len(json.SeedPeerClusters))
+	for _, resource := range json.SeedPeerClusters {
+		if _, ok := desiredSeedPeerClusters[resource.Name]; ok {
+			return nil, fmt.Errorf("duplicate %s %s", types.ResourceKindSeedPeerCluster, resource.Name)
+		}
+		desiredSeedPeerClusters[resource.Name] = struct{}{}
+
+		config, err := structure.StructToMap(resource.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		current, ok := currentSeedPeerClusters[resource.Name]
+		if !ok {
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSeedPeerCluster, Name: resource.Name, Action: types.ResourceActionCreate})
+			if !q.DryRun {
+				if _, err := s.CreateSeedPeerCluster(ctx, types.CreateSeedPeerClusterRequest{
+					Name:   resource.Name,
+					BIO:    resource.BIO,
+					Config: resource.Config,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			continue
+		}
+
+		if current.BIO == resource.BIO && len(diffJSONMap(current.Config, config)) == 0 {
+			continue
+		}
+
+		plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSeedPeerCluster, Name: resource.Name, Action: types.ResourceActionUpdate})
+		if !q.DryRun {
+			if _, err := s.UpdateSeedPeerCluster(ctx, current.ID, types.UpdateSeedPeerClusterRequest{
+				BIO:    resource.BIO,
+				Config: resource.Config,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if q.Prune {
+		for _, seedPeerCluster := range seedPeerClusters {
+			if _, ok := desiredSeedPeerClusters[seedPeerCluster.Name]; ok {
+				continue
+			}
+
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSeedPeerCluster, Name: seedPeerCluster.Name, Action: types.ResourceActionDelete})
+			id := seedPeerCluster.ID
+			deletes = append([]func() error{func() error { return s.DestroySeedPeerCluster(ctx, id) }}, deletes...)
+		}
+	}
+
+	// Apply scheduler clusters.
+	var schedulerClusters []models.SchedulerCluster
+	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Find(&schedulerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	currentSchedulerClusters := make(map[string]models.SchedulerCluster, len(schedulerClusters))
+	for _, schedulerCluster := range schedulerClusters {
+		currentSchedulerClusters[schedulerCluster.Name] = schedulerCluster
+	}
+
+	desiredSchedulerClusters := make(map[string]struct{}, len(json.SchedulerClusters))
+	for _, resource := range json.SchedulerClusters {
+		if _, ok := desiredSchedulerClusters[resource.Name]; ok {
+			return nil, fmt.Errorf("duplicate %s %s", types.ResourceKindSchedulerCluster, resource.Name)
+		}
+		desiredSchedulerClusters[resource.Name] = struct{}{}
+
+		var seedPeerClusterID uint
+		if resource.SeedPeerCluster != "" {
+			if _, ok := desiredSeedPeerClusters[resource.SeedPeerCluster]; !ok {
+				if _, ok := currentSeedPeerClusters[resource.SeedPeerCluster]; !ok || q.Prune {
+					return nil, fmt.Errorf("%s %s references unknown %s %s", types.ResourceKindSchedulerCluster, resource.Name,
+						types.ResourceKindSeedPeerCluster, resource.SeedPeerCluster)
+				}
+			}
+
+			// The seed peer cluster may be created by this apply, so find it again.
+			if !q.DryRun {
+				seedPeerCluster := models.SeedPeerCluster{}
+				if err := s.db.WithContext(ctx).First(&seedPeerCluster, &models.SeedPeerCluster{Name: resource.SeedPeerCluster}).Error; err != nil {
+					return nil, err
+				}
+				seedPeerClusterID = seedPeerCluster.ID
+			}
+		}
+
+		config, err := structure.StructToMap(resource.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		clientConfig, err := structure.StructToMap(resource.ClientConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		scopes, err := structure.StructToMap(resource.Scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		current, ok := currentSchedulerClusters[resource.Name]
+		if !ok {
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSchedulerCluster, Name: resource.Name, Action: types.ResourceActionCreate})
+			if !q.DryRun {
+				if _, err := s.CreateSchedulerCluster(ctx, types.CreateSchedulerClusterRequest{
+					Name:              resource.Name,
+					BIO:               resource.BIO,
+					Config:            resource.Config,
+					ClientConfig:      resource.ClientConfig,
+					Scopes:            resource.Scopes,
+					IsDefault:         resource.IsDefault,
+					SeedPeerClusterID: seedPeerClusterID,
+					UserID:            q.UserID,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			continue
+		}
+
+		var currentSeedPeerCluster string
+		if len(current.SeedPeerClusters) > 0 {
+			currentSeedPeerCluster = current.SeedPeerClusters[0].Name
+		}
+
+		if current.BIO == resource.BIO &&
+			current.IsDefault == resource.IsDefault &&
+			currentSeedPeerCluster == resource.SeedPeerCluster &&
+			len(diffJSONMap(current.Config, config)) == 0 &&
+			len(diffJSONMap(current.ClientConfig, clientConfig)) == 0 &&
+			len(diffJSONMap(current.Scopes, scopes)) == 0 {
+			continue
+		}
+
+		plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSchedulerCluster, Name: resource.Name, Action: types.ResourceActionUpdate})
+		if !q.DryRun {
+			if _, err := s.UpdateSchedulerCluster(ctx, current.ID, types.UpdateSchedulerClusterRequest{
+				BIO:               resource.BIO,
+				Config:            resource.Config,
+				ClientConfig:      resource.ClientConfig,
+				Scopes:            resource.Scopes,
+				IsDefault:         resource.IsDefault,
+				SeedPeerClusterID: seedPeerClusterID,
+				UserID:            q.UserID,
+			}); err != nil {
+				return nil, err
+			}
+
+			// UpdateSchedulerCluster only links the seed peer cluster, so unlink it here
+			// if it is removed from the resource. Otherwise the apply never converges.
+			if resource.SeedPeerCluster == "" && currentSeedPeerCluster != "" {
+				if err := s.db.WithContext(ctx).Model(&current).Association("SeedPeerClusters").Clear(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if q.Prune {
+		for _, schedulerCluster := range schedulerClusters {
+			if _, ok := desiredSchedulerClusters[schedulerCluster.Name]; ok {
+				continue
+			}
+
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindSchedulerCluster, Name: schedulerCluster.Name, Action: types.ResourceActionDelete})
+			id := schedulerCluster.ID
+			deletes = append([]func() error{func() error { return s.DestroySchedulerCluster(ctx, id) }}, deletes...)
+		}
+	}
+
+	// Apply applications.
+	var applications []models.Application
+	if err := s.db.WithContext(ctx).Find(&applications).Error; err != nil {
+		return nil, err
+	}
+
+	currentApplications := make(map[string]models.Application, len(applications))
+	for _, application := range applications {
+		currentApplications[application.Name] = application
+	}
+
+	desiredApplications := make(map[string]struct{}, len(json.Applications))
+	for _, resource := range json.Applications {
+		if _, ok := desiredApplications[resource.Name]; ok {
+			return nil, fmt.Errorf("duplicate %s %s", types.ResourceKindApplication, resource.Name)
+		}
+		desiredApplications[resource.Name] = struct{}{}
+
+		priority, err := structure.StructToMap(resource.Priority)
+		if err != nil {
+			return nil, err
+		}
+
+		current, ok := currentApplications[resource.Name]
+		if !ok {
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindApplication, Name: resource.Name, Action: types.ResourceActionCreate})
+			if !q.DryRun {
+				if _, err := s.CreateApplication(ctx, types.CreateApplicationRequest{
+					Name:     resource.Name,
+					URL:      resource.URL,
+					BIO:      resource.BIO,
+					Priority: resource.Priority,
+					UserID:   q.UserID,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			continue
+		}
+
+		if current.URL == resource.URL && current.BIO == resource.BIO && len(diffJSONMap(current.Priority, priority)) == 0 {
+			continue
+		}
+
+		plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindApplication, Name: resource.Name, Action: types.ResourceActionUpdate})
+		if !q.DryRun {
+			if _, err := s.UpdateApplication(ctx, current.ID, types.UpdateApplicationRequest{
+				URL:      resource.URL,
+				BIO:      resource.BIO,
+				Priority: resource.Priority,
+				UserID:   q.UserID,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if q.Prune {
+		for _, application := range applications {
+			if _, ok := desiredApplications[application.Name]; ok {
+				continue
+			}
+
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindApplication, Name: application.Name, Action: types.ResourceActionDelete})
+			id := application.ID
+			deletes = append([]func() error{func() error { return s.DestroyApplication(ctx, id) }}, deletes...)
+		}
+	}
+
+	// Apply configs.
+	var configs []models.Config
+	if err := s.db.WithContext(ctx).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	currentConfigs := make(map[string]models.Config, len(configs))
+	for _, config := range configs {
+		currentConfigs[config.Name] = config
+	}
+
+	desiredConfigs := make(map[string]struct{}, len(json.Configs))
+	for _, resource := range json.Configs {
+		if _, ok := desiredConfigs[resource.Name]; ok {
+			return nil, fmt.Errorf("duplicate %s %s", types.ResourceKindConfig, resource.Name)
+		}
+		desiredConfigs[resource.Name] = struct{}{}
+
+		current, ok := currentConfigs[resource.Name]
+		if !ok {
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindConfig, Name: resource.Name, Action: types.ResourceActionCreate})
+			if !q.DryRun {
+				if _, err := s.CreateConfig(ctx, types.CreateConfigRequest{
+					Name:   resource.Name,
+					Value:  resource.Value,
+					BIO:    resource.BIO,
+					UserID: q.UserID,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			continue
+		}
+
+		if current.Value == resource.Value && current.BIO == resource.BIO {
+			continue
+		}
+
+		plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindConfig, Name: resource.Name, Action: types.ResourceActionUpdate})
+		if !q.DryRun {
+			if _, err := s.UpdateConfig(ctx, current.ID, types.UpdateConfigRequest{
+				Value:  resource.Value,
+				BIO:    resource.BIO,
+				UserID: q.UserID,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if q.Prune {
+		for _, config := range configs {
+			if _, ok := desiredConfigs[config.Name]; ok {
+				continue
+			}
+
+			plans = append(plans, types.ResourcePlan{Kind: types.ResourceKindConfig, Name: config.Name, Action: types.ResourceActionDelete})
+			id := config.ID
+			deletes = append([]func() error{func() error { return s.DestroyConfig(ctx, id) }}, deletes...)
+		}
+	}
+
+	// Delete the resources after all resources are created and updated, the dependents
+	// are deleted before their dependencies.
+	if !q.DryRun {
+		for _, destroy := range deletes {
+			if err := destroy(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &types.ApplyResourcesResponse{
+		DryRun: q.DryRun,
+		Plans:  plans,
+	}, nil
+}
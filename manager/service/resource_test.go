@@ -0,0 +1,130 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func newTestResourceService(t *testing.T) *service {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(
+		&models.SeedPeerCluster{},
+		&models.SchedulerCluster{},
+		&models.SchedulerClusterConfigVersion{},
+		&models.Application{},
+		&models.Config{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return &service{db: db}
+}
+
+func TestService_ApplyResources(t *testing.T) {
+	seedPeerCluster := types.SeedPeerClusterResource{
+		Name:   "foo",
+		Config: &types.SeedPeerClusterConfig{LoadLimit: 300},
+	}
+
+	schedulerCluster := types.SchedulerClusterResource{
+		Name:            "bar",
+		Config:          &types.SchedulerClusterConfig{CandidateParentLimit: 4, FilterParentLimit: 40},
+		ClientConfig:    &types.SchedulerClusterClientConfig{LoadLimit: 50},
+		SeedPeerCluster: "foo",
+	}
+
+	unlinkedSchedulerCluster := schedulerCluster
+	unlinkedSchedulerCluster.SeedPeerCluster = ""
+
+	tests := []struct {
+		name      string
+		resources []types.Resources
+		expect    func(t *testing.T, s *service, plans [][]types.ResourcePlan)
+	}{
+		{
+			name: "apply the same resources twice",
+			resources: []types.Resources{
+				{SeedPeerClusters: []types.SeedPeerClusterResource{seedPeerCluster}, SchedulerClusters: []types.SchedulerClusterResource{schedulerCluster}},
+				{SeedPeerClusters: []types.SeedPeerClusterResource{seedPeerCluster}, SchedulerClusters: []types.SchedulerClusterResource{schedulerCluster}},
+			},
+			expect: func(t *testing.T, s *service, plans [][]types.ResourcePlan) {
+				assert := assert.New(t)
+				assert.Equal([]types.ResourcePlan{
+					{Kind: types.ResourceKindSeedPeerCluster, Name: "foo", Action: types.ResourceActionCreate},
+					{Kind: types.ResourceKindSchedulerCluster, Name: "bar", Action: types.ResourceActionCreate},
+				}, plans[0])
+				assert.Empty(plans[1])
+			},
+		},
+		{
+			name: "apply the resources without seed peer cluster twice",
+			resources: []types.Resources{
+				{SeedPeerClusters: []types.SeedPeerClusterResource{seedPeerCluster}, SchedulerClusters: []types.SchedulerClusterResource{schedulerCluster}},
+				{SeedPeerClusters: []types.SeedPeerClusterResource{seedPeerCluster}, SchedulerClusters: []types.SchedulerClusterResource{unlinkedSchedulerCluster}},
+				{SeedPeerClusters: []types.SeedPeerClusterResource{seedPeerCluster}, SchedulerClusters: []types.SchedulerClusterResource{unlinkedSchedulerCluster}},
+			},
+			expect: func(t *testing.T, s *service, plans [][]types.ResourcePlan) {
+				assert := assert.New(t)
+				assert.Equal([]types.ResourcePlan{
+					{Kind: types.ResourceKindSchedulerCluster, Name: "bar", Action: types.ResourceActionUpdate},
+				}, plans[1])
+				assert.Empty(plans[2])
+
+				schedulerCluster := models.SchedulerCluster{}
+				assert.NoError(s.db.Preload("SeedPeerClusters").First(&schedulerCluster, &models.SchedulerCluster{Name: "bar"}).Error)
+				assert.Empty(schedulerCluster.SeedPeerClusters)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestResourceService(t)
+
+			var plans [][]types.ResourcePlan
+			for _, resources := range tc.resources {
+				resp, err := s.ApplyResources(context.Background(), resources, types.ApplyResourcesQuery{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				plans = append(plans, resp.Plans)
+			}
+
+			tc.expect(t, s, plans)
+		})
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 
+	"gorm.io/gorm"
+
 	"d7y.io/dragonfly/v2/manager/models"
 	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/structure"
@@ -50,7 +52,13 @@ func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateS
 		IsDefault:    json.IsDefault,
 	}
 
-	if err := s.db.WithContext(ctx).Create(&schedulerCluster).Error; err != nil {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&schedulerCluster).Error; err != nil {
+			return err
+		}
+
+		return createSchedulerClusterConfigVersion(ctx, tx, nil, &schedulerCluster, json.UserID, json.BIO)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -77,6 +85,12 @@ func (s *service) DestroySchedulerCluster(ctx context.Context, id uint) error {
 		return err
 	}
 
+	if err := s.db.WithContext(ctx).Unscoped().Where(&models.SchedulerClusterConfigVersion{
+		SchedulerClusterID: id,
+	}).Delete(&models.SchedulerClusterConfigVersion{}).Error; err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -109,13 +123,24 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 	}
 
 	schedulerCluster := models.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, id).Updates(models.SchedulerCluster{
-		Name:         json.Name,
-		BIO:          json.BIO,
-		Config:       config,
-		ClientConfig: clientConfig,
-		Scopes:       scopes,
-	}).Error; err != nil {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSchedulerCluster(tx).First(&schedulerCluster, id).Error; err != nil {
+			return err
+		}
+		previous := schedulerCluster
+
+		if err := tx.Model(&schedulerCluster).Updates(models.SchedulerCluster{
+			Name:         json.Name,
+			BIO:          json.BIO,
+			Config:       config,
+			ClientConfig: clientConfig,
+			Scopes:       scopes,
+		}).Error; err != nil {
+			return err
+		}
+
+		return createSchedulerClusterConfigVersion(ctx, tx, &previous, &schedulerCluster, json.UserID, json.BIO)
+	}); err != nil {
 		return nil, err
 	}
 
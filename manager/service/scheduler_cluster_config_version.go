@@ -0,0 +1,144 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) GetSchedulerClusterConfigVersions(ctx context.Context, id uint, q types.GetSchedulerClusterConfigVersionsQuery) ([]models.SchedulerClusterConfigVersion, int64, error) {
+	var count int64
+	var versions []models.SchedulerClusterConfigVersion
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.SchedulerClusterConfigVersion{
+		SchedulerClusterID: id,
+	}).Order("version DESC").Preload("User").Find(&versions).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return versions, count, nil
+}
+
+func (s *service) RollbackSchedulerClusterConfig(ctx context.Context, id, version uint, json types.RollbackSchedulerClusterConfigRequest) (*models.SchedulerCluster, error) {
+	configVersion := models.SchedulerClusterConfigVersion{}
+	if err := s.db.WithContext(ctx).First(&configVersion, &models.SchedulerClusterConfigVersion{
+		SchedulerClusterID: id,
+		Version:            version,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	bio := json.BIO
+	if bio == "" {
+		bio = fmt.Sprintf("rollback to version %d", version)
+	}
+
+	schedulerCluster := models.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSchedulerCluster(tx).First(&schedulerCluster, id).Error; err != nil {
+			return err
+		}
+		previous := schedulerCluster
+
+		if err := tx.Model(&schedulerCluster).Updates(models.SchedulerCluster{
+			Config:       configVersion.Config,
+			ClientConfig: configVersion.ClientConfig,
+		}).Error; err != nil {
+			return err
+		}
+
+		return createSchedulerClusterConfigVersion(ctx, tx, &previous, &schedulerCluster, json.UserID, bio)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &schedulerCluster, nil
+}
+
+// lockSchedulerCluster locks the row of the scheduler cluster until the transaction ends, so the
+// concurrent writers read the previous config and allocate the next version one by one. SQLite has
+// no row-level locking and serializes the writers by the database lock.
+func lockSchedulerCluster(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// createSchedulerClusterConfigVersion records the config and client config of the scheduler cluster
+// as a new version, previous is nil when the scheduler cluster is created. The version is skipped
+// if neither config nor client config is changed. It must be called in the transaction which creates
+// the scheduler cluster or locks it by lockSchedulerCluster, the unique index of the version rejects
+// the duplicated version if not.
+func createSchedulerClusterConfigVersion(ctx context.Context, tx *gorm.DB, previous, current *models.SchedulerCluster, userID uint, bio string) error {
+	diff := models.JSONMap{}
+	if previous == nil {
+		previous = &models.SchedulerCluster{}
+	}
+
+	if configDiff := diffJSONMap(previous.Config, current.Config); len(configDiff) > 0 {
+		diff["config"] = configDiff
+	}
+
+	if clientConfigDiff := diffJSONMap(previous.ClientConfig, current.ClientConfig); len(clientConfigDiff) > 0 {
+		diff["client_config"] = clientConfigDiff
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+
+	var latest uint
+	if err := tx.WithContext(ctx).Model(&models.SchedulerClusterConfigVersion{}).Where(&models.SchedulerClusterConfigVersion{
+		SchedulerClusterID: current.ID,
+	}).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	return tx.WithContext(ctx).Create(&models.SchedulerClusterConfigVersion{
+		Version:            latest + 1,
+		BIO:                bio,
+		Config:             current.Config,
+		ClientConfig:       current.ClientConfig,
+		Diff:               diff,
+		UserID:             userID,
+		SchedulerClusterID: current.ID,
+	}).Error
+}
+
+// diffJSONMap returns the changed top-level keys between the previous and current map,
+// and each changed key contains the previous and current value.
+func diffJSONMap(previous, current map[string]any) map[string]any {
+	diff := map[string]any{}
+	for key, value := range current {
+		if previousValue, ok := previous[key]; !ok || !reflect.DeepEqual(previousValue, value) {
+			diff[key] = map[string]any{"previous": previous[key], "current": value}
+		}
+	}
+
+	for key, value := range previous {
+		if _, ok := current[key]; !ok {
+			diff[key] = map[string]any{"previous": value, "current": nil}
+		}
+	}
+
+	return diff
+}
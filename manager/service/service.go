@@ -88,6 +88,7 @@ type Service interface {
 	DestroyPeer(context.Context, uint) error
 	GetPeer(context.Context, uint) (*models.Peer, error)
 	GetPeers(context.Context, types.GetPeersQuery) ([]models.Peer, int64, error)
+	GetPeerVersions(context.Context, uint, types.GetPeerVersionsQuery) ([]models.PeerVersion, int64, error)
 
 	CreateSchedulerCluster(context.Context, types.CreateSchedulerClusterRequest) (*models.SchedulerCluster, error)
 	DestroySchedulerCluster(context.Context, uint) error
@@ -95,6 +96,8 @@ type Service interface {
 	GetSchedulerCluster(context.Context, uint) (*models.SchedulerCluster, error)
 	GetSchedulerClusters(context.Context, types.GetSchedulerClustersQuery) ([]models.SchedulerCluster, int64, error)
 	AddSchedulerToSchedulerCluster(context.Context, uint, uint) error
+	GetSchedulerClusterConfigVersions(context.Context, uint, types.GetSchedulerClusterConfigVersionsQuery) ([]models.SchedulerClusterConfigVersion, int64, error)
+	RollbackSchedulerClusterConfig(context.Context, uint, uint, types.RollbackSchedulerClusterConfigRequest) (*models.SchedulerCluster, error)
 
 	CreateScheduler(context.Context, types.CreateSchedulerRequest) (*models.Scheduler, error)
 	DestroyScheduler(context.Context, uint) error
@@ -118,6 +121,7 @@ type Service interface {
 	CreateSyncPeersJob(ctx context.Context, json types.CreateSyncPeersJobRequest) error
 	CreateDeleteTaskJob(context.Context, types.CreateDeleteTaskJobRequest) (*models.Job, error)
 	CreateGetTaskJob(context.Context, types.CreateGetTaskJobRequest) (*models.Job, error)
+	CreateListPersistentCacheTasksJob(context.Context, types.CreateListPersistentCacheTasksJobRequest) (*models.Job, error)
 	DestroyJob(context.Context, uint) error
 	UpdateJob(context.Context, uint, types.UpdateJobRequest) (*models.Job, error)
 	GetJob(context.Context, uint) (*models.Job, error)
@@ -137,6 +141,9 @@ type Service interface {
 	UpdatePersonalAccessToken(context.Context, uint, types.UpdatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error)
 	GetPersonalAccessToken(context.Context, uint) (*models.PersonalAccessToken, error)
 	GetPersonalAccessTokens(context.Context, types.GetPersonalAccessTokensQuery) ([]models.PersonalAccessToken, int64, error)
+
+	ExportResources(context.Context) (*types.Resources, error)
+	ApplyResources(context.Context, types.Resources, types.ApplyResourcesQuery) (*types.ApplyResourcesResponse, error)
 }
 
 type service struct {
@@ -223,3 +223,46 @@ type DeleteTaskArgs struct {
 	// Timeout is the timeout for deleting, default is 30 minutes.
 	Timeout time.Duration `json:"timeout" binding:"omitempty"`
 }
+
+type CreateListPersistentCacheTasksJobRequest struct {
+	// BIO is the description of the job.
+	BIO string `json:"bio" binding:"omitempty"`
+
+	// Type is the type of the job.
+	Type string `json:"type" binding:"required"`
+
+	// Args is the arguments of the job.
+	Args ListPersistentCacheTasksArgs `json:"args" binding:"omitempty"`
+
+	// UserID is the user id of the job.
+	UserID uint `json:"user_id" binding:"omitempty"`
+
+	// SchedulerClusterIDs is the scheduler cluster ids of the job.
+	SchedulerClusterIDs []uint `json:"scheduler_cluster_ids" binding:"omitempty"`
+}
+
+type ListPersistentCacheTasksArgs struct {
+	// Application is the application of the persistent cache tasks.
+	Application string `json:"application" binding:"omitempty"`
+
+	// Tag is the tag of the persistent cache tasks.
+	Tag string `json:"tag" binding:"omitempty"`
+
+	// State is the state of the persistent cache tasks.
+	State string `json:"state" binding:"omitempty,oneof=Pending Uploading Succeeded Failed"`
+
+	// MinAge is the minimum age of the persistent cache tasks since their creation.
+	MinAge time.Duration `json:"min_age" binding:"omitempty,gte=0"`
+
+	// MaxAge is the maximum age of the persistent cache tasks since their creation.
+	MaxAge time.Duration `json:"max_age" binding:"omitempty,gte=0"`
+
+	// Page is the page number of the persistent cache tasks in each scheduler cluster, default is 1.
+	Page int `json:"page" binding:"omitempty,gte=1"`
+
+	// PerPage is the task count per page of the persistent cache tasks in each scheduler cluster, default is 1000.
+	PerPage int `json:"per_page" binding:"omitempty,gte=1,lte=10000"`
+
+	// Timeout is the timeout for listing, default is 30 minutes.
+	Timeout time.Duration `json:"timeout" binding:"omitempty"`
+}
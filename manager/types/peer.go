@@ -43,7 +43,7 @@ type CreatePeerRequest struct {
 
 type GetPeersQuery struct {
 	Hostname           string `form:"host_name" binding:"omitempty"`
-	Type               string `form:"type" binding:"omitempty,oneof=super strong weak"`
+	Type               string `form:"type" binding:"omitempty,oneof=super strong weak normal"`
 	IDC                string `form:"idc" binding:"omitempty"`
 	Location           string `form:"location" binding:"omitempty"`
 	IP                 string `form:"ip" binding:"omitempty"`
@@ -60,6 +60,13 @@ type GetPeersQuery struct {
 	GitCommit          string `form:"git_commit" binding:"omitempty"`
 	BuildPlatform      string `form:"build_platform" binding:"omitempty"`
 	SchedulerClusterID uint   `form:"scheduler_cluster_id" binding:"omitempty"`
+	MinDiskFree        uint64 `form:"min_disk_free" binding:"omitempty"`
+	MaxDiskFree        uint64 `form:"max_disk_free" binding:"omitempty"`
 	Page               int    `form:"page" binding:"omitempty,gte=1"`
 	PerPage            int    `form:"per_page" binding:"omitempty,gte=1,lte=10000000"`
 }
+
+type GetPeerVersionsQuery struct {
+	Page    int `form:"page" binding:"omitempty,gte=1"`
+	PerPage int `form:"per_page" binding:"omitempty,gte=1,lte=10000000"`
+}
@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+const (
+	// ResourceKindSeedPeerCluster is the kind of seed peer cluster resource.
+	ResourceKindSeedPeerCluster = "SeedPeerCluster"
+
+	// ResourceKindSchedulerCluster is the kind of scheduler cluster resource.
+	ResourceKindSchedulerCluster = "SchedulerCluster"
+
+	// ResourceKindApplication is the kind of application resource.
+	ResourceKindApplication = "Application"
+
+	// ResourceKindConfig is the kind of config resource.
+	ResourceKindConfig = "Config"
+)
+
+const (
+	// ResourceActionCreate represents the resource will be created.
+	ResourceActionCreate = "create"
+
+	// ResourceActionUpdate represents the resource will be updated.
+	ResourceActionUpdate = "update"
+
+	// ResourceActionDelete represents the resource will be deleted.
+	ResourceActionDelete = "delete"
+)
+
+// Resources is the declarative document of manager resources, resources are identified by name.
+type Resources struct {
+	SeedPeerClusters  []SeedPeerClusterResource  `yaml:"seedPeerClusters" json:"seed_peer_clusters" binding:"omitempty,dive"`
+	SchedulerClusters []SchedulerClusterResource `yaml:"schedulerClusters" json:"scheduler_clusters" binding:"omitempty,dive"`
+	Applications      []ApplicationResource      `yaml:"applications" json:"applications" binding:"omitempty,dive"`
+	Configs           []ConfigResource           `yaml:"configs" json:"configs" binding:"omitempty,dive"`
+}
+
+type SeedPeerClusterResource struct {
+	Name   string                 `yaml:"name" json:"name" binding:"required"`
+	BIO    string                 `yaml:"bio,omitempty" json:"bio" binding:"omitempty"`
+	Config *SeedPeerClusterConfig `yaml:"config" json:"config" binding:"required"`
+}
+
+type SchedulerClusterResource struct {
+	Name         string                        `yaml:"name" json:"name" binding:"required"`
+	BIO          string                        `yaml:"bio,omitempty" json:"bio" binding:"omitempty"`
+	Config       *SchedulerClusterConfig       `yaml:"config" json:"config" binding:"required"`
+	ClientConfig *SchedulerClusterClientConfig `yaml:"clientConfig" json:"client_config" binding:"required"`
+	Scopes       *SchedulerClusterScopes       `yaml:"scopes,omitempty" json:"scopes" binding:"omitempty"`
+	IsDefault    bool                          `yaml:"isDefault" json:"is_default" binding:"omitempty"`
+
+	// SeedPeerCluster is the name of the seed peer cluster linked to the scheduler cluster.
+	SeedPeerCluster string `yaml:"seedPeerCluster,omitempty" json:"seed_peer_cluster" binding:"omitempty"`
+}
+
+type ApplicationResource struct {
+	Name     string          `yaml:"name" json:"name" binding:"required"`
+	URL      string          `yaml:"url" json:"url" binding:"required"`
+	BIO      string          `yaml:"bio,omitempty" json:"bio" binding:"omitempty"`
+	Priority *PriorityConfig `yaml:"priority" json:"priority" binding:"required"`
+}
+
+type ConfigResource struct {
+	Name  string `yaml:"name" json:"name" binding:"required"`
+	Value string `yaml:"value" json:"value" binding:"required"`
+	BIO   string `yaml:"bio,omitempty" json:"bio" binding:"omitempty"`
+}
+
+type ApplyResourcesQuery struct {
+	DryRun bool `form:"dry_run" binding:"omitempty"`
+	Prune  bool `form:"prune" binding:"omitempty"`
+	UserID uint `form:"user_id" binding:"omitempty"`
+}
+
+// ResourcePlan is the action computed by applying the resources.
+type ResourcePlan struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+type ApplyResourcesResponse struct {
+	DryRun bool           `json:"dry_run"`
+	Plans  []ResourcePlan `json:"plans"`
+}
@@ -25,6 +25,11 @@ type AddSchedulerToSchedulerClusterParams struct {
 	SchedulerID uint `uri:"scheduler_id" binding:"required"`
 }
 
+type SchedulerClusterConfigVersionParams struct {
+	ID      uint `uri:"id" binding:"required"`
+	Version uint `uri:"version" binding:"required"`
+}
+
 type CreateSchedulerClusterRequest struct {
 	Name              string                        `json:"name" binding:"required"`
 	BIO               string                        `json:"bio" binding:"omitempty"`
@@ -33,6 +38,7 @@ type CreateSchedulerClusterRequest struct {
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
+	UserID            uint                          `json:"user_id" binding:"omitempty"`
 }
 
 type UpdateSchedulerClusterRequest struct {
@@ -43,6 +49,7 @@ type UpdateSchedulerClusterRequest struct {
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
+	UserID            uint                          `json:"user_id" binding:"omitempty"`
 }
 
 type GetSchedulerClustersQuery struct {
@@ -51,14 +58,49 @@ type GetSchedulerClustersQuery struct {
 	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=10000000"`
 }
 
+type GetSchedulerClusterConfigVersionsQuery struct {
+	Page    int `form:"page" binding:"omitempty,gte=1"`
+	PerPage int `form:"per_page" binding:"omitempty,gte=1,lte=10000000"`
+}
+
+type RollbackSchedulerClusterConfigRequest struct {
+	BIO    string `json:"bio" binding:"omitempty"`
+	UserID uint   `json:"user_id" binding:"omitempty"`
+}
+
 type SchedulerClusterConfig struct {
 	CandidateParentLimit uint32 `yaml:"candidateParentLimit" mapstructure:"candidateParentLimit" json:"candidate_parent_limit" binding:"omitempty,gte=1,lte=20"`
 	FilterParentLimit    uint32 `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=10,lte=1000"`
 	JobRateLimit         uint32 `yaml:"jobRateLimit" mapstructure:"jobRateLimit" json:"job_rate_limit" binding:"omitempty,gte=1,lte=1000000"`
+
+	// Federated marks the scheduler cluster as federated, the schedulers of the federated
+	// clusters share the parents of the tasks with each other.
+	Federated bool `yaml:"federated" mapstructure:"federated" json:"federated" binding:"omitempty"`
+
+	// FederatedSchedulers are the active schedulers of the other federated clusters,
+	// they are filled by manager when the scheduler gets its config and are not stored.
+	FederatedSchedulers []SchedulerClusterFederatedScheduler `yaml:"federatedSchedulers" mapstructure:"federatedSchedulers" json:"federated_schedulers,omitempty" binding:"omitempty"`
+}
+
+type SchedulerClusterFederatedScheduler struct {
+	Hostname           string `yaml:"hostname" mapstructure:"hostname" json:"hostname"`
+	IP                 string `yaml:"ip" mapstructure:"ip" json:"ip"`
+	SchedulerClusterID uint   `yaml:"schedulerClusterID" mapstructure:"schedulerClusterID" json:"scheduler_cluster_id"`
 }
 
 type SchedulerClusterClientConfig struct {
-	LoadLimit uint32 `yaml:"loadLimit" mapstructure:"loadLimit" json:"load_limit" binding:"omitempty,gte=1,lte=2000"`
+	LoadLimit uint32                               `yaml:"loadLimit" mapstructure:"loadLimit" json:"load_limit" binding:"omitempty,gte=1,lte=2000"`
+	Rollout   *SchedulerClusterClientConfigRollout `yaml:"rollout" mapstructure:"rollout" json:"rollout,omitempty" binding:"omitempty"`
+}
+
+// SchedulerClusterClientConfigRollout is the staged rollout of the client config. The client config
+// in rollout is applied to the canary hosts matched by hostnames or cidrs, and to the percentage
+// of hosts selected by the hash of host id, the rest of hosts keep the stable client config.
+type SchedulerClusterClientConfigRollout struct {
+	LoadLimit  uint32   `yaml:"loadLimit" mapstructure:"loadLimit" json:"load_limit" binding:"omitempty,gte=1,lte=2000"`
+	Percentage uint32   `yaml:"percentage" mapstructure:"percentage" json:"percentage" binding:"omitempty,gte=0,lte=100"`
+	Hostnames  []string `yaml:"hostnames" mapstructure:"hostnames" json:"hostnames" binding:"omitempty"`
+	CIDRs      []string `yaml:"cidrs" mapstructure:"cidrs" json:"cidrs" binding:"omitempty"`
 }
 
 type SchedulerClusterScopes struct {
@@ -66,4 +108,7 @@ type SchedulerClusterScopes struct {
 	Location  string   `yaml:"location" mapstructure:"location" json:"location" binding:"omitempty"`
 	CIDRs     []string `yaml:"cidrs" mapstructure:"cidrs" json:"cidrs" binding:"omitempty"`
 	Hostnames []string `yaml:"hostnames" mapstructure:"hostnames" json:"hostnames" binding:"omitempty"`
+	Failover  []string `yaml:"failover" mapstructure:"failover" json:"failover,omitempty" binding:"omitempty"`
+	Weight    uint32   `yaml:"weight" mapstructure:"weight" json:"weight,omitempty" binding:"omitempty,gte=1,lte=100"`
+	LoadLimit uint32   `yaml:"loadLimit" mapstructure:"loadLimit" json:"load_limit,omitempty" binding:"omitempty,gte=1"`
 }
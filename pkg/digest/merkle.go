@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package digest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	// merkleLeafPrefix and merkleNodePrefix separate leaf hashes from inner node hashes,
+	// then an inner node can not be presented as a leaf.
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// MerkleRoot computes the SHA256 merkle root of hex encoded leaf digests,
+// the last node of an odd level is promoted to the next level.
+// It returns empty string when there is no leaf or any leaf is not hex encoded.
+func MerkleRoot(leaves ...string) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+
+	nodes := make([][]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		b, err := hex.DecodeString(leaf)
+		if err != nil {
+			return ""
+		}
+
+		h := sha256.New()
+		h.Write([]byte{merkleLeafPrefix})
+		h.Write(b)
+		nodes = append(nodes, h.Sum(nil))
+	}
+
+	for len(nodes) > 1 {
+		next := make([][]byte, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				next = append(next, nodes[i])
+				continue
+			}
+
+			h := sha256.New()
+			h.Write([]byte{merkleNodePrefix})
+			h.Write(nodes[i])
+			h.Write(nodes[i+1])
+			next = append(next, h.Sum(nil))
+		}
+
+		nodes = next
+	}
+
+	return hex.EncodeToString(nodes[0])
+}
@@ -0,0 +1,92 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	a := SHA256FromBytes([]byte("a"))
+	b := SHA256FromBytes([]byte("b"))
+	c := SHA256FromBytes([]byte("c"))
+
+	tests := []struct {
+		name   string
+		leaves []string
+		expect func(t *testing.T, root string)
+	}{
+		{
+			name:   "no leaf",
+			leaves: nil,
+			expect: func(t *testing.T, root string) {
+				assert.Empty(t, root)
+			},
+		},
+		{
+			name:   "invalid leaf",
+			leaves: []string{a, "foo"},
+			expect: func(t *testing.T, root string) {
+				assert.Empty(t, root)
+			},
+		},
+		{
+			name:   "single leaf differs from leaf digest",
+			leaves: []string{a},
+			expect: func(t *testing.T, root string) {
+				assert.Len(t, root, 64)
+				assert.NotEqual(t, a, root)
+			},
+		},
+		{
+			name:   "same leaves have same root",
+			leaves: []string{a, b, c},
+			expect: func(t *testing.T, root string) {
+				assert.Equal(t, MerkleRoot(a, b, c), root)
+			},
+		},
+		{
+			name:   "order of leaves matters",
+			leaves: []string{a, b, c},
+			expect: func(t *testing.T, root string) {
+				assert.NotEqual(t, MerkleRoot(b, a, c), root)
+			},
+		},
+		{
+			name:   "changed leaf changes root",
+			leaves: []string{a, b, c},
+			expect: func(t *testing.T, root string) {
+				assert.NotEqual(t, MerkleRoot(a, b, a), root)
+			},
+		},
+		{
+			name:   "promoted node is not duplicated",
+			leaves: []string{a, b, c},
+			expect: func(t *testing.T, root string) {
+				assert.NotEqual(t, MerkleRoot(a, b, c, c), root)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, MerkleRoot(tc.leaves...))
+		})
+	}
+}
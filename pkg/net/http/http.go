@@ -27,6 +27,9 @@ import (
 const (
 	// DefaultDialTimeout is the default timeout for dialing a http connection.
 	DefaultDialTimeout = 30 * time.Second
+
+	// EncodingZstd is the content encoding of zstd compressed data.
+	EncodingZstd = "zstd"
 )
 
 // HeaderToMap coverts request headers to map[string]string.
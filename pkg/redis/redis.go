@@ -167,6 +167,11 @@ func MakePersistentPeersOfPersistentCacheTaskInScheduler(schedulerClusterID uint
 	return MakeKeyInScheduler(SchedulerClustersNamespace, fmt.Sprintf("%d:%s:%s:%s", schedulerClusterID, PersistentCacheTasksNamespace, taskID, PersistentPeersNamespace))
 }
 
+// MakePersistentCacheTaskReplicationLockKeyInScheduler make replication lock key of persistent cache task in scheduler.
+func MakePersistentCacheTaskReplicationLockKeyInScheduler(schedulerClusterID uint, taskID string) string {
+	return MakeKeyInScheduler(SchedulerClustersNamespace, fmt.Sprintf("%d:%s:%s:replication-lock", schedulerClusterID, PersistentCacheTasksNamespace, taskID))
+}
+
 // MakePersistentCachePeerKeyInScheduler make persistent cache peer key in scheduler.
 func MakePersistentCachePeerKeyInScheduler(schedulerClusterID uint, peerID string) string {
 	return MakeKeyInScheduler(SchedulerClustersNamespace, fmt.Sprintf("%d:%s:%s", schedulerClusterID, PersistentCachePeersNamespace, peerID))
@@ -57,3 +57,16 @@ const (
 	// AffinitySeparator is separator of affinity.
 	AffinitySeparator = "|"
 )
+
+const (
+	// HostLabelsMetadataKey is the key of grpc metadata that the host announces its labels with,
+	// each value is a label formatted as key=value.
+	HostLabelsMetadataKey = "dragonfly-host-labels"
+
+	// HostQUICPortMetadataKey is the key of grpc metadata that the host announces the udp port
+	// of its HTTP/3 upload service with, peers download pieces from the port via QUIC.
+	HostQUICPortMetadataKey = "dragonfly-host-quic-port"
+
+	// HostLabelSeparator is separator of the key and value of host label.
+	HostLabelSeparator = "="
+)
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -136,6 +137,33 @@ func ParseHostType(name string) HostType {
 	return HostTypeNormal
 }
 
+// FormatHostLabels formats the host labels to the values of grpc metadata, the values are sorted by key.
+func FormatHostLabels(labels map[string]string) []string {
+	values := make([]string, 0, len(labels))
+	for key, value := range labels {
+		values = append(values, key+HostLabelSeparator+value)
+	}
+
+	sort.Strings(values)
+	return values
+}
+
+// ParseHostLabels parses the host labels from the values of grpc metadata,
+// the values without separator or key are ignored.
+func ParseHostLabels(values []string) map[string]string {
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, value, ok := strings.Cut(value, HostLabelSeparator)
+		if !ok || key == "" {
+			continue
+		}
+
+		labels[key] = value
+	}
+
+	return labels
+}
+
 // TaskTypeV1ToV2 converts task type from v1 to v2.
 func TaskTypeV1ToV2(typ commonv1.TaskType) commonv2.TaskType {
 	switch typ {
@@ -0,0 +1,434 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/federation"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/scheduling"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+const (
+	// DownloadsPath is the path of the download records.
+	DownloadsPath = "/api/v1/downloads"
+
+	// PeerExplanationPath is the path of the scheduling explanation of the peer.
+	PeerExplanationPath = "/api/v1/peers/{id}/explanation"
+
+	// TaskTopologyPath is the path of the peer DAG of the task.
+	TaskTopologyPath = "/api/v1/tasks/{id}/topology"
+
+	// TrafficPath is the path of the traffic summary by application.
+	TrafficPath = "/api/v1/traffic"
+
+	// PersistentCacheTasksPath is the path of the persistent cache tasks.
+	PersistentCacheTasksPath = "/api/v1/persistent-cache-tasks"
+
+	// PersistentCacheTaskPath is the path of the persistent cache task.
+	PersistentCacheTaskPath = "/api/v1/persistent-cache-tasks/{id}"
+
+	// HeaderDownloadCount is the header of the count of download records.
+	HeaderDownloadCount = "X-Dragonfly-Download-Count"
+)
+
+const (
+	// DefaultPage is the default page of the list.
+	DefaultPage = 1
+
+	// DefaultPerPage is the default item count per page of the list.
+	DefaultPerPage = 10
+
+	// MaxPerPage is the max item count per page of the list.
+	MaxPerPage = 10000000
+)
+
+// api provides the http handlers of scheduler.
+type api struct {
+	// Resource interface.
+	resource standard.Resource
+
+	// Persistent cache resource interface.
+	persistentCacheResource persistentcache.Resource
+
+	// Scheduling interface.
+	scheduling scheduling.Scheduling
+
+	// Storage interface.
+	storage storage.Storage
+
+	// token authenticates the requests of api.
+	token string
+
+	// federationToken authenticates the requests from the federated schedulers.
+	federationToken string
+}
+
+// Option is a functional option for configuring the api.
+type Option func(a *api)
+
+// WithFederationToken sets the token of the federated schedulers, the peers of tasks
+// are served only when the token is set.
+func WithFederationToken(token string) Option {
+	return func(a *api) {
+		a.federationToken = token
+	}
+}
+
+// New returns a new api server.
+func New(cfg *config.APIConfig, resource standard.Resource, persistentCacheResource persistentcache.Resource, scheduling scheduling.Scheduling, storage storage.Storage, options ...Option) *http.Server {
+	a := &api{
+		resource:                resource,
+		persistentCacheResource: persistentCacheResource,
+		scheduling:              scheduling,
+		storage:                 storage,
+		token:                   cfg.Token,
+	}
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+DownloadsPath, a.authenticate(a.getDownloads))
+	mux.HandleFunc("GET "+PeerExplanationPath, a.authenticate(a.getPeerExplanation))
+	mux.HandleFunc("GET "+TaskTopologyPath, a.authenticate(a.getTaskTopology))
+	mux.HandleFunc("GET "+TrafficPath, a.authenticate(a.getTraffic))
+
+	// Resources are modified only by the requests carrying the token.
+	if a.token != "" {
+		mux.HandleFunc("DELETE "+DownloadsPath, a.authenticate(a.deleteDownloads))
+	}
+
+	// Peers of tasks are shared with the federated schedulers carrying the federation token.
+	if a.federationToken != "" {
+		mux.HandleFunc("GET "+federation.PeersPath, a.getTaskPeers)
+	}
+
+	// Persistent cache resource is nil if redis is not enabled.
+	if persistentCacheResource != nil {
+		mux.HandleFunc("GET "+PersistentCacheTasksPath, a.authenticate(a.listPersistentCacheTasks))
+		if a.token != "" {
+			mux.HandleFunc("PATCH "+PersistentCacheTaskPath, a.authenticate(a.updatePersistentCacheTask))
+		}
+	}
+
+	return &http.Server{
+		Addr:    cfg.Addr,
+		Handler: mux,
+	}
+}
+
+// authenticate rejects the requests without the bearer token of api, the requests are not
+// authenticated if the token is empty.
+func (a *api) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.token != "" && !federation.Authorized(r, a.token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// getDownloads streams the download records in csv format.
+func (a *api) getDownloads(w http.ResponseWriter, r *http.Request) {
+	rc, err := a.storage.OpenDownload()
+	if err != nil {
+		logger.Errorf("open download failed: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=download.csv")
+	w.Header().Set(HeaderDownloadCount, strconv.FormatInt(a.storage.DownloadCount(), 10))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		logger.Errorf("copy download failed: %s", err.Error())
+	}
+}
+
+// deleteDownloads removes all download records.
+func (a *api) deleteDownloads(w http.ResponseWriter, r *http.Request) {
+	if err := a.storage.ClearDownload(); err != nil {
+		logger.Errorf("clear download failed: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getPeerExplanation explains how the candidate parents of the peer were filtered and evaluated
+// by the last scheduling.
+func (a *api) getPeerExplanation(w http.ResponseWriter, r *http.Request) {
+	peer, loaded := a.resource.PeerManager().Load(r.PathValue("id"))
+	if !loaded {
+		http.Error(w, "peer not found", http.StatusNotFound)
+		return
+	}
+
+	explanation, ok := a.scheduling.ExplainCandidateParents(peer)
+	if !ok {
+		http.Error(w, "peer has not been scheduled", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(explanation); err != nil {
+		logger.Errorf("encode explanation failed: %s", err.Error())
+	}
+}
+
+// getTaskTopology returns the peer DAG of the task in json or graphviz dot format.
+func (a *api) getTaskTopology(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = TopologyFormatJSON
+	}
+
+	if format != TopologyFormatJSON && format != TopologyFormatDOT {
+		http.Error(w, fmt.Sprintf("invalid format %s", format), http.StatusBadRequest)
+		return
+	}
+
+	task, loaded := a.resource.TaskManager().Load(r.PathValue("id"))
+	if !loaded {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+
+	topology := newTopology(task)
+	if format == TopologyFormatDOT {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		if _, err := w.Write(topology.DOT()); err != nil {
+			logger.Errorf("write topology failed: %s", err.Error())
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(topology); err != nil {
+		logger.Errorf("encode topology failed: %s", err.Error())
+	}
+}
+
+// getTraffic returns the traffic summary by application and the top tasks by back-to-source traffic.
+func (a *api) getTraffic(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metrics.GetTrafficSummary()); err != nil {
+		logger.Errorf("encode traffic failed: %s", err.Error())
+	}
+}
+
+// getTaskPeers returns the succeeded peers of the task, they are shared with the schedulers of the federated clusters.
+func (a *api) getTaskPeers(w http.ResponseWriter, r *http.Request) {
+	if !federation.Authorized(r, a.federationToken) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	task, loaded := a.resource.TaskManager().Load(r.PathValue("id"))
+	if !loaded {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+
+	peers := []federation.Peer{}
+	for _, peer := range task.LoadPeers() {
+		if peer.FSM.Is(standard.PeerStateSucceeded) {
+			peers = append(peers, federation.NewPeer(peer))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(peers); err != nil {
+		logger.Errorf("encode peers failed: %s", err.Error())
+	}
+}
+
+// updatePersistentCacheTask extends the ttl of the persistent cache task or pins it.
+func (a *api) updatePersistentCacheTask(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePersistentCacheTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.TTL == "" && req.Pinned == nil {
+		http.Error(w, "ttl or pinned is required", http.StatusBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %s", req.TTL), http.StatusBadRequest)
+			return
+		}
+	}
+
+	task, loaded := a.persistentCacheResource.TaskManager().Load(r.Context(), r.PathValue("id"))
+	if !loaded {
+		http.Error(w, "persistent cache task not found", http.StatusNotFound)
+		return
+	}
+
+	// The ttl of the persistent cache task is counted from its creation,
+	// so the requested ttl from now is added to the elapsed time.
+	if ttl > 0 {
+		task.TTL = time.Since(task.CreatedAt) + ttl
+	}
+
+	if req.Pinned != nil {
+		task.Pinned = *req.Pinned
+	}
+
+	task.UpdatedAt = time.Now()
+	if err := a.persistentCacheResource.TaskManager().Store(r.Context(), task); err != nil {
+		logger.Errorf("store persistent cache task failed: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The peers of the persistent cache task expire with it, so the new ttl or pin is applied to them.
+	if err := a.persistentCacheResource.PeerManager().RefreshTTLByTask(r.Context(), task); err != nil {
+		logger.Errorf("refresh ttl of persistent cache peers failed: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newPersistentCacheTask(task)); err != nil {
+		logger.Errorf("encode persistent cache task failed: %s", err.Error())
+	}
+}
+
+// listPersistentCacheTasks returns the persistent cache tasks matching the filter by page,
+// the newest task is the first.
+func (a *api) listPersistentCacheTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := persistentcache.TaskFilter{
+		Application: query.Get("application"),
+		Tag:         query.Get("tag"),
+		State:       query.Get("state"),
+	}
+
+	var err error
+	if minAge := query.Get("min_age"); minAge != "" {
+		if filter.MinAge, err = time.ParseDuration(minAge); err != nil {
+			http.Error(w, fmt.Sprintf("invalid min_age %s", minAge), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if maxAge := query.Get("max_age"); maxAge != "" {
+		if filter.MaxAge, err = time.ParseDuration(maxAge); err != nil {
+			http.Error(w, fmt.Sprintf("invalid max_age %s", maxAge), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page := DefaultPage
+	if rawPage := query.Get("page"); rawPage != "" {
+		if page, err = strconv.Atoi(rawPage); err != nil || page < 1 {
+			http.Error(w, fmt.Sprintf("invalid page %s", rawPage), http.StatusBadRequest)
+			return
+		}
+	}
+
+	perPage := DefaultPerPage
+	if rawPerPage := query.Get("per_page"); rawPerPage != "" {
+		if perPage, err = strconv.Atoi(rawPerPage); err != nil || perPage < 1 || perPage > MaxPerPage {
+			http.Error(w, fmt.Sprintf("invalid per_page %s", rawPerPage), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tasks, err := a.persistentCacheResource.TaskManager().LoadAll(r.Context())
+	if err != nil {
+		logger.Errorf("load persistent cache tasks failed: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tasks = persistentcache.FilterTasks(tasks, filter)
+	resp := ListPersistentCacheTasksResponse{
+		TotalCount: len(tasks),
+		Tasks:      []PersistentCacheTaskWithReplicas{},
+	}
+
+	for _, task := range tasks {
+		resp.TotalContentLength += task.ContentLength
+	}
+
+	// The page is checked by division, because the start of a huge page overflows.
+	if page-1 > len(tasks)/perPage {
+		http.Error(w, fmt.Sprintf("page %d is out of range", page), http.StatusBadRequest)
+		return
+	}
+
+	start := (page - 1) * perPage
+	end := min(start+perPage, len(tasks))
+	for i := start; i < end; i++ {
+		resp.Tasks = append(resp.Tasks, a.newPersistentCacheTaskWithReplicas(r.Context(), tasks[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Errorf("encode persistent cache tasks failed: %s", err.Error())
+	}
+}
+
+// newPersistentCacheTaskWithReplicas returns the persistent cache task with its current replica counts,
+// the counts are left zero if they can not be loaded.
+func (a *api) newPersistentCacheTaskWithReplicas(ctx context.Context, task *persistentcache.Task) PersistentCacheTaskWithReplicas {
+	persistentCacheTask := PersistentCacheTaskWithReplicas{
+		PersistentCacheTask: newPersistentCacheTask(task),
+	}
+
+	currentPersistentReplicaCount, err := a.persistentCacheResource.TaskManager().LoadCurrentPersistentReplicaCount(ctx, task.ID)
+	if err != nil {
+		task.Log.Errorf("load current persistent replica count failed: %s", err.Error())
+	}
+	persistentCacheTask.CurrentPersistentReplicaCount = currentPersistentReplicaCount
+
+	currentReplicaCount, err := a.persistentCacheResource.TaskManager().LoadCorrentReplicaCount(ctx, task.ID)
+	if err != nil {
+		task.Log.Errorf("load current replica count failed: %s", err.Error())
+	}
+	persistentCacheTask.CurrentReplicaCount = currentReplicaCount
+
+	return persistentCacheTask
+}
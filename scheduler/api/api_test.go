@@ -0,0 +1,818 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/federation"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/scheduling"
+	schedulingmocks "d7y.io/dragonfly/v2/scheduler/scheduling/mocks"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+func TestAPI_New(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, standard.NewMockResource(ctl), persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+	assert := assert.New(t)
+	assert.Equal(config.DefaultAPIAddr, svr.Addr)
+	assert.NotNil(svr.Handler)
+}
+
+func TestAPI_NewWithoutToken(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, standard.NewMockResource(ctl), persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+	assert := assert.New(t)
+
+	// The endpoints which modify the resources are not served without token.
+	w := httptest.NewRecorder()
+	svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, DownloadsPath, nil))
+	assert.Equal(http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, strings.Replace(PersistentCacheTaskPath, "{id}", "foo", 1), strings.NewReader(`{"pinned": true}`)))
+	assert.Equal(http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestAPI_Downloads(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		mock   func(ms *storagemocks.MockStorageMockRecorder)
+		expect func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:   "get downloads",
+			method: http.MethodGet,
+			token:  "foo",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				gomock.InOrder(
+					ms.OpenDownload().Return(io.NopCloser(strings.NewReader("id\n1\n")), nil).Times(1),
+					ms.DownloadCount().Return(int64(1)).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("text/csv", resp.Header.Get("Content-Type"))
+				assert.Equal("1", resp.Header.Get(HeaderDownloadCount))
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal("id\n1\n", string(data))
+			},
+		},
+		{
+			name:   "get downloads failed",
+			method: http.MethodGet,
+			token:  "foo",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				ms.OpenDownload().Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+		{
+			name:   "delete downloads",
+			method: http.MethodDelete,
+			token:  "foo",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				ms.ClearDownload().Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name:   "delete downloads failed",
+			method: http.MethodDelete,
+			token:  "foo",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				ms.ClearDownload().Return(errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+		{
+			name:   "get downloads without token",
+			method: http.MethodGet,
+			mock:   func(ms *storagemocks.MockStorageMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			name:   "delete downloads with invalid token",
+			method: http.MethodDelete,
+			token:  "bar",
+			mock:   func(ms *storagemocks.MockStorageMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodPost,
+			token:  "foo",
+			mock:   func(ms *storagemocks.MockStorageMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			storage := storagemocks.NewMockStorage(ctl)
+			tc.mock(storage.EXPECT())
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr, Token: "foo"}, standard.NewMockResource(ctl), persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storage)
+			req := httptest.NewRequest(tc.method, DownloadsPath, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, req)
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestAPI_PeerExplanation(t *testing.T) {
+	mockHost := standard.NewHost(idgen.HostIDV2("127.0.0.1", "foo", false), "127.0.0.1", "foo", 8003, 8001, types.HostTypeNormal)
+	mockTask := standard.NewTask(idgen.TaskIDV2("https://example.com", "", "", []string{}), "https://example.com", "", "", commonv2.TaskType_STANDARD, []string{}, map[string]string{}, 200)
+	mockPeer := standard.NewPeer(idgen.PeerIDV2(), mockTask, mockHost)
+
+	tests := []struct {
+		name   string
+		mock   func(mr *standard.MockResourceMockRecorder, mp *standard.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder, peerManager standard.PeerManager)
+		expect func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "get peer explanation",
+			mock: func(mr *standard.MockResourceMockRecorder, mp *standard.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder, peerManager standard.PeerManager) {
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Eq(mockPeer.ID)).Return(mockPeer, true).Times(1),
+					ms.ExplainCandidateParents(gomock.Eq(mockPeer)).DoAndReturn(
+						func(peer *standard.Peer) (*scheduling.Explanation, bool) {
+							return &scheduling.Explanation{
+								PeerID: peer.ID,
+								CandidateParents: []scheduling.CandidateParent{
+									{ID: "foo", FilterReason: scheduling.FilterReasonBlocklist},
+								},
+							}, true
+						}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+				var explanation scheduling.Explanation
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&explanation))
+				assert.Equal(mockPeer.ID, explanation.PeerID)
+				assert.Len(explanation.CandidateParents, 1)
+				assert.Equal(scheduling.FilterReasonBlocklist, explanation.CandidateParents[0].FilterReason)
+			},
+		},
+		{
+			name: "peer has not been scheduled",
+			mock: func(mr *standard.MockResourceMockRecorder, mp *standard.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder, peerManager standard.PeerManager) {
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Eq(mockPeer.ID)).Return(mockPeer, true).Times(1),
+					ms.ExplainCandidateParents(gomock.Eq(mockPeer)).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "peer not found",
+			mock: func(mr *standard.MockResourceMockRecorder, mp *standard.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder, peerManager standard.PeerManager) {
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Eq(mockPeer.ID)).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			resource := standard.NewMockResource(ctl)
+			peerManager := standard.NewMockPeerManager(ctl)
+			scheduling := schedulingmocks.NewMockScheduling(ctl)
+			tc.mock(resource.EXPECT(), peerManager.EXPECT(), scheduling.EXPECT(), peerManager)
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, resource, persistentcache.NewMockResource(ctl), scheduling, storagemocks.NewMockStorage(ctl))
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, strings.Replace(PeerExplanationPath, "{id}", mockPeer.ID, 1), nil))
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestAPI_TaskTopology(t *testing.T) {
+	mockTask, _, _ := newTopologyTask(t)
+
+	tests := []struct {
+		name   string
+		query  string
+		mock   func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager)
+		expect func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:  "get task topology in json format",
+			query: "",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTask.ID)).Return(mockTask, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+				var topology Topology
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&topology))
+				assert.Equal(mockTask.ID, topology.TaskID)
+				assert.Len(topology.Nodes, 2)
+				assert.Len(topology.Edges, 1)
+			},
+		},
+		{
+			name:  "get task topology in dot format",
+			query: "?format=dot",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTask.ID)).Return(mockTask, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("text/vnd.graphviz", resp.Header.Get("Content-Type"))
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.True(strings.HasPrefix(string(data), "digraph"))
+			},
+		},
+		{
+			name:  "invalid format",
+			query: "?format=foo",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "task not found",
+			query: "",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTask.ID)).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			resource := standard.NewMockResource(ctl)
+			taskManager := standard.NewMockTaskManager(ctl)
+			tc.mock(resource.EXPECT(), taskManager.EXPECT(), taskManager)
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, resource, persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, strings.Replace(TaskTopologyPath, "{id}", mockTask.ID, 1)+tc.query, nil))
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestAPI_TaskPeers(t *testing.T) {
+	mockTask, mockParent, _ := newTopologyTask(t)
+	mockParent.FSM.SetState(standard.PeerStateSucceeded)
+
+	tests := []struct {
+		name          string
+		authorization string
+		mock          func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager)
+		expect        func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:          "get succeeded peers of task",
+			authorization: "Bearer foo",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTask.ID)).Return(mockTask, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+				var peers []federation.Peer
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&peers))
+				assert.Len(peers, 1)
+				assert.Equal(mockParent.ID, peers[0].ID)
+				assert.Equal(uint(2), peers[0].FinishedPieceCount)
+				assert.Equal(mockParent.Host.ID, peers[0].Host.ID)
+				assert.Equal("127.0.0.1", peers[0].Host.IP)
+			},
+		},
+		{
+			name:          "task not found",
+			authorization: "Bearer foo",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTask.ID)).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:          "token mismatches",
+			authorization: "Bearer bar",
+			mock: func(mr *standard.MockResourceMockRecorder, mt *standard.MockTaskManagerMockRecorder, taskManager standard.TaskManager) {
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			resource := standard.NewMockResource(ctl)
+			taskManager := standard.NewMockTaskManager(ctl)
+			tc.mock(resource.EXPECT(), taskManager.EXPECT(), taskManager)
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, resource, persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl), WithFederationToken("foo"))
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, strings.Replace(federation.PeersPath, "{id}", mockTask.ID, 1), nil)
+			req.Header.Set("Authorization", tc.authorization)
+			svr.Handler.ServeHTTP(w, req)
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestAPI_Traffic(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	metrics.InitTraffic(&config.TrafficConfig{
+		MaxApplications: config.DefaultMetricsTrafficMaxApplications,
+		MaxTags:         config.DefaultMetricsTrafficMaxTags,
+		TopTaskCount:    config.DefaultMetricsTrafficTopTaskCount,
+		TopTaskWindow:   config.DefaultMetricsTrafficTopTaskWindow,
+	})
+	metrics.AddTaskTraffic(commonv2.TrafficType_REMOTE_PEER, "foo", "baz", commonv2.Priority_LEVEL0, "bar", 300)
+	metrics.AddTaskTraffic(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", commonv2.Priority_LEVEL0, "bar", 100)
+
+	svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, standard.NewMockResource(ctl), persistentcache.NewMockResource(ctl), schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+	w := httptest.NewRecorder()
+	svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, TrafficPath, nil))
+
+	resp := w.Result()
+	assert := assert.New(t)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	var summary metrics.TrafficSummary
+	assert.NoError(json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal([]metrics.ApplicationTrafficSummary{
+		{Application: "foo", P2PBytes: 300, BackToSourceBytes: 100, HitRatio: 0.75},
+	}, summary.Applications)
+	assert.Equal([]metrics.TagTrafficSummary{
+		{Tag: "baz", P2PBytes: 300, BackToSourceBytes: 100, HitRatio: 0.75},
+	}, summary.Tags)
+	assert.Equal([]metrics.TaskTrafficSummary{
+		{TaskID: "bar", Application: "foo", Tag: "baz", BackToSourceBytes: 100},
+	}, summary.TopTasks)
+}
+
+func TestAPI_UpdatePersistentCacheTask(t *testing.T) {
+	newTask := func() *persistentcache.Task {
+		return persistentcache.NewTask("foo", "bar", "baz", persistentcache.TaskStateSucceeded, 2, 1024, 2048, 2, time.Hour, time.Now().Add(-30*time.Minute), time.Now().Add(-30*time.Minute), logger.WithTaskID("foo"))
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		mock   func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager)
+		expect func(t *testing.T, task *persistentcache.Task, resp *http.Response)
+	}{
+		{
+			name: "extend ttl of persistent cache task",
+			body: `{"ttl": "2h"}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any(), gomock.Eq(task.ID)).Return(task, true).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any(), gomock.Eq(task)).Return(nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.RefreshTTLByTask(gomock.Any(), gomock.Eq(task)).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("application/json", resp.Header.Get("Content-Type"))
+				assert.InDelta((2*time.Hour + 30*time.Minute).Seconds(), task.TTL.Seconds(), 1)
+				assert.False(task.Pinned)
+
+				var persistentCacheTask PersistentCacheTask
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&persistentCacheTask))
+				assert.Equal(task.ID, persistentCacheTask.ID)
+				assert.Equal(task.TTL.String(), persistentCacheTask.TTL)
+			},
+		},
+		{
+			name: "pin persistent cache task",
+			body: `{"pinned": true}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any(), gomock.Eq(task.ID)).Return(task, true).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any(), gomock.Eq(task)).Return(nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.RefreshTTLByTask(gomock.Any(), gomock.Eq(task)).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal(time.Hour, task.TTL)
+				assert.True(task.Pinned)
+
+				var persistentCacheTask PersistentCacheTask
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&persistentCacheTask))
+				assert.True(persistentCacheTask.Pinned)
+			},
+		},
+		{
+			name: "invalid request body",
+			body: `foo`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "ttl and pinned are empty",
+			body: `{}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "invalid ttl",
+			body: `{"ttl": "-1h"}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "persistent cache task not found",
+			body: `{"pinned": true}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any(), gomock.Eq(task.ID)).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name: "refresh ttl of persistent cache peers failed",
+			body: `{"ttl": "2h"}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any(), gomock.Eq(task.ID)).Return(task, true).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any(), gomock.Eq(task)).Return(nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.RefreshTTLByTask(gomock.Any(), gomock.Eq(task)).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+		{
+			name: "store persistent cache task failed",
+			body: `{"pinned": true}`,
+			mock: func(task *persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any(), gomock.Eq(task.ID)).Return(task, true).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any(), gomock.Eq(task)).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *persistentcache.Task, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			persistentCacheResource := persistentcache.NewMockResource(ctl)
+			taskManager := persistentcache.NewMockTaskManager(ctl)
+			peerManager := persistentcache.NewMockPeerManager(ctl)
+			task := newTask()
+			tc.mock(task, persistentCacheResource.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), taskManager, peerManager)
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr, Token: "foo"}, standard.NewMockResource(ctl), persistentCacheResource, schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+			req := httptest.NewRequest(http.MethodPatch, strings.Replace(PersistentCacheTaskPath, "{id}", task.ID, 1), strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer foo")
+
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, req)
+			tc.expect(t, task, w.Result())
+		})
+	}
+}
+
+func TestAPI_UpdatePersistentCacheTaskWithoutRedis(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr, Token: "foo"}, standard.NewMockResource(ctl), nil, schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+	req := httptest.NewRequest(http.MethodPatch, strings.Replace(PersistentCacheTaskPath, "{id}", "foo", 1), strings.NewReader(`{"pinned": true}`))
+	req.Header.Set("Authorization", "Bearer foo")
+
+	w := httptest.NewRecorder()
+	svr.Handler.ServeHTTP(w, req)
+
+	assert := assert.New(t)
+	assert.Equal(http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestAPI_ListPersistentCacheTasks(t *testing.T) {
+	newTasks := func() []*persistentcache.Task {
+		return []*persistentcache.Task{
+			persistentcache.NewTask("foo", "bar", "baz", persistentcache.TaskStateSucceeded, 2, 1024, 2048, 2, time.Hour, time.Now().Add(-2*time.Hour), time.Now(), logger.WithTaskID("foo")),
+			persistentcache.NewTask("bar", "bar", "baz", persistentcache.TaskStateSucceeded, 2, 1024, 4096, 4, time.Hour, time.Now().Add(-time.Hour), time.Now(), logger.WithTaskID("bar")),
+			persistentcache.NewTask("baz", "bar", "qux", persistentcache.TaskStateFailed, 2, 1024, 1024, 1, time.Hour, time.Now(), time.Now(), logger.WithTaskID("baz")),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		mock   func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager)
+		expect func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:  "list persistent cache tasks by application",
+			query: "?application=baz",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(tasks, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq("bar")).Return(uint64(2), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq("bar")).Return(uint64(3), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq("foo")).Return(uint64(1), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq("foo")).Return(uint64(0), errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+				var listResp ListPersistentCacheTasksResponse
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&listResp))
+				assert.Equal(2, listResp.TotalCount)
+				assert.Equal(uint64(6144), listResp.TotalContentLength)
+				assert.Len(listResp.Tasks, 2)
+				assert.Equal("bar", listResp.Tasks[0].ID)
+				assert.Equal(uint64(2), listResp.Tasks[0].CurrentPersistentReplicaCount)
+				assert.Equal(uint64(3), listResp.Tasks[0].CurrentReplicaCount)
+				assert.Equal("foo", listResp.Tasks[1].ID)
+				assert.Equal(uint64(1), listResp.Tasks[1].CurrentPersistentReplicaCount)
+				assert.Equal(uint64(0), listResp.Tasks[1].CurrentReplicaCount)
+			},
+		},
+		{
+			name:  "list persistent cache tasks by page",
+			query: "?page=2&per_page=2",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(tasks, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq("foo")).Return(uint64(2), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq("foo")).Return(uint64(2), nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+
+				var listResp ListPersistentCacheTasksResponse
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&listResp))
+				assert.Equal(3, listResp.TotalCount)
+				assert.Equal(uint64(7168), listResp.TotalContentLength)
+				assert.Len(listResp.Tasks, 1)
+				assert.Equal("foo", listResp.Tasks[0].ID)
+			},
+		},
+		{
+			name:  "page is out of range",
+			query: "?state=Failed&page=2",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(tasks, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "huge page overflows",
+			query: "?page=9223372036854775807&per_page=10000000",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(tasks, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "invalid min age",
+			query: "?min_age=foo",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "invalid per page",
+			query: "?per_page=0",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "per page exceeds max",
+			query: "?per_page=10000001",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name:  "load persistent cache tasks failed",
+			query: "",
+			mock: func(tasks []*persistentcache.Task, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, taskManager persistentcache.TaskManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			persistentCacheResource := persistentcache.NewMockResource(ctl)
+			taskManager := persistentcache.NewMockTaskManager(ctl)
+			tc.mock(newTasks(), persistentCacheResource.EXPECT(), taskManager.EXPECT(), taskManager)
+
+			svr := New(&config.APIConfig{Enable: true, Addr: config.DefaultAPIAddr}, standard.NewMockResource(ctl), persistentCacheResource, schedulingmocks.NewMockScheduling(ctl), storagemocks.NewMockStorage(ctl))
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PersistentCacheTasksPath+tc.query, nil))
+			tc.expect(t, w.Result())
+		})
+	}
+}
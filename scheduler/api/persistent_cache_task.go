@@ -0,0 +1,105 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"time"
+
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
+)
+
+// UpdatePersistentCacheTaskRequest is the request of updating the persistent cache task.
+type UpdatePersistentCacheTaskRequest struct {
+	// TTL is the time to live of the persistent cache task from now, e.g. 24h.
+	TTL string `json:"ttl,omitempty"`
+
+	// Pinned pins or unpins the persistent cache task, the pinned
+	// persistent cache task will not be expired by TTL.
+	Pinned *bool `json:"pinned,omitempty"`
+}
+
+// PersistentCacheTask is the persistent cache task.
+type PersistentCacheTask struct {
+	// ID is the id of the persistent cache task.
+	ID string `json:"id"`
+
+	// Tag is the tag of the persistent cache task.
+	Tag string `json:"tag"`
+
+	// Application is the application of the persistent cache task.
+	Application string `json:"application"`
+
+	// State is the state of the persistent cache task.
+	State string `json:"state"`
+
+	// PersistentReplicaCount is the desired persistent replica count of the persistent cache task.
+	PersistentReplicaCount uint64 `json:"persistent_replica_count"`
+
+	// ContentLength is the content length of the persistent cache task.
+	ContentLength uint64 `json:"content_length"`
+
+	// TTL is the time to live of the persistent cache task from its creation.
+	TTL string `json:"ttl"`
+
+	// Pinned is whether the persistent cache task is pinned.
+	Pinned bool `json:"pinned"`
+
+	// CreatedAt is the creation time of the persistent cache task.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the update time of the persistent cache task.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PersistentCacheTaskWithReplicas is the persistent cache task with its current replica counts.
+type PersistentCacheTaskWithReplicas struct {
+	PersistentCacheTask
+
+	// CurrentPersistentReplicaCount is the current persistent replica count of the persistent cache task.
+	CurrentPersistentReplicaCount uint64 `json:"current_persistent_replica_count"`
+
+	// CurrentReplicaCount is the current replica count of the persistent cache task.
+	CurrentReplicaCount uint64 `json:"current_replica_count"`
+}
+
+// ListPersistentCacheTasksResponse is the response of listing the persistent cache tasks.
+type ListPersistentCacheTasksResponse struct {
+	// TotalCount is the count of the persistent cache tasks matching the filter.
+	TotalCount int `json:"total_count"`
+
+	// TotalContentLength is the total content length of the persistent cache tasks matching the filter.
+	TotalContentLength uint64 `json:"total_content_length"`
+
+	// Tasks is the persistent cache tasks of the page.
+	Tasks []PersistentCacheTaskWithReplicas `json:"tasks"`
+}
+
+// newPersistentCacheTask returns the persistent cache task of the api.
+func newPersistentCacheTask(task *persistentcache.Task) PersistentCacheTask {
+	return PersistentCacheTask{
+		ID:                     task.ID,
+		Tag:                    task.Tag,
+		Application:            task.Application,
+		State:                  task.FSM.Current(),
+		PersistentReplicaCount: task.PersistentReplicaCount,
+		ContentLength:          task.ContentLength,
+		TTL:                    task.TTL.String(),
+		Pinned:                 task.Pinned,
+		CreatedAt:              task.CreatedAt,
+		UpdatedAt:              task.UpdatedAt,
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+)
+
+const (
+	// TopologyFormatJSON is the json format of the topology.
+	TopologyFormatJSON = "json"
+
+	// TopologyFormatDOT is the graphviz dot format of the topology.
+	TopologyFormatDOT = "dot"
+)
+
+// Topology is the peer DAG of the task.
+type Topology struct {
+	// TaskID is the id of the task.
+	TaskID string `json:"task_id"`
+
+	// State is the state of the task.
+	State string `json:"state"`
+
+	// Nodes are the peers of the task.
+	Nodes []TopologyNode `json:"nodes"`
+
+	// Edges are the parent-child relations between the peers, including the parents
+	// that are no longer current but the child downloaded pieces from.
+	Edges []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode is the peer in the topology.
+type TopologyNode struct {
+	// ID is the id of the peer.
+	ID string `json:"id"`
+
+	// State is the state of the peer.
+	State string `json:"state"`
+
+	// FinishedPieceCount is the count of the finished pieces of the peer.
+	FinishedPieceCount uint `json:"finished_piece_count"`
+
+	// HostID is the id of the host.
+	HostID string `json:"host_id"`
+
+	// Hostname is the hostname of the host.
+	Hostname string `json:"hostname"`
+
+	// IP is the ip of the host.
+	IP string `json:"ip"`
+
+	// HostType is the type of the host.
+	HostType string `json:"host_type"`
+
+	// ConcurrentUploadCount is the concurrent upload count of the host.
+	ConcurrentUploadCount int32 `json:"concurrent_upload_count"`
+
+	// ConcurrentUploadLimit is the concurrent upload limit of the host.
+	ConcurrentUploadLimit int32 `json:"concurrent_upload_limit"`
+}
+
+// TopologyEdge is the parent-child relation in the topology.
+type TopologyEdge struct {
+	// Parent is the id of the parent peer.
+	Parent string `json:"parent"`
+
+	// Child is the id of the child peer.
+	Child string `json:"child"`
+
+	// Current indicates whether the parent is the current parent of the child,
+	// the parent may have left the task and is not in the nodes if it is not current.
+	Current bool `json:"current"`
+
+	// PieceCount is the count of the pieces the child downloaded from the parent.
+	PieceCount int64 `json:"piece_count"`
+
+	// ContentLength is the bytes the child downloaded from the parent.
+	ContentLength uint64 `json:"content_length"`
+}
+
+// newTopology builds the topology from the peer DAG of the task,
+// nodes and edges are sorted by peer id for stable output.
+func newTopology(task *standard.Task) *Topology {
+	topology := &Topology{
+		TaskID: task.ID,
+		State:  task.FSM.Current(),
+		Nodes:  []TopologyNode{},
+		Edges:  []TopologyEdge{},
+	}
+
+	for _, peer := range task.LoadPeers() {
+		topology.Nodes = append(topology.Nodes, TopologyNode{
+			ID:                    peer.ID,
+			State:                 peer.FSM.Current(),
+			FinishedPieceCount:    peer.FinishedPieces.Count(),
+			HostID:                peer.Host.ID,
+			Hostname:              peer.Host.Hostname,
+			IP:                    peer.Host.IP,
+			HostType:              peer.Host.Type.Name(),
+			ConcurrentUploadCount: peer.Host.ConcurrentUploadCount.Load(),
+			ConcurrentUploadLimit: peer.Host.ConcurrentUploadLimit.Load(),
+		})
+
+		// Aggregate the pieces of the child by the parent they were downloaded from.
+		pieceCounts := make(map[string]int64)
+		contentLengths := make(map[string]uint64)
+		peer.Pieces.Range(func(_, value any) bool {
+			piece, ok := value.(*standard.Piece)
+			if !ok {
+				return true
+			}
+
+			// Pieces downloaded back-to-source have no parent.
+			if piece.ParentID == "" {
+				return true
+			}
+
+			pieceCounts[piece.ParentID]++
+			contentLengths[piece.ParentID] += piece.Length
+			return true
+		})
+
+		for _, parent := range peer.Parents() {
+			topology.Edges = append(topology.Edges, TopologyEdge{
+				Parent:        parent.ID,
+				Child:         peer.ID,
+				Current:       true,
+				PieceCount:    pieceCounts[parent.ID],
+				ContentLength: contentLengths[parent.ID],
+			})
+			delete(pieceCounts, parent.ID)
+		}
+
+		// The remaining parents were replaced by rescheduling.
+		for parentID, pieceCount := range pieceCounts {
+			topology.Edges = append(topology.Edges, TopologyEdge{
+				Parent:        parentID,
+				Child:         peer.ID,
+				PieceCount:    pieceCount,
+				ContentLength: contentLengths[parentID],
+			})
+		}
+	}
+
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		return topology.Nodes[i].ID < topology.Nodes[j].ID
+	})
+
+	sort.Slice(topology.Edges, func(i, j int) bool {
+		if topology.Edges[i].Parent != topology.Edges[j].Parent {
+			return topology.Edges[i].Parent < topology.Edges[j].Parent
+		}
+
+		return topology.Edges[i].Child < topology.Edges[j].Child
+	})
+
+	return topology
+}
+
+// DOT renders the topology in graphviz dot format.
+func (t *Topology) DOT() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph %q {\n", t.TaskID)
+	fmt.Fprintf(&buf, "  label=%q;\n", fmt.Sprintf("%s\n%s", t.TaskID, t.State))
+	buf.WriteString("  node [shape=box];\n")
+	for _, node := range t.Nodes {
+		label := fmt.Sprintf("%s\n%s (%s)\n%s\npieces: %d\nuploads: %d/%d",
+			node.Hostname, node.IP, node.HostType, node.State, node.FinishedPieceCount, node.ConcurrentUploadCount, node.ConcurrentUploadLimit)
+		fmt.Fprintf(&buf, "  %q [label=%q];\n", node.ID, label)
+	}
+
+	for _, edge := range t.Edges {
+		label := fmt.Sprintf("%d pieces\n%d bytes", edge.PieceCount, edge.ContentLength)
+		if !edge.Current {
+			fmt.Fprintf(&buf, "  %q -> %q [label=%q, style=dashed];\n", edge.Parent, edge.Child, label)
+			continue
+		}
+
+		fmt.Fprintf(&buf, "  %q -> %q [label=%q];\n", edge.Parent, edge.Child, label)
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
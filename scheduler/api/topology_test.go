@@ -0,0 +1,124 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+)
+
+func newTopologyTask(t *testing.T) (*standard.Task, *standard.Peer, *standard.Peer) {
+	seedHost := standard.NewHost(idgen.HostIDV2("127.0.0.1", "foo", true), "127.0.0.1", "foo", 8003, 8001, types.HostTypeSuperSeed)
+	host := standard.NewHost(idgen.HostIDV2("127.0.0.2", "bar", false), "127.0.0.2", "bar", 8003, 8001, types.HostTypeNormal)
+	task := standard.NewTask(idgen.TaskIDV2("https://example.com", "", "", []string{}), "https://example.com", "", "", commonv2.TaskType_STANDARD, []string{}, map[string]string{}, 200)
+
+	parent := standard.NewPeer(idgen.PeerIDV2(), task, seedHost)
+	child := standard.NewPeer(idgen.PeerIDV2(), task, host)
+	task.StorePeer(parent)
+	task.StorePeer(child)
+	if err := task.AddPeerEdge(parent, child); err != nil {
+		t.Fatal(err)
+	}
+
+	parent.FinishedPieces.Set(0).Set(1)
+	child.FinishedPieces.Set(0).Set(1)
+	child.StorePiece(&standard.Piece{Number: 0, ParentID: parent.ID, Length: 1024})
+	child.StorePiece(&standard.Piece{Number: 1, ParentID: parent.ID, Length: 512})
+	return task, parent, child
+}
+
+func TestTopology_newTopology(t *testing.T) {
+	task, parent, child := newTopologyTask(t)
+
+	assert := assert.New(t)
+	topology := newTopology(task)
+	assert.Equal(task.ID, topology.TaskID)
+	assert.Equal(standard.TaskStatePending, topology.State)
+	assert.Len(topology.Nodes, 2)
+	for _, node := range topology.Nodes {
+		assert.Equal(uint(2), node.FinishedPieceCount)
+		assert.Equal(standard.PeerStatePending, node.State)
+		switch node.ID {
+		case parent.ID:
+			assert.Equal("foo", node.Hostname)
+			assert.Equal(types.HostTypeSuperSeedName, node.HostType)
+			assert.Equal(int32(1), node.ConcurrentUploadCount)
+		case child.ID:
+			assert.Equal("bar", node.Hostname)
+			assert.Equal(types.HostTypeNormalName, node.HostType)
+			assert.Equal(int32(0), node.ConcurrentUploadCount)
+		default:
+			t.Fatalf("unexpected node %s", node.ID)
+		}
+	}
+
+	assert.Equal([]TopologyEdge{{
+		Parent:        parent.ID,
+		Child:         child.ID,
+		Current:       true,
+		PieceCount:    2,
+		ContentLength: 1536,
+	}}, topology.Edges)
+}
+
+func TestTopology_newTopologyWithPreviousParent(t *testing.T) {
+	task, parent, child := newTopologyTask(t)
+	previousParentID := idgen.PeerIDV2()
+	child.StorePiece(&standard.Piece{Number: 2, ParentID: previousParentID, Length: 256})
+	child.StorePiece(&standard.Piece{Number: 3, Length: 128})
+
+	assert := assert.New(t)
+	topology := newTopology(task)
+	assert.Len(topology.Edges, 2)
+	for _, edge := range topology.Edges {
+		assert.Equal(child.ID, edge.Child)
+		switch edge.Parent {
+		case parent.ID:
+			assert.True(edge.Current)
+			assert.Equal(int64(2), edge.PieceCount)
+			assert.Equal(uint64(1536), edge.ContentLength)
+		case previousParentID:
+			assert.False(edge.Current)
+			assert.Equal(int64(1), edge.PieceCount)
+			assert.Equal(uint64(256), edge.ContentLength)
+		default:
+			t.Fatalf("unexpected edge %s -> %s", edge.Parent, edge.Child)
+		}
+	}
+
+	dot := string(topology.DOT())
+	assert.Contains(dot, "\""+previousParentID+"\" -> \""+child.ID+"\" [label=\"1 pieces\\n256 bytes\", style=dashed];")
+}
+
+func TestTopology_DOT(t *testing.T) {
+	task, parent, child := newTopologyTask(t)
+
+	assert := assert.New(t)
+	dot := string(newTopology(task).DOT())
+	assert.True(strings.HasPrefix(dot, "digraph \""+task.ID+"\" {\n"))
+	assert.True(strings.HasSuffix(dot, "}\n"))
+	assert.Contains(dot, "\""+parent.ID+"\" [label=\"foo\\n127.0.0.1 (super)\\nPending\\npieces: 2\\nuploads: 1/")
+	assert.Contains(dot, "\""+parent.ID+"\" -> \""+child.ID+"\" [label=\"2 pieces\\n1536 bytes\"];")
+}
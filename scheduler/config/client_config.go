@@ -0,0 +1,66 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"net"
+	"slices"
+
+	xxhash "github.com/cespare/xxhash/v2"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// ClientConfigForHost returns the client config applied to the host. The client config in rollout
+// is applied when the host is one of the canary hostnames or cidrs, or the hash of host id falls
+// in the rollout percentage, otherwise the stable client config is applied.
+func ClientConfigForHost(config types.SchedulerClusterClientConfig, hostID, hostname, ip string) types.SchedulerClusterClientConfig {
+	rollout := config.Rollout
+	if rollout == nil {
+		return config
+	}
+
+	stable := types.SchedulerClusterClientConfig{LoadLimit: config.LoadLimit}
+	if !matchClientConfigRollout(rollout, hostID, hostname, ip) {
+		return stable
+	}
+
+	if rollout.LoadLimit > 0 {
+		stable.LoadLimit = rollout.LoadLimit
+	}
+
+	return stable
+}
+
+// matchClientConfigRollout returns whether the host is in the rollout.
+func matchClientConfigRollout(rollout *types.SchedulerClusterClientConfigRollout, hostID, hostname, ip string) bool {
+	if slices.Contains(rollout.Hostnames, hostname) {
+		return true
+	}
+
+	if netIP := net.ParseIP(ip); netIP != nil {
+		for _, cidr := range rollout.CIDRs {
+			if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(netIP) {
+				return true
+			}
+		}
+	}
+
+	// Hosts are bucketed by the hash of host id, so the same host is always
+	// in the rollout while the percentage is increasing.
+	return xxhash.Sum64String(hostID)%100 < uint64(rollout.Percentage)
+}
@@ -0,0 +1,117 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestClientConfigForHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   types.SchedulerClusterClientConfig
+		hostname string
+		ip       string
+		expect   func(t *testing.T, config types.SchedulerClusterClientConfig)
+	}{
+		{
+			name:     "client config without rollout",
+			config:   types.SchedulerClusterClientConfig{LoadLimit: 10},
+			hostname: "foo",
+			ip:       "127.0.0.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 10}, config)
+			},
+		},
+		{
+			name: "host matches canary hostnames",
+			config: types.SchedulerClusterClientConfig{LoadLimit: 10, Rollout: &types.SchedulerClusterClientConfigRollout{
+				LoadLimit: 20,
+				Hostnames: []string{"foo"},
+			}},
+			hostname: "foo",
+			ip:       "127.0.0.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 20}, config)
+			},
+		},
+		{
+			name: "host matches canary cidrs",
+			config: types.SchedulerClusterClientConfig{LoadLimit: 10, Rollout: &types.SchedulerClusterClientConfigRollout{
+				LoadLimit: 20,
+				CIDRs:     []string{"invalid", "10.0.0.0/8"},
+			}},
+			hostname: "foo",
+			ip:       "10.1.1.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 20}, config)
+			},
+		},
+		{
+			name: "host matches full percentage",
+			config: types.SchedulerClusterClientConfig{LoadLimit: 10, Rollout: &types.SchedulerClusterClientConfigRollout{
+				LoadLimit:  20,
+				Percentage: 100,
+			}},
+			hostname: "foo",
+			ip:       "127.0.0.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 20}, config)
+			},
+		},
+		{
+			name: "host does not match rollout",
+			config: types.SchedulerClusterClientConfig{LoadLimit: 10, Rollout: &types.SchedulerClusterClientConfigRollout{
+				LoadLimit: 20,
+				Hostnames: []string{"bar"},
+				CIDRs:     []string{"10.0.0.0/8"},
+			}},
+			hostname: "foo",
+			ip:       "127.0.0.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 10}, config)
+			},
+		},
+		{
+			name: "rollout without load limit keeps stable load limit",
+			config: types.SchedulerClusterClientConfig{LoadLimit: 10, Rollout: &types.SchedulerClusterClientConfigRollout{
+				Hostnames: []string{"foo"},
+			}},
+			hostname: "foo",
+			ip:       "127.0.0.1",
+			expect: func(t *testing.T, config types.SchedulerClusterClientConfig) {
+				assert := assert.New(t)
+				assert.Equal(types.SchedulerClusterClientConfig{LoadLimit: 10}, config)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, ClientConfigForHost(tc.config, "id", tc.hostname, tc.ip))
+		})
+	}
+}
@@ -64,6 +64,9 @@ type Config struct {
 	// Metrics configuration.
 	Metrics MetricsConfig `yaml:"metrics" mapstructure:"metrics"`
 
+	// API configuration.
+	API APIConfig `yaml:"api" mapstructure:"api"`
+
 	// Network configuration.
 	Network NetworkConfig `yaml:"network" mapstructure:"network"`
 }
@@ -138,6 +141,112 @@ type SchedulerConfig struct {
 
 	// GC configuration.
 	GC GCConfig `yaml:"gc" mapstructure:"gc"`
+
+	// HostLoad configuration.
+	HostLoad HostLoadConfig `yaml:"hostLoad" mapstructure:"hostLoad"`
+
+	// Federation configuration.
+	Federation FederationConfig `yaml:"federation" mapstructure:"federation"`
+
+	// Plugins configuration.
+	Plugins PluginsConfig `yaml:"plugins" mapstructure:"plugins"`
+}
+
+type PluginsConfig struct {
+	// Filters veto the candidate parents, the candidate parent vetoed by any filter
+	// can not be selected as a parent.
+	Filters []FilterPluginConfig `yaml:"filters" mapstructure:"filters"`
+
+	// Scores add weighted terms to the evaluation score of the candidate parents.
+	Scores []ScorePluginConfig `yaml:"scores" mapstructure:"scores"`
+}
+
+type FilterPluginConfig struct {
+	// Name is the name of the filter, it is reported as the filter reason of the vetoed candidate parents.
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Plugin loads the filter from d7y-scheduler-plugin-<name>.so in the plugin directory instead of the rules.
+	Plugin bool `yaml:"plugin" mapstructure:"plugin"`
+
+	// Options are passed to the plugin when it is loaded.
+	Options map[string]string `yaml:"options" mapstructure:"options"`
+
+	// Rules veto the candidate parents whose host labels match all the rules.
+	Rules []LabelRule `yaml:"rules" mapstructure:"rules"`
+}
+
+type ScorePluginConfig struct {
+	// Name is the name of the score, it is reported as the feature of the evaluation score.
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Plugin loads the score from d7y-scheduler-plugin-<name>.so in the plugin directory instead of the rules.
+	Plugin bool `yaml:"plugin" mapstructure:"plugin"`
+
+	// Options are passed to the plugin when it is loaded.
+	Options map[string]string `yaml:"options" mapstructure:"options"`
+
+	// Weight is the weight of the score added to the evaluation score.
+	Weight float64 `yaml:"weight" mapstructure:"weight"`
+
+	// Rules score 1 for the candidate parents whose host labels match all the rules, otherwise 0.
+	Rules []LabelRule `yaml:"rules" mapstructure:"rules"`
+}
+
+type LabelRule struct {
+	// Key is the key of the host label.
+	Key string `yaml:"key" mapstructure:"key"`
+
+	// Operator is the operator of the rule, supports In, NotIn, Exists and DoesNotExist.
+	Operator string `yaml:"operator" mapstructure:"operator"`
+
+	// Values are the values of the host label used by In and NotIn operators.
+	Values []string `yaml:"values" mapstructure:"values"`
+}
+
+type FederationConfig struct {
+	// Enable finds parents in the schedulers of the federated clusters marked by manager,
+	// when no parent is found in the local cluster.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// APIPort is the port of the api server of the federated schedulers.
+	APIPort int `yaml:"apiPort" mapstructure:"apiPort"`
+
+	// Timeout is the timeout of finding parents in the federated schedulers, the schedulers
+	// are requested concurrently and the peer registration waits for the finding at most the timeout.
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+
+	// CandidateParentLimit is the max number of parents offered from the federated clusters.
+	CandidateParentLimit int `yaml:"candidateParentLimit" mapstructure:"candidateParentLimit"`
+
+	// UploadRatePercentThreshold limits the cross-cluster bandwidth, the parents whose upload rate
+	// relative to upload rate limit of the host is past the threshold are not offered, range is 0~100.
+	UploadRatePercentThreshold float64 `yaml:"uploadRatePercentThreshold" mapstructure:"uploadRatePercentThreshold"`
+
+	// CacheTTL is the ttl of the parents found in the federated clusters for the task,
+	// the federated schedulers are requested at most once per ttl for each task.
+	CacheTTL time.Duration `yaml:"cacheTTL" mapstructure:"cacheTTL"`
+
+	// Token authenticates the requests between the federated schedulers, it is shared by the schedulers
+	// of the federated clusters. The api server serves the peers of tasks only when token is set.
+	Token string `yaml:"token" mapstructure:"token"`
+}
+
+type HostLoadConfig struct {
+	// Enable evaluates parents with the load of their hosts, the hosts past any
+	// threshold can not be selected as parents.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// CPUPercentThreshold is the threshold of cpu percent of the host, range is 0~100.
+	CPUPercentThreshold float64 `yaml:"cpuPercentThreshold" mapstructure:"cpuPercentThreshold"`
+
+	// MemoryUsedPercentThreshold is the threshold of memory used percent of the host, range is 0~100.
+	MemoryUsedPercentThreshold float64 `yaml:"memoryUsedPercentThreshold" mapstructure:"memoryUsedPercentThreshold"`
+
+	// DiskUsedPercentThreshold is the threshold of disk used percent on the data path of the host, range is 0~100.
+	DiskUsedPercentThreshold float64 `yaml:"diskUsedPercentThreshold" mapstructure:"diskUsedPercentThreshold"`
+
+	// UploadRatePercentThreshold is the threshold of upload rate relative to upload rate limit of the host, range is 0~100.
+	UploadRatePercentThreshold float64 `yaml:"uploadRatePercentThreshold" mapstructure:"uploadRatePercentThreshold"`
 }
 
 type DatabaseConfig struct {
@@ -183,6 +292,12 @@ type GCConfig struct {
 	// HostTTL is time to live of host. If host announces message to scheduler,
 	// then HostTTl will be reset.
 	HostTTL time.Duration `yaml:"hostTTL" mapstructure:"hostTTL"`
+
+	// PersistentCacheTaskReconcileInterval is interval of reconciling the persistent replicas of
+	// the persistent cache tasks. If the current persistent replica count is less than the desired
+	// persistent replica count, the persistent cache task will be replicated to other hosts. The task being
+	// replicated is locked for the interval, so it is not replicated again by any scheduler in the cluster.
+	PersistentCacheTaskReconcileInterval time.Duration `yaml:"persistentCacheTaskReconcileInterval" mapstructure:"persistentCacheTaskReconcileInterval"`
 }
 
 type DynConfig struct {
@@ -314,6 +429,37 @@ type MetricsConfig struct {
 
 	// Enable host metrics.
 	EnableHost bool `yaml:"enableHost" mapstructure:"enableHost"`
+
+	// Traffic accounting configuration.
+	Traffic TrafficConfig `yaml:"traffic" mapstructure:"traffic"`
+}
+
+type TrafficConfig struct {
+	// MaxApplications is the max number of applications accounted separately,
+	// traffic of the applications beyond the limit is accounted as other application.
+	MaxApplications int `yaml:"maxApplications" mapstructure:"maxApplications"`
+
+	// MaxTags is the max number of tags accounted separately,
+	// traffic of the tags beyond the limit is accounted as other tag.
+	MaxTags int `yaml:"maxTags" mapstructure:"maxTags"`
+
+	// TopTaskCount is the number of tasks ranked by back-to-source traffic.
+	TopTaskCount int `yaml:"topTaskCount" mapstructure:"topTaskCount"`
+
+	// TopTaskWindow is the sliding window for ranking tasks by back-to-source traffic.
+	TopTaskWindow time.Duration `yaml:"topTaskWindow" mapstructure:"topTaskWindow"`
+}
+
+type APIConfig struct {
+	// Enable api service.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// API service address.
+	Addr string `yaml:"addr" mapstructure:"addr"`
+
+	// Token is the bearer token required by the requests of api service. The endpoints
+	// which modify the resources are served only when the token is set.
+	Token string `yaml:"token" mapstructure:"token"`
 }
 
 type NetworkConfig struct {
@@ -339,12 +485,28 @@ func New() *Config {
 			RetryLimit:             DefaultSchedulerRetryLimit,
 			RetryInterval:          DefaultSchedulerRetryInterval,
 			GC: GCConfig{
-				PieceDownloadTimeout: DefaultSchedulerPieceDownloadTimeout,
-				PeerGCInterval:       DefaultSchedulerPeerGCInterval,
-				PeerTTL:              DefaultSchedulerPeerTTL,
-				TaskGCInterval:       DefaultSchedulerTaskGCInterval,
-				HostGCInterval:       DefaultSchedulerHostGCInterval,
-				HostTTL:              DefaultSchedulerHostTTL,
+				PieceDownloadTimeout:                 DefaultSchedulerPieceDownloadTimeout,
+				PeerGCInterval:                       DefaultSchedulerPeerGCInterval,
+				PeerTTL:                              DefaultSchedulerPeerTTL,
+				TaskGCInterval:                       DefaultSchedulerTaskGCInterval,
+				HostGCInterval:                       DefaultSchedulerHostGCInterval,
+				HostTTL:                              DefaultSchedulerHostTTL,
+				PersistentCacheTaskReconcileInterval: DefaultSchedulerPersistentCacheTaskReconcileInterval,
+			},
+			HostLoad: HostLoadConfig{
+				Enable:                     false,
+				CPUPercentThreshold:        DefaultSchedulerHostLoadCPUPercentThreshold,
+				MemoryUsedPercentThreshold: DefaultSchedulerHostLoadMemoryUsedPercentThreshold,
+				DiskUsedPercentThreshold:   DefaultSchedulerHostLoadDiskUsedPercentThreshold,
+				UploadRatePercentThreshold: DefaultSchedulerHostLoadUploadRatePercentThreshold,
+			},
+			Federation: FederationConfig{
+				Enable:                     false,
+				APIPort:                    DefaultSchedulerFederationAPIPort,
+				Timeout:                    DefaultSchedulerFederationTimeout,
+				CandidateParentLimit:       DefaultSchedulerFederationCandidateParentLimit,
+				UploadRatePercentThreshold: DefaultSchedulerFederationUploadRatePercentThreshold,
+				CacheTTL:                   DefaultSchedulerFederationCacheTTL,
 			},
 		},
 		Database: DatabaseConfig{
@@ -382,6 +544,16 @@ func New() *Config {
 			Enable:     false,
 			Addr:       DefaultMetricsAddr,
 			EnableHost: false,
+			Traffic: TrafficConfig{
+				MaxApplications: DefaultMetricsTrafficMaxApplications,
+				MaxTags:         DefaultMetricsTrafficMaxTags,
+				TopTaskCount:    DefaultMetricsTrafficTopTaskCount,
+				TopTaskWindow:   DefaultMetricsTrafficTopTaskWindow,
+			},
+		},
+		API: APIConfig{
+			Enable: false,
+			Addr:   DefaultAPIAddr,
 		},
 		Network: NetworkConfig{
 			EnableIPv6: DefaultNetworkEnableIPv6,
@@ -469,6 +641,90 @@ func (cfg *Config) Validate() error {
 		return errors.New("scheduler requires parameter hostTTL")
 	}
 
+	if cfg.Scheduler.GC.PersistentCacheTaskReconcileInterval <= 0 {
+		return errors.New("scheduler requires parameter persistentCacheTaskReconcileInterval")
+	}
+
+	if cfg.Scheduler.HostLoad.Enable {
+		if cfg.Scheduler.HostLoad.CPUPercentThreshold <= 0 || cfg.Scheduler.HostLoad.CPUPercentThreshold > 100 {
+			return errors.New("scheduler hostLoad requires parameter cpuPercentThreshold")
+		}
+
+		if cfg.Scheduler.HostLoad.MemoryUsedPercentThreshold <= 0 || cfg.Scheduler.HostLoad.MemoryUsedPercentThreshold > 100 {
+			return errors.New("scheduler hostLoad requires parameter memoryUsedPercentThreshold")
+		}
+
+		if cfg.Scheduler.HostLoad.DiskUsedPercentThreshold <= 0 || cfg.Scheduler.HostLoad.DiskUsedPercentThreshold > 100 {
+			return errors.New("scheduler hostLoad requires parameter diskUsedPercentThreshold")
+		}
+
+		if cfg.Scheduler.HostLoad.UploadRatePercentThreshold <= 0 || cfg.Scheduler.HostLoad.UploadRatePercentThreshold > 100 {
+			return errors.New("scheduler hostLoad requires parameter uploadRatePercentThreshold")
+		}
+	}
+
+	if cfg.Scheduler.Federation.Enable {
+		if cfg.Scheduler.Federation.APIPort <= 0 {
+			return errors.New("scheduler federation requires parameter apiPort")
+		}
+
+		if cfg.Scheduler.Federation.Timeout <= 0 {
+			return errors.New("scheduler federation requires parameter timeout")
+		}
+
+		if cfg.Scheduler.Federation.CandidateParentLimit <= 0 {
+			return errors.New("scheduler federation requires parameter candidateParentLimit")
+		}
+
+		if cfg.Scheduler.Federation.UploadRatePercentThreshold <= 0 || cfg.Scheduler.Federation.UploadRatePercentThreshold > 100 {
+			return errors.New("scheduler federation requires parameter uploadRatePercentThreshold")
+		}
+
+		if cfg.Scheduler.Federation.CacheTTL <= 0 {
+			return errors.New("scheduler federation requires parameter cacheTTL")
+		}
+
+		if cfg.Scheduler.Federation.Token == "" {
+			return errors.New("scheduler federation requires parameter token")
+		}
+	}
+
+	for _, filter := range cfg.Scheduler.Plugins.Filters {
+		if filter.Name == "" {
+			return errors.New("scheduler plugins filters requires parameter name")
+		}
+
+		if !filter.Plugin {
+			if len(filter.Rules) == 0 {
+				return fmt.Errorf("scheduler plugins filter %s requires parameter rules", filter.Name)
+			}
+
+			if err := validateLabelRules(filter.Rules); err != nil {
+				return fmt.Errorf("scheduler plugins filter %s: %w", filter.Name, err)
+			}
+		}
+	}
+
+	for _, score := range cfg.Scheduler.Plugins.Scores {
+		if score.Name == "" {
+			return errors.New("scheduler plugins scores requires parameter name")
+		}
+
+		if score.Weight <= 0 {
+			return fmt.Errorf("scheduler plugins score %s requires parameter weight", score.Name)
+		}
+
+		if !score.Plugin {
+			if len(score.Rules) == 0 {
+				return fmt.Errorf("scheduler plugins score %s requires parameter rules", score.Name)
+			}
+
+			if err := validateLabelRules(score.Rules); err != nil {
+				return fmt.Errorf("scheduler plugins score %s: %w", score.Name, err)
+			}
+		}
+	}
+
 	if cfg.Database.Redis.BrokerDB < 0 {
 		return errors.New("redis requires parameter brokerDB")
 	}
@@ -557,6 +813,49 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Metrics.Traffic.MaxApplications <= 0 {
+		return errors.New("metrics traffic requires parameter maxApplications")
+	}
+
+	if cfg.Metrics.Traffic.MaxTags <= 0 {
+		return errors.New("metrics traffic requires parameter maxTags")
+	}
+
+	if cfg.Metrics.Traffic.TopTaskCount <= 0 {
+		return errors.New("metrics traffic requires parameter topTaskCount")
+	}
+
+	if cfg.Metrics.Traffic.TopTaskWindow <= 0 {
+		return errors.New("metrics traffic requires parameter topTaskWindow")
+	}
+
+	if cfg.API.Enable {
+		if cfg.API.Addr == "" {
+			return errors.New("api requires parameter addr")
+		}
+	}
+
+	return nil
+}
+
+// validateLabelRules validates the rules on host labels.
+func validateLabelRules(rules []LabelRule) error {
+	for _, rule := range rules {
+		if rule.Key == "" {
+			return errors.New("rules requires parameter key")
+		}
+
+		switch rule.Operator {
+		case LabelRuleOperatorIn, LabelRuleOperatorNotIn:
+			if len(rule.Values) == 0 {
+				return fmt.Errorf("rule %s requires parameter values", rule.Key)
+			}
+		case LabelRuleOperatorExists, LabelRuleOperatorDoesNotExist:
+		default:
+			return fmt.Errorf("rule %s has invalid operator %s", rule.Key, rule.Operator)
+		}
+	}
+
 	return nil
 }
 
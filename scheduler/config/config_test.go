@@ -45,6 +45,17 @@ var (
 	mockMetricsConfig = MetricsConfig{
 		Enable: true,
 		Addr:   DefaultMetricsAddr,
+		Traffic: TrafficConfig{
+			MaxApplications: DefaultMetricsTrafficMaxApplications,
+			MaxTags:         DefaultMetricsTrafficMaxTags,
+			TopTaskCount:    DefaultMetricsTrafficTopTaskCount,
+			TopTaskWindow:   DefaultMetricsTrafficTopTaskWindow,
+		},
+	}
+
+	mockAPIConfig = APIConfig{
+		Enable: true,
+		Addr:   DefaultAPIAddr,
 	}
 
 	mockRedisConfig = RedisConfig{
@@ -66,12 +77,53 @@ func TestConfig_Load(t *testing.T) {
 			RetryLimit:             10,
 			RetryInterval:          10 * time.Second,
 			GC: GCConfig{
-				PieceDownloadTimeout: 5 * time.Second,
-				PeerGCInterval:       10 * time.Second,
-				PeerTTL:              1 * time.Minute,
-				TaskGCInterval:       30 * time.Second,
-				HostGCInterval:       1 * time.Minute,
-				HostTTL:              1 * time.Minute,
+				PieceDownloadTimeout:                 5 * time.Second,
+				PeerGCInterval:                       10 * time.Second,
+				PeerTTL:                              1 * time.Minute,
+				TaskGCInterval:                       30 * time.Second,
+				HostGCInterval:                       1 * time.Minute,
+				HostTTL:                              1 * time.Minute,
+				PersistentCacheTaskReconcileInterval: 5 * time.Minute,
+			},
+			HostLoad: HostLoadConfig{
+				Enable:                     true,
+				CPUPercentThreshold:        80,
+				MemoryUsedPercentThreshold: 85,
+				DiskUsedPercentThreshold:   90,
+				UploadRatePercentThreshold: 95,
+			},
+			Federation: FederationConfig{
+				Enable:                     true,
+				APIPort:                    8003,
+				Timeout:                    1 * time.Second,
+				CandidateParentLimit:       3,
+				UploadRatePercentThreshold: 70,
+				CacheTTL:                   10 * time.Second,
+				Token:                      "foo",
+			},
+			Plugins: PluginsConfig{
+				Filters: []FilterPluginConfig{
+					{
+						Name: "spot",
+						Rules: []LabelRule{
+							{Key: "lifecycle", Operator: LabelRuleOperatorIn, Values: []string{"spot"}},
+						},
+					},
+					{
+						Name:    "foo",
+						Plugin:  true,
+						Options: map[string]string{"bar": "baz"},
+					},
+				},
+				Scores: []ScorePluginConfig{
+					{
+						Name:   "ssd",
+						Weight: 0.1,
+						Rules: []LabelRule{
+							{Key: "disk", Operator: LabelRuleOperatorExists},
+						},
+					},
+				},
 			},
 		},
 		Server: ServerConfig{
@@ -147,6 +199,17 @@ func TestConfig_Load(t *testing.T) {
 			Enable:     false,
 			Addr:       ":8000",
 			EnableHost: true,
+			Traffic: TrafficConfig{
+				MaxApplications: 10,
+				MaxTags:         10,
+				TopTaskCount:    5,
+				TopTaskWindow:   10 * time.Minute,
+			},
+		},
+		API: APIConfig{
+			Enable: true,
+			Addr:   ":8003",
+			Token:  "foo",
 		},
 		Network: NetworkConfig{
 			EnableIPv6: true,
@@ -473,6 +536,290 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "scheduler requires parameter hostTTL")
 			},
 		},
+		{
+			name:   "scheduler requires parameter persistentCacheTaskReconcileInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.GC.PersistentCacheTaskReconcileInterval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter persistentCacheTaskReconcileInterval")
+			},
+		},
+		{
+			name:   "scheduler hostLoad requires parameter cpuPercentThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.HostLoad.Enable = true
+				cfg.Scheduler.HostLoad.CPUPercentThreshold = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler hostLoad requires parameter cpuPercentThreshold")
+			},
+		},
+		{
+			name:   "scheduler hostLoad requires parameter memoryUsedPercentThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.HostLoad.Enable = true
+				cfg.Scheduler.HostLoad.MemoryUsedPercentThreshold = 101
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler hostLoad requires parameter memoryUsedPercentThreshold")
+			},
+		},
+		{
+			name:   "scheduler hostLoad requires parameter diskUsedPercentThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.HostLoad.Enable = true
+				cfg.Scheduler.HostLoad.DiskUsedPercentThreshold = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler hostLoad requires parameter diskUsedPercentThreshold")
+			},
+		},
+		{
+			name:   "scheduler hostLoad requires parameter uploadRatePercentThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.HostLoad.Enable = true
+				cfg.Scheduler.HostLoad.UploadRatePercentThreshold = 101
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler hostLoad requires parameter uploadRatePercentThreshold")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter apiPort",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+				cfg.Scheduler.Federation.APIPort = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter apiPort")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter timeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+				cfg.Scheduler.Federation.Timeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter timeout")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter candidateParentLimit",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+				cfg.Scheduler.Federation.CandidateParentLimit = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter candidateParentLimit")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter uploadRatePercentThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+				cfg.Scheduler.Federation.UploadRatePercentThreshold = 101
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter uploadRatePercentThreshold")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter cacheTTL",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+				cfg.Scheduler.Federation.CacheTTL = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter cacheTTL")
+			},
+		},
+		{
+			name:   "scheduler federation requires parameter token",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Federation.Enable = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler federation requires parameter token")
+			},
+		},
+		{
+			name:   "scheduler plugins filters requires parameter name",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Filters = []FilterPluginConfig{{Plugin: true}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins filters requires parameter name")
+			},
+		},
+		{
+			name:   "scheduler plugins filter spot requires parameter rules",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Filters = []FilterPluginConfig{{Name: "spot"}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins filter spot requires parameter rules")
+			},
+		},
+		{
+			name:   "scheduler plugins filter spot: rules requires parameter key",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Filters = []FilterPluginConfig{{
+					Name:  "spot",
+					Rules: []LabelRule{{Operator: LabelRuleOperatorExists}},
+				}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins filter spot: rules requires parameter key")
+			},
+		},
+		{
+			name:   "scheduler plugins filter spot: rule lifecycle requires parameter values",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Filters = []FilterPluginConfig{{
+					Name:  "spot",
+					Rules: []LabelRule{{Key: "lifecycle", Operator: LabelRuleOperatorIn}},
+				}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins filter spot: rule lifecycle requires parameter values")
+			},
+		},
+		{
+			name:   "scheduler plugins filter spot: rule lifecycle has invalid operator Equal",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Filters = []FilterPluginConfig{{
+					Name:  "spot",
+					Rules: []LabelRule{{Key: "lifecycle", Operator: "Equal", Values: []string{"spot"}}},
+				}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins filter spot: rule lifecycle has invalid operator Equal")
+			},
+		},
+		{
+			name:   "scheduler plugins scores requires parameter name",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Scores = []ScorePluginConfig{{Plugin: true, Weight: 0.1}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins scores requires parameter name")
+			},
+		},
+		{
+			name:   "scheduler plugins score ssd requires parameter weight",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Scores = []ScorePluginConfig{{Name: "ssd", Plugin: true}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins score ssd requires parameter weight")
+			},
+		},
+		{
+			name:   "scheduler plugins score ssd requires parameter rules",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Plugins.Scores = []ScorePluginConfig{{Name: "ssd", Weight: 0.1}}
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler plugins score ssd requires parameter rules")
+			},
+		},
 		{
 			name:   "dynconfig requires parameter refreshInterval",
 			config: New(),
@@ -746,6 +1093,77 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "metrics requires parameter addr")
 			},
 		},
+		{
+			name:   "metrics traffic requires parameter maxApplications",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Metrics.Traffic.MaxApplications = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "metrics traffic requires parameter maxApplications")
+			},
+		},
+		{
+			name:   "metrics traffic requires parameter maxTags",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Metrics.Traffic.MaxTags = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "metrics traffic requires parameter maxTags")
+			},
+		},
+		{
+			name:   "metrics traffic requires parameter topTaskCount",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Metrics.Traffic.TopTaskCount = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "metrics traffic requires parameter topTaskCount")
+			},
+		},
+		{
+			name:   "metrics traffic requires parameter topTaskWindow",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Metrics.Traffic.TopTaskWindow = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "metrics traffic requires parameter topTaskWindow")
+			},
+		},
+		{
+			name:   "api requires parameter addr",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.API = mockAPIConfig
+				cfg.API.Addr = ""
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "api requires parameter addr")
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -87,6 +87,36 @@ const (
 	// DefaultSchedulerHostTTL is default ttl for host.
 	DefaultSchedulerHostTTL = 1 * time.Hour
 
+	// DefaultSchedulerPersistentCacheTaskReconcileInterval is default interval for reconciling persistent cache tasks.
+	DefaultSchedulerPersistentCacheTaskReconcileInterval = 10 * time.Minute
+
+	// DefaultSchedulerHostLoadCPUPercentThreshold is default threshold of cpu percent of the parent's host.
+	DefaultSchedulerHostLoadCPUPercentThreshold = 90
+
+	// DefaultSchedulerHostLoadMemoryUsedPercentThreshold is default threshold of memory used percent of the parent's host.
+	DefaultSchedulerHostLoadMemoryUsedPercentThreshold = 90
+
+	// DefaultSchedulerHostLoadDiskUsedPercentThreshold is default threshold of disk used percent of the parent's host.
+	DefaultSchedulerHostLoadDiskUsedPercentThreshold = 95
+
+	// DefaultSchedulerHostLoadUploadRatePercentThreshold is default threshold of upload rate percent of the parent's host.
+	DefaultSchedulerHostLoadUploadRatePercentThreshold = 95
+
+	// DefaultSchedulerFederationAPIPort is default port of the api server of the federated schedulers.
+	DefaultSchedulerFederationAPIPort = 8003
+
+	// DefaultSchedulerFederationTimeout is default timeout of finding parents in the federated schedulers.
+	DefaultSchedulerFederationTimeout = 2 * time.Second
+
+	// DefaultSchedulerFederationCandidateParentLimit is default max number of parents offered from the federated clusters.
+	DefaultSchedulerFederationCandidateParentLimit = 2
+
+	// DefaultSchedulerFederationUploadRatePercentThreshold is default threshold of upload rate percent of the federated parents.
+	DefaultSchedulerFederationUploadRatePercentThreshold = 80
+
+	// DefaultSchedulerFederationCacheTTL is default ttl of the parents found in the federated clusters for the task.
+	DefaultSchedulerFederationCacheTTL = 5 * time.Second
+
 	// DefaultRefreshModelInterval is model refresh interval.
 	DefaultRefreshModelInterval = 168 * time.Hour
 
@@ -132,6 +162,23 @@ const (
 const (
 	// DefaultMetricsAddr is default address for metrics server.
 	DefaultMetricsAddr = ":8000"
+
+	// DefaultMetricsTrafficMaxApplications is default max number of applications for traffic accounting.
+	DefaultMetricsTrafficMaxApplications = 100
+
+	// DefaultMetricsTrafficMaxTags is default max number of tags for traffic accounting.
+	DefaultMetricsTrafficMaxTags = 100
+
+	// DefaultMetricsTrafficTopTaskCount is default number of tasks ranked by back-to-source traffic.
+	DefaultMetricsTrafficTopTaskCount = 10
+
+	// DefaultMetricsTrafficTopTaskWindow is default sliding window for ranking tasks by back-to-source traffic.
+	DefaultMetricsTrafficTopTaskWindow = 1 * time.Hour
+)
+
+const (
+	// DefaultAPIAddr is default address for api server.
+	DefaultAPIAddr = ":8003"
 )
 
 var (
@@ -171,3 +218,18 @@ const (
 	// DefaultLogRotateMaxBackups is the default number of old log files to keep.
 	DefaultLogRotateMaxBackups = 20
 )
+
+const (
+	// LabelRuleOperatorIn matches the hosts whose label value is in the values.
+	LabelRuleOperatorIn = "In"
+
+	// LabelRuleOperatorNotIn matches the hosts whose label value is not in the values,
+	// the hosts without the label are matched.
+	LabelRuleOperatorNotIn = "NotIn"
+
+	// LabelRuleOperatorExists matches the hosts with the label.
+	LabelRuleOperatorExists = "Exists"
+
+	// LabelRuleOperatorDoesNotExist matches the hosts without the label.
+	LabelRuleOperatorDoesNotExist = "DoesNotExist"
+)
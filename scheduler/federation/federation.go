@@ -0,0 +1,301 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/federation_mock.go -source federation.go -package mocks
+
+package federation
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/cache"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+)
+
+const (
+	// PeersPath is the path of the succeeded peers of the task in the api server of scheduler.
+	PeersPath = "/api/v1/tasks/{id}/peers"
+
+	// authorizationPrefix is the prefix of the authorization header carrying the federation token.
+	authorizationPrefix = "Bearer "
+)
+
+// Peer is the succeeded peer of the task shared with the federated clusters.
+type Peer struct {
+	// ID is the id of the peer.
+	ID string `json:"id"`
+
+	// FinishedPieceCount is the count of the finished pieces of the peer.
+	FinishedPieceCount uint `json:"finished_piece_count"`
+
+	// Host is the host of the peer.
+	Host Host `json:"host"`
+}
+
+// Host is the host of the peer shared with the federated clusters.
+type Host struct {
+	// ID is the id of the host.
+	ID string `json:"id"`
+
+	// Type is the type of the host.
+	Type string `json:"type"`
+
+	// Hostname is the hostname of the host.
+	Hostname string `json:"hostname"`
+
+	// IP is the ip of the host.
+	IP string `json:"ip"`
+
+	// Port is the grpc port of the host.
+	Port int32 `json:"port"`
+
+	// DownloadPort is the download port of the host.
+	DownloadPort int32 `json:"download_port"`
+
+	// IDC is the idc of the host.
+	IDC string `json:"idc"`
+
+	// Location is the location of the host.
+	Location string `json:"location"`
+
+	// ConcurrentUploadCount is the concurrent upload count of the host.
+	ConcurrentUploadCount int32 `json:"concurrent_upload_count"`
+
+	// ConcurrentUploadLimit is the concurrent upload limit of the host.
+	ConcurrentUploadLimit int32 `json:"concurrent_upload_limit"`
+
+	// UploadRate is the upload rate of the host, unit is byte/s.
+	UploadRate uint64 `json:"upload_rate"`
+
+	// UploadRateLimit is the upload rate limit of the host, unit is byte/s.
+	UploadRateLimit uint64 `json:"upload_rate_limit"`
+}
+
+// NewPeer returns the peer shared with the federated clusters.
+func NewPeer(peer *standard.Peer) Peer {
+	return Peer{
+		ID:                 peer.ID,
+		FinishedPieceCount: peer.FinishedPieces.Count(),
+		Host: Host{
+			ID:                    peer.Host.ID,
+			Type:                  peer.Host.Type.Name(),
+			Hostname:              peer.Host.Hostname,
+			IP:                    peer.Host.IP,
+			Port:                  peer.Host.Port,
+			DownloadPort:          peer.Host.DownloadPort,
+			IDC:                   peer.Host.Network.IDC,
+			Location:              peer.Host.Network.Location,
+			ConcurrentUploadCount: peer.Host.ConcurrentUploadCount.Load(),
+			ConcurrentUploadLimit: peer.Host.ConcurrentUploadLimit.Load(),
+			UploadRate:            peer.Host.Network.UploadRate,
+			UploadRateLimit:       peer.Host.Network.UploadRateLimit,
+		},
+	}
+}
+
+// Federation is an interface for finding parents in the federated clusters.
+type Federation interface {
+	// FindParents finds the succeeded peers of the task in the schedulers of the federated clusters,
+	// the peers past the cross-cluster bandwidth policy are not returned.
+	FindParents(ctx context.Context, taskID string) []Peer
+}
+
+// federation is an implementation of Federation.
+type federation struct {
+	// Federation configuration.
+	config *config.FederationConfig
+
+	// Scheduler dynamic configuration.
+	dynconfig config.DynconfigInterface
+
+	// HTTP client of the api servers of the federated schedulers.
+	client *http.Client
+
+	// parents caches the parents found in the federated clusters by task id.
+	parents cache.Cache
+
+	// group merges the concurrent finding of the same task.
+	group singleflight.Group
+}
+
+// New returns a new Federation.
+func New(cfg *config.FederationConfig, dynconfig config.DynconfigInterface) Federation {
+	return &federation{
+		config:    cfg,
+		dynconfig: dynconfig,
+		client:    &http.Client{},
+		parents:   cache.New(cfg.CacheTTL, cfg.CacheTTL),
+	}
+}
+
+// FindParents finds the succeeded peers of the task in the schedulers of the federated clusters,
+// the peers past the cross-cluster bandwidth policy are not returned. The result is cached for
+// cacheTTL, including the empty one, so federated schedulers are not requested in every scheduling.
+// The finding is shared by the concurrent callers and bounded by the timeout, it is not canceled
+// with the context of the caller, the caller returns no parents when its context is done.
+func (f *federation) FindParents(ctx context.Context, taskID string) []Peer {
+	if parents, ok := f.parents.Get(taskID); ok {
+		return parents.([]Peer)
+	}
+
+	findCtx := context.WithoutCancel(ctx)
+	result := f.group.DoChan(taskID, func() (any, error) {
+		ctx, cancel := context.WithTimeout(findCtx, f.config.Timeout)
+		defer cancel()
+
+		parents, ok := f.findParents(ctx, taskID)
+		if ok {
+			f.parents.SetDefault(taskID, parents)
+		}
+
+		return parents, nil
+	})
+
+	select {
+	case r := <-result:
+		return r.Val.([]Peer)
+	case <-ctx.Done():
+		logger.Warnf("find parents of task %s canceled: %s", taskID, ctx.Err().Error())
+		return nil
+	}
+}
+
+// findParents finds the parents in the federated schedulers, ok is false if the result should not be cached.
+func (f *federation) findParents(ctx context.Context, taskID string) ([]Peer, bool) {
+	schedulerClusterConfig, err := f.dynconfig.GetSchedulerClusterConfig()
+	if err != nil {
+		logger.Warnf("get scheduler cluster config failed: %s", err.Error())
+		return nil, false
+	}
+
+	if !schedulerClusterConfig.Federated || len(schedulerClusterConfig.FederatedSchedulers) == 0 {
+		return nil, true
+	}
+
+	var (
+		parents []Peer
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for _, scheduler := range schedulerClusterConfig.FederatedSchedulers {
+		wg.Add(1)
+		go func(scheduler types.SchedulerClusterFederatedScheduler) {
+			defer wg.Done()
+			peers, err := f.findPeers(ctx, scheduler.IP, taskID)
+			if err != nil {
+				logger.Warnf("find peers of task %s in scheduler %s %s failed: %s", taskID, scheduler.Hostname, scheduler.IP, err.Error())
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, peer := range peers {
+				if f.isAvailable(peer) {
+					parents = append(parents, peer)
+				}
+			}
+		}(scheduler)
+	}
+	wg.Wait()
+
+	// Parents with more free upload count are offered first.
+	sort.Slice(parents, func(i, j int) bool {
+		iFree := parents[i].Host.ConcurrentUploadLimit - parents[i].Host.ConcurrentUploadCount
+		jFree := parents[j].Host.ConcurrentUploadLimit - parents[j].Host.ConcurrentUploadCount
+		if iFree != jFree {
+			return iFree > jFree
+		}
+
+		return parents[i].ID < parents[j].ID
+	})
+
+	if len(parents) > f.config.CandidateParentLimit {
+		parents = parents[:f.config.CandidateParentLimit]
+	}
+
+	// The result of canceled finding is incomplete.
+	return parents, ctx.Err() == nil
+}
+
+// findPeers finds the succeeded peers of the task in the api server of the scheduler.
+func (f *federation) findPeers(ctx context.Context, ip, taskID string) ([]Peer, error) {
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(ip, strconv.Itoa(f.config.APIPort)), strings.Replace(PeersPath, "{id}", taskID, 1))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorizationPrefix+f.config.Token)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Task is not found in the scheduler.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var peers []Peer
+	if err := json.NewDecoder(resp.Body).Decode(&peers); err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
+
+// isAvailable determines whether the peer can be offered as a parent by the cross-cluster bandwidth policy.
+func (f *federation) isAvailable(peer Peer) bool {
+	if peer.Host.ConcurrentUploadLimit-peer.Host.ConcurrentUploadCount <= 0 {
+		return false
+	}
+
+	if peer.Host.UploadRateLimit > 0 &&
+		float64(peer.Host.UploadRate)*100/float64(peer.Host.UploadRateLimit) > f.config.UploadRatePercentThreshold {
+		return false
+	}
+
+	return true
+}
+
+// Authorized determines whether the request carries the bearer token, the token must not be empty.
+func Authorized(r *http.Request, token string) bool {
+	authorization := r.Header.Get("Authorization")
+	if token == "" || !strings.HasPrefix(authorization, authorizationPrefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, authorizationPrefix)), []byte(token)) == 1
+}
@@ -0,0 +1,335 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
+)
+
+var (
+	mockTaskID = "4ba6fd9f3e9aecfd6e8b4ad3d2fe3dc3a8f37ed9d6c7d2c0e9d5c3bd1e5a3c2b"
+
+	mockPeers = []Peer{
+		{
+			ID:                 "foo",
+			FinishedPieceCount: 10,
+			Host:               Host{ID: "foo", IP: "127.0.0.1", ConcurrentUploadCount: 1, ConcurrentUploadLimit: 50},
+		},
+		{
+			ID:                 "bar",
+			FinishedPieceCount: 10,
+			Host:               Host{ID: "bar", IP: "127.0.0.2", ConcurrentUploadCount: 0, ConcurrentUploadLimit: 50},
+		},
+		{
+			ID:                 "baz",
+			FinishedPieceCount: 10,
+			Host:               Host{ID: "baz", IP: "127.0.0.3", ConcurrentUploadCount: 50, ConcurrentUploadLimit: 50},
+		},
+		{
+			ID:                 "bas",
+			FinishedPieceCount: 10,
+			Host:               Host{ID: "bas", IP: "127.0.0.4", ConcurrentUploadLimit: 50, UploadRate: 90, UploadRateLimit: 100},
+		},
+	}
+)
+
+func TestFederation_FindParents(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		mock    func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string)
+		expect  func(t *testing.T, parents []Peer)
+	}{
+		{
+			name: "find parents in federated schedulers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/tasks/"+mockTaskID+"/peers" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				if !Authorized(r, "foo") {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				json.NewEncoder(w).Encode(mockPeers) // nolint: errcheck
+			},
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string) {
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+					Federated:           true,
+					FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: ip, SchedulerClusterID: 2}},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, parents []Peer) {
+				assert := assert.New(t)
+				assert.Len(parents, 2)
+				assert.Equal("bar", parents[0].ID)
+				assert.Equal("foo", parents[1].ID)
+			},
+		},
+		{
+			name: "task not found in federated schedulers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string) {
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+					Federated:           true,
+					FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: ip, SchedulerClusterID: 2}},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, parents []Peer) {
+				assert := assert.New(t)
+				assert.Empty(parents)
+			},
+		},
+		{
+			name: "federated scheduler returns error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string) {
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+					Federated:           true,
+					FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: ip, SchedulerClusterID: 2}},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, parents []Peer) {
+				assert := assert.New(t)
+				assert.Empty(parents)
+			},
+		},
+		{
+			name: "scheduler cluster is not federated",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(mockPeers) // nolint: errcheck
+			},
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string) {
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+					FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: ip, SchedulerClusterID: 2}},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, parents []Peer) {
+				assert := assert.New(t)
+				assert.Empty(parents)
+			},
+		},
+		{
+			name: "get scheduler cluster config failed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(mockPeers) // nolint: errcheck
+			},
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder, ip string) {
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, parents []Peer) {
+				assert := assert.New(t)
+				assert.Empty(parents)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			svr := httptest.NewServer(tc.handler)
+			defer svr.Close()
+
+			host, rawPort, err := net.SplitHostPort(svr.Listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			port, err := strconv.Atoi(rawPort)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			tc.mock(dynconfig.EXPECT(), host)
+
+			f := New(&config.FederationConfig{
+				Enable:                     true,
+				APIPort:                    port,
+				Timeout:                    time.Second,
+				CandidateParentLimit:       2,
+				UploadRatePercentThreshold: 80,
+				CacheTTL:                   time.Minute,
+				Token:                      "foo",
+			}, dynconfig)
+			tc.expect(t, f.FindParents(context.Background(), mockTaskID))
+		})
+	}
+}
+
+func TestFederation_FindParentsCache(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	var requests atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(mockPeers) // nolint: errcheck
+	}))
+	defer svr.Close()
+
+	host, rawPort, err := net.SplitHostPort(svr.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+		Federated:           true,
+		FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: host, SchedulerClusterID: 2}},
+	}, nil).Times(1)
+
+	f := New(&config.FederationConfig{
+		Enable:                     true,
+		APIPort:                    port,
+		Timeout:                    time.Second,
+		CandidateParentLimit:       2,
+		UploadRatePercentThreshold: 80,
+		CacheTTL:                   time.Minute,
+		Token:                      "foo",
+	}, dynconfig)
+
+	assert := assert.New(t)
+	assert.Len(f.FindParents(context.Background(), mockTaskID), 2)
+	assert.Len(f.FindParents(context.Background(), mockTaskID), 2)
+	assert.Equal(int32(1), requests.Load())
+}
+
+func TestFederation_FindParentsWithCanceledContext(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(mockPeers) // nolint: errcheck
+	}))
+	defer svr.Close()
+
+	host, rawPort, err := net.SplitHostPort(svr.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+		Federated:           true,
+		FederatedSchedulers: []types.SchedulerClusterFederatedScheduler{{Hostname: "foo", IP: host, SchedulerClusterID: 2}},
+	}, nil).Times(1)
+
+	f := New(&config.FederationConfig{
+		Enable:                     true,
+		APIPort:                    port,
+		Timeout:                    10 * time.Second,
+		CandidateParentLimit:       2,
+		UploadRatePercentThreshold: 80,
+		CacheTTL:                   time.Minute,
+		Token:                      "foo",
+	}, dynconfig)
+
+	// The caller returns when its context is done, the finding keeps running.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert := assert.New(t)
+	assert.Empty(f.FindParents(ctx, mockTaskID))
+
+	// The finding shared by the next caller is not canceled with the first caller and is cached.
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+	assert.Len(f.FindParents(context.Background(), mockTaskID), 2)
+	assert.Len(f.FindParents(context.Background(), mockTaskID), 2)
+	assert.Equal(int32(1), requests.Load())
+}
+
+func TestFederation_Authorized(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		token         string
+		expect        bool
+	}{
+		{
+			name:          "token matches",
+			authorization: "Bearer foo",
+			token:         "foo",
+			expect:        true,
+		},
+		{
+			name:          "token mismatches",
+			authorization: "Bearer bar",
+			token:         "foo",
+			expect:        false,
+		},
+		{
+			name:   "authorization is empty",
+			token:  "foo",
+			expect: false,
+		},
+		{
+			name:          "token is empty",
+			authorization: "Bearer ",
+			expect:        false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+
+			assert.Equal(t, tc.expect, Authorized(r, tc.token))
+		})
+	}
+}
@@ -46,6 +46,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	resource "d7y.io/dragonfly/v2/scheduler/resource/standard"
 )
 
@@ -56,15 +57,16 @@ type Job interface {
 
 // job is an implementation of Job.
 type job struct {
-	globalJob    *internaljob.Job
-	schedulerJob *internaljob.Job
-	localJob     *internaljob.Job
-	resource     resource.Resource
-	config       *config.Config
+	globalJob               *internaljob.Job
+	schedulerJob            *internaljob.Job
+	localJob                *internaljob.Job
+	resource                resource.Resource
+	persistentCacheResource persistentcache.Resource
+	config                  *config.Config
 }
 
 // New creates a new Job.
-func New(cfg *config.Config, resource resource.Resource) (Job, error) {
+func New(cfg *config.Config, resource resource.Resource, persistentCacheResource persistentcache.Resource) (Job, error) {
 	redisConfig := &internaljob.Config{
 		Addrs:            cfg.Database.Redis.Addrs,
 		MasterName:       cfg.Database.Redis.MasterName,
@@ -104,18 +106,20 @@ func New(cfg *config.Config, resource resource.Resource) (Job, error) {
 	logger.Infof("create local job queue: %v", localQueue)
 
 	t := &job{
-		globalJob:    globalJob,
-		schedulerJob: schedulerJob,
-		localJob:     localJob,
-		resource:     resource,
-		config:       cfg,
+		globalJob:               globalJob,
+		schedulerJob:            schedulerJob,
+		localJob:                localJob,
+		resource:                resource,
+		persistentCacheResource: persistentCacheResource,
+		config:                  cfg,
 	}
 
 	namedJobFuncs := map[string]any{
-		internaljob.PreheatJob:    t.preheat,
-		internaljob.SyncPeersJob:  t.syncPeers,
-		internaljob.GetTaskJob:    t.getTask,
-		internaljob.DeleteTaskJob: t.deleteTask,
+		internaljob.PreheatJob:                  t.preheat,
+		internaljob.SyncPeersJob:                t.syncPeers,
+		internaljob.GetTaskJob:                  t.getTask,
+		internaljob.DeleteTaskJob:               t.deleteTask,
+		internaljob.ListPersistentCacheTasksJob: t.listPersistentCacheTasks,
 	}
 
 	if err := localJob.RegisterJob(namedJobFuncs); err != nil {
@@ -760,3 +764,97 @@ func (j *job) deleteTask(ctx context.Context, data string) (string, error) {
 		SchedulerClusterID: j.config.Manager.SchedulerClusterID,
 	})
 }
+
+// listPersistentCacheTasks is a job to list persistent cache tasks matching the filter by page,
+// the total count and content length are of all matched tasks.
+func (j *job) listPersistentCacheTasks(ctx context.Context, data string) (string, error) {
+	req := &internaljob.ListPersistentCacheTasksRequest{}
+	if err := internaljob.UnmarshalRequest(data, req); err != nil {
+		logger.Errorf("unmarshal request err: %s, request body: %s", err.Error(), data)
+		return "", err
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		logger.Errorf("list persistent cache tasks validate failed: %s", err.Error())
+		return "", err
+	}
+
+	logger.Infof("list persistent cache tasks request: %#v", req)
+	if j.persistentCacheResource == nil {
+		// Do not return error if persistent cache is not enabled, just return empty response.
+		logger.Warn("persistent cache resource is not enabled")
+		return internaljob.MarshalResponse(&internaljob.ListPersistentCacheTasksResponse{
+			SchedulerClusterID: j.config.Manager.SchedulerClusterID,
+		})
+	}
+
+	tasks, err := j.persistentCacheResource.TaskManager().LoadAll(ctx)
+	if err != nil {
+		logger.Errorf("load persistent cache tasks failed: %s", err.Error())
+		return "", err
+	}
+
+	page := internaljob.DefaultListPersistentCacheTasksPage
+	if req.Page > 0 {
+		page = req.Page
+	}
+
+	perPage := internaljob.DefaultListPersistentCacheTasksPerPage
+	if req.PerPage > 0 {
+		perPage = min(req.PerPage, internaljob.MaxListPersistentCacheTasksPerPage)
+	}
+
+	tasks = persistentcache.FilterTasks(tasks, persistentcache.TaskFilter{
+		Application: req.Application,
+		Tag:         req.Tag,
+		State:       req.State,
+		MinAge:      req.MinAge,
+		MaxAge:      req.MaxAge,
+	})
+
+	resp := &internaljob.ListPersistentCacheTasksResponse{
+		SchedulerClusterID: j.config.Manager.SchedulerClusterID,
+		TotalCount:         len(tasks),
+	}
+
+	for _, task := range tasks {
+		resp.TotalContentLength += task.ContentLength
+	}
+
+	// The page beyond the matched tasks is empty, it is checked by division
+	// because the start of a huge page overflows.
+	if page-1 > len(tasks)/perPage {
+		return internaljob.MarshalResponse(resp)
+	}
+
+	start := (page - 1) * perPage
+	end := min(start+perPage, len(tasks))
+	for _, task := range tasks[start:end] {
+		currentPersistentReplicaCount, err := j.persistentCacheResource.TaskManager().LoadCurrentPersistentReplicaCount(ctx, task.ID)
+		if err != nil {
+			task.Log.Errorf("load current persistent replica count failed: %s", err.Error())
+		}
+
+		currentReplicaCount, err := j.persistentCacheResource.TaskManager().LoadCorrentReplicaCount(ctx, task.ID)
+		if err != nil {
+			task.Log.Errorf("load current replica count failed: %s", err.Error())
+		}
+
+		resp.PersistentCacheTasks = append(resp.PersistentCacheTasks, &internaljob.PersistentCacheTask{
+			ID:                            task.ID,
+			Tag:                           task.Tag,
+			Application:                   task.Application,
+			State:                         task.FSM.Current(),
+			PersistentReplicaCount:        task.PersistentReplicaCount,
+			CurrentPersistentReplicaCount: currentPersistentReplicaCount,
+			CurrentReplicaCount:           currentReplicaCount,
+			ContentLength:                 task.ContentLength,
+			TTL:                           task.TTL,
+			Pinned:                        task.Pinned,
+			CreatedAt:                     task.CreatedAt,
+			UpdatedAt:                     task.UpdatedAt,
+		})
+	}
+
+	return internaljob.MarshalResponse(resp)
+}
@@ -266,6 +266,20 @@ var (
 		Help:      "Counter of the number of per host traffic.",
 	}, []string{"type", "task_type", "host_type", "host_id", "host_ip", "host_name"})
 
+	ApplicationTraffic = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "application_traffic",
+		Help:      "Counter of the number of per application traffic.",
+	}, []string{"type", "application", "priority"})
+
+	TagTraffic = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "tag_traffic",
+		Help:      "Counter of the number of per tag traffic.",
+	}, []string{"type", "tag", "priority"})
+
 	DownloadPeerDuration = promauto.NewSummaryVec(prometheus.SummaryOpts{
 		Namespace:  types.MetricsNamespace,
 		Subsystem:  types.SchedulerMetricsName,
@@ -0,0 +1,360 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// OtherApplication is the application of the traffic beyond the application limit.
+	OtherApplication = "other"
+
+	// UnknownApplication is the application of the traffic without application.
+	UnknownApplication = "unknown"
+
+	// OtherTag is the tag of the traffic beyond the tag limit.
+	OtherTag = "other"
+
+	// UnknownTag is the tag of the traffic without tag.
+	UnknownTag = "unknown"
+
+	// trafficBucketCount is the number of buckets in the sliding window of the top tasks.
+	trafficBucketCount = 12
+)
+
+// trafficAccountant is the global traffic accountant, it is replaced by InitTraffic.
+var trafficAccountant = NewTrafficAccountant(&config.TrafficConfig{
+	MaxApplications: config.DefaultMetricsTrafficMaxApplications,
+	MaxTags:         config.DefaultMetricsTrafficMaxTags,
+	TopTaskCount:    config.DefaultMetricsTrafficTopTaskCount,
+	TopTaskWindow:   config.DefaultMetricsTrafficTopTaskWindow,
+})
+
+// InitTraffic initializes the global traffic accountant, it must be called before collecting traffic.
+func InitTraffic(cfg *config.TrafficConfig) {
+	trafficAccountant = NewTrafficAccountant(cfg)
+}
+
+// AddTaskTraffic collects the traffic of the task by application and tag.
+func AddTaskTraffic(trafficType commonv2.TrafficType, application, tag string, priority commonv2.Priority, taskID string, length uint64) {
+	application, tag = trafficAccountant.Add(trafficType, application, tag, taskID, length)
+	ApplicationTraffic.WithLabelValues(trafficType.String(), application, priority.String()).Add(float64(length))
+	TagTraffic.WithLabelValues(trafficType.String(), tag, priority.String()).Add(float64(length))
+}
+
+// GetTrafficSummary returns the traffic summary of the global traffic accountant.
+func GetTrafficSummary() *TrafficSummary {
+	return trafficAccountant.Summary()
+}
+
+// TrafficSummary is the summary of the traffic by application and tag.
+type TrafficSummary struct {
+	// Applications are the traffic of the applications, sorted by application.
+	Applications []ApplicationTrafficSummary `json:"applications"`
+
+	// Tags are the traffic of the tags, sorted by tag.
+	Tags []TagTrafficSummary `json:"tags"`
+
+	// TopTasks are the tasks with the most back-to-source traffic in the sliding window.
+	TopTasks []TaskTrafficSummary `json:"top_tasks"`
+}
+
+// ApplicationTrafficSummary is the traffic summary of the application.
+type ApplicationTrafficSummary struct {
+	// Application is the application of the traffic.
+	Application string `json:"application"`
+
+	// P2PBytes is the bytes downloaded from peers.
+	P2PBytes uint64 `json:"p2p_bytes"`
+
+	// BackToSourceBytes is the bytes downloaded from the source.
+	BackToSourceBytes uint64 `json:"back_to_source_bytes"`
+
+	// HitRatio is the ratio of the bytes downloaded from peers.
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// TagTrafficSummary is the traffic summary of the tag.
+type TagTrafficSummary struct {
+	// Tag is the tag of the traffic.
+	Tag string `json:"tag"`
+
+	// P2PBytes is the bytes downloaded from peers.
+	P2PBytes uint64 `json:"p2p_bytes"`
+
+	// BackToSourceBytes is the bytes downloaded from the source.
+	BackToSourceBytes uint64 `json:"back_to_source_bytes"`
+
+	// HitRatio is the ratio of the bytes downloaded from peers.
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// TaskTrafficSummary is the back-to-source traffic summary of the task.
+type TaskTrafficSummary struct {
+	// TaskID is the id of the task.
+	TaskID string `json:"task_id"`
+
+	// Application is the bounded application of the task.
+	Application string `json:"application"`
+
+	// Tag is the bounded tag of the task.
+	Tag string `json:"tag"`
+
+	// BackToSourceBytes is the bytes downloaded from the source in the sliding window.
+	BackToSourceBytes uint64 `json:"back_to_source_bytes"`
+}
+
+// TrafficAccountant accounts the traffic by application and tag with bounded cardinality,
+// and ranks the tasks by back-to-source traffic in a sliding window.
+type TrafficAccountant struct {
+	// maxApplications is the max number of applications accounted separately.
+	maxApplications int
+
+	// maxTags is the max number of tags accounted separately.
+	maxTags int
+
+	// topTaskCount is the number of tasks ranked by back-to-source traffic.
+	topTaskCount int
+
+	// window is the sliding window of the top tasks.
+	window time.Duration
+
+	// bucketDuration is the duration of each bucket in the sliding window.
+	bucketDuration time.Duration
+
+	// now returns the current time.
+	now func() time.Time
+
+	// mu protects the applications, the tags and the buckets.
+	mu sync.Mutex
+
+	// applications are the traffic of the applications.
+	applications map[string]*traffic
+
+	// tags are the traffic of the tags.
+	tags map[string]*traffic
+
+	// buckets are the back-to-source traffic of the tasks in the sliding window.
+	buckets [trafficBucketCount]trafficBucket
+}
+
+// traffic is the traffic of the application or the tag.
+type traffic struct {
+	p2p          uint64
+	backToSource uint64
+}
+
+// add accounts the traffic by traffic type.
+func (t *traffic) add(trafficType commonv2.TrafficType, length uint64) {
+	if trafficType == commonv2.TrafficType_BACK_TO_SOURCE {
+		t.backToSource += length
+		return
+	}
+
+	t.p2p += length
+}
+
+// hitRatio returns the ratio of the bytes downloaded from peers.
+func (t *traffic) hitRatio() float64 {
+	if total := t.p2p + t.backToSource; total > 0 {
+		return float64(t.p2p) / float64(total)
+	}
+
+	return 0
+}
+
+// trafficBucket is the back-to-source traffic of the tasks in a period of time.
+type trafficBucket struct {
+	start time.Time
+	tasks map[string]*taskTraffic
+}
+
+// taskTraffic is the back-to-source traffic of the task.
+type taskTraffic struct {
+	application  string
+	tag          string
+	backToSource uint64
+}
+
+// NewTrafficAccountant returns a new TrafficAccountant.
+func NewTrafficAccountant(cfg *config.TrafficConfig) *TrafficAccountant {
+	bucketDuration := cfg.TopTaskWindow / trafficBucketCount
+	if bucketDuration <= 0 {
+		bucketDuration = 1
+	}
+
+	return &TrafficAccountant{
+		maxApplications: cfg.MaxApplications,
+		maxTags:         cfg.MaxTags,
+		topTaskCount:    cfg.TopTaskCount,
+		window:          cfg.TopTaskWindow,
+		bucketDuration:  bucketDuration,
+		now:             time.Now,
+		applications:    make(map[string]*traffic),
+		tags:            make(map[string]*traffic),
+	}
+}
+
+// Add accounts the traffic of the task, and returns the bounded application
+// and tag which are used as the metrics labels.
+func (t *TrafficAccountant) Add(trafficType commonv2.TrafficType, application, tag, taskID string, length uint64) (string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	application = boundedName(t.applications, t.maxApplications, application, UnknownApplication, OtherApplication)
+	tag = boundedName(t.tags, t.maxTags, tag, UnknownTag, OtherTag)
+	loadTraffic(t.applications, application).add(trafficType, length)
+	loadTraffic(t.tags, tag).add(trafficType, length)
+	if trafficType != commonv2.TrafficType_BACK_TO_SOURCE {
+		return application, tag
+	}
+
+	bucket := t.bucket(t.now())
+	task, ok := bucket.tasks[taskID]
+	if !ok {
+		task = &taskTraffic{application: application, tag: tag}
+		bucket.tasks[taskID] = task
+	}
+
+	task.backToSource += length
+	return application, tag
+}
+
+// Summary returns the traffic summary of the applications and the top tasks.
+func (t *TrafficAccountant) Summary() *TrafficSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := &TrafficSummary{
+		Applications: []ApplicationTrafficSummary{},
+		Tags:         []TagTrafficSummary{},
+		TopTasks:     []TaskTrafficSummary{},
+	}
+
+	for application, traffic := range t.applications {
+		summary.Applications = append(summary.Applications, ApplicationTrafficSummary{
+			Application:       application,
+			P2PBytes:          traffic.p2p,
+			BackToSourceBytes: traffic.backToSource,
+			HitRatio:          traffic.hitRatio(),
+		})
+	}
+
+	sort.Slice(summary.Applications, func(i, j int) bool {
+		return summary.Applications[i].Application < summary.Applications[j].Application
+	})
+
+	for tag, traffic := range t.tags {
+		summary.Tags = append(summary.Tags, TagTrafficSummary{
+			Tag:               tag,
+			P2PBytes:          traffic.p2p,
+			BackToSourceBytes: traffic.backToSource,
+			HitRatio:          traffic.hitRatio(),
+		})
+	}
+
+	sort.Slice(summary.Tags, func(i, j int) bool {
+		return summary.Tags[i].Tag < summary.Tags[j].Tag
+	})
+
+	// Aggregate the back-to-source traffic of the tasks in the buckets which are in the sliding window.
+	tasks := make(map[string]*TaskTrafficSummary)
+	after := t.now().Add(-t.window)
+	for _, bucket := range t.buckets {
+		if bucket.tasks == nil || !bucket.start.Add(t.bucketDuration).After(after) {
+			continue
+		}
+
+		for taskID, traffic := range bucket.tasks {
+			task, ok := tasks[taskID]
+			if !ok {
+				task = &TaskTrafficSummary{TaskID: taskID, Application: traffic.application, Tag: traffic.tag}
+				tasks[taskID] = task
+			}
+
+			task.BackToSourceBytes += traffic.backToSource
+		}
+	}
+
+	for _, task := range tasks {
+		summary.TopTasks = append(summary.TopTasks, *task)
+	}
+
+	sort.Slice(summary.TopTasks, func(i, j int) bool {
+		if summary.TopTasks[i].BackToSourceBytes != summary.TopTasks[j].BackToSourceBytes {
+			return summary.TopTasks[i].BackToSourceBytes > summary.TopTasks[j].BackToSourceBytes
+		}
+
+		return summary.TopTasks[i].TaskID < summary.TopTasks[j].TaskID
+	})
+
+	if len(summary.TopTasks) > t.topTaskCount {
+		summary.TopTasks = summary.TopTasks[:t.topTaskCount]
+	}
+
+	return summary
+}
+
+// boundedName returns the bounded name of the application or the tag, the names
+// beyond the limit are accounted as the other name.
+func boundedName(traffics map[string]*traffic, limit int, name, unknown, other string) string {
+	if name == "" {
+		name = unknown
+	}
+
+	if _, ok := traffics[name]; ok {
+		return name
+	}
+
+	if len(traffics) >= limit {
+		return other
+	}
+
+	return name
+}
+
+// loadTraffic returns the traffic of the name, it is created if not exists.
+func loadTraffic(traffics map[string]*traffic, name string) *traffic {
+	t, ok := traffics[name]
+	if !ok {
+		t = &traffic{}
+		traffics[name] = t
+	}
+
+	return t
+}
+
+// bucket returns the bucket of the time, the expired bucket is reset.
+func (t *TrafficAccountant) bucket(now time.Time) *trafficBucket {
+	index := now.UnixNano() / int64(t.bucketDuration)
+	start := time.Unix(0, index*int64(t.bucketDuration))
+
+	bucket := &t.buckets[index%trafficBucketCount]
+	if bucket.tasks == nil || !bucket.start.Equal(start) {
+		bucket.start = start
+		bucket.tasks = make(map[string]*taskTraffic)
+	}
+
+	return bucket
+}
@@ -0,0 +1,160 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestTrafficAccountant_Add(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *config.TrafficConfig
+		run    func(t *testing.T, ta *TrafficAccountant)
+		expect func(t *testing.T, summary *TrafficSummary)
+	}{
+		{
+			name: "account traffic by application and tag",
+			cfg:  &config.TrafficConfig{MaxApplications: 10, MaxTags: 10, TopTaskCount: 10, TopTaskWindow: time.Hour},
+			run: func(t *testing.T, ta *TrafficAccountant) {
+				assert := assert.New(t)
+				application, tag := ta.Add(commonv2.TrafficType_REMOTE_PEER, "foo", "baz", "a", 300)
+				assert.Equal("foo", application)
+				assert.Equal("baz", tag)
+				ta.Add(commonv2.TrafficType_LOCAL_PEER, "foo", "baz", "a", 100)
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "a", 100)
+				application, tag = ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "", "", "b", 200)
+				assert.Equal(UnknownApplication, application)
+				assert.Equal(UnknownTag, tag)
+			},
+			expect: func(t *testing.T, summary *TrafficSummary) {
+				assert := assert.New(t)
+				assert.Equal([]ApplicationTrafficSummary{
+					{Application: "foo", P2PBytes: 400, BackToSourceBytes: 100, HitRatio: 0.8},
+					{Application: UnknownApplication, P2PBytes: 0, BackToSourceBytes: 200, HitRatio: 0},
+				}, summary.Applications)
+				assert.Equal([]TagTrafficSummary{
+					{Tag: "baz", P2PBytes: 400, BackToSourceBytes: 100, HitRatio: 0.8},
+					{Tag: UnknownTag, P2PBytes: 0, BackToSourceBytes: 200, HitRatio: 0},
+				}, summary.Tags)
+				assert.Equal([]TaskTrafficSummary{
+					{TaskID: "b", Application: UnknownApplication, Tag: UnknownTag, BackToSourceBytes: 200},
+					{TaskID: "a", Application: "foo", Tag: "baz", BackToSourceBytes: 100},
+				}, summary.TopTasks)
+			},
+		},
+		{
+			name: "account applications beyond the limit as other application",
+			cfg:  &config.TrafficConfig{MaxApplications: 1, MaxTags: 10, TopTaskCount: 10, TopTaskWindow: time.Hour},
+			run: func(t *testing.T, ta *TrafficAccountant) {
+				assert := assert.New(t)
+				for _, tc := range []struct {
+					application string
+					expect      string
+				}{
+					{"foo", "foo"},
+					{"bar", OtherApplication},
+					{"baz", OtherApplication},
+					{"foo", "foo"},
+				} {
+					application, _ := ta.Add(commonv2.TrafficType_REMOTE_PEER, tc.application, "baz", "a", 100)
+					assert.Equal(tc.expect, application)
+				}
+			},
+			expect: func(t *testing.T, summary *TrafficSummary) {
+				assert := assert.New(t)
+				assert.Equal([]ApplicationTrafficSummary{
+					{Application: "foo", P2PBytes: 200, HitRatio: 1},
+					{Application: OtherApplication, P2PBytes: 200, HitRatio: 1},
+				}, summary.Applications)
+				assert.Empty(summary.TopTasks)
+			},
+		},
+		{
+			name: "account tags beyond the limit as other tag",
+			cfg:  &config.TrafficConfig{MaxApplications: 10, MaxTags: 1, TopTaskCount: 10, TopTaskWindow: time.Hour},
+			run: func(t *testing.T, ta *TrafficAccountant) {
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "bar", "a", 100)
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "b", 300)
+			},
+			expect: func(t *testing.T, summary *TrafficSummary) {
+				assert := assert.New(t)
+				assert.Equal([]TagTrafficSummary{
+					{Tag: "bar", BackToSourceBytes: 100, HitRatio: 0},
+					{Tag: OtherTag, BackToSourceBytes: 300, HitRatio: 0},
+				}, summary.Tags)
+				assert.Equal([]TaskTrafficSummary{
+					{TaskID: "b", Application: "foo", Tag: OtherTag, BackToSourceBytes: 300},
+					{TaskID: "a", Application: "foo", Tag: "bar", BackToSourceBytes: 100},
+				}, summary.TopTasks)
+			},
+		},
+		{
+			name: "limit the count of top tasks",
+			cfg:  &config.TrafficConfig{MaxApplications: 10, MaxTags: 10, TopTaskCount: 2, TopTaskWindow: time.Hour},
+			run: func(t *testing.T, ta *TrafficAccountant) {
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "a", 100)
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "b", 300)
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "c", 200)
+			},
+			expect: func(t *testing.T, summary *TrafficSummary) {
+				assert := assert.New(t)
+				assert.Equal([]TaskTrafficSummary{
+					{TaskID: "b", Application: "foo", Tag: "baz", BackToSourceBytes: 300},
+					{TaskID: "c", Application: "foo", Tag: "baz", BackToSourceBytes: 200},
+				}, summary.TopTasks)
+			},
+		},
+		{
+			name: "expire tasks out of the sliding window",
+			cfg:  &config.TrafficConfig{MaxApplications: 10, MaxTags: 10, TopTaskCount: 10, TopTaskWindow: time.Hour},
+			run: func(t *testing.T, ta *TrafficAccountant) {
+				now := time.Now()
+				ta.now = func() time.Time { return now.Add(-2 * time.Hour) }
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "a", 100)
+				ta.now = func() time.Time { return now.Add(-30 * time.Minute) }
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "b", 100)
+				ta.now = func() time.Time { return now }
+				ta.Add(commonv2.TrafficType_BACK_TO_SOURCE, "foo", "baz", "b", 100)
+			},
+			expect: func(t *testing.T, summary *TrafficSummary) {
+				assert := assert.New(t)
+				assert.Equal([]ApplicationTrafficSummary{
+					{Application: "foo", BackToSourceBytes: 300, HitRatio: 0},
+				}, summary.Applications)
+				assert.Equal([]TaskTrafficSummary{
+					{TaskID: "b", Application: "foo", Tag: "baz", BackToSourceBytes: 200},
+				}, summary.TopTasks)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ta := NewTrafficAccountant(tc.cfg)
+			tc.run(t, ta)
+			tc.expect(t, ta.Summary())
+		})
+	}
+}
@@ -60,6 +60,12 @@ type PeerManager interface {
 	// DeleteAllByTaskID deletes all peers by task id.
 	DeleteAllByTaskID(context.Context, string) error
 
+	// DeleteStalePersistentByTaskID deletes the stale persistent peers by task id and returns their ids.
+	DeleteStalePersistentByTaskID(context.Context, string) ([]string, error)
+
+	// RefreshTTLByTask applies the ttl or the pin of the task to its peers.
+	RefreshTTLByTask(context.Context, *Task) error
+
 	// LoadAllByHostID returns all peers by host id.
 	LoadAllByHostID(context.Context, string) ([]*Peer, error)
 
@@ -184,7 +190,26 @@ func (p *peerManager) Store(ctx context.Context, peer *Peer) error {
 		return err
 	}
 
+	// The joint-set with host is shared by the peers of different tasks, so its expiration
+	// is only extended, the peers of the pinned or longer-lived tasks are not dropped from it.
+	hostTTL, err := p.rdb.TTL(ctx, pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(p.config.Manager.SchedulerClusterID, peer.Host.ID)).Result()
+	if err != nil {
+		peer.Log.Errorf("get host joint-set ttl failed: %v", err)
+		return err
+	}
+
+	ttl := peer.Task.RemainingTTL()
 	if _, err := p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Pinned task will not be expired, remove the existing ttl of the key,
+		// otherwise the key expires with the task.
+		expire := func(key string) error {
+			if peer.Task.Pinned {
+				return pipe.Persist(ctx, key).Err()
+			}
+
+			return pipe.Expire(ctx, key, ttl).Err()
+		}
+
 		// Store peer information and set expiration.
 		if _, err := pipe.HSet(ctx,
 			pkgredis.MakePersistentCachePeerKeyInScheduler(p.config.Manager.SchedulerClusterID, peer.ID),
@@ -202,8 +227,7 @@ func (p *peerManager) Store(ctx context.Context, peer *Peer) error {
 			return err
 		}
 
-		ttl := peer.Task.TTL - time.Since(peer.Task.CreatedAt)
-		if _, err := pipe.Expire(ctx, pkgredis.MakePersistentCachePeerKeyInScheduler(p.config.Manager.SchedulerClusterID, peer.ID), ttl).Result(); err != nil {
+		if err := expire(pkgredis.MakePersistentCachePeerKeyInScheduler(p.config.Manager.SchedulerClusterID, peer.ID)); err != nil {
 			peer.Log.Errorf("set peer ttl failed: %v", err)
 			return err
 		}
@@ -214,7 +238,7 @@ func (p *peerManager) Store(ctx context.Context, peer *Peer) error {
 			return err
 		}
 
-		if _, err := pipe.Expire(ctx, pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, peer.Task.ID), ttl).Result(); err != nil {
+		if err := expire(pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, peer.Task.ID)); err != nil {
 			peer.Log.Errorf("set task joint-set ttl failed: %v", err)
 			return err
 		}
@@ -226,7 +250,7 @@ func (p *peerManager) Store(ctx context.Context, peer *Peer) error {
 				return err
 			}
 
-			if _, err := pipe.Expire(ctx, pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, peer.Task.ID), ttl).Result(); err != nil {
+			if err := expire(pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, peer.Task.ID)); err != nil {
 				peer.Log.Errorf("set task joint-set ttl failed: %v", err)
 				return err
 			}
@@ -238,8 +262,13 @@ func (p *peerManager) Store(ctx context.Context, peer *Peer) error {
 			return err
 		}
 
-		if _, err := pipe.Expire(ctx, pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(p.config.Manager.SchedulerClusterID, peer.Host.ID), ttl).Result(); err != nil {
-			peer.Log.Errorf("set task joint-set ttl failed: %v", err)
+		// The joint-set with host has no expiration (-1) if it is persisted by the peer of the pinned task.
+		if !peer.Task.Pinned && (hostTTL == -1 || hostTTL >= ttl) {
+			return nil
+		}
+
+		if err := expire(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(p.config.Manager.SchedulerClusterID, peer.Host.ID)); err != nil {
+			peer.Log.Errorf("set host joint-set ttl failed: %v", err)
 			return err
 		}
 
@@ -405,6 +434,89 @@ func (p *peerManager) DeleteAllByTaskID(ctx context.Context, taskID string) erro
 	return nil
 }
 
+// DeleteStalePersistentByTaskID deletes the stale persistent cache peers by task id and returns their ids. The persistent
+// cache peer is stale when it has been deleted or its host has been reclaimed, but it is still counted as the persistent
+// replica of the task.
+func (p *peerManager) DeleteStalePersistentByTaskID(ctx context.Context, taskID string) ([]string, error) {
+	log := logger.WithTaskID(taskID)
+	peerIDs, err := p.rdb.SMembers(ctx, pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, taskID)).Result()
+	if err != nil {
+		log.Errorf("get persistent peer ids failed: %v", err)
+		return nil, err
+	}
+
+	var stalePeerIDs []string
+	for _, peerID := range peerIDs {
+		hostID, err := p.rdb.HGet(ctx, pkgredis.MakePersistentCachePeerKeyInScheduler(p.config.Manager.SchedulerClusterID, peerID), "host_id").Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			log.Errorf("get host id of peer %s failed: %v", peerID, err)
+			return nil, err
+		}
+
+		// Only the peer whose host is confirmed missing is stale, hostManager.Load can not
+		// tell the missing host from the transient redis error.
+		if err == nil {
+			exists, err := p.rdb.Exists(ctx, pkgredis.MakePersistentCacheHostKeyInScheduler(p.config.Manager.SchedulerClusterID, hostID)).Result()
+			if err != nil {
+				log.Errorf("check host %s of peer %s failed: %v", hostID, peerID, err)
+				return nil, err
+			}
+
+			if exists > 0 {
+				continue
+			}
+		}
+
+		if _, err := p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if _, err := pipe.Del(ctx, pkgredis.MakePersistentCachePeerKeyInScheduler(p.config.Manager.SchedulerClusterID, peerID)).Result(); err != nil {
+				return err
+			}
+
+			if _, err := pipe.SRem(ctx, pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, taskID), peerID).Result(); err != nil {
+				return err
+			}
+
+			if _, err := pipe.SRem(ctx, pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(p.config.Manager.SchedulerClusterID, taskID), peerID).Result(); err != nil {
+				return err
+			}
+
+			if hostID != "" {
+				if _, err := pipe.SRem(ctx, pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(p.config.Manager.SchedulerClusterID, hostID), peerID).Result(); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			log.Errorf("delete stale peer %s failed: %v", peerID, err)
+			return nil, err
+		}
+
+		stalePeerIDs = append(stalePeerIDs, peerID)
+	}
+
+	return stalePeerIDs, nil
+}
+
+// RefreshTTLByTask applies the ttl or the pin of the persistent cache task to its peers, the joint-sets
+// with task and the joint-sets with host, it is called when the ttl of the task is extended or it is pinned.
+func (p *peerManager) RefreshTTLByTask(ctx context.Context, task *Task) error {
+	peers, err := p.LoadAllByTaskID(ctx, task.ID)
+	if err != nil {
+		task.Log.Errorf("load peers failed: %v", err)
+		return err
+	}
+
+	for _, peer := range peers {
+		peer.Task = task
+		if err := p.Store(ctx, peer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LoadAllByHostID returns all persistent cache peers by host id.
 func (p *peerManager) LoadAllByHostID(ctx context.Context, hostID string) ([]*Peer, error) {
 	log := logger.WithHostID(hostID)
@@ -82,6 +82,21 @@ func (mr *MockPeerManagerMockRecorder) DeleteAllByTaskID(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllByTaskID", reflect.TypeOf((*MockPeerManager)(nil).DeleteAllByTaskID), arg0, arg1)
 }
 
+// DeleteStalePersistentByTaskID mocks base method.
+func (m *MockPeerManager) DeleteStalePersistentByTaskID(arg0 context.Context, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStalePersistentByTaskID", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStalePersistentByTaskID indicates an expected call of DeleteStalePersistentByTaskID.
+func (mr *MockPeerManagerMockRecorder) DeleteStalePersistentByTaskID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStalePersistentByTaskID", reflect.TypeOf((*MockPeerManager)(nil).DeleteStalePersistentByTaskID), arg0, arg1)
+}
+
 // Load mocks base method.
 func (m *MockPeerManager) Load(arg0 context.Context, arg1 string) (*Peer, bool) {
 	m.ctrl.T.Helper()
@@ -187,6 +202,20 @@ func (mr *MockPeerManagerMockRecorder) LoadPersistentAllByTaskID(arg0, arg1 any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPersistentAllByTaskID", reflect.TypeOf((*MockPeerManager)(nil).LoadPersistentAllByTaskID), arg0, arg1)
 }
 
+// RefreshTTLByTask mocks base method.
+func (m *MockPeerManager) RefreshTTLByTask(arg0 context.Context, arg1 *Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTTLByTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshTTLByTask indicates an expected call of RefreshTTLByTask.
+func (mr *MockPeerManagerMockRecorder) RefreshTTLByTask(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTTLByTask", reflect.TypeOf((*MockPeerManager)(nil).RefreshTTLByTask), arg0, arg1)
+}
+
 // Store mocks base method.
 func (m *MockPeerManager) Store(arg0 context.Context, arg1 *Peer) error {
 	m.ctrl.T.Helper()
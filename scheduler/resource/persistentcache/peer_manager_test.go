@@ -276,6 +276,7 @@ func TestPeerManager_Store(t *testing.T) {
 					t.Fatalf("failed to marshal block_parents: %v", err)
 				}
 
+				mock.ExpectTTL(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1")).SetVal(-2)
 				mock.ExpectTxPipeline()
 				mock.ExpectHSet(
 					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer"),
@@ -322,6 +323,127 @@ func TestPeerManager_Store(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			name: "store peer of pinned task",
+			args: args{
+				peer: NewPeer(
+					"goodpeer",
+					PeerStateSucceeded,
+					false,
+					bitset.New(2).Set(1),
+					[]string{"parent1", "parent2"},
+					&Task{ID: "task1", TTL: time.Minute, Pinned: true, CreatedAt: time.Now().Add(-2 * time.Minute)},
+					&Host{ID: "host1"},
+					time.Second,
+					time.Now(),
+					time.Now(),
+					logger.WithPeer("host1", "task1", "goodpeer"),
+				),
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				finishedPieces, err := bitset.New(2).Set(1).MarshalBinary()
+				if err != nil {
+					t.Fatalf("failed to marshal bitset: %v", err)
+				}
+
+				blockParents, err := json.Marshal([]string{"parent1", "parent2"})
+				if err != nil {
+					t.Fatalf("failed to marshal block_parents: %v", err)
+				}
+
+				mock.ExpectTTL(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1")).SetVal(time.Hour)
+				mock.ExpectTxPipeline()
+				mock.ExpectHSet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer"),
+					"id", "goodpeer",
+					"persistent", false,
+					"finished_pieces", finishedPieces,
+					"state", PeerStateSucceeded,
+					"block_parents", blockParents,
+					"task_id", "task1",
+					"host_id", "host1",
+					"cost", time.Second.Nanoseconds(),
+					"created_at", time.Now().Format(time.RFC3339),
+					"updated_at", time.Now().Format(time.RFC3339),
+				).SetVal(1)
+				mock.ExpectPersist(pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer")).SetVal(true)
+				mock.ExpectSAdd(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"goodpeer",
+				).SetVal(1)
+				mock.ExpectPersist(pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1")).SetVal(true)
+				mock.ExpectSAdd(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1"),
+					"goodpeer",
+				).SetVal(1)
+				mock.ExpectPersist(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1")).SetVal(true)
+				mock.ExpectTxPipelineExec()
+			},
+			expectedErr: false,
+		},
+		{
+			name: "host joint-set expires later than task",
+			args: args{
+				peer: NewPeer(
+					"goodpeer",
+					PeerStateSucceeded,
+					false,
+					bitset.New(2).Set(1),
+					[]string{"parent1", "parent2"},
+					&Task{ID: "task1", TTL: 4 * time.Minute, CreatedAt: time.Now().Add(1 * time.Second)},
+					&Host{ID: "host1"},
+					time.Second,
+					time.Now(),
+					time.Now(),
+					logger.WithPeer("host1", "task1", "goodpeer"),
+				),
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				finishedPieces, err := bitset.New(2).Set(1).MarshalBinary()
+				if err != nil {
+					t.Fatalf("failed to marshal bitset: %v", err)
+				}
+
+				blockParents, err := json.Marshal([]string{"parent1", "parent2"})
+				if err != nil {
+					t.Fatalf("failed to marshal block_parents: %v", err)
+				}
+
+				mock.ExpectTTL(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1")).SetVal(-1)
+				mock.ExpectTxPipeline()
+				mock.ExpectHSet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer"),
+					"id", "goodpeer",
+					"persistent", false,
+					"finished_pieces", finishedPieces,
+					"state", PeerStateSucceeded,
+					"block_parents", blockParents,
+					"task_id", "task1",
+					"host_id", "host1",
+					"cost", time.Second.Nanoseconds(),
+					"created_at", time.Now().Format(time.RFC3339),
+					"updated_at", time.Now().Format(time.RFC3339),
+				).SetVal(1)
+				mock.ExpectExpire(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer"),
+					4*time.Minute,
+				).SetVal(true)
+				mock.ExpectSAdd(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"goodpeer",
+				).SetVal(1)
+				mock.ExpectExpire(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					4*time.Minute,
+				).SetVal(true)
+				mock.ExpectSAdd(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1"),
+					"goodpeer",
+				).SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+			expectedErr: false,
+		},
 		{
 			name: "redis transaction error",
 			args: args{
@@ -350,6 +472,7 @@ func TestPeerManager_Store(t *testing.T) {
 					t.Fatalf("failed to marshal block_parents: %v", err)
 				}
 
+				mock.ExpectTTL(pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "host1")).SetVal(-2)
 				mock.ExpectTxPipeline()
 				mock.ExpectHSet(
 					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "goodpeer"),
@@ -1264,6 +1387,193 @@ func TestPeerManager_DeleteAllByTaskID(t *testing.T) {
 	}
 }
 
+func TestPeerManager_DeleteStalePersistentByTaskID(t *testing.T) {
+	type args struct {
+		taskID string
+	}
+
+	tests := []struct {
+		name            string
+		args            args
+		mock            func(mockHostManager *MockHostManagerMockRecorder)
+		mockRedis       func(mock redismock.ClientMock)
+		expectedPeerIDs []string
+		expectedErr     bool
+	}{
+		{
+			name: "load persistent peers error",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetErr(errors.New("redis error"))
+			},
+			expectedErr: true,
+		},
+		{
+			name: "host of peer is alive",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetVal([]string{"peer1"})
+				mock.ExpectHGet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+					"host_id",
+				).SetVal("127.0.0.1-foo")
+				mock.ExpectExists(
+					pkgredis.MakePersistentCacheHostKeyInScheduler(42, "127.0.0.1-foo"),
+				).SetVal(1)
+			},
+			expectedErr: false,
+		},
+		{
+			name: "check host of peer error",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetVal([]string{"peer1"})
+				mock.ExpectHGet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+					"host_id",
+				).SetVal("127.0.0.1-foo")
+				mock.ExpectExists(
+					pkgredis.MakePersistentCacheHostKeyInScheduler(42, "127.0.0.1-foo"),
+				).SetErr(errors.New("redis error"))
+			},
+			expectedErr: true,
+		},
+		{
+			name: "host of peer has been reclaimed",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetVal([]string{"peer1"})
+				mock.ExpectHGet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+					"host_id",
+				).SetVal("127.0.0.1-foo")
+				mock.ExpectExists(
+					pkgredis.MakePersistentCacheHostKeyInScheduler(42, "127.0.0.1-foo"),
+				).SetVal(0)
+				mock.ExpectTxPipeline()
+				mock.ExpectDel(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+				).SetVal(1)
+				mock.ExpectSRem(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"peer1",
+				).SetVal(1)
+				mock.ExpectSRem(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"peer1",
+				).SetVal(1)
+				mock.ExpectSRem(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheHostInScheduler(42, "127.0.0.1-foo"),
+					"peer1",
+				).SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+			expectedPeerIDs: []string{"peer1"},
+			expectedErr:     false,
+		},
+		{
+			name: "peer has been deleted",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetVal([]string{"peer1"})
+				mock.ExpectHGet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+					"host_id",
+				).RedisNil()
+				mock.ExpectTxPipeline()
+				mock.ExpectDel(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+				).SetVal(0)
+				mock.ExpectSRem(
+					pkgredis.MakePersistentCachePeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"peer1",
+				).SetVal(1)
+				mock.ExpectSRem(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+					"peer1",
+				).SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+			expectedPeerIDs: []string{"peer1"},
+			expectedErr:     false,
+		},
+		{
+			name: "get host id of peer error",
+			args: args{
+				taskID: "task1",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers(
+					pkgredis.MakePersistentPeersOfPersistentCacheTaskInScheduler(42, "task1"),
+				).SetVal([]string{"peer1"})
+				mock.ExpectHGet(
+					pkgredis.MakePersistentCachePeerKeyInScheduler(42, "peer1"),
+					"host_id",
+				).SetErr(errors.New("redis error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rdb, mock := redismock.NewClientMock()
+			tt.mockRedis(mock)
+
+			hostManager := NewMockHostManager(ctrl)
+			if tt.mock != nil {
+				tt.mock(hostManager.EXPECT())
+			}
+
+			pm := &peerManager{
+				config: &config.Config{
+					Manager: config.ManagerConfig{
+						SchedulerClusterID: 42,
+					},
+				},
+				rdb:         rdb,
+				hostManager: hostManager,
+				taskManager: NewMockTaskManager(ctrl),
+			}
+
+			peerIDs, err := pm.DeleteStalePersistentByTaskID(context.Background(), tt.args.taskID)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPeerIDs, peerIDs)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet redis expectations: %v", err)
+			}
+		})
+	}
+}
+
 func TestPeerManager_LoadAllByHostID(t *testing.T) {
 	type args struct {
 		hostID string
@@ -35,6 +35,12 @@ const (
 	EmptyFileSize = 0
 )
 
+const (
+	// PinnedTTL is the ttl of the pinned task announced to the hosts, the pinned
+	// task is kept on the hosts until it is unpinned.
+	PinnedTTL = 100 * 365 * 24 * time.Hour
+)
+
 const (
 	// Task has been created but did not start uploading.
 	TaskStatePending = "Pending"
@@ -91,6 +97,10 @@ type Task struct {
 	// TTL is persistent cache task time to live.
 	TTL time.Duration
 
+	// Pinned is whether the persistent cache task is pinned, the pinned
+	// persistent cache task will not be expired by TTL.
+	Pinned bool
+
 	// CreatedAt is persistent cache task create time.
 	CreatedAt time.Time
 
@@ -144,6 +154,20 @@ func NewTask(id, tag, application, state string, persistentReplicaCount, pieceLe
 	return t
 }
 
+// RemainingTTL returns the time to live left of the task, the ttl is counted from its creation.
+func (t *Task) RemainingTTL() time.Duration {
+	return t.TTL - time.Since(t.CreatedAt)
+}
+
+// HostTTL returns the ttl of the task announced to the hosts.
+func (t *Task) HostTTL() time.Duration {
+	if t.Pinned {
+		return PinnedTTL
+	}
+
+	return t.TTL
+}
+
 // SizeScope return task size scope type.
 func (t *Task) SizeScope() commonv2.SizeScope {
 	if t.ContentLength < 0 {
@@ -0,0 +1,82 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package persistentcache
+
+import (
+	"sort"
+	"time"
+)
+
+// TaskFilter is the filter of the persistent cache tasks, the empty fields are ignored.
+type TaskFilter struct {
+	// Application is the application of the persistent cache task.
+	Application string
+
+	// Tag is the tag of the persistent cache task.
+	Tag string
+
+	// State is the state of the persistent cache task.
+	State string
+
+	// MinAge is the minimum age of the persistent cache task since its creation.
+	MinAge time.Duration
+
+	// MaxAge is the maximum age of the persistent cache task since its creation.
+	MaxAge time.Duration
+}
+
+// Match returns whether the persistent cache task matches the filter.
+func (f TaskFilter) Match(task *Task) bool {
+	if f.Application != "" && task.Application != f.Application {
+		return false
+	}
+
+	if f.Tag != "" && task.Tag != f.Tag {
+		return false
+	}
+
+	if f.State != "" && !task.FSM.Is(f.State) {
+		return false
+	}
+
+	age := time.Since(task.CreatedAt)
+	if f.MinAge > 0 && age < f.MinAge {
+		return false
+	}
+
+	if f.MaxAge > 0 && age > f.MaxAge {
+		return false
+	}
+
+	return true
+}
+
+// FilterTasks returns the persistent cache tasks matching the filter, the newest task is the first.
+func FilterTasks(tasks []*Task, filter TaskFilter) []*Task {
+	var matchedTasks []*Task
+	for _, task := range tasks {
+		if filter.Match(task) {
+			matchedTasks = append(matchedTasks, task)
+		}
+	}
+
+	sort.SliceStable(matchedTasks, func(i, j int) bool {
+		return matchedTasks[i].CreatedAt.After(matchedTasks[j].CreatedAt)
+	})
+
+	return matchedTasks
+}
@@ -0,0 +1,149 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package persistentcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+func TestTaskFilter_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		expect func(t *testing.T, matched bool)
+	}{
+		{
+			name:   "empty filter",
+			filter: TaskFilter{},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.True(matched)
+			},
+		},
+		{
+			name:   "match all fields",
+			filter: TaskFilter{Application: "foo", Tag: "bar", State: TaskStateSucceeded, MinAge: time.Minute, MaxAge: 2 * time.Hour},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.True(matched)
+			},
+		},
+		{
+			name:   "application does not match",
+			filter: TaskFilter{Application: "baz"},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.False(matched)
+			},
+		},
+		{
+			name:   "tag does not match",
+			filter: TaskFilter{Tag: "baz"},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.False(matched)
+			},
+		},
+		{
+			name:   "state does not match",
+			filter: TaskFilter{State: TaskStateFailed},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.False(matched)
+			},
+		},
+		{
+			name:   "task is younger than min age",
+			filter: TaskFilter{MinAge: 2 * time.Hour},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.False(matched)
+			},
+		},
+		{
+			name:   "task is older than max age",
+			filter: TaskFilter{MaxAge: time.Minute},
+			expect: func(t *testing.T, matched bool) {
+				assert := assert.New(t)
+				assert.False(matched)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask("task-1", "bar", "foo", TaskStateSucceeded, 1, 1024, 1024, 1, time.Hour, time.Now().Add(-time.Hour), time.Now(), logger.WithTaskID("task-1"))
+			tc.expect(t, tc.filter.Match(task))
+		})
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	now := time.Now()
+	tasks := []*Task{
+		NewTask("task-1", "bar", "foo", TaskStateSucceeded, 1, 1024, 1024, 1, time.Hour, now.Add(-3*time.Hour), now, logger.WithTaskID("task-1")),
+		NewTask("task-2", "bar", "baz", TaskStateSucceeded, 1, 1024, 1024, 1, time.Hour, now.Add(-time.Hour), now, logger.WithTaskID("task-2")),
+		NewTask("task-3", "bar", "foo", TaskStateSucceeded, 1, 1024, 1024, 1, time.Hour, now.Add(-2*time.Hour), now, logger.WithTaskID("task-3")),
+	}
+
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		expect func(t *testing.T, tasks []*Task)
+	}{
+		{
+			name:   "filter tasks by application",
+			filter: TaskFilter{Application: "foo"},
+			expect: func(t *testing.T, tasks []*Task) {
+				assert := assert.New(t)
+				assert.Len(tasks, 2)
+				assert.Equal("task-3", tasks[0].ID)
+				assert.Equal("task-1", tasks[1].ID)
+			},
+		},
+		{
+			name:   "filter all tasks",
+			filter: TaskFilter{},
+			expect: func(t *testing.T, tasks []*Task) {
+				assert := assert.New(t)
+				assert.Len(tasks, 3)
+				assert.Equal("task-2", tasks[0].ID)
+				assert.Equal("task-3", tasks[1].ID)
+				assert.Equal("task-1", tasks[2].ID)
+			},
+		},
+		{
+			name:   "no tasks match",
+			filter: TaskFilter{Tag: "foo"},
+			expect: func(t *testing.T, tasks []*Task) {
+				assert := assert.New(t)
+				assert.Empty(tasks)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, FilterTasks(tasks, tc.filter))
+		})
+	}
+}
@@ -49,6 +49,10 @@ type TaskManager interface {
 
 	// LoadAll returns all persistent cache tasks.
 	LoadAll(context.Context) ([]*Task, error)
+
+	// LockReplication locks the replication of the persistent cache task for the ttl,
+	// it returns false if the replication has been locked.
+	LockReplication(context.Context, string, time.Duration) (bool, error)
 }
 
 // taskManager contains content for persistent cache task manager.
@@ -110,6 +114,16 @@ func (t *taskManager) Load(ctx context.Context, taskID string) (*Task, bool) {
 		return nil, false
 	}
 
+	// The pinned field is missing if the task is stored before it is pinned.
+	var pinned bool
+	if rawPinned, ok := rawTask["pinned"]; ok {
+		pinned, err = strconv.ParseBool(rawPinned)
+		if err != nil {
+			log.Errorf("parsing pinned failed: %v", err)
+			return nil, false
+		}
+	}
+
 	createdAt, err := time.Parse(time.RFC3339, rawTask["created_at"])
 	if err != nil {
 		log.Errorf("parsing created at failed: %v", err)
@@ -122,7 +136,7 @@ func (t *taskManager) Load(ctx context.Context, taskID string) (*Task, bool) {
 		return nil, false
 	}
 
-	return NewTask(
+	task := NewTask(
 		rawTask["id"],
 		rawTask["tag"],
 		rawTask["application"],
@@ -135,7 +149,10 @@ func (t *taskManager) Load(ctx context.Context, taskID string) (*Task, bool) {
 		createdAt,
 		updatedAt,
 		logger.WithTaskID(rawTask["id"]),
-	), true
+	)
+	task.Pinned = pinned
+
+	return task, true
 }
 
 // LoadCorrentReplicaCount returns current replica count of the persistent cache task.
@@ -164,14 +181,24 @@ func (t *taskManager) Store(ctx context.Context, task *Task) error {
 			"total_piece_count", task.TotalPieceCount,
 			"state", task.FSM.Current(),
 			"ttl", task.TTL.Nanoseconds(),
+			"pinned", task.Pinned,
 			"created_at", task.CreatedAt.Format(time.RFC3339),
 			"updated_at", task.UpdatedAt.Format(time.RFC3339)).Result(); err != nil {
 			task.Log.Errorf("store task failed: %v", err)
 			return err
 		}
 
-		ttl := task.TTL - time.Since(task.CreatedAt)
-		if _, err := pipe.Expire(ctx, pkgredis.MakePersistentCacheTaskKeyInScheduler(t.config.Manager.SchedulerClusterID, task.ID), ttl).Result(); err != nil {
+		// Pinned task will not be expired, remove the existing ttl of the task.
+		if task.Pinned {
+			if _, err := pipe.Persist(ctx, pkgredis.MakePersistentCacheTaskKeyInScheduler(t.config.Manager.SchedulerClusterID, task.ID)).Result(); err != nil {
+				task.Log.Errorf("persist task failed: %v", err)
+				return err
+			}
+
+			return nil
+		}
+
+		if _, err := pipe.Expire(ctx, pkgredis.MakePersistentCacheTaskKeyInScheduler(t.config.Manager.SchedulerClusterID, task.ID), task.RemainingTTL()).Result(); err != nil {
 			task.Log.Errorf("set task ttl failed: %v", err)
 			return err
 		}
@@ -227,3 +254,10 @@ func (t *taskManager) LoadAll(ctx context.Context) ([]*Task, error) {
 
 	return tasks, nil
 }
+
+// LockReplication locks the replication of the persistent cache task for the ttl, it returns false if the
+// replication has been locked. The lock is shared by the schedulers in the cluster and is not released
+// after replicating, it expires after the ttl to wait for the in-flight replications to be counted.
+func (t *taskManager) LockReplication(ctx context.Context, taskID string, ttl time.Duration) (bool, error) {
+	return t.rdb.SetNX(ctx, pkgredis.MakePersistentCacheTaskReplicationLockKeyInScheduler(t.config.Manager.SchedulerClusterID, taskID), t.config.Server.Host, ttl).Result()
+}
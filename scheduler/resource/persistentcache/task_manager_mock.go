@@ -12,6 +12,7 @@ package persistentcache
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -114,6 +115,21 @@ func (mr *MockTaskManagerMockRecorder) LoadCurrentPersistentReplicaCount(arg0, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCurrentPersistentReplicaCount", reflect.TypeOf((*MockTaskManager)(nil).LoadCurrentPersistentReplicaCount), arg0, arg1)
 }
 
+// LockReplication mocks base method.
+func (m *MockTaskManager) LockReplication(arg0 context.Context, arg1 string, arg2 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockReplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockReplication indicates an expected call of LockReplication.
+func (mr *MockTaskManagerMockRecorder) LockReplication(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockReplication", reflect.TypeOf((*MockTaskManager)(nil).LockReplication), arg0, arg1, arg2)
+}
+
 // Store mocks base method.
 func (m *MockTaskManager) Store(arg0 context.Context, arg1 *Task) error {
 	m.ctrl.T.Helper()
@@ -143,6 +143,58 @@ func TestTaskManager_Load(t *testing.T) {
 			expectedTask:   NewTask("goodtask", "tag_value", "app_value", TaskStateSucceeded, 2, 1024, 2048, 2, 5*time.Minute, time.Now(), time.Now(), logger.WithTaskID("goodtask")),
 			expectedLoaded: true,
 		},
+		{
+			name: "parsing error on pinned",
+			args: args{
+				taskID: "badpinned",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(
+					pkgredis.MakePersistentCacheTaskKeyInScheduler(42, "badpinned"),
+				).SetVal(map[string]string{
+					"id":                       "badpinned",
+					"persistent_replica_count": "2",
+					"piece_length":             "1024",
+					"content_length":           "2048",
+					"total_piece_count":        "2",
+					"ttl":                      strconv.FormatInt((time.Second * 300).Nanoseconds(), 10),
+					"pinned":                   "x",
+				})
+			},
+			expectedTask:   nil,
+			expectedLoaded: false,
+		},
+		{
+			name: "successful load with pinned",
+			args: args{
+				taskID: "pinnedtask",
+			},
+			mockRedis: func(mock redismock.ClientMock) {
+				mockData := map[string]string{
+					"id":                       "pinnedtask",
+					"tag":                      "tag_value",
+					"application":              "app_value",
+					"state":                    TaskStateSucceeded,
+					"persistent_replica_count": "2",
+					"piece_length":             "1024",
+					"content_length":           "2048",
+					"total_piece_count":        "2",
+					"ttl":                      strconv.FormatInt((time.Second * 300).Nanoseconds(), 10),
+					"pinned":                   "true",
+					"created_at":               time.Now().Format(time.RFC3339),
+					"updated_at":               time.Now().Format(time.RFC3339),
+				}
+				mock.ExpectHGetAll(
+					pkgredis.MakePersistentCacheTaskKeyInScheduler(42, "pinnedtask"),
+				).SetVal(mockData)
+			},
+			expectedTask: func() *Task {
+				task := NewTask("pinnedtask", "tag_value", "app_value", TaskStateSucceeded, 2, 1024, 2048, 2, 5*time.Minute, time.Now(), time.Now(), logger.WithTaskID("pinnedtask"))
+				task.Pinned = true
+				return task
+			}(),
+			expectedLoaded: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +224,7 @@ func TestTaskManager_Load(t *testing.T) {
 				assert.Equal(t, tt.expectedTask.ContentLength, got.ContentLength)
 				assert.Equal(t, tt.expectedTask.TotalPieceCount, got.TotalPieceCount)
 				assert.Equal(t, tt.expectedTask.FSM.Current(), got.FSM.Current())
+				assert.Equal(t, tt.expectedTask.Pinned, got.Pinned)
 			} else {
 				assert.Nil(t, got)
 			}
@@ -225,6 +278,7 @@ func TestTaskManager_Store(t *testing.T) {
 					"total_piece_count", task.TotalPieceCount,
 					"state", task.FSM.Current(),
 					"ttl", task.TTL.Nanoseconds(),
+					"pinned", task.Pinned,
 					"created_at", task.CreatedAt.Format(time.RFC3339),
 					"updated_at", task.UpdatedAt.Format(time.RFC3339),
 				).SetVal(int64(1))
@@ -236,6 +290,52 @@ func TestTaskManager_Store(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			name: "store pinned success",
+			args: args{
+				task: func() *Task {
+					task := NewTask(
+						"store-pinned-success",
+						"test-tag",
+						"test-app",
+						TaskStateSucceeded,
+						1,
+						1024,
+						2048,
+						2,
+						5*time.Minute,
+						time.Now().Add(-1*time.Minute),
+						time.Now(),
+						logger.WithTaskID("store-pinned-success"),
+					)
+					task.Pinned = true
+					return task
+				}(),
+			},
+			mockRedis: func(mock redismock.ClientMock, task *Task) {
+				mock.ExpectTxPipeline()
+				mock.ExpectHSet(
+					pkgredis.MakePersistentCacheTaskKeyInScheduler(42, task.ID),
+					"id", task.ID,
+					"persistent_replica_count", task.PersistentReplicaCount,
+					"tag", task.Tag,
+					"application", task.Application,
+					"piece_length", task.PieceLength,
+					"content_length", task.ContentLength,
+					"total_piece_count", task.TotalPieceCount,
+					"state", task.FSM.Current(),
+					"ttl", task.TTL.Nanoseconds(),
+					"pinned", task.Pinned,
+					"created_at", task.CreatedAt.Format(time.RFC3339),
+					"updated_at", task.UpdatedAt.Format(time.RFC3339),
+				).SetVal(int64(1))
+				mock.ExpectPersist(
+					pkgredis.MakePersistentCacheTaskKeyInScheduler(42, task.ID),
+				).SetVal(true)
+				mock.ExpectTxPipelineExec()
+			},
+			expectedErr: false,
+		},
 		{
 			name: "hset error",
 			args: args{
@@ -267,6 +367,7 @@ func TestTaskManager_Store(t *testing.T) {
 					"total_piece_count", task.TotalPieceCount,
 					"state", task.FSM.Current(),
 					"ttl", task.TTL.Nanoseconds(),
+					"pinned", task.Pinned,
 					"created_at", task.CreatedAt.Format(time.RFC3339),
 					"updated_at", task.UpdatedAt.Format(time.RFC3339),
 				).SetErr(errors.New("hset error"))
@@ -304,6 +405,7 @@ func TestTaskManager_Store(t *testing.T) {
 					"total_piece_count", task.TotalPieceCount,
 					"state", task.FSM.Current(),
 					"ttl", task.TTL.Nanoseconds(),
+					"pinned", task.Pinned,
 					"created_at", task.CreatedAt.Format(time.RFC3339),
 					"updated_at", task.UpdatedAt.Format(time.RFC3339),
 				).SetVal(int64(1))
@@ -561,3 +663,57 @@ func TestTaskManager_LoadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskManager_LockReplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockRedis   func(mock redismock.ClientMock)
+		expectedOK  bool
+		expectedErr bool
+	}{
+		{
+			name: "lock replication",
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(pkgredis.MakePersistentCacheTaskReplicationLockKeyInScheduler(42, "foo"), "127.0.0.1", time.Minute).SetVal(true)
+			},
+			expectedOK:  true,
+			expectedErr: false,
+		},
+		{
+			name: "replication has been locked",
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(pkgredis.MakePersistentCacheTaskReplicationLockKeyInScheduler(42, "foo"), "127.0.0.1", time.Minute).SetVal(false)
+			},
+			expectedOK:  false,
+			expectedErr: false,
+		},
+		{
+			name: "redis error",
+			mockRedis: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(pkgredis.MakePersistentCacheTaskReplicationLockKeyInScheduler(42, "foo"), "127.0.0.1", time.Minute).SetErr(errors.New("redis error"))
+			},
+			expectedOK:  false,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb, mock := redismock.NewClientMock()
+			tt.mockRedis(mock)
+
+			tm := &taskManager{
+				config: &config.Config{
+					Server:  config.ServerConfig{Host: "127.0.0.1"},
+					Manager: config.ManagerConfig{SchedulerClusterID: 42},
+				},
+				rdb: rdb,
+			}
+
+			ok, err := tm.LockReplication(context.Background(), "foo", time.Minute)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedErr, err != nil, "error mismatch")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -168,3 +168,13 @@ func TestTask_SizeScope(t *testing.T) {
 		})
 	}
 }
+
+func TestTask_HostTTL(t *testing.T) {
+	task := NewTask("task-1", "tag-1", "app-1", TaskStateSucceeded, 3, 1024, 1024, 1, time.Hour, time.Now(), time.Now(), logger.WithTaskID("task-1"))
+
+	assert := assert.New(t)
+	assert.Equal(time.Hour, task.HostTTL())
+
+	task.Pinned = true
+	assert.Equal(PinnedTTL, task.HostTTL())
+}
@@ -136,6 +136,13 @@ func WithAnnounceInterval(announceInterval time.Duration) HostOption {
 	}
 }
 
+// WithLabels sets host's custom labels.
+func WithLabels(labels map[string]string) HostOption {
+	return func(h *Host) {
+		h.StoreLabels(labels)
+	}
+}
+
 // Host contains content for host.
 type Host struct {
 	// ID is host id.
@@ -199,6 +206,10 @@ type Host struct {
 	// AnnounceInterval is the interval between host announces to scheduler.
 	AnnounceInterval time.Duration
 
+	// labels are the custom labels announced by host, the filter and score plugins
+	// of scheduling match the rules on them while the host announces again.
+	labels atomic.Pointer[map[string]string]
+
 	// ConcurrentUploadLimit is concurrent upload limit count.
 	ConcurrentUploadLimit *atomic.Int32
 
@@ -453,6 +464,20 @@ func (h *Host) LeavePeers() {
 	})
 }
 
+// Labels returns the custom labels announced by host, the returned labels must not be modified.
+func (h *Host) Labels() map[string]string {
+	if labels := h.labels.Load(); labels != nil {
+		return *labels
+	}
+
+	return nil
+}
+
+// StoreLabels replaces the custom labels announced by host.
+func (h *Host) StoreLabels(labels map[string]string) {
+	h.labels.Store(&labels)
+}
+
 // FreeUploadCount return free upload count of host.
 func (h *Host) FreeUploadCount() int32 {
 	return h.ConcurrentUploadLimit.Load() - h.ConcurrentUploadCount.Load()
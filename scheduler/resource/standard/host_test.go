@@ -566,6 +566,27 @@ func TestHost_NewHost(t *testing.T) {
 				assert.NotNil(host.Log)
 			},
 		},
+		{
+			name:    "new host and set labels",
+			rawHost: mockRawHost,
+			options: []HostOption{WithLabels(map[string]string{"lifecycle": "spot"})},
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				assert.Equal(host.ID, mockRawHost.ID)
+				assert.Equal(host.Type, types.HostTypeNormal)
+				assert.Equal(host.Hostname, mockRawHost.Hostname)
+				assert.Equal(host.IP, mockRawHost.IP)
+				assert.Equal(host.Port, mockRawHost.Port)
+				assert.Equal(host.DownloadPort, mockRawHost.DownloadPort)
+				assert.Equal(host.Labels(), map[string]string{"lifecycle": "spot"})
+				assert.Equal(host.ConcurrentUploadLimit.Load(), int32(config.DefaultPeerConcurrentUploadLimit))
+				assert.NotNil(host.Peers)
+				assert.Equal(host.PeerCount.Load(), int32(0))
+				assert.NotEmpty(host.CreatedAt.Load())
+				assert.NotEmpty(host.UpdatedAt.Load())
+				assert.NotNil(host.Log)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -164,6 +164,10 @@ type Peer struct {
 	// Used only in v2 version of the grpc.
 	AnnouncePeerStream *atomic.Value
 
+	// SchedulingExplanation is the explanation of the last scheduling of the peer,
+	// it records how the sampled candidate parents are filtered and evaluated.
+	SchedulingExplanation *atomic.Value
+
 	// Peer state machine.
 	FSM *fsm.FSM
 
@@ -207,6 +211,7 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 		Cost:                    atomic.NewDuration(0),
 		ReportPieceResultStream: &atomic.Value{},
 		AnnouncePeerStream:      &atomic.Value{},
+		SchedulingExplanation:   &atomic.Value{},
 		Task:                    task,
 		Host:                    host,
 		BlockParents:            set.NewSafeSet[string](),
@@ -209,6 +209,8 @@ func (s *seedPeer) TriggerTask(ctx context.Context, rg *http.Range, task *Task)
 			}
 			metrics.Traffic.WithLabelValues(trafficType.String(), peer.Task.Type.String(),
 				peer.Host.Type.Name()).Add(float64(pieceSeed.PieceInfo.RangeSize))
+			metrics.AddTaskTraffic(trafficType, peer.Task.Application, peer.Task.Tag, peer.Priority, peer.Task.ID,
+				uint64(pieceSeed.PieceInfo.RangeSize))
 		}
 
 		// Handle end of piece.
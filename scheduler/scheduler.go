@@ -39,6 +39,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	"d7y.io/dragonfly/v2/scheduler/announcer"
+	"d7y.io/dragonfly/v2/scheduler/api"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/job"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
@@ -46,6 +47,9 @@ import (
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/rpcserver"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/hook"
+	"d7y.io/dragonfly/v2/scheduler/service"
+	"d7y.io/dragonfly/v2/scheduler/storage"
 )
 
 const (
@@ -65,6 +69,9 @@ type Server struct {
 	// Metrics server.
 	metricsServer *http.Server
 
+	// API server.
+	apiServer *http.Server
+
 	// Manager client.
 	managerClient managerclient.V2
 
@@ -77,6 +84,9 @@ type Server struct {
 	// Dynamic config.
 	dynconfig config.DynconfigInterface
 
+	// Storage interface.
+	storage storage.Storage
+
 	// Async job.
 	job job.Job
 
@@ -187,14 +197,31 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 
 	// Initialize job service.
 	if cfg.Job.Enable && rdb != nil {
-		s.job, err = job.New(cfg, resource)
+		s.job, err = job.New(cfg, resource, s.persistentCacheResource)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// Initialize storage.
+	s.storage, err = storage.New(
+		filepath.Join(d.DataDir(), "storage"),
+		cfg.Storage.MaxSize,
+		cfg.Storage.MaxBackups,
+		cfg.Storage.BufferSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize hook chain of scheduling.
+	hooks, err := hook.New(&cfg.Scheduler.Plugins, d.PluginDir())
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize scheduling.
-	scheduling := scheduling.New(&cfg.Scheduler, s.persistentCacheResource, dynconfig, d.PluginDir())
+	scheduling := scheduling.New(&cfg.Scheduler, s.persistentCacheResource, dynconfig, d.PluginDir(), scheduling.WithHooks(hooks))
 
 	// Initialize server options of scheduler grpc server.
 	schedulerServerOptions := []grpc.ServerOption{}
@@ -212,14 +239,33 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		schedulerServerOptions = append(schedulerServerOptions, grpc.Creds(rpc.NewInsecureCredentials()))
 	}
 
-	svr := rpcserver.New(cfg, resource, s.persistentCacheResource, scheduling, dynconfig, schedulerServerOptions...)
+	svr := rpcserver.New(cfg, resource, s.persistentCacheResource, scheduling, dynconfig, s.storage, schedulerServerOptions...)
 	s.grpcServer = svr
 
+	// Initialize persistent cache task reconciler.
+	if s.persistentCacheResource != nil {
+		if err := s.gc.Add(gc.Task{
+			ID:       service.GCPersistentCacheTaskReconcilerID,
+			Interval: cfg.Scheduler.GC.PersistentCacheTaskReconcileInterval,
+			Timeout:  cfg.Scheduler.GC.PersistentCacheTaskReconcileInterval,
+			Runner:   service.NewPersistentCacheTaskReconciler(s.persistentCacheResource, scheduling, cfg.Scheduler.GC.PersistentCacheTaskReconcileInterval),
+		}); err != nil {
+			logger.Errorf("failed to add persistent cache task reconciler: %v", err)
+			return nil, err
+		}
+	}
+
 	// Initialize metrics.
+	metrics.InitTraffic(&cfg.Metrics.Traffic)
 	if cfg.Metrics.Enable {
 		s.metricsServer = metrics.New(&cfg.Metrics, s.grpcServer)
 	}
 
+	// Initialize api server.
+	if cfg.API.Enable {
+		s.apiServer = api.New(&cfg.API, resource, s.persistentCacheResource, scheduling, s.storage, api.WithFederationToken(cfg.Scheduler.Federation.Token))
+	}
+
 	return s, nil
 }
 
@@ -258,6 +304,20 @@ func (s *Server) Serve() error {
 		}()
 	}
 
+	// Started api server.
+	if s.apiServer != nil {
+		go func() {
+			logger.Infof("started api server at %s", s.apiServer.Addr)
+			if err := s.apiServer.ListenAndServe(); err != nil {
+				if err == http.ErrServerClosed {
+					return
+				}
+
+				logger.Fatalf("api server closed unexpect: %s", err.Error())
+			}
+		}()
+	}
+
 	// Serve announcer.
 	go func() {
 		s.announcer.Serve()
@@ -315,6 +375,15 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop api server.
+	if s.apiServer != nil {
+		if err := s.apiServer.Shutdown(context.Background()); err != nil {
+			logger.Errorf("api server failed to stop: %s", err.Error())
+		} else {
+			logger.Info("api server closed under request")
+		}
+	}
+
 	// Stop announcer.
 	s.announcer.Stop()
 	logger.Info("stop announcer closed")
@@ -343,4 +412,11 @@ func (s *Server) Stop() {
 	case <-stopped:
 		t.Stop()
 	}
+
+	// Stop storage after grpc server, the records are written by the services.
+	if err := s.storage.Stop(); err != nil {
+		logger.Errorf("stop storage failed %s", err.Error())
+	} else {
+		logger.Info("stop storage closed")
+	}
 }
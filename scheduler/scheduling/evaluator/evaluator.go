@@ -73,11 +73,30 @@ type Evaluator interface {
 	IsBadPersistentCacheParent(peer *persistentcache.Peer) bool
 }
 
+// Explainer is an optional interface of Evaluator that explains the feature scores of the parent.
+type Explainer interface {
+	// ExplainParent returns the feature scores used to evaluate the parent,
+	// the evaluation score of the parent is the weighted sum of them.
+	ExplainParent(parent *standard.Peer, child *standard.Peer, totalPieceCount uint32) []Score
+}
+
+// Score is the score of a feature used to evaluate the parent.
+type Score struct {
+	// Feature is the name of the feature.
+	Feature string `json:"feature"`
+
+	// Weight is the weight of the feature.
+	Weight float64 `json:"weight"`
+
+	// Value is the score of the feature.
+	Value float64 `json:"value"`
+}
+
 // evaluator is an implementation of Evaluator.
 type evaluator struct{}
 
-// New returns a new Evaluator.
-func New(algorithm string, pluginDir string) Evaluator {
+// New returns a new Evaluator, the options are not applied to the plugin evaluator.
+func New(algorithm string, pluginDir string, options ...Option) Evaluator {
 	switch algorithm {
 	case PluginAlgorithm:
 		if plugin, err := LoadPlugin(pluginDir); err == nil {
@@ -85,10 +104,10 @@ func New(algorithm string, pluginDir string) Evaluator {
 		}
 	// TODO Implement MLAlgorithm.
 	case MLAlgorithm, DefaultAlgorithm:
-		return newEvaluatorBase()
+		return newEvaluatorBase(options...)
 	}
 
-	return newEvaluatorBase()
+	return newEvaluatorBase(options...)
 }
 
 // IsBadParent determine if peer is a bad parent, it can not be selected as a parent.
@@ -22,6 +22,7 @@ import (
 
 	"d7y.io/dragonfly/v2/pkg/math"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 )
@@ -44,16 +45,61 @@ const (
 
 	// Location affinity weight.
 	locationAffinityWeight = 0.15
+
+	// Host load weight, it is used only when host load is enabled and
+	// the weights of the other features are scaled down to keep the sum at 1.
+	hostLoadWeight = 0.15
+)
+
+const (
+	// FeatureFinishedPiece is the feature of finished piece.
+	FeatureFinishedPiece = "FinishedPiece"
+
+	// FeatureParentHostUploadSuccess is the feature of parent's host upload success.
+	FeatureParentHostUploadSuccess = "ParentHostUploadSuccess"
+
+	// FeatureFreeUpload is the feature of free upload.
+	FeatureFreeUpload = "FreeUpload"
+
+	// FeatureHostType is the feature of host type.
+	FeatureHostType = "HostType"
+
+	// FeatureIDCAffinity is the feature of idc affinity.
+	FeatureIDCAffinity = "IDCAffinity"
+
+	// FeatureLocationAffinity is the feature of location affinity.
+	FeatureLocationAffinity = "LocationAffinity"
+
+	// FeatureHostLoad is the feature of host load.
+	FeatureHostLoad = "HostLoad"
 )
 
 // evaluatorBase is an implementation of Evaluator.
 type evaluatorBase struct {
 	evaluator
+
+	// hostLoad is the configuration of evaluating parents with the load of their hosts.
+	hostLoad config.HostLoadConfig
+}
+
+// Option is a functional option for configuring the evaluator.
+type Option func(e *evaluatorBase)
+
+// WithHostLoad sets the configuration of evaluating parents with the load of their hosts.
+func WithHostLoad(cfg config.HostLoadConfig) Option {
+	return func(e *evaluatorBase) {
+		e.hostLoad = cfg
+	}
 }
 
 // NewEvaluatorBase returns a new EvaluatorBase.
-func newEvaluatorBase() Evaluator {
-	return &evaluatorBase{}
+func newEvaluatorBase(options ...Option) Evaluator {
+	e := &evaluatorBase{}
+	for _, opt := range options {
+		opt(e)
+	}
+
+	return e
 }
 
 // EvaluateParents sort parents by evaluating multiple feature scores.
@@ -70,17 +116,49 @@ func (e *evaluatorBase) EvaluateParents(parents []*standard.Peer, child *standar
 
 // evaluateParents sort parents by evaluating multiple feature scores.
 func (e *evaluatorBase) evaluateParents(parent *standard.Peer, child *standard.Peer, totalPieceCount uint32) float64 {
+	var score float64
+	for _, s := range e.ExplainParent(parent, child, totalPieceCount) {
+		score += s.Weight * s.Value
+	}
+
+	return score
+}
+
+// ExplainParent returns the feature scores used to evaluate the parent.
+func (e *evaluatorBase) ExplainParent(parent *standard.Peer, child *standard.Peer, totalPieceCount uint32) []Score {
 	parentLocation := parent.Host.Network.Location
 	parentIDC := parent.Host.Network.IDC
 	childLocation := child.Host.Network.Location
 	childIDC := child.Host.Network.IDC
 
-	return finishedPieceWeight*e.calculatePieceScore(parent.FinishedPieces.Count(), child.FinishedPieces.Count(), totalPieceCount) +
-		parentHostUploadSuccessWeight*e.calculateParentHostUploadSuccessScore(parent.Host.UploadCount.Load(), parent.Host.UploadFailedCount.Load()) +
-		freeUploadWeight*e.calculateFreeUploadScore(parent.Host) +
-		hostTypeWeight*e.calculateHostTypeScore(parent) +
-		idcAffinityWeight*e.calculateIDCAffinityScore(parentIDC, childIDC) +
-		locationAffinityWeight*e.calculateMultiElementAffinityScore(parentLocation, childLocation)
+	scores := []Score{
+		{FeatureFinishedPiece, finishedPieceWeight, e.calculatePieceScore(parent.FinishedPieces.Count(), child.FinishedPieces.Count(), totalPieceCount)},
+		{FeatureParentHostUploadSuccess, parentHostUploadSuccessWeight, e.calculateParentHostUploadSuccessScore(parent.Host.UploadCount.Load(), parent.Host.UploadFailedCount.Load())},
+		{FeatureFreeUpload, freeUploadWeight, e.calculateFreeUploadScore(parent.Host)},
+		{FeatureHostType, hostTypeWeight, e.calculateHostTypeScore(parent)},
+		{FeatureIDCAffinity, idcAffinityWeight, e.calculateIDCAffinityScore(parentIDC, childIDC)},
+		{FeatureLocationAffinity, locationAffinityWeight, e.calculateMultiElementAffinityScore(parentLocation, childLocation)},
+	}
+
+	if e.hostLoad.Enable {
+		for i := range scores {
+			scores[i].Weight *= 1 - hostLoadWeight
+		}
+
+		scores = append(scores, Score{FeatureHostLoad, hostLoadWeight, e.calculateHostLoadScore(parent.Host)})
+	}
+
+	return scores
+}
+
+// IsBadParent determine if peer is a bad parent, it can not be selected as a parent.
+func (e *evaluatorBase) IsBadParent(peer *standard.Peer) bool {
+	if e.isOverloadedHost(peer.Host) {
+		peer.Log.Debugf("peer is bad node because host %s is overloaded", peer.Host.ID)
+		return true
+	}
+
+	return e.evaluator.IsBadParent(peer)
 }
 
 // EvaluatePersistentCacheParents sort persistent cache parents by evaluating multiple feature scores.
@@ -145,6 +223,48 @@ func (e *evaluatorBase) calculateFreeUploadScore(host *standard.Host) float64 {
 	return minScore
 }
 
+// calculateHostLoadScore 0.0~1.0 larger and better, it is the headroom of
+// the most loaded resource among cpu, memory, disk and upload rate of the host.
+func (e *evaluatorBase) calculateHostLoadScore(host *standard.Host) float64 {
+	usages := []float64{host.CPU.Percent / 100, host.Memory.UsedPercent / 100, host.Disk.UsedPercent / 100}
+	if host.Network.UploadRateLimit > 0 {
+		usages = append(usages, float64(host.Network.UploadRate)/float64(host.Network.UploadRateLimit))
+	}
+
+	score := maxScore
+	for _, usage := range usages {
+		if maxScore-usage < score {
+			score = maxScore - usage
+		}
+	}
+
+	if score < minScore {
+		return minScore
+	}
+
+	return score
+}
+
+// isOverloadedHost determine if the load of the host is past any threshold.
+func (e *evaluatorBase) isOverloadedHost(host *standard.Host) bool {
+	if !e.hostLoad.Enable {
+		return false
+	}
+
+	if host.CPU.Percent > e.hostLoad.CPUPercentThreshold ||
+		host.Memory.UsedPercent > e.hostLoad.MemoryUsedPercentThreshold ||
+		host.Disk.UsedPercent > e.hostLoad.DiskUsedPercentThreshold {
+		return true
+	}
+
+	if host.Network.UploadRateLimit > 0 &&
+		float64(host.Network.UploadRate)*100/float64(host.Network.UploadRateLimit) > e.hostLoad.UploadRatePercentThreshold {
+		return true
+	}
+
+	return false
+}
+
 // calculateHostTypeScore 0.0~1.0 larger and better.
 func (e *evaluatorBase) calculateHostTypeScore(peer *standard.Peer) float64 {
 	// When the task is downloaded for the first time,
@@ -29,6 +29,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 )
 
@@ -396,6 +397,38 @@ func TestEvaluatorBase_evaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluatorBase_ExplainParent(t *testing.T) {
+	parent := standard.NewPeer(idgen.PeerIDV1("127.0.0.1"),
+		standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength)),
+		standard.NewHost(
+			mockRawSeedHost.ID, mockRawSeedHost.IP, mockRawSeedHost.Hostname,
+			mockRawSeedHost.Port, mockRawSeedHost.DownloadPort, mockRawSeedHost.Type))
+	child := standard.NewPeer(idgen.PeerIDV1("127.0.0.1"),
+		standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength)),
+		standard.NewHost(
+			mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+			mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type))
+	parent.FinishedPieces.Set(0)
+
+	e := newEvaluatorBase()
+	scores := e.(Explainer).ExplainParent(parent, child, 1)
+
+	assert := assert.New(t)
+	var (
+		features []string
+		score    float64
+	)
+	for _, s := range scores {
+		features = append(features, s.Feature)
+		score += s.Weight * s.Value
+	}
+
+	assert.Equal([]string{FeatureFinishedPiece, FeatureParentHostUploadSuccess, FeatureFreeUpload, FeatureHostType, FeatureIDCAffinity, FeatureLocationAffinity}, features)
+	assert.Equal(Score{FeatureFinishedPiece, finishedPieceWeight, 1}, scores[0])
+	assert.Equal(e.(*evaluatorBase).evaluateParents(parent, child, 1), score)
+	assert.Equal(float64(0.55), score)
+}
+
 func TestEvaluatorBase_calculatePieceScore(t *testing.T) {
 	mockHost := standard.NewHost(
 		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -1045,3 +1078,210 @@ func TestEvaluatorBase_IsBadParent(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluatorBase_ExplainParentWithHostLoad(t *testing.T) {
+	parent := standard.NewPeer(idgen.PeerIDV1("127.0.0.1"),
+		standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength)),
+		standard.NewHost(
+			mockRawSeedHost.ID, mockRawSeedHost.IP, mockRawSeedHost.Hostname,
+			mockRawSeedHost.Port, mockRawSeedHost.DownloadPort, mockRawSeedHost.Type))
+	child := standard.NewPeer(idgen.PeerIDV1("127.0.0.1"),
+		standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength)),
+		standard.NewHost(
+			mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+			mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type))
+	parent.Host.CPU.Percent = 60
+
+	e := newEvaluatorBase(WithHostLoad(config.HostLoadConfig{Enable: true}))
+	scores := e.(Explainer).ExplainParent(parent, child, 1)
+
+	assert := assert.New(t)
+	assert.Len(scores, 7)
+	assert.Equal(FeatureHostLoad, scores[6].Feature)
+	assert.Equal(hostLoadWeight, scores[6].Weight)
+	assert.InDelta(0.4, scores[6].Value, 1e-9)
+	assert.InDelta(finishedPieceWeight*(1-hostLoadWeight), scores[0].Weight, 1e-9)
+
+	var weight float64
+	for _, score := range scores {
+		weight += score.Weight
+	}
+	assert.InDelta(1, weight, 1e-9)
+}
+
+func TestEvaluatorBase_calculateHostLoadScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(host *standard.Host)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name: "host is idle",
+			mock: func(host *standard.Host) {},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(1), score)
+			},
+		},
+		{
+			name: "memory is the most loaded resource",
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 20
+				host.Memory.UsedPercent = 75
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.InDelta(0.25, score, 1e-9)
+			},
+		},
+		{
+			name: "upload rate is the most loaded resource",
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 20
+				host.Network.UploadRate = 90
+				host.Network.UploadRateLimit = 100
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.InDelta(0.1, score, 1e-9)
+			},
+		},
+		{
+			name: "disk is the most loaded resource",
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 20
+				host.Memory.UsedPercent = 30
+				host.Disk.UsedPercent = 80
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.InDelta(0.2, score, 1e-9)
+			},
+		},
+		{
+			name: "upload rate is beyond the limit",
+			mock: func(host *standard.Host) {
+				host.Network.UploadRate = 200
+				host.Network.UploadRateLimit = 100
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(0), score)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := standard.NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			e := newEvaluatorBase()
+			tc.mock(host)
+			tc.expect(t, e.(*evaluatorBase).calculateHostLoadScore(host))
+		})
+	}
+}
+
+func TestEvaluatorBase_IsBadParentWithHostLoad(t *testing.T) {
+	mockHostLoadConfig := config.HostLoadConfig{
+		Enable:                     true,
+		CPUPercentThreshold:        90,
+		MemoryUsedPercentThreshold: 90,
+		DiskUsedPercentThreshold:   95,
+		UploadRatePercentThreshold: 95,
+	}
+
+	tests := []struct {
+		name     string
+		hostLoad config.HostLoadConfig
+		mock     func(host *standard.Host)
+		expect   func(t *testing.T, isBadParent bool)
+	}{
+		{
+			name:     "host is not overloaded",
+			hostLoad: mockHostLoadConfig,
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 50
+				host.Memory.UsedPercent = 50
+				host.Disk.UsedPercent = 50
+				host.Network.UploadRate = 50
+				host.Network.UploadRateLimit = 100
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.False(isBadParent)
+			},
+		},
+		{
+			name:     "cpu percent is past the threshold",
+			hostLoad: mockHostLoadConfig,
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 91
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.True(isBadParent)
+			},
+		},
+		{
+			name:     "memory used percent is past the threshold",
+			hostLoad: mockHostLoadConfig,
+			mock: func(host *standard.Host) {
+				host.Memory.UsedPercent = 91
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.True(isBadParent)
+			},
+		},
+		{
+			name:     "disk used percent is past the threshold",
+			hostLoad: mockHostLoadConfig,
+			mock: func(host *standard.Host) {
+				host.Disk.UsedPercent = 96
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.True(isBadParent)
+			},
+		},
+		{
+			name:     "upload rate is past the threshold",
+			hostLoad: mockHostLoadConfig,
+			mock: func(host *standard.Host) {
+				host.Network.UploadRate = 96
+				host.Network.UploadRateLimit = 100
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.True(isBadParent)
+			},
+		},
+		{
+			name:     "host load is disabled",
+			hostLoad: config.HostLoadConfig{},
+			mock: func(host *standard.Host) {
+				host.CPU.Percent = 100
+			},
+			expect: func(t *testing.T, isBadParent bool) {
+				assert := assert.New(t)
+				assert.False(isBadParent)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := standard.NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+			peer := standard.NewPeer(mockPeerID, mockTask, host)
+			peer.FSM.SetState(standard.PeerStateRunning)
+			e := newEvaluatorBase(WithHostLoad(tc.hostLoad))
+			tc.mock(host)
+			tc.expect(t, e.IsBadParent(peer))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
+)
+
+const (
+	// FilterReasonBlocklist is the reason that the candidate parent is in blocklist.
+	FilterReasonBlocklist = "Blocklist"
+
+	// FilterReasonDisableShared is the reason that the candidate parent host is disable shared.
+	FilterReasonDisableShared = "DisableShared"
+
+	// FilterReasonSameHost is the reason that the candidate parent host is the same as the peer host.
+	FilterReasonSameHost = "SameHost"
+
+	// FilterReasonNotInDAG is the reason that the candidate parent can not be found in dag.
+	FilterReasonNotInDAG = "NotInDAG"
+
+	// FilterReasonNotReady is the reason that the candidate parent has no parent,
+	// and it is neither back-to-source, succeeded nor seed peer.
+	FilterReasonNotReady = "NotReady"
+
+	// FilterReasonBadNode is the reason that the candidate parent is bad node.
+	FilterReasonBadNode = "BadNode"
+
+	// FilterReasonUploadFull is the reason that the free upload of candidate parent host is empty.
+	FilterReasonUploadFull = "UploadFull"
+
+	// FilterReasonPlugin is the reason that the candidate parent is vetoed by the filter plugin,
+	// it is followed by the name of the filter, e.g. Plugin:spot.
+	FilterReasonPlugin = "Plugin"
+
+	// FilterReasonDAGCycle is the reason that the edge with candidate parent makes a cycle in dag.
+	FilterReasonDAGCycle = "DAGCycle"
+
+	// FilterReasonCandidateParentLimit is the reason that the candidate parent exceeds the candidate parent limit.
+	FilterReasonCandidateParentLimit = "CandidateParentLimit"
+)
+
+const (
+	// filterCandidateParentEventName is the span event name of the filtered candidate parent.
+	filterCandidateParentEventName = "filter candidate parent"
+
+	// evaluateCandidateParentEventName is the span event name of the evaluated candidate parent.
+	evaluateCandidateParentEventName = "evaluate candidate parent"
+)
+
+// Explanation explains how the candidate parents of the peer are scheduled, it is recorded by the last
+// scheduling with the candidate parents sampled from the task at that time.
+type Explanation struct {
+	// PeerID is the id of the peer.
+	PeerID string `json:"peer_id"`
+
+	// TaskID is the id of the task.
+	TaskID string `json:"task_id"`
+
+	// HostID is the id of the peer host.
+	HostID string `json:"host_id"`
+
+	// State is the state of the peer when it is scheduled.
+	State string `json:"state"`
+
+	// Parents are the ids of the current parents of the peer when the explanation is requested.
+	Parents []string `json:"parents"`
+
+	// CandidateParentLimit is the limit of the selected candidate parents,
+	// it is zero if all candidate parents are filtered.
+	CandidateParentLimit int `json:"candidate_parent_limit"`
+
+	// CandidateParents are the candidate parents sampled by the scheduling, the evaluated ones
+	// are sorted by score and followed by the filtered ones.
+	CandidateParents []CandidateParent `json:"candidate_parents"`
+
+	// ScheduledAt is the time of the scheduling.
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// CandidateParent explains how the candidate parent is scheduled.
+type CandidateParent struct {
+	// ID is the id of the candidate parent.
+	ID string `json:"id"`
+
+	// HostID is the id of the candidate parent host.
+	HostID string `json:"host_id"`
+
+	// Hostname is the hostname of the candidate parent host.
+	Hostname string `json:"hostname"`
+
+	// IP is the ip of the candidate parent host.
+	IP string `json:"ip"`
+
+	// State is the state of the candidate parent.
+	State string `json:"state"`
+
+	// Selected indicates whether the candidate parent is selected by the scheduling.
+	Selected bool `json:"selected"`
+
+	// FilterReason is the reason that the candidate parent is not selected.
+	FilterReason string `json:"filter_reason,omitempty"`
+
+	// Score is the evaluation score of the candidate parent.
+	Score float64 `json:"score"`
+
+	// Scores are the feature scores of the candidate parent followed by the scores of score plugins,
+	// it is empty if the candidate parent is filtered or neither of them scores it.
+	Scores []evaluator.Score `json:"scores,omitempty"`
+}
+
+// explainCandidateParent explains the evaluation of the candidate parent,
+// the scores of the scorers in hook chain are added to the feature scores.
+func (s *scheduling) explainCandidateParent(candidateParent *standard.Peer, peer *standard.Peer, totalPieceCount uint32) ([]evaluator.Score, float64) {
+	var scores []evaluator.Score
+	if explainer, ok := s.evaluator.(evaluator.Explainer); ok {
+		scores = explainer.ExplainParent(candidateParent, peer, totalPieceCount)
+	}
+
+	if s.hooks != nil {
+		scores = append(scores, s.hooks.Score(candidateParent, peer)...)
+	}
+
+	var score float64
+	for _, s := range scores {
+		score += s.Weight * s.Value
+	}
+
+	return scores, score
+}
+
+// addFilterCandidateParentEvent adds the filtered candidate parent to the span.
+func addFilterCandidateParentEvent(ctx context.Context, candidateParent *standard.Peer, reason string) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.AddEvent(filterCandidateParentEventName, trace.WithAttributes(
+		attribute.String("parent.id", candidateParent.ID),
+		attribute.String("parent.host.id", candidateParent.Host.ID),
+		attribute.String("reason", reason),
+	))
+}
+
+// addEvaluateCandidateParentEvents adds the evaluated candidate parents to the span.
+func (s *scheduling) addEvaluateCandidateParentEvents(ctx context.Context, candidateParents []*standard.Peer, peer *standard.Peer, totalPieceCount uint32) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	for _, candidateParent := range candidateParents {
+		scores, score := s.explainCandidateParent(candidateParent, peer, totalPieceCount)
+		attributes := []attribute.KeyValue{
+			attribute.String("parent.id", candidateParent.ID),
+			attribute.String("parent.host.id", candidateParent.Host.ID),
+			attribute.Float64("score", score),
+		}
+
+		for _, s := range scores {
+			attributes = append(attributes, attribute.Float64("score."+s.Feature, s.Value))
+		}
+
+		span.AddEvent(evaluateCandidateParentEventName, trace.WithAttributes(attributes...))
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hook
+
+import (
+	"fmt"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
+)
+
+// Filter vetoes the candidate parents, it is lighter than the evaluator plugin
+// which must replace the whole evaluator.
+type Filter interface {
+	// Filter returns false if the candidate parent can not be selected as the parent of the child.
+	Filter(parent *standard.Peer, child *standard.Peer) bool
+}
+
+// Scorer adds a weighted term to the evaluation score of the candidate parents.
+type Scorer interface {
+	// Score returns the score of the candidate parent for the child, range is 0~1.
+	Score(parent *standard.Peer, child *standard.Peer) float64
+}
+
+// Chain is the chain of the filters and scorers applied in scheduling.
+type Chain struct {
+	// filters are the filters in order of configuration.
+	filters []namedFilter
+
+	// scorers are the scorers in order of configuration.
+	scorers []weightedScorer
+}
+
+// namedFilter is the filter with its name.
+type namedFilter struct {
+	name string
+	Filter
+}
+
+// weightedScorer is the scorer with its name and weight.
+type weightedScorer struct {
+	name   string
+	weight float64
+	Scorer
+}
+
+// New returns a new Chain, the filters and scorers are loaded from the go plugins
+// in plugin directory or constructed by the rules on host labels.
+func New(cfg *config.PluginsConfig, pluginDir string) (*Chain, error) {
+	c := &Chain{}
+	for _, f := range cfg.Filters {
+		var filter Filter = labelRules(f.Rules)
+		if f.Plugin {
+			plugin, err := LoadFilterPlugin(pluginDir, f.Name, f.Options)
+			if err != nil {
+				return nil, fmt.Errorf("load filter plugin %s: %w", f.Name, err)
+			}
+
+			filter = plugin
+		}
+
+		c.filters = append(c.filters, namedFilter{name: f.Name, Filter: filter})
+	}
+
+	for _, s := range cfg.Scores {
+		var scorer Scorer = labelRules(s.Rules)
+		if s.Plugin {
+			plugin, err := LoadScorePlugin(pluginDir, s.Name, s.Options)
+			if err != nil {
+				return nil, fmt.Errorf("load score plugin %s: %w", s.Name, err)
+			}
+
+			scorer = plugin
+		}
+
+		c.scorers = append(c.scorers, weightedScorer{name: s.Name, weight: s.Weight, Scorer: scorer})
+	}
+
+	return c, nil
+}
+
+// Filter returns false and the name of the filter if any filter vetoes the candidate parent.
+func (c *Chain) Filter(parent *standard.Peer, child *standard.Peer) (string, bool) {
+	for _, f := range c.filters {
+		if !f.Filter.Filter(parent, child) {
+			return f.name, false
+		}
+	}
+
+	return "", true
+}
+
+// Score returns the weighted scores of the candidate parent, the feature of
+// the score is the name of the scorer.
+func (c *Chain) Score(parent *standard.Peer, child *standard.Peer) []evaluator.Score {
+	scores := make([]evaluator.Score, 0, len(c.scorers))
+	for _, s := range c.scorers {
+		scores = append(scores, evaluator.Score{Feature: s.name, Weight: s.weight, Value: s.Scorer.Score(parent, child)})
+	}
+
+	return scores
+}
+
+// HasScorers returns whether the chain has any scorer.
+func (c *Chain) HasScorers() bool {
+	return len(c.scorers) > 0
+}
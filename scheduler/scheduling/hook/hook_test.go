@@ -0,0 +1,222 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
+)
+
+var (
+	mockSpotRule = config.LabelRule{Key: "lifecycle", Operator: config.LabelRuleOperatorIn, Values: []string{"spot"}}
+	mockSSDRule  = config.LabelRule{Key: "disk", Operator: config.LabelRuleOperatorIn, Values: []string{"ssd"}}
+)
+
+func newMockPeer(id string, labels map[string]string) *standard.Peer {
+	var options []standard.HostOption
+	if labels != nil {
+		options = append(options, standard.WithLabels(labels))
+	}
+
+	host := standard.NewHost(id, "127.0.0.1", "foo", 8003, 8001, types.HostTypeNormal, options...)
+	task := standard.NewTask("task", "https://example.com", "", "", commonv2.TaskType_STANDARD, nil, nil, 1)
+	return standard.NewPeer(id, task, host)
+}
+
+func TestChain_New(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.PluginsConfig
+		expect func(t *testing.T, c *Chain, err error)
+	}{
+		{
+			name:   "new chain without plugins",
+			config: &config.PluginsConfig{},
+			expect: func(t *testing.T, c *Chain, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(c.filters)
+				assert.False(c.HasScorers())
+			},
+		},
+		{
+			name: "new chain with rules",
+			config: &config.PluginsConfig{
+				Filters: []config.FilterPluginConfig{{Name: "spot", Rules: []config.LabelRule{mockSpotRule}}},
+				Scores:  []config.ScorePluginConfig{{Name: "ssd", Weight: 0.5, Rules: []config.LabelRule{mockSSDRule}}},
+			},
+			expect: func(t *testing.T, c *Chain, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(c.filters, 1)
+				assert.True(c.HasScorers())
+			},
+		},
+		{
+			name: "new chain with filter plugin not found",
+			config: &config.PluginsConfig{
+				Filters: []config.FilterPluginConfig{{Name: "foo", Plugin: true}},
+			},
+			expect: func(t *testing.T, c *Chain, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "load filter plugin foo")
+				assert.Nil(c)
+			},
+		},
+		{
+			name: "new chain with score plugin not found",
+			config: &config.PluginsConfig{
+				Scores: []config.ScorePluginConfig{{Name: "foo", Plugin: true, Weight: 0.5}},
+			},
+			expect: func(t *testing.T, c *Chain, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "load score plugin foo")
+				assert.Nil(c)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := New(tc.config, t.TempDir())
+			tc.expect(t, c, err)
+		})
+	}
+}
+
+func TestChain_Filter(t *testing.T) {
+	c, err := New(&config.PluginsConfig{
+		Filters: []config.FilterPluginConfig{
+			{Name: "spot", Rules: []config.LabelRule{mockSpotRule}},
+			{Name: "hdd", Rules: []config.LabelRule{{Key: "disk", Operator: config.LabelRuleOperatorNotIn, Values: []string{"ssd"}}}},
+		},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		expect func(t *testing.T, name string, ok bool)
+	}{
+		{
+			name:   "parent on spot instance is vetoed",
+			labels: map[string]string{"lifecycle": "spot", "disk": "ssd"},
+			expect: func(t *testing.T, name string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal("spot", name)
+			},
+		},
+		{
+			name:   "parent without ssd is vetoed",
+			labels: map[string]string{"lifecycle": "on-demand"},
+			expect: func(t *testing.T, name string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal("hdd", name)
+			},
+		},
+		{
+			name:   "parent host has no labels",
+			labels: nil,
+			expect: func(t *testing.T, name string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal("hdd", name)
+			},
+		},
+		{
+			name:   "parent is not vetoed",
+			labels: map[string]string{"lifecycle": "on-demand", "disk": "ssd"},
+			expect: func(t *testing.T, name string, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Empty(name)
+			},
+		},
+	}
+
+	child := newMockPeer("child", nil)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, ok := c.Filter(newMockPeer("parent", tc.labels), child)
+			tc.expect(t, name, ok)
+		})
+	}
+}
+
+func TestChain_FilterWhileAnnouncingLabels(t *testing.T) {
+	c, err := New(&config.PluginsConfig{
+		Filters: []config.FilterPluginConfig{{Name: "spot", Rules: []config.LabelRule{mockSpotRule}}},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := newMockPeer("parent", nil)
+	child := newMockPeer("child", nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			parent.Host.StoreLabels(map[string]string{"lifecycle": "spot"})
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		c.Filter(parent, child)
+	}
+	<-done
+
+	name, ok := c.Filter(parent, child)
+	assert := assert.New(t)
+	assert.False(ok)
+	assert.Equal("spot", name)
+}
+
+func TestChain_Score(t *testing.T) {
+	c, err := New(&config.PluginsConfig{
+		Scores: []config.ScorePluginConfig{
+			{Name: "ssd", Weight: 0.5, Rules: []config.LabelRule{mockSSDRule}},
+			{Name: "spot", Weight: 0.2, Rules: []config.LabelRule{{Key: "lifecycle", Operator: config.LabelRuleOperatorDoesNotExist}}},
+		},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	child := newMockPeer("child", nil)
+	assert.Equal([]evaluator.Score{
+		{Feature: "ssd", Weight: 0.5, Value: 1},
+		{Feature: "spot", Weight: 0.2, Value: 0},
+	}, c.Score(newMockPeer("parent", map[string]string{"disk": "ssd", "lifecycle": "spot"}), child))
+	assert.Equal([]evaluator.Score{
+		{Feature: "ssd", Weight: 0.5, Value: 0},
+		{Feature: "spot", Weight: 0.2, Value: 1},
+	}, c.Score(newMockPeer("parent", nil), child))
+}
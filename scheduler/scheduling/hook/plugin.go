@@ -0,0 +1,49 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hook
+
+import (
+	"errors"
+
+	"d7y.io/dragonfly/v2/internal/dfplugin"
+)
+
+// LoadFilterPlugin loads the filter from the go plugin d7y-scheduler-plugin-<name>.so in the directory.
+func LoadFilterPlugin(dir string, name string, options map[string]string) (Filter, error) {
+	client, _, err := dfplugin.Load(dir, dfplugin.PluginTypeScheduler, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := client.(Filter); ok {
+		return f, nil
+	}
+	return nil, errors.New("invalid filter plugin")
+}
+
+// LoadScorePlugin loads the scorer from the go plugin d7y-scheduler-plugin-<name>.so in the directory.
+func LoadScorePlugin(dir string, name string, options map[string]string) (Scorer, error) {
+	client, _, err := dfplugin.Load(dir, dfplugin.PluginTypeScheduler, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, ok := client.(Scorer); ok {
+		return s, nil
+	}
+	return nil, errors.New("invalid score plugin")
+}
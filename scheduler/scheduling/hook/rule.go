@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hook
+
+import (
+	"slices"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+)
+
+// labelRules are the rules on the labels of the candidate parent host,
+// they are matched if the host labels match all the rules.
+type labelRules []config.LabelRule
+
+// Filter vetoes the candidate parent if its host labels match the rules.
+func (r labelRules) Filter(parent *standard.Peer, child *standard.Peer) bool {
+	return !r.match(parent.Host.Labels())
+}
+
+// Score returns 1 if the host labels of the candidate parent match the rules, otherwise 0.
+func (r labelRules) Score(parent *standard.Peer, child *standard.Peer) float64 {
+	if r.match(parent.Host.Labels()) {
+		return 1
+	}
+
+	return 0
+}
+
+// match returns whether the labels match all the rules.
+func (r labelRules) match(labels map[string]string) bool {
+	for _, rule := range r {
+		value, ok := labels[rule.Key]
+		switch rule.Operator {
+		case config.LabelRuleOperatorIn:
+			if !ok || !slices.Contains(rule.Values, value) {
+				return false
+			}
+		case config.LabelRuleOperatorNotIn:
+			if ok && slices.Contains(rule.Values, value) {
+				return false
+			}
+		case config.LabelRuleOperatorExists:
+			if !ok {
+				return false
+			}
+		case config.LabelRuleOperatorDoesNotExist:
+			if ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
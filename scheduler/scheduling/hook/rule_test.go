@@ -0,0 +1,114 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestLabelRules_match(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  labelRules
+		labels map[string]string
+		expect bool
+	}{
+		{
+			name:   "empty rules match any labels",
+			rules:  labelRules{},
+			labels: map[string]string{"foo": "bar"},
+			expect: true,
+		},
+		{
+			name:   "label value is in values",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorIn, Values: []string{"bar", "baz"}}},
+			labels: map[string]string{"foo": "baz"},
+			expect: true,
+		},
+		{
+			name:   "label value is not in values",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorIn, Values: []string{"bar"}}},
+			labels: map[string]string{"foo": "baz"},
+			expect: false,
+		},
+		{
+			name:   "label does not exist with in operator",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorIn, Values: []string{"bar"}}},
+			labels: nil,
+			expect: false,
+		},
+		{
+			name:   "label value is excluded by not in operator",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorNotIn, Values: []string{"bar"}}},
+			labels: map[string]string{"foo": "bar"},
+			expect: false,
+		},
+		{
+			name:   "label does not exist with not in operator",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorNotIn, Values: []string{"bar"}}},
+			labels: nil,
+			expect: true,
+		},
+		{
+			name:   "label exists",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorExists}},
+			labels: map[string]string{"foo": ""},
+			expect: true,
+		},
+		{
+			name:   "label does not exist",
+			rules:  labelRules{{Key: "foo", Operator: config.LabelRuleOperatorDoesNotExist}},
+			labels: map[string]string{"foo": ""},
+			expect: false,
+		},
+		{
+			name: "labels match all rules",
+			rules: labelRules{
+				{Key: "foo", Operator: config.LabelRuleOperatorExists},
+				{Key: "bar", Operator: config.LabelRuleOperatorDoesNotExist},
+			},
+			labels: map[string]string{"foo": "baz"},
+			expect: true,
+		},
+		{
+			name: "labels do not match all rules",
+			rules: labelRules{
+				{Key: "foo", Operator: config.LabelRuleOperatorExists},
+				{Key: "bar", Operator: config.LabelRuleOperatorExists},
+			},
+			labels: map[string]string{"foo": "baz"},
+			expect: false,
+		},
+		{
+			name:   "invalid operator",
+			rules:  labelRules{{Key: "foo", Operator: "Equal", Values: []string{"bar"}}},
+			labels: map[string]string{"foo": "bar"},
+			expect: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expect, tc.rules.match(tc.labels))
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	set "d7y.io/dragonfly/v2/pkg/container/set"
 	persistentcache "d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	standard "d7y.io/dragonfly/v2/scheduler/resource/standard"
+	scheduling "d7y.io/dragonfly/v2/scheduler/scheduling"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -43,6 +44,21 @@ func (m *MockScheduling) EXPECT() *MockSchedulingMockRecorder {
 	return m.recorder
 }
 
+// ExplainCandidateParents mocks base method.
+func (m *MockScheduling) ExplainCandidateParents(arg0 *standard.Peer) (*scheduling.Explanation, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExplainCandidateParents", arg0)
+	ret0, _ := ret[0].(*scheduling.Explanation)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// ExplainCandidateParents indicates an expected call of ExplainCandidateParents.
+func (mr *MockSchedulingMockRecorder) ExplainCandidateParents(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainCandidateParents", reflect.TypeOf((*MockScheduling)(nil).ExplainCandidateParents), arg0)
+}
+
 // FindCandidateParents mocks base method.
 func (m *MockScheduling) FindCandidateParents(arg0 context.Context, arg1 *standard.Peer, arg2 set.SafeSet[string]) ([]*standard.Peer, bool) {
 	m.ctrl.T.Helper()
@@ -73,6 +89,21 @@ func (mr *MockSchedulingMockRecorder) FindCandidatePersistentCacheParents(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCandidatePersistentCacheParents", reflect.TypeOf((*MockScheduling)(nil).FindCandidatePersistentCacheParents), arg0, arg1, arg2)
 }
 
+// FindFederatedCandidateParents mocks base method.
+func (m *MockScheduling) FindFederatedCandidateParents(arg0 context.Context, arg1 *standard.Peer, arg2 set.SafeSet[string]) ([]*standard.Peer, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFederatedCandidateParents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*standard.Peer)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// FindFederatedCandidateParents indicates an expected call of FindFederatedCandidateParents.
+func (mr *MockSchedulingMockRecorder) FindFederatedCandidateParents(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFederatedCandidateParents", reflect.TypeOf((*MockScheduling)(nil).FindFederatedCandidateParents), arg0, arg1, arg2)
+}
+
 // FindParentAndCandidateParents mocks base method.
 func (m *MockScheduling) FindParentAndCandidateParents(arg0 context.Context, arg1 *standard.Peer, arg2 set.SafeSet[string]) ([]*standard.Peer, bool) {
 	m.ctrl.T.Helper()
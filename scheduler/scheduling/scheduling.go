@@ -21,6 +21,7 @@ package scheduling
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -36,9 +37,11 @@ import (
 	"d7y.io/dragonfly/v2/pkg/container/set"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/federation"
 	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/hook"
 )
 
 type Scheduling interface {
@@ -61,6 +64,15 @@ type Scheduling interface {
 	// FindSuccessParent finds success parent for the peer to download the task.
 	FindSuccessParent(context.Context, *standard.Peer, set.SafeSet[string]) (*standard.Peer, bool)
 
+	// FindFederatedCandidateParents finds candidate parents in the federated clusters for the peer to download the task,
+	// the parents are not stored in the task of the local cluster.
+	// Used only in v2 version of the grpc.
+	FindFederatedCandidateParents(context.Context, *standard.Peer, set.SafeSet[string]) ([]*standard.Peer, bool)
+
+	// ExplainCandidateParents returns how the candidate parents were filtered and evaluated by the last
+	// scheduling of the peer, it returns false if the peer has not been scheduled.
+	ExplainCandidateParents(*standard.Peer) (*Explanation, bool)
+
 	// FindReplicatePersistentCacheHosts finds replicate persistent cache hosts for the peer to replicate the task. It will compare the current
 	// persistent replica count with the persistent replica count and try to find enough parents. Then function will return the cached replicate parents,
 	// the replicate hosts without cache and found flag.
@@ -82,15 +94,41 @@ type scheduling struct {
 
 	// Scheduler dynamic configuration.
 	dynconfig config.DynconfigInterface
+
+	// Federation interface, it is nil if federation is disabled.
+	federation federation.Federation
+
+	// Hook chain of the filters and scorers, it is nil if no plugin is configured.
+	hooks *hook.Chain
 }
 
-func New(cfg *config.SchedulerConfig, persistentCacheResource persistentcache.Resource, dynconfig config.DynconfigInterface, pluginDir string) Scheduling {
-	return &scheduling{
-		evaluator:               evaluator.New(cfg.Algorithm, pluginDir),
+// Option is a functional option for configuring the scheduling.
+type Option func(s *scheduling)
+
+// WithHooks sets the hook chain of the filters and scorers applied to the candidate parents.
+func WithHooks(hooks *hook.Chain) Option {
+	return func(s *scheduling) {
+		s.hooks = hooks
+	}
+}
+
+func New(cfg *config.SchedulerConfig, persistentCacheResource persistentcache.Resource, dynconfig config.DynconfigInterface, pluginDir string, options ...Option) Scheduling {
+	s := &scheduling{
+		evaluator:               evaluator.New(cfg.Algorithm, pluginDir, evaluator.WithHostLoad(cfg.HostLoad)),
 		config:                  cfg,
 		persistentCacheResource: persistentCacheResource,
 		dynconfig:               dynconfig,
 	}
+
+	if cfg.Federation.Enable {
+		s.federation = federation.New(&cfg.Federation, dynconfig)
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
 }
 
 // ScheduleCandidateParents schedules candidate parents to the normal peer.
@@ -179,8 +217,15 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *standar
 			return status.Error(codes.Internal, err.Error())
 		}
 
-		// Find candidate parents.
+		// Find candidate parents, the parents in the federated clusters are
+		// found only when no candidate parent is found in the local cluster.
+		var federated bool
 		candidateParents, found := s.FindCandidateParents(ctx, peer, blocklist)
+		if !found {
+			candidateParents, found = s.FindFederatedCandidateParents(ctx, peer, blocklist)
+			federated = found
+		}
+
 		if !found {
 			n++
 			peer.Log.Infof("scheduling failed in %d times, because of candidate parents not found", n)
@@ -212,7 +257,13 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *standar
 			return status.Error(codes.FailedPrecondition, err.Error())
 		}
 
-		// Add edge from parent to peer.
+		// Add edge from parent to peer, the parents in the federated
+		// clusters are not in the DAG of the task.
+		if federated {
+			peer.Log.Infof("scheduling success with federated parents in %d times", n+1)
+			return nil
+		}
+
 		for _, candidateParent := range candidateParents {
 			if err := peer.Task.AddPeerEdge(candidateParent, peer); err != nil {
 				peer.Log.Warnf("peer adds edge failed: %s", err.Error())
@@ -404,15 +455,16 @@ func (s *scheduling) FindCandidateParents(ctx context.Context, peer *standard.Pe
 	}
 
 	// Find the candidate parent that can be scheduled.
-	candidateParents := s.filterCandidateParents(peer, blocklist)
+	candidateParents, filteredCandidateParents := s.filterCandidateParents(ctx, peer, blocklist)
 	if len(candidateParents) == 0 {
+		s.storeSchedulingExplanation(peer, nil, filteredCandidateParents, 0, 0)
 		peer.Log.Info("can not find candidate parents")
 		return []*standard.Peer{}, false
 	}
 
 	// Sort candidate parents by evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	candidateParents = s.evaluator.EvaluateParents(candidateParents, peer, uint32(taskTotalPieceCount))
+	candidateParents = s.evaluateParents(candidateParents, peer, uint32(taskTotalPieceCount))
 
 	// Get the parents with candidateParentLimit.
 	candidateParentLimit := config.DefaultSchedulerCandidateParentLimit
@@ -422,9 +474,11 @@ func (s *scheduling) FindCandidateParents(ctx context.Context, peer *standard.Pe
 		}
 	}
 
+	s.storeSchedulingExplanation(peer, candidateParents, filteredCandidateParents, candidateParentLimit, uint32(taskTotalPieceCount))
 	if len(candidateParents) > candidateParentLimit {
 		candidateParents = candidateParents[:candidateParentLimit]
 	}
+	s.addEvaluateCandidateParentEvents(ctx, candidateParents, peer, uint32(taskTotalPieceCount))
 
 	var parentIDs []string
 	for _, candidateParent := range candidateParents {
@@ -446,15 +500,16 @@ func (s *scheduling) FindParentAndCandidateParents(ctx context.Context, peer *st
 	}
 
 	// Find the candidate parent that can be scheduled.
-	candidateParents := s.filterCandidateParents(peer, blocklist)
+	candidateParents, filteredCandidateParents := s.filterCandidateParents(ctx, peer, blocklist)
 	if len(candidateParents) == 0 {
+		s.storeSchedulingExplanation(peer, nil, filteredCandidateParents, 0, 0)
 		peer.Log.Info("can not find candidate parents")
 		return []*standard.Peer{}, false
 	}
 
 	// Sort candidate parents by evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	candidateParents = s.evaluator.EvaluateParents(candidateParents, peer, uint32(taskTotalPieceCount))
+	candidateParents = s.evaluateParents(candidateParents, peer, uint32(taskTotalPieceCount))
 
 	// Get the parents with candidateParentLimit.
 	candidateParentLimit := config.DefaultSchedulerCandidateParentLimit
@@ -464,9 +519,11 @@ func (s *scheduling) FindParentAndCandidateParents(ctx context.Context, peer *st
 		}
 	}
 
+	s.storeSchedulingExplanation(peer, candidateParents, filteredCandidateParents, candidateParentLimit, uint32(taskTotalPieceCount))
 	if len(candidateParents) > candidateParentLimit {
 		candidateParents = candidateParents[:candidateParentLimit]
 	}
+	s.addEvaluateCandidateParentEvents(ctx, candidateParents, peer, uint32(taskTotalPieceCount))
 
 	var parentIDs []string
 	for _, candidateParent := range candidateParents {
@@ -488,7 +545,7 @@ func (s *scheduling) FindSuccessParent(ctx context.Context, peer *standard.Peer,
 	}
 
 	// Find the candidate parent that can be scheduled.
-	candidateParents := s.filterCandidateParents(peer, blocklist)
+	candidateParents, _ := s.filterCandidateParents(ctx, peer, blocklist)
 	if len(candidateParents) == 0 {
 		peer.Log.Info("can not find candidate parents")
 		return nil, false
@@ -503,14 +560,112 @@ func (s *scheduling) FindSuccessParent(ctx context.Context, peer *standard.Peer,
 
 	// Sort candidate parents by evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	successParents = s.evaluator.EvaluateParents(successParents, peer, uint32(taskTotalPieceCount))
+	successParents = s.evaluateParents(successParents, peer, uint32(taskTotalPieceCount))
 
 	peer.Log.Infof("scheduling success parent is %s", successParents[0].ID)
 	return successParents[0], true
 }
 
-// filterCandidateParents filters the candidate parents that can be scheduled.
-func (s *scheduling) filterCandidateParents(peer *standard.Peer, blocklist set.SafeSet[string]) []*standard.Peer {
+// FindFederatedCandidateParents finds candidate parents in the federated clusters for the peer to download the task,
+// the parents are not stored in the task of the local cluster.
+// Used only in v2 version of the grpc.
+func (s *scheduling) FindFederatedCandidateParents(ctx context.Context, peer *standard.Peer, blocklist set.SafeSet[string]) ([]*standard.Peer, bool) {
+	if s.federation == nil {
+		return []*standard.Peer{}, false
+	}
+
+	var candidateParents []*standard.Peer
+	for _, parent := range s.federation.FindParents(ctx, peer.Task.ID) {
+		if blocklist.Contains(parent.ID) || parent.Host.ID == peer.Host.ID {
+			peer.Log.Debugf("federated parent %s is not selected", parent.ID)
+			continue
+		}
+
+		candidateParents = append(candidateParents, newFederatedParent(peer.Task, parent))
+	}
+
+	if len(candidateParents) == 0 {
+		peer.Log.Info("can not find federated candidate parents")
+		return []*standard.Peer{}, false
+	}
+
+	peer.Log.Infof("find %d federated candidate parents", len(candidateParents))
+	return candidateParents, true
+}
+
+// newFederatedParent returns the succeeded parent in the federated cluster, it shares the task of the local cluster.
+func newFederatedParent(task *standard.Task, parent federation.Peer) *standard.Peer {
+	host := standard.NewHost(parent.Host.ID, parent.Host.IP, parent.Host.Hostname, parent.Host.Port, parent.Host.DownloadPort, types.ParseHostType(parent.Host.Type))
+	host.Network.IDC = parent.Host.IDC
+	host.Network.Location = parent.Host.Location
+
+	peer := standard.NewPeer(parent.ID, task, host)
+	peer.FSM.SetState(standard.PeerStateSucceeded)
+	return peer
+}
+
+// ExplainCandidateParents returns how the candidate parents were filtered and evaluated by the last
+// scheduling of the peer, the current parents of the peer are filled when it is called.
+func (s *scheduling) ExplainCandidateParents(peer *standard.Peer) (*Explanation, bool) {
+	schedulingExplanation, ok := peer.SchedulingExplanation.Load().(*Explanation)
+	if !ok {
+		return nil, false
+	}
+
+	explanation := *schedulingExplanation
+	explanation.Parents = []string{}
+	for _, parent := range peer.Parents() {
+		explanation.Parents = append(explanation.Parents, parent.ID)
+	}
+
+	return &explanation, true
+}
+
+// storeSchedulingExplanation records how the sampled candidate parents are filtered and evaluated
+// by the scheduling on the peer, the candidate parents are sorted by evaluation score.
+func (s *scheduling) storeSchedulingExplanation(peer *standard.Peer, candidateParents []*standard.Peer, filteredCandidateParents []CandidateParent,
+	candidateParentLimit int, totalPieceCount uint32) {
+	explanation := &Explanation{
+		PeerID:               peer.ID,
+		TaskID:               peer.Task.ID,
+		HostID:               peer.Host.ID,
+		State:                peer.FSM.Current(),
+		CandidateParentLimit: candidateParentLimit,
+		CandidateParents:     make([]CandidateParent, 0, len(candidateParents)+len(filteredCandidateParents)),
+		ScheduledAt:          time.Now(),
+	}
+
+	for i, candidateParent := range candidateParents {
+		reason := FilterReasonCandidateParentLimit
+		if i < candidateParentLimit {
+			reason = ""
+		}
+
+		c := newCandidateParent(candidateParent, reason)
+		c.Scores, c.Score = s.explainCandidateParent(candidateParent, peer, totalPieceCount)
+		explanation.CandidateParents = append(explanation.CandidateParents, c)
+	}
+
+	explanation.CandidateParents = append(explanation.CandidateParents, filteredCandidateParents...)
+	peer.SchedulingExplanation.Store(explanation)
+}
+
+// newCandidateParent constructs the explanation of the candidate parent.
+func newCandidateParent(candidateParent *standard.Peer, reason string) CandidateParent {
+	return CandidateParent{
+		ID:           candidateParent.ID,
+		HostID:       candidateParent.Host.ID,
+		Hostname:     candidateParent.Host.Hostname,
+		IP:           candidateParent.Host.IP,
+		State:        candidateParent.FSM.Current(),
+		Selected:     reason == "",
+		FilterReason: reason,
+	}
+}
+
+// filterCandidateParents filters the candidate parents that can be scheduled,
+// and explains the filtered ones.
+func (s *scheduling) filterCandidateParents(ctx context.Context, peer *standard.Peer, blocklist set.SafeSet[string]) ([]*standard.Peer, []CandidateParent) {
 	filterParentLimit := config.DefaultSchedulerFilterParentLimit
 	if config, err := s.dynconfig.GetSchedulerClusterConfig(); err == nil {
 		if config.FilterParentLimit > 0 {
@@ -519,73 +674,130 @@ func (s *scheduling) filterCandidateParents(peer *standard.Peer, blocklist set.S
 	}
 
 	var (
-		candidateParents   []*standard.Peer
-		candidateParentIDs []string
+		candidateParents         []*standard.Peer
+		candidateParentIDs       []string
+		filteredCandidateParents []CandidateParent
 	)
 	for _, candidateParent := range peer.Task.LoadRandomPeers(uint(filterParentLimit)) {
-		// Candidate parent is in blocklist.
-		if blocklist.Contains(candidateParent.ID) {
-			peer.Log.Debugf("parent %s host %s is not selected because it is in blocklist", candidateParent.ID, candidateParent.Host.ID)
-			continue
-		}
+		if reason, ok := s.filterCandidateParent(peer, candidateParent, blocklist); !ok {
+			addFilterCandidateParentEvent(ctx, candidateParent, reason)
+			if candidateParent.ID != peer.ID {
+				filteredCandidateParents = append(filteredCandidateParents, newCandidateParent(candidateParent, reason))
+			}
 
-		// Candidate parent is disable shared.
-		if candidateParent.Host.DisableShared {
-			peer.Log.Debugf("parent %s host %s is not selected because it is disable shared", candidateParent.ID, candidateParent.Host.ID)
 			continue
 		}
 
-		// Candidate parent host is not allowed to be the same as the peer host,
-		// because dfdaemon cannot handle the situation
-		// where two tasks are downloading and downloading each other.
-		if peer.Host.ID == candidateParent.Host.ID {
-			peer.Log.Debugf("parent %s host %s is the same as peer host", candidateParent.ID, candidateParent.Host.ID)
-			continue
-		}
+		candidateParents = append(candidateParents, candidateParent)
+		candidateParentIDs = append(candidateParentIDs, candidateParent.ID)
+	}
 
-		// Candidate parent can not find in dag.
-		inDegree, err := peer.Task.PeerInDegree(candidateParent.ID)
-		if err != nil {
-			peer.Log.Debugf("can not find parent %s host %s vertex in dag", candidateParent.ID, candidateParent.Host.ID)
-			continue
-		}
+	peer.Log.Infof("filter candidate parents is %#v", candidateParentIDs)
+	return candidateParents, filteredCandidateParents
+}
 
-		// Parent can be parent of the peer:
-		// Condition 1: Parent has parent.
-		// Condition 2: Parent has been back-to-source.
-		// Condition 3: Parent has been succeeded.
-		// Condition 4: Parent is seed peer.
-		if candidateParent.Host.Type == types.HostTypeNormal && inDegree == 0 && !candidateParent.FSM.Is(standard.PeerStateBackToSource) &&
-			!candidateParent.FSM.Is(standard.PeerStateSucceeded) {
-			peer.Log.Debugf("parent %s host %s is not selected, because its download state is %d %d %s",
-				candidateParent.ID, candidateParent.Host.ID, inDegree, int(candidateParent.Host.Type), candidateParent.FSM.Current())
-			continue
-		}
+// filterCandidateParent determines whether the candidate parent can be scheduled,
+// if not, it returns the filter reason.
+func (s *scheduling) filterCandidateParent(peer *standard.Peer, candidateParent *standard.Peer, blocklist set.SafeSet[string]) (string, bool) {
+	// Candidate parent is in blocklist.
+	if blocklist.Contains(candidateParent.ID) {
+		peer.Log.Debugf("parent %s host %s is not selected because it is in blocklist", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonBlocklist, false
+	}
 
-		// Candidate parent is bad parent.
-		if s.evaluator.IsBadParent(candidateParent) {
-			peer.Log.Debugf("parent %s host %s is not selected because it is bad node", candidateParent.ID, candidateParent.Host.ID)
-			continue
-		}
+	// Candidate parent is disable shared.
+	if candidateParent.Host.DisableShared {
+		peer.Log.Debugf("parent %s host %s is not selected because it is disable shared", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonDisableShared, false
+	}
 
-		// Candidate parent's free upload is empty.
-		if candidateParent.Host.FreeUploadCount() <= 0 {
-			peer.Log.Debugf("parent %s host %s is not selected because its free upload is empty, upload limit is %d, upload count is %d",
-				candidateParent.ID, candidateParent.Host.ID, candidateParent.Host.ConcurrentUploadLimit.Load(), candidateParent.Host.ConcurrentUploadCount.Load())
-			continue
+	// Candidate parent host is not allowed to be the same as the peer host,
+	// because dfdaemon cannot handle the situation
+	// where two tasks are downloading and downloading each other.
+	if peer.Host.ID == candidateParent.Host.ID {
+		peer.Log.Debugf("parent %s host %s is the same as peer host", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonSameHost, false
+	}
+
+	// Candidate parent can not find in dag.
+	inDegree, err := peer.Task.PeerInDegree(candidateParent.ID)
+	if err != nil {
+		peer.Log.Debugf("can not find parent %s host %s vertex in dag", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonNotInDAG, false
+	}
+
+	// Parent can be parent of the peer:
+	// Condition 1: Parent has parent.
+	// Condition 2: Parent has been back-to-source.
+	// Condition 3: Parent has been succeeded.
+	// Condition 4: Parent is seed peer.
+	if candidateParent.Host.Type == types.HostTypeNormal && inDegree == 0 && !candidateParent.FSM.Is(standard.PeerStateBackToSource) &&
+		!candidateParent.FSM.Is(standard.PeerStateSucceeded) {
+		peer.Log.Debugf("parent %s host %s is not selected, because its download state is %d %d %s",
+			candidateParent.ID, candidateParent.Host.ID, inDegree, int(candidateParent.Host.Type), candidateParent.FSM.Current())
+		return FilterReasonNotReady, false
+	}
+
+	// Candidate parent is bad parent.
+	if s.evaluator.IsBadParent(candidateParent) {
+		peer.Log.Debugf("parent %s host %s is not selected because it is bad node", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonBadNode, false
+	}
+
+	// Candidate parent's free upload is empty.
+	if candidateParent.Host.FreeUploadCount() <= 0 {
+		peer.Log.Debugf("parent %s host %s is not selected because its free upload is empty, upload limit is %d, upload count is %d",
+			candidateParent.ID, candidateParent.Host.ID, candidateParent.Host.ConcurrentUploadLimit.Load(), candidateParent.Host.ConcurrentUploadCount.Load())
+		return FilterReasonUploadFull, false
+	}
+
+	// Candidate parent is vetoed by the filter plugin.
+	if s.hooks != nil {
+		if name, ok := s.hooks.Filter(candidateParent, peer); !ok {
+			peer.Log.Debugf("parent %s host %s is not selected because it is vetoed by filter %s", candidateParent.ID, candidateParent.Host.ID, name)
+			return FilterReasonPlugin + ":" + name, false
 		}
+	}
 
-		// Candidate parent can add edge with peer.
-		if !peer.Task.CanAddPeerEdge(candidateParent.ID, peer.ID) {
-			peer.Log.Debugf("can not add edge with parent %s host %s", candidateParent.ID, candidateParent.Host.ID)
-			continue
+	// Candidate parent can add edge with peer.
+	if !peer.Task.CanAddPeerEdge(candidateParent.ID, peer.ID) {
+		peer.Log.Debugf("can not add edge with parent %s host %s", candidateParent.ID, candidateParent.Host.ID)
+		return FilterReasonDAGCycle, false
+	}
+
+	return "", true
+}
+
+// evaluateParents sorts the candidate parents by evaluation score, the weighted scores of
+// the scorers in hook chain are added to the evaluation score. If the evaluator can not
+// explain its scores, the evaluation score is the rank of the evaluator normalized to (0, 1],
+// so the order of the evaluator still weighs against the scorers.
+func (s *scheduling) evaluateParents(candidateParents []*standard.Peer, peer *standard.Peer, totalPieceCount uint32) []*standard.Peer {
+	candidateParents = s.evaluator.EvaluateParents(candidateParents, peer, totalPieceCount)
+	if s.hooks == nil || !s.hooks.HasScorers() {
+		return candidateParents
+	}
+
+	explainer, explainable := s.evaluator.(evaluator.Explainer)
+	scores := make(map[string]float64, len(candidateParents))
+	for i, candidateParent := range candidateParents {
+		var featureScores []evaluator.Score
+		if explainable {
+			featureScores = explainer.ExplainParent(candidateParent, peer, totalPieceCount)
+		} else {
+			featureScores = []evaluator.Score{{Weight: 1, Value: float64(len(candidateParents)-i) / float64(len(candidateParents))}}
 		}
 
-		candidateParents = append(candidateParents, candidateParent)
-		candidateParentIDs = append(candidateParentIDs, candidateParent.ID)
+		for _, featureScore := range append(featureScores, s.hooks.Score(candidateParent, peer)...) {
+			scores[candidateParent.ID] += featureScore.Weight * featureScore.Value
+		}
 	}
 
-	peer.Log.Infof("filter candidate parents is %#v", candidateParentIDs)
+	// The stable sort keeps the order of the evaluator for the candidate parents with the same score.
+	sort.SliceStable(candidateParents, func(i, j int) bool {
+		return scores[candidateParents[i].ID] > scores[candidateParents[j].ID]
+	})
+
 	return candidateParents
 }
 
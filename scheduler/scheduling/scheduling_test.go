@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
@@ -48,9 +49,12 @@ import (
 	pkgtypes "d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
+	"d7y.io/dragonfly/v2/scheduler/federation"
+	federationmocks "d7y.io/dragonfly/v2/scheduler/federation/mocks"
 	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/scheduling/hook"
 )
 
 var (
@@ -1640,6 +1644,388 @@ func TestScheduling_FindSuccessParent(t *testing.T) {
 	}
 }
 
+func TestScheduling_ExplainCandidateParents(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(peer *standard.Peer, mockPeers []*standard.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder)
+		expect func(t *testing.T, peer *standard.Peer, mockPeers []*standard.Peer, explanation *Explanation, ok bool)
+	}{
+		{
+			name: "peer has not been scheduled",
+			mock: func(peer *standard.Peer, mockPeers []*standard.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(standard.PeerStateSucceeded)
+				peer.Task.StorePeer(peer)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, mockPeers []*standard.Peer, explanation *Explanation, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Nil(explanation)
+			},
+		},
+		{
+			name: "task contains only one peer and peer is itself",
+			mock: func(peer *standard.Peer, mockPeers []*standard.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(standard.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, mockPeers []*standard.Peer, explanation *Explanation, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(peer.ID, explanation.PeerID)
+				assert.Equal(peer.Task.ID, explanation.TaskID)
+				assert.Equal(peer.Host.ID, explanation.HostID)
+				assert.Equal(standard.PeerStateRunning, explanation.State)
+				assert.Empty(explanation.Parents)
+				assert.Equal(0, explanation.CandidateParentLimit)
+				assert.Empty(explanation.CandidateParents)
+				assert.False(explanation.ScheduledAt.IsZero())
+			},
+		},
+		{
+			name: "explain filter reasons and scores of candidate parents",
+			mock: func(peer *standard.Peer, mockPeers []*standard.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(standard.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				for _, mockPeer := range mockPeers[:6] {
+					mockPeer.FSM.SetState(standard.PeerStateBackToSource)
+					peer.Task.StorePeer(mockPeer)
+				}
+
+				blocklist.Add(mockPeers[0].ID)
+				mockPeers[1].Host.DisableShared = true
+				mockPeers[2].FSM.SetState(standard.PeerStateRunning)
+				mockPeers[3].Host.ConcurrentUploadLimit.Store(0)
+				mockPeers[4].FinishedPieces.Set(0)
+				mockPeers[5].FinishedPieces.Set(0)
+				mockPeers[5].FinishedPieces.Set(1)
+				mockPeers[5].FinishedPieces.Set(2)
+
+				sameHostPeer := standard.NewPeer(idgen.PeerIDV1("127.0.0.100"), peer.Task, peer.Host)
+				sameHostPeer.FSM.SetState(standard.PeerStateBackToSource)
+				peer.Task.StorePeer(sameHostPeer)
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+					CandidateParentLimit: 1,
+				}, nil).Times(2)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, mockPeers []*standard.Peer, explanation *Explanation, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(1, explanation.CandidateParentLimit)
+				assert.Len(explanation.CandidateParents, 7)
+
+				// Evaluated candidate parents are sorted by score and followed by the filtered ones.
+				assert.Equal(mockPeers[5].ID, explanation.CandidateParents[0].ID)
+				assert.True(explanation.CandidateParents[0].Selected)
+				assert.Empty(explanation.CandidateParents[0].FilterReason)
+				assert.Len(explanation.CandidateParents[0].Scores, 6)
+				assert.Equal(mockPeers[4].ID, explanation.CandidateParents[1].ID)
+				assert.False(explanation.CandidateParents[1].Selected)
+				assert.Equal(FilterReasonCandidateParentLimit, explanation.CandidateParents[1].FilterReason)
+				assert.Greater(explanation.CandidateParents[0].Score, explanation.CandidateParents[1].Score)
+
+				reasons := make(map[string]string)
+				for _, candidateParent := range explanation.CandidateParents[2:] {
+					assert.False(candidateParent.Selected)
+					assert.Empty(candidateParent.Scores)
+					if candidateParent.HostID == peer.Host.ID {
+						assert.Equal(FilterReasonSameHost, candidateParent.FilterReason)
+						continue
+					}
+
+					reasons[candidateParent.ID] = candidateParent.FilterReason
+				}
+
+				assert.Equal(FilterReasonBlocklist, reasons[mockPeers[0].ID])
+				assert.Equal(FilterReasonDisableShared, reasons[mockPeers[1].ID])
+				assert.Equal(FilterReasonNotReady, reasons[mockPeers[2].ID])
+				assert.Equal(FilterReasonUploadFull, reasons[mockPeers[3].ID])
+				assert.Len(reasons, 4)
+			},
+		},
+		{
+			name: "report parents of the last scheduling",
+			mock: func(peer *standard.Peer, mockPeers []*standard.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(standard.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				mockPeers[0].FSM.SetState(standard.PeerStateBackToSource)
+				peer.Task.StorePeer(mockPeers[0])
+				if err := peer.Task.AddPeerEdge(mockPeers[0], peer); err != nil {
+					t.Fatal(err)
+				}
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, mockPeers []*standard.Peer, explanation *Explanation, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal([]string{mockPeers[0].ID}, explanation.Parents)
+				assert.Len(explanation.CandidateParents, 1)
+				assert.Equal(FilterReasonDAGCycle, explanation.CandidateParents[0].FilterReason)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			persistentCacheResource := persistentcache.NewMockResource(ctl)
+			mockHost := standard.NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+			peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+
+			var mockPeers []*standard.Peer
+			for i := 0; i < 11; i++ {
+				mockHost := standard.NewHost(
+					idgen.HostIDV2("127.0.0.1", uuid.New().String(), false), mockRawHost.IP, mockRawHost.Hostname,
+					mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+				peer := standard.NewPeer(idgen.PeerIDV1(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost)
+				mockPeers = append(mockPeers, peer)
+			}
+
+			blocklist := set.NewSafeSet[string]()
+			tc.mock(peer, mockPeers, blocklist, dynconfig.EXPECT())
+			scheduling := New(mockSchedulerConfig, persistentCacheResource, dynconfig, mockPluginDir)
+			scheduling.FindCandidateParents(context.Background(), peer, blocklist)
+			explanation, ok := scheduling.ExplainCandidateParents(peer)
+			tc.expect(t, peer, mockPeers, explanation, ok)
+		})
+	}
+}
+
+func TestScheduling_ExplainCandidateParentsWithHooks(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	persistentCacheResource := persistentcache.NewMockResource(ctl)
+	mockHost := standard.NewHost(
+		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+		mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+	mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+	peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+	peer.FSM.SetState(standard.PeerStateRunning)
+	mockTask.StorePeer(peer)
+
+	var mockPeers []*standard.Peer
+	for i := 0; i < 3; i++ {
+		mockHost := standard.NewHost(
+			idgen.HostIDV2("127.0.0.1", uuid.New().String(), false), mockRawHost.IP, mockRawHost.Hostname,
+			mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+		mockPeer := standard.NewPeer(idgen.PeerIDV1(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost)
+		mockPeer.FSM.SetState(standard.PeerStateBackToSource)
+		mockTask.StorePeer(mockPeer)
+		mockPeers = append(mockPeers, mockPeer)
+	}
+
+	mockPeers[0].Host.StoreLabels(map[string]string{"lifecycle": "spot"})
+	mockPeers[1].FinishedPieces.Set(0)
+	mockPeers[1].FinishedPieces.Set(1)
+	mockPeers[2].Host.StoreLabels(map[string]string{"disk": "ssd"})
+
+	hooks, err := hook.New(&config.PluginsConfig{
+		Filters: []config.FilterPluginConfig{
+			{
+				Name:  "spot",
+				Rules: []config.LabelRule{{Key: "lifecycle", Operator: config.LabelRuleOperatorIn, Values: []string{"spot"}}},
+			},
+		},
+		Scores: []config.ScorePluginConfig{
+			{
+				Name:   "ssd",
+				Weight: 1,
+				Rules:  []config.LabelRule{{Key: "disk", Operator: config.LabelRuleOperatorExists}},
+			},
+		},
+	}, mockPluginDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(2)
+	scheduling := New(mockSchedulerConfig, persistentCacheResource, dynconfig, mockPluginDir, WithHooks(hooks))
+	scheduling.FindCandidateParents(context.Background(), peer, set.NewSafeSet[string]())
+	explanation, ok := scheduling.ExplainCandidateParents(peer)
+
+	assert := assert.New(t)
+	assert.True(ok)
+	assert.Len(explanation.CandidateParents, 3)
+
+	// The score of the score plugin outweighs the finished pieces.
+	assert.Equal(mockPeers[2].ID, explanation.CandidateParents[0].ID)
+	assert.True(explanation.CandidateParents[0].Selected)
+	assert.Len(explanation.CandidateParents[0].Scores, 7)
+	assert.Equal(evaluator.Score{Feature: "ssd", Weight: 1, Value: 1}, explanation.CandidateParents[0].Scores[6])
+	assert.Equal(mockPeers[1].ID, explanation.CandidateParents[1].ID)
+	assert.True(explanation.CandidateParents[1].Selected)
+	assert.Equal(evaluator.Score{Feature: "ssd", Weight: 1, Value: 0}, explanation.CandidateParents[1].Scores[6])
+	assert.Greater(explanation.CandidateParents[0].Score, explanation.CandidateParents[1].Score)
+
+	// The candidate parent on the spot instance is vetoed by the filter plugin.
+	assert.Equal(mockPeers[0].ID, explanation.CandidateParents[2].ID)
+	assert.False(explanation.CandidateParents[2].Selected)
+	assert.Equal(FilterReasonPlugin+":spot", explanation.CandidateParents[2].FilterReason)
+	assert.Empty(explanation.CandidateParents[2].Scores)
+}
+
+// unexplainableEvaluator keeps the order of the parents and can not explain the scores.
+type unexplainableEvaluator struct {
+	evaluator.Evaluator
+}
+
+func (e *unexplainableEvaluator) EvaluateParents(parents []*standard.Peer, child *standard.Peer, taskPieceCount uint32) []*standard.Peer {
+	return parents
+}
+
+func TestScheduling_evaluateParentsWithUnexplainableEvaluator(t *testing.T) {
+	mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+	peer := standard.NewPeer(mockPeerID, mockTask, standard.NewHost(
+		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+		mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type))
+
+	var mockPeers []*standard.Peer
+	for i := 0; i < 3; i++ {
+		mockHost := standard.NewHost(
+			idgen.HostIDV2("127.0.0.1", uuid.New().String(), false), mockRawHost.IP, mockRawHost.Hostname,
+			mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+		mockPeers = append(mockPeers, standard.NewPeer(idgen.PeerIDV1(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost))
+	}
+	mockPeers[2].Host.StoreLabels(map[string]string{"disk": "ssd"})
+
+	hooks, err := hook.New(&config.PluginsConfig{
+		Scores: []config.ScorePluginConfig{
+			{
+				Name:   "ssd",
+				Weight: 0.5,
+				Rules:  []config.LabelRule{{Key: "disk", Operator: config.LabelRuleOperatorExists}},
+			},
+		},
+	}, mockPluginDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ranks of the evaluator are 1, 2/3 and 1/3, the score plugin only
+	// lifts the last parent over the second one.
+	s := &scheduling{evaluator: &unexplainableEvaluator{}, hooks: hooks}
+	assert.Equal(t, []*standard.Peer{mockPeers[0], mockPeers[2], mockPeers[1]}, s.evaluateParents(slices.Clone(mockPeers), peer, 0))
+}
+
+func TestScheduling_FindFederatedCandidateParents(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(peer *standard.Peer, blocklist set.SafeSet[string], mf *federationmocks.MockFederationMockRecorder)
+		expect func(t *testing.T, peer *standard.Peer, parents []*standard.Peer, ok bool)
+	}{
+		{
+			name: "find federated candidate parents",
+			mock: func(peer *standard.Peer, blocklist set.SafeSet[string], mf *federationmocks.MockFederationMockRecorder) {
+				mf.FindParents(gomock.Any(), gomock.Eq(peer.Task.ID)).Return([]federation.Peer{
+					{
+						ID:                 "foo",
+						FinishedPieceCount: 10,
+						Host: federation.Host{
+							ID:           "foo",
+							Type:         pkgtypes.HostTypeSuperSeedName,
+							Hostname:     "foo",
+							IP:           "127.0.0.2",
+							Port:         8003,
+							DownloadPort: 8001,
+							IDC:          "bar",
+							Location:     "baz",
+						},
+					},
+				}).Times(1)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, parents []*standard.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Len(parents, 1)
+				assert.Equal("foo", parents[0].ID)
+				assert.Equal(peer.Task, parents[0].Task)
+				assert.Equal(standard.PeerStateSucceeded, parents[0].FSM.Current())
+				assert.Equal(pkgtypes.HostTypeSuperSeed, parents[0].Host.Type)
+				assert.Equal("127.0.0.2", parents[0].Host.IP)
+				assert.Equal(int32(8001), parents[0].Host.DownloadPort)
+				assert.Equal("bar", parents[0].Host.Network.IDC)
+				assert.Equal("baz", parents[0].Host.Network.Location)
+				_, loaded := peer.Task.LoadPeer("foo")
+				assert.False(loaded)
+			},
+		},
+		{
+			name: "federated parents are in blocklist or on the same host",
+			mock: func(peer *standard.Peer, blocklist set.SafeSet[string], mf *federationmocks.MockFederationMockRecorder) {
+				blocklist.Add("foo")
+				mf.FindParents(gomock.Any(), gomock.Eq(peer.Task.ID)).Return([]federation.Peer{
+					{ID: "foo", Host: federation.Host{ID: "foo"}},
+					{ID: "bar", Host: federation.Host{ID: peer.Host.ID}},
+				}).Times(1)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, parents []*standard.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Empty(parents)
+			},
+		},
+		{
+			name: "federated parents not found",
+			mock: func(peer *standard.Peer, blocklist set.SafeSet[string], mf *federationmocks.MockFederationMockRecorder) {
+				mf.FindParents(gomock.Any(), gomock.Eq(peer.Task.ID)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, peer *standard.Peer, parents []*standard.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Empty(parents)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			persistentCacheResource := persistentcache.NewMockResource(ctl)
+			federation := federationmocks.NewMockFederation(ctl)
+			mockHost := standard.NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+			peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+
+			blocklist := set.NewSafeSet[string]()
+			tc.mock(peer, blocklist, federation.EXPECT())
+			scheduling := New(mockSchedulerConfig, persistentCacheResource, dynconfig, mockPluginDir).(*scheduling)
+			scheduling.federation = federation
+			parents, ok := scheduling.FindFederatedCandidateParents(context.Background(), peer, blocklist)
+			tc.expect(t, peer, parents, ok)
+		})
+	}
+}
+
+func TestScheduling_FindFederatedCandidateParentsWithoutFederation(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockHost := standard.NewHost(
+		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+		mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+	mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+	peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+
+	scheduling := New(mockSchedulerConfig, persistentcache.NewMockResource(ctl), configmocks.NewMockDynconfigInterface(ctl), mockPluginDir)
+	parents, ok := scheduling.FindFederatedCandidateParents(context.Background(), peer, set.NewSafeSet[string]())
+
+	assert := assert.New(t)
+	assert.False(ok)
+	assert.Empty(parents)
+}
+
 func TestScheduling_constructSuccessNormalTaskResponse(t *testing.T) {
 	tests := []struct {
 		name   string
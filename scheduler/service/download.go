@@ -0,0 +1,97 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+// createDownload records the finished download of the peer,
+// it does nothing if the storage is not set.
+func createDownload(s storage.Storage, peer *standard.Peer, state string) {
+	if s == nil {
+		return
+	}
+
+	if err := s.CreateDownload(newDownload(peer, state)); err != nil {
+		peer.Log.Errorf("create download failed: %s", err.Error())
+	}
+}
+
+// newDownload constructs the download record of the peer.
+func newDownload(peer *standard.Peer, state string) storage.Download {
+	parents := make([]storage.Parent, 0)
+	for _, parent := range peer.Parents() {
+		parents = append(parents, storage.Parent{
+			ID:                 parent.ID,
+			State:              parent.FSM.Current(),
+			Cost:               parent.Cost.Load().Nanoseconds(),
+			FinishedPieceCount: int32(parent.FinishedPieces.Count()),
+			Host:               newDownloadHost(parent.Host),
+			CreatedAt:          parent.CreatedAt.Load().UnixNano(),
+			UpdatedAt:          parent.UpdatedAt.Load().UnixNano(),
+		})
+	}
+
+	return storage.Download{
+		ID:                 peer.ID,
+		Tag:                peer.Task.Tag,
+		Application:        peer.Task.Application,
+		State:              state,
+		Cost:               peer.Cost.Load().Nanoseconds(),
+		FinishedPieceCount: int32(peer.FinishedPieces.Count()),
+		BackToSource:       peer.Task.BackToSourcePeers.Contains(peer.ID),
+		PieceCosts:         storage.DurationsToNanoseconds(peer.PieceCosts()),
+		Task: storage.Task{
+			ID:                    peer.Task.ID,
+			URL:                   peer.Task.URL,
+			Type:                  peer.Task.Type.String(),
+			ContentLength:         peer.Task.ContentLength.Load(),
+			TotalPieceCount:       peer.Task.TotalPieceCount.Load(),
+			BackToSourceLimit:     peer.Task.BackToSourceLimit.Load(),
+			BackToSourcePeerCount: int32(peer.Task.BackToSourcePeers.Len()),
+			State:                 peer.Task.FSM.Current(),
+			CreatedAt:             peer.Task.CreatedAt.Load().UnixNano(),
+			UpdatedAt:             peer.Task.UpdatedAt.Load().UnixNano(),
+		},
+		Host:      newDownloadHost(peer.Host),
+		Parents:   parents,
+		CreatedAt: peer.CreatedAt.Load().UnixNano(),
+		UpdatedAt: peer.UpdatedAt.Load().UnixNano(),
+	}
+}
+
+// newDownloadHost constructs the host of the download record.
+func newDownloadHost(host *standard.Host) storage.Host {
+	return storage.Host{
+		ID:                    host.ID,
+		Type:                  host.Type.Name(),
+		Hostname:              host.Hostname,
+		IP:                    host.IP,
+		Port:                  host.Port,
+		DownloadPort:          host.DownloadPort,
+		Location:              host.Network.Location,
+		IDC:                   host.Network.IDC,
+		ConcurrentUploadLimit: host.ConcurrentUploadLimit.Load(),
+		ConcurrentUploadCount: host.ConcurrentUploadCount.Load(),
+		UploadCount:           host.UploadCount.Load(),
+		UploadFailedCount:     host.UploadFailedCount.Load(),
+		CreatedAt:             host.CreatedAt.Load().UnixNano(),
+		UpdatedAt:             host.UpdatedAt.Load().UnixNano(),
+	}
+}
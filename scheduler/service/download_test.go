@@ -0,0 +1,129 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	commonv2 "d7y.io/api/v2/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/scheduler/resource/standard"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+func TestService_newDownload(t *testing.T) {
+	mockHost := standard.NewHost(
+		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+		mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+	mockSeedHost := standard.NewHost(
+		mockRawSeedHost.ID, mockRawSeedHost.IP, mockRawSeedHost.Hostname,
+		mockRawSeedHost.Port, mockRawSeedHost.DownloadPort, mockRawSeedHost.Type)
+	mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+	peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+	seedPeer := standard.NewPeer(mockSeedPeerID, mockTask, mockSeedHost)
+
+	mockTask.StorePeer(peer)
+	mockTask.StorePeer(seedPeer)
+	if err := mockTask.AddPeerEdge(seedPeer, peer); err != nil {
+		t.Fatal(err)
+	}
+
+	mockTask.BackToSourcePeers.Add(seedPeer.ID)
+	seedPeer.FSM.SetState(standard.PeerStateSucceeded)
+	peer.FSM.SetState(standard.PeerStateSucceeded)
+	peer.FinishedPieces.Set(0)
+	peer.FinishedPieces.Set(1)
+	peer.AppendPieceCost(time.Second)
+	peer.AppendPieceCost(2 * time.Second)
+	peer.Cost.Store(3 * time.Second)
+
+	assert := assert.New(t)
+	download := newDownload(peer, storage.DownloadStateSucceeded)
+	assert.Equal(peer.ID, download.ID)
+	assert.Equal(mockTaskTag, download.Tag)
+	assert.Equal(mockTaskApplication, download.Application)
+	assert.Equal(storage.DownloadStateSucceeded, download.State)
+	assert.Equal(int64(3*time.Second), download.Cost)
+	assert.Equal(int32(2), download.FinishedPieceCount)
+	assert.False(download.BackToSource)
+	assert.Equal([]int64{int64(time.Second), int64(2 * time.Second)}, download.PieceCosts)
+	assert.Equal(mockTaskID, download.Task.ID)
+	assert.Equal(mockTaskURL, download.Task.URL)
+	assert.Equal(commonv2.TaskType_STANDARD.String(), download.Task.Type)
+	assert.Equal(int32(1), download.Task.BackToSourcePeerCount)
+	assert.Equal(mockRawHost.ID, download.Host.ID)
+	assert.Equal(mockRawHost.Type.Name(), download.Host.Type)
+	assert.Len(download.Parents, 1)
+	assert.Equal(seedPeer.ID, download.Parents[0].ID)
+	assert.Equal(standard.PeerStateSucceeded, download.Parents[0].State)
+	assert.Equal(mockRawSeedHost.ID, download.Parents[0].Host.ID)
+
+	download = newDownload(seedPeer, storage.DownloadStateSucceeded)
+	assert.True(download.BackToSource)
+	assert.Empty(download.Parents)
+}
+
+func TestService_createDownload(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(ms *storagemocks.MockStorageMockRecorder)
+	}{
+		{
+			name: "create download",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "create download failed",
+			mock: func(ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(errors.New("foo")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			mockHost := standard.NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+			peer := standard.NewPeer(mockPeerID, mockTask, mockHost)
+
+			tc.mock(mockStorage.EXPECT())
+			createDownload(mockStorage, peer, storage.DownloadStateSucceeded)
+		})
+	}
+
+	t.Run("storage is nil", func(t *testing.T) {
+		mockHost := standard.NewHost(
+			mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+			mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+		mockTask := standard.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, standard.WithDigest(mockTaskDigest), standard.WithPieceLength(mockTaskPieceLength))
+		createDownload(nil, standard.NewPeer(mockPeerID, mockTask, mockHost), storage.DownloadStateFailed)
+	})
+}
@@ -0,0 +1,35 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+)
+
+// hostLabelsFromContext returns the labels announced by the host with grpc metadata.
+func hostLabelsFromContext(ctx context.Context) map[string]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return map[string]string{}
+	}
+
+	return types.ParseHostLabels(md.Get(types.HostLabelsMetadataKey))
+}
@@ -0,0 +1,72 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+)
+
+func TestService_hostLabelsFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect func(t *testing.T, labels map[string]string)
+	}{
+		{
+			name: "context without metadata",
+			ctx:  context.Background(),
+			expect: func(t *testing.T, labels map[string]string) {
+				assert := assert.New(t)
+				assert.Empty(labels)
+			},
+		},
+		{
+			name: "context with labels",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.HostLabelsMetadataKey, "lifecycle=spot",
+				types.HostLabelsMetadataKey, "disk=",
+			)),
+			expect: func(t *testing.T, labels map[string]string) {
+				assert := assert.New(t)
+				assert.Equal(labels, map[string]string{"lifecycle": "spot", "disk": ""})
+			},
+		},
+		{
+			name: "context with invalid labels",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.HostLabelsMetadataKey, "lifecycle",
+				types.HostLabelsMetadataKey, "=spot",
+			)),
+			expect: func(t *testing.T, labels map[string]string) {
+				assert := assert.New(t)
+				assert.Empty(labels)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, hostLabelsFromContext(tc.ctx))
+		})
+	}
+}
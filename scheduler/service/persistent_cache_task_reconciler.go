@@ -0,0 +1,151 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
+	"d7y.io/dragonfly/v2/scheduler/scheduling"
+)
+
+const (
+	// GCPersistentCacheTaskReconcilerID is the id of the persistent cache task reconciler in gc.
+	GCPersistentCacheTaskReconcilerID = "persistent-cache-task-reconciler"
+)
+
+// persistentCacheTaskReplicator replicates the persistent cache tasks to the hosts.
+type persistentCacheTaskReplicator struct {
+	// Persistent cache resource interface.
+	persistentCacheResource persistentcache.Resource
+
+	// Scheduling interface.
+	scheduling scheduling.Scheduling
+}
+
+// newPersistentCacheTaskReplicator returns a new persistentCacheTaskReplicator.
+func newPersistentCacheTaskReplicator(persistentCacheResource persistentcache.Resource, scheduling scheduling.Scheduling) *persistentCacheTaskReplicator {
+	return &persistentCacheTaskReplicator{
+		persistentCacheResource: persistentCacheResource,
+		scheduling:              scheduling,
+	}
+}
+
+// PersistentCacheTaskReconciler reconciles the persistent replicas of the persistent cache tasks.
+type PersistentCacheTaskReconciler struct {
+	// Persistent cache resource interface.
+	persistentCacheResource persistentcache.Resource
+
+	// Replicator of the persistent cache tasks.
+	replicator *persistentCacheTaskReplicator
+
+	// replicationLockTTL is the ttl of the replication lock of the persistent cache task,
+	// the task is not replicated again by any scheduler in the cluster before it expires.
+	replicationLockTTL time.Duration
+}
+
+// NewPersistentCacheTaskReconciler returns a new PersistentCacheTaskReconciler.
+func NewPersistentCacheTaskReconciler(persistentCacheResource persistentcache.Resource, scheduling scheduling.Scheduling, replicationLockTTL time.Duration) *PersistentCacheTaskReconciler {
+	return &PersistentCacheTaskReconciler{
+		persistentCacheResource: persistentCacheResource,
+		replicator:              newPersistentCacheTaskReplicator(persistentCacheResource, scheduling),
+		replicationLockTTL:      replicationLockTTL,
+	}
+}
+
+// RunGC reconciles the persistent replicas of the persistent cache tasks, it is run by gc periodically.
+func (r *PersistentCacheTaskReconciler) RunGC() error {
+	return r.Reconcile(context.Background())
+}
+
+// Reconcile reconciles the persistent replicas of the persistent cache tasks. The persistent
+// replicas will be lost when the hosts leave or the data are reclaimed, so the stale persistent peers are deleted and
+// the persistent cache tasks are replicated again if the current persistent replica count is less than the desired.
+func (r *PersistentCacheTaskReconciler) Reconcile(ctx context.Context) error {
+	if r.persistentCacheResource == nil {
+		return nil
+	}
+
+	tasks, err := r.persistentCacheResource.TaskManager().LoadAll(ctx)
+	if err != nil {
+		logger.Errorf("load all persistent cache tasks failed %s", err)
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := r.reconcile(ctx, task); err != nil {
+			task.Log.Errorf("reconcile persistent cache task failed %s", err)
+		}
+	}
+
+	return nil
+}
+
+// reconcile reconciles the persistent replicas of the persistent cache task.
+func (r *PersistentCacheTaskReconciler) reconcile(ctx context.Context, task *persistentcache.Task) error {
+	// The persistent cache task is uploading or failed, there is no replica to replicate from.
+	if !task.FSM.Is(persistentcache.TaskStateSucceeded) {
+		return nil
+	}
+
+	stalePeerIDs, err := r.persistentCacheResource.PeerManager().DeleteStalePersistentByTaskID(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(stalePeerIDs) > 0 {
+		task.Log.Infof("delete stale persistent peers %#v", stalePeerIDs)
+	}
+
+	currentPersistentReplicaCount, err := r.persistentCacheResource.TaskManager().LoadCurrentPersistentReplicaCount(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	if currentPersistentReplicaCount >= task.PersistentReplicaCount {
+		return nil
+	}
+
+	currentReplicaCount, err := r.persistentCacheResource.TaskManager().LoadCorrentReplicaCount(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	// All replicas have been lost, the persistent cache task can not be replicated from any peer.
+	if currentReplicaCount == 0 {
+		task.Log.Warn("all replicas have been lost")
+		return nil
+	}
+
+	// The replications are asynchronous and the replicas are not counted until they finish,
+	// so the task is locked to prevent the schedulers from replicating it again in the meantime.
+	locked, err := r.persistentCacheResource.TaskManager().LockReplication(ctx, task.ID, r.replicationLockTTL)
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		task.Log.Info("persistent cache task is being replicated")
+		return nil
+	}
+
+	task.Log.Infof("current persistent replica count %d is less than %d, replicate persistent cache task", currentPersistentReplicaCount, task.PersistentReplicaCount)
+	return r.replicator.replicate(ctx, task, set.NewSafeSet[string]())
+}
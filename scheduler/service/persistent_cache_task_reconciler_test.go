@@ -0,0 +1,196 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
+	schedulingmocks "d7y.io/dragonfly/v2/scheduler/scheduling/mocks"
+)
+
+func TestPersistentCacheTaskReconciler_Reconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder)
+	}{
+		{
+			name: "load all persistent cache tasks failed",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.EqualError(r.Reconcile(context.Background()), "foo")
+			},
+		},
+		{
+			name: "persistent cache task is not succeeded",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				task.FSM.SetState(persistentcache.TaskStateUploading)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "delete stale persistent peers failed",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return(nil, errors.New("foo")).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "persistent replicas are enough",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return(nil, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(task.PersistentReplicaCount, nil).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "all replicas have been lost",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return([]string{"foo", "bar"}, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(0), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(0), nil).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "replicate persistent cache task",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return([]string{"foo"}, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LockReplication(gomock.Any(), gomock.Eq(task.ID), gomock.Eq(time.Minute)).Return(true, nil).Times(1),
+					ms.FindReplicatePersistentCacheHosts(gomock.Any(), gomock.Eq(task), gomock.Any()).Return(nil, nil, false).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "lock replication failed",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return(nil, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LockReplication(gomock.Any(), gomock.Eq(task.ID), gomock.Eq(time.Minute)).Return(false, errors.New("foo")).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+		{
+			name: "reconcile back-to-back while the persistent cache task is being replicated",
+			run: func(t *testing.T, r *PersistentCacheTaskReconciler, task *persistentcache.Task, taskManager persistentcache.TaskManager, peerManager persistentcache.PeerManager, mr *persistentcache.MockResourceMockRecorder, mt *persistentcache.MockTaskManagerMockRecorder, mp *persistentcache.MockPeerManagerMockRecorder, ms *schedulingmocks.MockSchedulingMockRecorder) {
+				// The replicas of the first reconcile are in flight and not counted by the second one,
+				// so only the first reconcile replicates the persistent cache task.
+				var locked bool
+				mr.TaskManager().Return(taskManager).AnyTimes()
+				mr.PeerManager().Return(peerManager).AnyTimes()
+				mt.LoadAll(gomock.Any()).Return([]*persistentcache.Task{task}, nil).Times(2)
+				mp.DeleteStalePersistentByTaskID(gomock.Any(), gomock.Eq(task.ID)).Return(nil, nil).Times(2)
+				mt.LoadCurrentPersistentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(2)
+				mt.LoadCorrentReplicaCount(gomock.Any(), gomock.Eq(task.ID)).Return(uint64(1), nil).Times(2)
+				mt.LockReplication(gomock.Any(), gomock.Eq(task.ID), gomock.Eq(time.Minute)).DoAndReturn(
+					func(ctx context.Context, taskID string, ttl time.Duration) (bool, error) {
+						if locked {
+							return false, nil
+						}
+
+						locked = true
+						return true, nil
+					}).Times(2)
+				ms.FindReplicatePersistentCacheHosts(gomock.Any(), gomock.Eq(task), gomock.Any()).Return(nil, nil, false).Times(1)
+
+				assert := assert.New(t)
+				assert.NoError(r.Reconcile(context.Background()))
+				assert.NoError(r.Reconcile(context.Background()))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduling := schedulingmocks.NewMockScheduling(ctl)
+			persistentCacheResource := persistentcache.NewMockResource(ctl)
+			taskManager := persistentcache.NewMockTaskManager(ctl)
+			peerManager := persistentcache.NewMockPeerManager(ctl)
+			task := persistentcache.NewTask(mockTaskID, mockTaskTag, mockTaskApplication, persistentcache.TaskStateSucceeded, 2, uint64(mockTaskPieceLength), 1024, 1, time.Hour, time.Now(), time.Now(), logger.WithTaskID(mockTaskID))
+
+			r := NewPersistentCacheTaskReconciler(persistentCacheResource, scheduling, time.Minute)
+			tc.run(t, r, task, taskManager, peerManager, persistentCacheResource.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), scheduling.EXPECT())
+		})
+	}
+}
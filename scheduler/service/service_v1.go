@@ -46,6 +46,7 @@ import (
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	resource "d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
+	"d7y.io/dragonfly/v2/scheduler/storage"
 )
 
 // V1 is the interface for v1 version of the service.
@@ -61,6 +62,9 @@ type V1 struct {
 
 	// Dynamic config.
 	dynconfig config.DynconfigInterface
+
+	// Storage interface.
+	storage storage.Storage
 }
 
 // New v1 version of service instance.
@@ -69,12 +73,14 @@ func NewV1(
 	resource resource.Resource,
 	scheduling scheduling.Scheduling,
 	dynconfig config.DynconfigInterface,
+	storage storage.Storage,
 ) *V1 {
 	return &V1{
 		resource:   resource,
 		scheduling: scheduling,
 		config:     cfg,
 		dynconfig:  dynconfig,
+		storage:    storage,
 	}
 }
 
@@ -253,9 +259,13 @@ func (v *V1) ReportPieceResult(stream schedulerv1.Scheduler_ReportPieceResultSer
 			if !resource.IsPieceBackToSource(piece.DstPid) {
 				metrics.Traffic.WithLabelValues(commonv2.TrafficType_REMOTE_PEER.String(), peer.Task.Type.String(),
 					peer.Host.Type.Name()).Add(float64(piece.PieceInfo.RangeSize))
+				metrics.AddTaskTraffic(commonv2.TrafficType_REMOTE_PEER, peer.Task.Application, peer.Task.Tag, peer.Priority,
+					peer.Task.ID, uint64(piece.PieceInfo.RangeSize))
 			} else {
 				metrics.Traffic.WithLabelValues(commonv2.TrafficType_BACK_TO_SOURCE.String(), peer.Task.Type.String(),
 					peer.Host.Type.Name()).Add(float64(piece.PieceInfo.RangeSize))
+				metrics.AddTaskTraffic(commonv2.TrafficType_BACK_TO_SOURCE, peer.Task.Application, peer.Task.Tag, peer.Priority,
+					peer.Task.ID, uint64(piece.PieceInfo.RangeSize))
 			}
 			continue
 		}
@@ -461,7 +471,7 @@ func (v *V1) AnnounceHost(ctx context.Context, req *schedulerv1.AnnounceHostRequ
 	// Get scheduler cluster client config by manager.
 	var concurrentUploadLimit int32
 	if clientConfig, err := v.dynconfig.GetSchedulerClusterClientConfig(); err == nil {
-		concurrentUploadLimit = int32(clientConfig.LoadLimit)
+		concurrentUploadLimit = int32(config.ClientConfigForHost(clientConfig, req.GetId(), req.GetHostname(), req.GetIp()).LoadLimit)
 	}
 
 	host, loaded := v.resource.HostManager().Load(req.GetId())
@@ -473,6 +483,7 @@ func (v *V1) AnnounceHost(ctx context.Context, req *schedulerv1.AnnounceHostRequ
 			resource.WithPlatformVersion(req.GetPlatformVersion()),
 			resource.WithKernelVersion(req.GetKernelVersion()),
 			resource.WithSchedulerClusterID(uint64(v.config.Manager.SchedulerClusterID)),
+			resource.WithLabels(hostLabelsFromContext(ctx)),
 		}
 
 		if concurrentUploadLimit > 0 {
@@ -565,6 +576,7 @@ func (v *V1) AnnounceHost(ctx context.Context, req *schedulerv1.AnnounceHostRequ
 	host.PlatformFamily = req.GetPlatformFamily()
 	host.PlatformVersion = req.GetPlatformVersion()
 	host.KernelVersion = req.GetKernelVersion()
+	host.StoreLabels(hostLabelsFromContext(ctx))
 	host.UpdatedAt.Store(time.Now())
 
 	if concurrentUploadLimit > 0 {
@@ -830,8 +842,10 @@ func (v *V1) storeHost(_ context.Context, peerHost *schedulerv1.PeerHost) *resou
 			Location: peerHost.Location,
 			IDC:      peerHost.Idc,
 		})}
-		if clientConfig, err := v.dynconfig.GetSchedulerClusterClientConfig(); err == nil && clientConfig.LoadLimit > 0 {
-			options = append(options, resource.WithConcurrentUploadLimit(int32(clientConfig.LoadLimit)))
+		if clientConfig, err := v.dynconfig.GetSchedulerClusterClientConfig(); err == nil {
+			if clientConfig = config.ClientConfigForHost(clientConfig, peerHost.Id, peerHost.Hostname, peerHost.Ip); clientConfig.LoadLimit > 0 {
+				options = append(options, resource.WithConcurrentUploadLimit(int32(clientConfig.LoadLimit)))
+			}
 		}
 
 		host := resource.NewHost(
@@ -1192,6 +1206,9 @@ func (v *V1) handlePeerSuccess(ctx context.Context, peer *resource.Peer) {
 	// Update peer cost of downloading.
 	peer.Cost.Store(time.Since(peer.CreatedAt.Load()))
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateSucceeded)
+
 	// If the peer type is tiny and back-to-source,
 	// it needs to directly download the tiny file and store the data in task DirectPiece.
 	if types.SizeScopeV2ToV1(peer.Task.SizeScope()) == commonv1.SizeScope_TINY && len(peer.Task.DirectPiece) == 0 {
@@ -1218,6 +1235,9 @@ func (v *V1) handlePeerFailure(ctx context.Context, peer *resource.Peer) {
 		return
 	}
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateFailed)
+
 	// Reschedule a new parent to children of peer to exclude the current failed peer.
 	for _, child := range peer.Children() {
 		child.Log.Infof("reschedule parent because of parent peer %s is failed", peer.ID)
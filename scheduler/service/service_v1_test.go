@@ -57,6 +57,8 @@ import (
 	resource "d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
 	"d7y.io/dragonfly/v2/scheduler/scheduling/mocks"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
 )
 
 var (
@@ -136,8 +138,9 @@ func TestService_NewV1(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			resource := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
 
-			tc.expect(t, NewV1(&config.Config{Scheduler: mockSchedulerConfig}, resource, scheduling, dynconfig))
+			tc.expect(t, NewV1(&config.Config{Scheduler: mockSchedulerConfig}, resource, scheduling, dynconfig, storage))
 		})
 	}
 }
@@ -801,7 +804,7 @@ func TestServiceV1_RegisterPeerTask(t *testing.T) {
 			hostManager := resource.NewMockHostManager(ctl)
 			taskManager := resource.NewMockTaskManager(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -1064,7 +1067,7 @@ func TestServiceV1_ReportPieceResult(t *testing.T) {
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
 			stream := schedulerv1mocks.NewMockScheduler_ReportPieceResultServer(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -1213,7 +1216,7 @@ func TestServiceV1_ReportPeerResult(t *testing.T) {
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -1276,7 +1279,7 @@ func TestServiceV1_StatTask(t *testing.T) {
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
 			taskManager := resource.NewMockTaskManager(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 
 			tc.mock(mockTask, taskManager, res.EXPECT(), taskManager.EXPECT())
@@ -1571,7 +1574,7 @@ func TestServiceV1_AnnounceTask(t *testing.T) {
 			hostManager := resource.NewMockHostManager(ctl)
 			taskManager := resource.NewMockTaskManager(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
@@ -1773,7 +1776,7 @@ func TestServiceV1_LeaveTask(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			peer := resource.NewPeer(mockSeedPeerID, mockTask, mockHost)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 
 			tc.mock(peer, peerManager, scheduling.EXPECT(), res.EXPECT(), peerManager.EXPECT())
 			tc.expect(t, peer, svc.LeaveTask(context.Background(), &schedulerv1.PeerTarget{}))
@@ -2200,7 +2203,7 @@ func TestServiceV1_AnnounceHost(t *testing.T) {
 			host := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 
 			tc.run(t, svc, tc.req, host, hostManager, res.EXPECT(), hostManager.EXPECT(), dynconfig.EXPECT())
 		})
@@ -2435,7 +2438,7 @@ func TestServiceV1_LeaveHost(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			mockPeer := resource.NewPeer(mockSeedPeerID, mockTask, host)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 
 			tc.mock(host, mockPeer, hostManager, scheduling.EXPECT(), res.EXPECT(), hostManager.EXPECT())
 			tc.expect(t, mockPeer, svc.LeaveHost(context.Background(), &schedulerv1.LeaveHostRequest{
@@ -2539,7 +2542,7 @@ func TestServiceV1_prefetchTask(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			task := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			peer := resource.NewPeer(mockPeerID, task, mockHost)
-			svc := NewV1(tc.config, res, scheduling, dynconfig)
+			svc := NewV1(tc.config, res, scheduling, dynconfig, nil)
 			taskManager := resource.NewMockTaskManager(ctl)
 
 			tc.mock(task, peer, taskManager, seedPeer, res.EXPECT(), taskManager.EXPECT(), seedPeer.EXPECT())
@@ -2998,7 +3001,7 @@ func TestServiceV1_triggerTask(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(tc.config, res, scheduling, dynconfig)
+			svc := NewV1(tc.config, res, scheduling, dynconfig, nil)
 
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -3101,7 +3104,7 @@ func TestServiceV1_storeTask(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 			taskManager := resource.NewMockTaskManager(ctl)
 			tc.run(t, svc, taskManager, res.EXPECT(), taskManager.EXPECT())
 		})
@@ -3178,7 +3181,7 @@ func TestServiceV1_storeHost(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 			hostManager := resource.NewMockHostManager(ctl)
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
@@ -3261,7 +3264,7 @@ func TestServiceV1_storePeer(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 			peerManager := resource.NewMockPeerManager(ctl)
 
 			tc.run(t, svc, peerManager, res.EXPECT(), peerManager.EXPECT())
@@ -3325,7 +3328,7 @@ func TestServiceV1_triggerSeedPeerTask(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			task := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			peer := resource.NewPeer(mockPeerID, task, mockHost)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, SeedPeer: mockSeedPeerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, SeedPeer: mockSeedPeerConfig}, res, scheduling, dynconfig, nil)
 
 			tc.mock(task, peer, seedPeer, res.EXPECT(), seedPeer.EXPECT())
 			svc.triggerSeedPeerTask(context.Background(), &mockPeerRange, task)
@@ -3405,7 +3408,7 @@ func TestServiceV1_handleBeginOfPiece(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, nil)
 
 			tc.mock(peer, scheduling.EXPECT())
 			svc.handleBeginOfPiece(context.Background(), peer)
@@ -3539,7 +3542,7 @@ func TestServiceV1_handlePieceSuccess(t *testing.T) {
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 
 			tc.mock(tc.peer, peerManager, res.EXPECT(), peerManager.EXPECT())
 			svc.handlePieceSuccess(context.Background(), tc.peer, tc.piece)
@@ -3734,7 +3737,7 @@ func TestServiceV1_handlePieceFail(t *testing.T) {
 			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
 			parent := resource.NewPeer(mockSeedPeerID, mockTask, mockHost)
 			seedPeer := resource.NewMockSeedPeer(ctl)
-			svc := NewV1(tc.config, res, scheduling, dynconfig)
+			svc := NewV1(tc.config, res, scheduling, dynconfig, nil)
 
 			tc.run(t, svc, peer, parent, tc.piece, peerManager, seedPeer, scheduling.EXPECT(), res.EXPECT(), peerManager.EXPECT(), seedPeer.EXPECT())
 		})
@@ -3754,15 +3757,24 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 
 	tests := []struct {
 		name   string
-		mock   func(peer *resource.Peer)
+		mock   func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder)
 		expect func(t *testing.T, peer *resource.Peer)
 	}{
 		{
 			name: "peer is tiny type and download piece success",
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder) {
 				peer.FSM.SetState(resource.PeerStateBackToSource)
 				peer.Task.ContentLength.Store(1)
 				peer.Task.TotalPieceCount.Store(1)
+				ms.CreateDownload(gomock.Any()).DoAndReturn(func(download storage.Download) error {
+					assert := assert.New(t)
+					assert.Equal(peer.ID, download.ID)
+					assert.Equal(storage.DownloadStateSucceeded, download.State)
+					assert.Equal(peer.Task.ID, download.Task.ID)
+					assert.Equal(peer.Host.ID, download.Host.ID)
+					assert.NotZero(download.Cost)
+					return nil
+				}).Times(1)
 			},
 			expect: func(t *testing.T, peer *resource.Peer) {
 				assert := assert.New(t)
@@ -3773,7 +3785,8 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 		},
 		{
 			name: "get task size scope failed",
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(nil).Times(1)
 				peer.FSM.SetState(resource.PeerStateBackToSource)
 				peer.Task.ContentLength.Store(-1)
 				peer.Task.TotalPieceCount.Store(1)
@@ -3787,7 +3800,8 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 		},
 		{
 			name: "peer is tiny type and download piece failed",
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(nil).Times(1)
 				peer.FSM.SetState(resource.PeerStateBackToSource)
 			},
 			expect: func(t *testing.T, peer *resource.Peer) {
@@ -3799,7 +3813,8 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 		},
 		{
 			name: "peer is small and state is PeerStateBackToSource",
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(nil).Times(1)
 				peer.FSM.SetState(resource.PeerStateBackToSource)
 				peer.Task.ContentLength.Store(resource.TinyFileSize + 1)
 				peer.Task.TotalPieceCount.Store(1)
@@ -3813,7 +3828,8 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 		},
 		{
 			name: "peer is small and state is PeerStateRunning",
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *storagemocks.MockStorageMockRecorder) {
+				ms.CreateDownload(gomock.Any()).Return(nil).Times(1)
 				peer.FSM.SetState(resource.PeerStateRunning)
 				peer.Task.ContentLength.Store(resource.TinyFileSize + 1)
 			},
@@ -3833,6 +3849,7 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
 
 			url, err := url.Parse(s.URL)
 			if err != nil {
@@ -3856,9 +3873,9 @@ func TestServiceV1_handlePeerSuccess(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, storage)
 
-			tc.mock(peer)
+			tc.mock(peer, storage.EXPECT())
 			svc.handlePeerSuccess(context.Background(), peer)
 			tc.expect(t, peer)
 		})
@@ -3869,12 +3886,12 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 
 	tests := []struct {
 		name   string
-		mock   func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder)
+		mock   func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder, mst *storagemocks.MockStorageMockRecorder)
 		expect func(t *testing.T, peer *resource.Peer, child *resource.Peer)
 	}{
 		{
 			name: "peer state is PeerStateFailed",
-			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder) {
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder, mst *storagemocks.MockStorageMockRecorder) {
 				peer.FSM.SetState(resource.PeerStateFailed)
 			},
 			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
@@ -3884,7 +3901,7 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 		},
 		{
 			name: "peer state is PeerStateLeave",
-			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder) {
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder, mst *storagemocks.MockStorageMockRecorder) {
 				peer.FSM.SetState(resource.PeerStateLeave)
 			},
 			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
@@ -3894,7 +3911,7 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 		},
 		{
 			name: "peer state is PeerStateRunning and children need to be scheduled",
-			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder) {
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder, mst *storagemocks.MockStorageMockRecorder) {
 				peer.Task.StorePeer(peer)
 				peer.Task.StorePeer(child)
 				if err := peer.Task.AddPeerEdge(peer, child); err != nil {
@@ -3904,6 +3921,7 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 				child.FSM.SetState(resource.PeerStateRunning)
 
 				ms.ScheduleParentAndCandidateParents(gomock.Any(), gomock.Eq(child), gomock.Eq(set.NewSafeSet[string]())).Return().Times(1)
+				mst.CreateDownload(gomock.Any()).Return(nil).Times(1)
 			},
 			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
 				assert := assert.New(t)
@@ -3912,9 +3930,15 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 		},
 		{
 			name: "peer state is PeerStateRunning and it has no children",
-			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder) {
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulingMockRecorder, mst *storagemocks.MockStorageMockRecorder) {
 				peer.Task.StorePeer(peer)
 				peer.FSM.SetState(resource.PeerStateRunning)
+				mst.CreateDownload(gomock.Any()).DoAndReturn(func(download storage.Download) error {
+					assert := assert.New(t)
+					assert.Equal(peer.ID, download.ID)
+					assert.Equal(storage.DownloadStateFailed, download.State)
+					return nil
+				}).Times(1)
 			},
 			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
 				assert := assert.New(t)
@@ -3930,7 +3954,8 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			storage := storagemocks.NewMockStorage(ctl)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, storage)
 			mockHost := resource.NewHost(
 				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
@@ -3938,7 +3963,7 @@ func TestServiceV1_handlePeerFail(t *testing.T) {
 			peer := resource.NewPeer(mockSeedPeerID, mockTask, mockHost)
 			child := resource.NewPeer(mockPeerID, mockTask, mockHost)
 
-			tc.mock(peer, child, scheduling.EXPECT())
+			tc.mock(peer, child, scheduling.EXPECT(), storage.EXPECT())
 			svc.handlePeerFailure(context.Background(), peer)
 			tc.expect(t, peer, child)
 		})
@@ -4015,7 +4040,7 @@ func TestServiceV1_handleTaskSuccess(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 			task := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 
 			tc.mock(task)
@@ -4153,7 +4178,7 @@ func TestServiceV1_handleTaskFail(t *testing.T) {
 			scheduling := mocks.NewMockScheduling(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
-			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig)
+			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnableHost: true}}, res, scheduling, dynconfig, nil)
 			task := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_STANDARD, mockTaskFilteredQueryParams, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 
 			tc.mock(task)
@@ -46,6 +46,7 @@ import (
 	"d7y.io/dragonfly/v2/scheduler/resource/persistentcache"
 	"d7y.io/dragonfly/v2/scheduler/resource/standard"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
+	"d7y.io/dragonfly/v2/scheduler/storage"
 )
 
 // V2 is the interface for v2 version of the service.
@@ -64,6 +65,12 @@ type V2 struct {
 
 	// Dynamic config.
 	dynconfig config.DynconfigInterface
+
+	// Storage interface.
+	storage storage.Storage
+
+	// Replicator of the persistent cache tasks.
+	replicator *persistentCacheTaskReplicator
 }
 
 // New v2 version of service instance.
@@ -73,6 +80,7 @@ func NewV2(
 	persistentCacheResource persistentcache.Resource,
 	scheduling scheduling.Scheduling,
 	dynconfig config.DynconfigInterface,
+	storage storage.Storage,
 ) *V2 {
 	return &V2{
 		resource:                resource,
@@ -80,6 +88,8 @@ func NewV2(
 		scheduling:              scheduling,
 		config:                  cfg,
 		dynconfig:               dynconfig,
+		storage:                 storage,
+		replicator:              newPersistentCacheTaskReplicator(persistentCacheResource, scheduling),
 	}
 }
 
@@ -550,7 +560,7 @@ func (v *V2) AnnounceHost(ctx context.Context, req *schedulerv2.AnnounceHostRequ
 	switch types.HostType(req.Host.GetType()) {
 	case types.HostTypeNormal:
 		if clientConfig, err := v.dynconfig.GetSchedulerClusterClientConfig(); err == nil {
-			concurrentUploadLimit = int32(clientConfig.LoadLimit)
+			concurrentUploadLimit = int32(config.ClientConfigForHost(clientConfig, req.Host.GetId(), req.Host.GetHostname(), req.Host.GetIp()).LoadLimit)
 		}
 	case types.HostTypeSuperSeed, types.HostTypeStrongSeed, types.HostTypeWeakSeed:
 		if seedPeerConfig, err := v.dynconfig.GetSeedPeerClusterConfig(); err == nil {
@@ -569,6 +579,7 @@ func (v *V2) AnnounceHost(ctx context.Context, req *schedulerv2.AnnounceHostRequ
 			standard.WithPlatformVersion(req.Host.GetPlatformVersion()),
 			standard.WithKernelVersion(req.Host.GetKernelVersion()),
 			standard.WithSchedulerClusterID(uint64(v.config.Manager.SchedulerClusterID)),
+			standard.WithLabels(hostLabelsFromContext(ctx)),
 		}
 
 		if concurrentUploadLimit > 0 {
@@ -667,6 +678,7 @@ func (v *V2) AnnounceHost(ctx context.Context, req *schedulerv2.AnnounceHostRequ
 		host.PlatformFamily = req.Host.GetPlatformFamily()
 		host.PlatformVersion = req.Host.GetPlatformVersion()
 		host.KernelVersion = req.Host.GetKernelVersion()
+		host.StoreLabels(hostLabelsFromContext(ctx))
 		host.UpdatedAt.Store(time.Now())
 
 		if concurrentUploadLimit > 0 {
@@ -1044,7 +1056,7 @@ func (v *V2) DeleteHost(_ctx context.Context, req *schedulerv2.DeleteHostRequest
 				blocklist.Add(peer.Host.ID)
 				go func(peer *persistentcache.Peer, blocklist set.SafeSet[string]) {
 					log.Infof("replicate persistent cache task %s", peer.Task.ID)
-					if err := v.replicatePersistentCacheTask(context.Background(), peer, blocklist); err != nil {
+					if err := v.replicator.replicate(context.Background(), peer.Task, blocklist); err != nil {
 						log.Errorf("replicate persistent cache task failed %s", err)
 					}
 
@@ -1092,6 +1104,16 @@ func (v *V2) handleRegisterPeerRequest(ctx context.Context, stream schedulerv2.S
 		task.FSM.Is(standard.TaskStateSucceeded) &&
 			!task.HasAvailablePeer(blocklist):
 
+		// If the task can be downloaded from the parents in the federated clusters,
+		// peer downloads from them instead of triggering seed peer download back-to-source.
+		// The finding is bounded by the federation timeout, the result is cached per task.
+		if v.config.Scheduler.Federation.Enable && host.Type == types.HostTypeNormal {
+			if _, found := v.scheduling.FindFederatedCandidateParents(ctx, peer, blocklist); found {
+				peer.Log.Info("task can be downloaded from federated parents")
+				break
+			}
+		}
+
 		// If HostType is normal, trigger seed peer download back-to-source.
 		if host.Type == types.HostTypeNormal {
 			// If trigger the seed peer download back-to-source,
@@ -1264,6 +1286,9 @@ func (v *V2) handleDownloadPeerFinishedRequest(ctx context.Context, peerID strin
 		return status.Error(codes.Internal, err.Error())
 	}
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateSucceeded)
+
 	// Collect DownloadPeerCount and DownloadPeerDuration metrics.
 	priority := peer.CalculatePriority(v.dynconfig)
 	metrics.DownloadPeerCount.WithLabelValues(priority.String(), peer.Task.Type.String(),
@@ -1297,6 +1322,9 @@ func (v *V2) handleDownloadPeerBackToSourceFinishedRequest(ctx context.Context,
 		}
 	}
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateSucceeded)
+
 	// Collect DownloadPeerCount and DownloadPeerDuration metrics.
 	priority := peer.CalculatePriority(v.dynconfig)
 	metrics.DownloadPeerCount.WithLabelValues(priority.String(), peer.Task.Type.String(),
@@ -1319,6 +1347,9 @@ func (v *V2) handleDownloadPeerFailedRequest(ctx context.Context, peerID string)
 		return status.Error(codes.Internal, err.Error())
 	}
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateFailed)
+
 	// Collect DownloadPeerCount and DownloadPeerFailureCount metrics.
 	priority := peer.CalculatePriority(v.dynconfig)
 	metrics.DownloadPeerCount.WithLabelValues(priority.String(), peer.Task.Type.String(),
@@ -1349,6 +1380,9 @@ func (v *V2) handleDownloadPeerBackToSourceFailedRequest(ctx context.Context, pe
 		return status.Error(codes.Internal, err.Error())
 	}
 
+	// Record the download of peer.
+	createDownload(v.storage, peer, storage.DownloadStateFailed)
+
 	// Collect DownloadPeerCount and DownloadPeerBackToSourceFailureCount metrics.
 	priority := peer.CalculatePriority(v.dynconfig)
 	metrics.DownloadPeerCount.WithLabelValues(priority.String(), peer.Task.Type.String(),
@@ -1410,6 +1444,7 @@ func (v *V2) handleDownloadPieceFinishedRequest(peerID string, req *schedulerv2.
 		peer.Host.Type.Name()).Inc()
 	metrics.Traffic.WithLabelValues(piece.TrafficType.String(), peer.Task.Type.String(),
 		peer.Host.Type.Name()).Add(float64(piece.Length))
+	metrics.AddTaskTraffic(piece.TrafficType, peer.Task.Application, peer.Task.Tag, peer.Priority, peer.Task.ID, piece.Length)
 	if v.config.Metrics.EnableHost {
 		metrics.HostTraffic.WithLabelValues(metrics.HostTrafficDownloadType, peer.Task.Type.String(),
 			peer.Host.Type.Name(), peer.Host.ID, peer.Host.IP, peer.Host.Hostname).Add(float64(piece.Length))
@@ -1466,6 +1501,7 @@ func (v *V2) handleDownloadPieceBackToSourceFinishedRequest(_ context.Context, p
 		peer.Host.Type.Name()).Inc()
 	metrics.Traffic.WithLabelValues(piece.TrafficType.String(), peer.Task.Type.String(),
 		peer.Host.Type.Name()).Add(float64(piece.Length))
+	metrics.AddTaskTraffic(piece.TrafficType, peer.Task.Application, peer.Task.Tag, peer.Priority, peer.Task.ID, piece.Length)
 	if v.config.Metrics.EnableHost {
 		metrics.HostTraffic.WithLabelValues(metrics.HostTrafficDownloadType, peer.Task.Type.String(),
 			peer.Host.Type.Name(), peer.Host.ID, peer.Host.IP, peer.Host.Hostname).Add(float64(piece.Length))
@@ -1869,7 +1905,7 @@ func (v *V2) handleRegisterPersistentCachePeerRequest(ctx context.Context, strea
 					ContentLength:                 parent.Task.ContentLength,
 					PieceCount:                    parent.Task.TotalPieceCount,
 					State:                         parent.Task.FSM.Current(),
-					Ttl:                           durationpb.New(parent.Task.TTL),
+					Ttl:                           durationpb.New(parent.Task.HostTTL()),
 					CreatedAt:                     timestamppb.New(parent.Task.CreatedAt),
 					UpdatedAt:                     timestamppb.New(parent.Task.UpdatedAt),
 				},
@@ -2064,7 +2100,7 @@ func (v *V2) handleReschedulePersistentCachePeerRequest(ctx context.Context, str
 				ContentLength:                 parent.Task.ContentLength,
 				PieceCount:                    parent.Task.TotalPieceCount,
 				State:                         parent.Task.FSM.Current(),
-				Ttl:                           durationpb.New(parent.Task.TTL),
+				Ttl:                           durationpb.New(parent.Task.HostTTL()),
 				CreatedAt:                     timestamppb.New(parent.Task.CreatedAt),
 				UpdatedAt:                     timestamppb.New(parent.Task.UpdatedAt),
 			},
@@ -2326,7 +2362,7 @@ func (v *V2) StatPersistentCachePeer(ctx context.Context, req *schedulerv2.StatP
 			ContentLength:                 peer.Task.ContentLength,
 			PieceCount:                    uint32(peer.Task.TotalPieceCount),
 			State:                         peer.Task.FSM.Current(),
-			Ttl:                           durationpb.New(peer.Task.TTL),
+			Ttl:                           durationpb.New(peer.Task.HostTTL()),
 			CreatedAt:                     timestamppb.New(peer.Task.CreatedAt),
 			UpdatedAt:                     timestamppb.New(peer.Task.UpdatedAt),
 		},
@@ -2444,7 +2480,7 @@ func (v *V2) DeletePersistentCachePeer(_ctx context.Context, req *schedulerv2.De
 	blocklist.Add(req.GetHostId())
 	go func(peer *persistentcache.Peer, blocklist set.SafeSet[string]) {
 		log.Infof("replicate persistent cache task %s", peer.Task.ID)
-		if err := v.replicatePersistentCacheTask(context.Background(), peer, blocklist); err != nil {
+		if err := v.replicator.replicate(context.Background(), peer.Task, blocklist); err != nil {
 			log.Errorf("replicate persistent cache task failed %s", err)
 		}
 
@@ -2574,7 +2610,7 @@ func (v *V2) UploadPersistentCacheTaskFinished(ctx context.Context, req *schedul
 		ContentLength:                 peer.Task.ContentLength,
 		PieceCount:                    peer.Task.TotalPieceCount,
 		State:                         peer.Task.FSM.Current(),
-		Ttl:                           durationpb.New(peer.Task.TTL),
+		Ttl:                           durationpb.New(peer.Task.HostTTL()),
 		CreatedAt:                     timestamppb.New(peer.Task.CreatedAt),
 		UpdatedAt:                     timestamppb.New(peer.Task.UpdatedAt),
 	}
@@ -2584,7 +2620,7 @@ func (v *V2) UploadPersistentCacheTaskFinished(ctx context.Context, req *schedul
 	blocklist.Add(peer.Host.ID)
 	go func(peer *persistentcache.Peer, blocklist set.SafeSet[string]) {
 		log.Infof("replicate persistent cache task %s", peer.Task.ID)
-		if err := v.replicatePersistentCacheTask(context.Background(), peer, blocklist); err != nil {
+		if err := v.replicator.replicate(context.Background(), peer.Task, blocklist); err != nil {
 			log.Errorf("replicate persistent cache task failed %s", err)
 		}
 
@@ -2594,11 +2630,11 @@ func (v *V2) UploadPersistentCacheTaskFinished(ctx context.Context, req *schedul
 	return persistentCacheTask, nil
 }
 
-// replicatePersistentCacheTask replicates the persistent cache task to the remote peer.
-func (v *V2) replicatePersistentCacheTask(ctx context.Context, peer *persistentcache.Peer, blocklist set.SafeSet[string]) error {
-	cachedParents, hosts, found := v.scheduling.FindReplicatePersistentCacheHosts(ctx, peer.Task, blocklist)
+// replicate replicates the persistent cache task to the remote peer.
+func (r *persistentCacheTaskReplicator) replicate(ctx context.Context, task *persistentcache.Task, blocklist set.SafeSet[string]) error {
+	cachedParents, hosts, found := r.scheduling.FindReplicatePersistentCacheHosts(ctx, task, blocklist)
 	if !found {
-		peer.Log.Warn("no replicate hosts found")
+		task.Log.Warn("no replicate hosts found")
 		return nil
 	}
 
@@ -2606,33 +2642,33 @@ func (v *V2) replicatePersistentCacheTask(ctx context.Context, peer *persistentc
 	// because the cached parent has the temporary cache.
 	for _, cachedParent := range cachedParents {
 		go func(*persistentcache.Task, *persistentcache.Peer) {
-			peer.Log.Infof("replicate to cached parent %s", cachedParent.ID)
-			if err := v.persistPersistentCacheTaskByPeer(context.Background(), peer, cachedParent); err != nil {
-				peer.Log.Errorf("replicate to cached parent %s failed %s", cachedParent.ID, err)
+			task.Log.Infof("replicate to cached parent %s", cachedParent.ID)
+			if err := r.persistByPeer(context.Background(), task, cachedParent); err != nil {
+				task.Log.Errorf("replicate to cached parent %s failed %s", cachedParent.ID, err)
 			}
 
-			peer.Log.Infof("replicate to cached parent %s finished", cachedParent.ID)
-		}(peer.Task, cachedParent)
+			task.Log.Infof("replicate to cached parent %s finished", cachedParent.ID)
+		}(task, cachedParent)
 	}
 
 	// Replicate the persistent cache task to the host, trigger the download task from the other peer,
 	// because the host has no cache.
 	for _, host := range hosts {
 		go func(*persistentcache.Task, *persistentcache.Host) {
-			peer.Log.Infof("replicate to host %s", host.ID)
-			if err := v.downloadPersistentCacheTaskByPeer(context.Background(), peer.Task, host); err != nil {
-				peer.Log.Errorf("replicate to host %s failed %s", host.ID, err)
+			task.Log.Infof("replicate to host %s", host.ID)
+			if err := r.downloadByPeer(context.Background(), task, host); err != nil {
+				task.Log.Errorf("replicate to host %s failed %s", host.ID, err)
 			}
 
-			peer.Log.Infof("replicate to host %s finished", host.ID)
-		}(peer.Task, host)
+			task.Log.Infof("replicate to host %s finished", host.ID)
+		}(task, host)
 	}
 
 	return nil
 }
 
-// downloadPersistentCacheTaskByPeer downloads the persistent cache task by peer.
-func (v *V2) downloadPersistentCacheTaskByPeer(ctx context.Context, task *persistentcache.Task, host *persistentcache.Host) error {
+// downloadByPeer downloads the persistent cache task by peer.
+func (r *persistentCacheTaskReplicator) downloadByPeer(ctx context.Context, task *persistentcache.Task, host *persistentcache.Host) error {
 	addr := fmt.Sprintf("%s:%d", host.IP, host.DownloadPort)
 	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	dfdaemonClient, err := dfdaemonclient.GetV2ByAddr(ctx, addr, dialOptions...)
@@ -2667,27 +2703,27 @@ func (v *V2) downloadPersistentCacheTaskByPeer(ctx context.Context, task *persis
 	}
 }
 
-// persistPersistentCacheTaskByPeer persists the persistent cache task by peer.
-func (v *V2) persistPersistentCacheTaskByPeer(ctx context.Context, peer *persistentcache.Peer, cachedParent *persistentcache.Peer) error {
+// persistByPeer persists the persistent cache task by peer.
+func (r *persistentCacheTaskReplicator) persistByPeer(ctx context.Context, task *persistentcache.Task, cachedParent *persistentcache.Peer) error {
 	addr := fmt.Sprintf("%s:%d", cachedParent.Host.IP, cachedParent.Host.DownloadPort)
 	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	dfdaemonClient, err := dfdaemonclient.GetV2ByAddr(ctx, addr, dialOptions...)
 	if err != nil {
-		peer.Log.Errorf("get dfdaemon client failed %s", err)
+		task.Log.Errorf("get dfdaemon client failed %s", err)
 		return err
 	}
 
 	if err := dfdaemonClient.UpdatePersistentCacheTask(ctx, &dfdaemonv2.UpdatePersistentCacheTaskRequest{
-		TaskId:     peer.Task.ID,
+		TaskId:     task.ID,
 		Persistent: true,
 	}); err != nil {
-		peer.Log.Errorf("update persistent cache task failed %s", err)
+		task.Log.Errorf("update persistent cache task failed %s", err)
 		return err
 	}
 
 	cachedParent.Persistent = true
-	if err := v.persistentCacheResource.PeerManager().Store(ctx, cachedParent); err != nil {
-		peer.Log.Errorf("store persistent cache peer %s error %s", cachedParent.ID, err)
+	if err := r.persistentCacheResource.PeerManager().Store(ctx, cachedParent); err != nil {
+		task.Log.Errorf("store persistent cache peer %s error %s", cachedParent.ID, err)
 		return err
 	}
 
@@ -2774,7 +2810,7 @@ func (v *V2) StatPersistentCacheTask(ctx context.Context, req *schedulerv2.StatP
 		ContentLength:                 task.ContentLength,
 		PieceCount:                    task.TotalPieceCount,
 		State:                         task.FSM.Current(),
-		Ttl:                           durationpb.New(task.TTL),
+		Ttl:                           durationpb.New(task.HostTTL()),
 		CreatedAt:                     timestamppb.New(task.CreatedAt),
 		UpdatedAt:                     timestamppb.New(task.UpdatedAt),
 	}, nil
@@ -0,0 +1,393 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/storage_mock.go -source storage.go -package mocks
+
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// DownloadFilePrefix is prefix of download file name.
+	DownloadFilePrefix = "download"
+
+	// CSVFileExt is extension of file name.
+	CSVFileExt = "csv"
+)
+
+const (
+	// megabyte is the converted factor of MaxSize and bytes.
+	megabyte = 1024 * 1024
+
+	// backupTimeFormat is the timestamp format of backup filename.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// Storage is the interface used for storage.
+type Storage interface {
+	// CreateDownload inserts the download into csv file.
+	CreateDownload(Download) error
+
+	// OpenDownload opens download files for read, it returns the csv header
+	// followed by the records of backups and the current file.
+	OpenDownload() (io.ReadCloser, error)
+
+	// DownloadCount returns the count of downloads.
+	DownloadCount() int64
+
+	// ClearDownload removes all download files.
+	ClearDownload() error
+
+	// Stop flushes the buffered downloads and closes the download file.
+	Stop() error
+}
+
+// storage provides storage function.
+type storage struct {
+	baseDir    string
+	maxSize    int64
+	maxBackups int
+	bufferSize int
+
+	downloadMu       *sync.RWMutex
+	downloadFilename string
+	downloadFile     *os.File
+	downloadBuffer   []Download
+	downloadCount    int64
+}
+
+// New returns a new Storage instance.
+func New(baseDir string, maxSize, maxBackups, bufferSize int) (Storage, error) {
+	if err := os.MkdirAll(baseDir, 0700); err != nil {
+		return nil, err
+	}
+
+	s := &storage{
+		baseDir:          baseDir,
+		maxSize:          int64(maxSize * megabyte),
+		maxBackups:       maxBackups,
+		bufferSize:       bufferSize,
+		downloadMu:       &sync.RWMutex{},
+		downloadFilename: filepath.Join(baseDir, fmt.Sprintf("%s.%s", DownloadFilePrefix, CSVFileExt)),
+		downloadBuffer:   make([]Download, 0, bufferSize),
+	}
+
+	downloadFile, err := os.OpenFile(s.downloadFilename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.downloadFile = downloadFile
+
+	// Initialize the count of downloads from the existing files.
+	filenames, err := s.downloadFilenames()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filename := range filenames {
+		count, err := countRecords(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		s.downloadCount += count
+	}
+
+	return s, nil
+}
+
+// CreateDownload inserts the download into csv file.
+func (s *storage) CreateDownload(download Download) error {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	s.downloadBuffer = append(s.downloadBuffer, download)
+	s.downloadCount++
+
+	// Write the buffered downloads to the file when the buffer is full.
+	if len(s.downloadBuffer) >= s.bufferSize {
+		return s.flushDownload()
+	}
+
+	return nil
+}
+
+// OpenDownload opens download files for read, it returns the csv header
+// followed by the records of backups and the current file.
+func (s *storage) OpenDownload() (io.ReadCloser, error) {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	if err := s.flushDownload(); err != nil {
+		return nil, err
+	}
+
+	filenames, err := s.downloadFilenames()
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	w := csv.NewWriter(&header)
+	if err := w.Write(downloadHeader); err != nil {
+		return nil, err
+	}
+	w.Flush()
+
+	readers := []io.Reader{&header}
+	files := make([]*os.File, 0, len(filenames))
+	for _, filename := range filenames {
+		file, err := os.Open(filename)
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+
+			return nil, err
+		}
+
+		readers = append(readers, file)
+		files = append(files, file)
+	}
+
+	return &multiReadCloser{Reader: io.MultiReader(readers...), files: files}, nil
+}
+
+// DownloadCount returns the count of downloads.
+func (s *storage) DownloadCount() int64 {
+	s.downloadMu.RLock()
+	defer s.downloadMu.RUnlock()
+
+	return s.downloadCount
+}
+
+// ClearDownload removes all download files.
+func (s *storage) ClearDownload() error {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	filenames, err := s.downloadFilenames()
+	if err != nil {
+		return err
+	}
+
+	// Remove the download files before closing the current one, then the storage
+	// keeps writing the current download file if removing failed.
+	for _, filename := range filenames {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := s.downloadFile.Close(); err != nil {
+		logger.Warnf("close removed download file failed: %s", err.Error())
+	}
+
+	downloadFile, err := os.OpenFile(s.downloadFilename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	s.downloadFile = downloadFile
+	s.downloadBuffer = s.downloadBuffer[:0]
+	s.downloadCount = 0
+	return nil
+}
+
+// Stop flushes the buffered downloads and closes the download file.
+func (s *storage) Stop() error {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	if err := s.flushDownload(); err != nil {
+		return err
+	}
+
+	return s.downloadFile.Close()
+}
+
+// flushDownload writes the buffered downloads to the file,
+// the caller must hold the downloadMu.
+func (s *storage) flushDownload() error {
+	if len(s.downloadBuffer) == 0 {
+		return nil
+	}
+
+	fileInfo, err := s.downloadFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Rotate the file if it exceeds the max size.
+	if fileInfo.Size() >= s.maxSize {
+		if err := s.rotateDownload(); err != nil {
+			return err
+		}
+	}
+
+	w := csv.NewWriter(s.downloadFile)
+	for _, download := range s.downloadBuffer {
+		record, err := download.record()
+		if err != nil {
+			logger.Errorf("encode download %s failed: %s", download.ID, err.Error())
+			continue
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	s.downloadBuffer = s.downloadBuffer[:0]
+	return nil
+}
+
+// rotateDownload renames the current file to a backup, opens a new file
+// and removes the oldest backups exceeding the max backups.
+func (s *storage) rotateDownload() error {
+	if err := s.downloadFile.Close(); err != nil {
+		return err
+	}
+
+	backupFilename := filepath.Join(s.baseDir, fmt.Sprintf("%s-%s.%s", DownloadFilePrefix, time.Now().UTC().Format(backupTimeFormat), CSVFileExt))
+	if err := os.Rename(s.downloadFilename, backupFilename); err != nil {
+		return err
+	}
+
+	downloadFile, err := os.OpenFile(s.downloadFilename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.downloadFile = downloadFile
+
+	backupFilenames, err := s.downloadBackupFilenames()
+	if err != nil {
+		return err
+	}
+
+	for len(backupFilenames) > s.maxBackups {
+		count, err := countRecords(backupFilenames[0])
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(backupFilenames[0]); err != nil {
+			return err
+		}
+
+		s.downloadCount -= count
+		backupFilenames = backupFilenames[1:]
+	}
+
+	return nil
+}
+
+// downloadFilenames returns the backup filenames in chronological order
+// followed by the current filename.
+func (s *storage) downloadFilenames() ([]string, error) {
+	filenames, err := s.downloadBackupFilenames()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(filenames, s.downloadFilename), nil
+}
+
+// downloadBackupFilenames returns the backup filenames in chronological order.
+func (s *storage) downloadBackupFilenames() ([]string, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, fmt.Sprintf("%s-", DownloadFilePrefix)) || filepath.Ext(name) != fmt.Sprintf(".%s", CSVFileExt) {
+			continue
+		}
+
+		filenames = append(filenames, filepath.Join(s.baseDir, name))
+	}
+
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// countRecords returns the count of csv records in the file.
+func countRecords(filename string) (int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	var count int64
+	for {
+		if _, err := r.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			return 0, err
+		}
+
+		count++
+	}
+}
+
+// multiReadCloser reads the files in sequence and closes all of them.
+type multiReadCloser struct {
+	io.Reader
+	files []*os.File
+}
+
+// Close closes all files.
+func (m *multiReadCloser) Close() error {
+	var errs []error
+	for _, file := range m.files {
+		if err := file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,256 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mockDownload = Download{
+	ID:                 "4",
+	Tag:                "d7y",
+	Application:        "dfget",
+	State:              DownloadStateSucceeded,
+	Cost:               1000,
+	FinishedPieceCount: 2,
+	BackToSource:       false,
+	PieceCosts:         []int64{400, 600},
+	Task: Task{
+		ID:              "1",
+		URL:             "https://example.com",
+		Type:            "STANDARD",
+		ContentLength:   100,
+		TotalPieceCount: 2,
+		State:           "Succeeded",
+	},
+	Host: Host{
+		ID:       "2",
+		Type:     "normal",
+		Hostname: "localhost",
+		IP:       "127.0.0.1",
+		Port:     8002,
+	},
+	Parents: []Parent{
+		{
+			ID:                 "5",
+			State:              "Succeeded",
+			FinishedPieceCount: 2,
+			Host: Host{
+				ID:       "3",
+				Type:     "super",
+				Hostname: "seed",
+				IP:       "127.0.0.2",
+			},
+		},
+	},
+}
+
+func readDownloads(t *testing.T, s Storage) [][]string {
+	rc, err := s.OpenDownload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	records, err := csv.NewReader(rc).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return records
+}
+
+func TestStorage_New(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseDir string
+		expect  func(t *testing.T, s Storage, err error)
+	}{
+		{
+			name:    "new storage",
+			baseDir: filepath.Join(t.TempDir(), "storage"),
+			expect: func(t *testing.T, s Storage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(s.DownloadCount(), int64(0))
+				assert.NoError(s.Stop())
+			},
+		},
+		{
+			name:    "new storage with invalid base dir",
+			baseDir: "/dev/null/storage",
+			expect: func(t *testing.T, s Storage, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(tc.baseDir, 1, 1, 1)
+			tc.expect(t, s, err)
+		})
+	}
+}
+
+func TestStorage_CreateDownload(t *testing.T) {
+	tests := []struct {
+		name       string
+		bufferSize int
+		count      int
+		expect     func(t *testing.T, s Storage, baseDir string)
+	}{
+		{
+			name:       "create download with buffer",
+			bufferSize: 10,
+			count:      1,
+			expect: func(t *testing.T, s Storage, baseDir string) {
+				assert := assert.New(t)
+				assert.Equal(s.DownloadCount(), int64(1))
+
+				data, err := os.ReadFile(filepath.Join(baseDir, "download.csv"))
+				assert.NoError(err)
+				assert.Empty(data)
+			},
+		},
+		{
+			name:       "create download and flush buffer",
+			bufferSize: 2,
+			count:      3,
+			expect: func(t *testing.T, s Storage, baseDir string) {
+				assert := assert.New(t)
+				assert.Equal(s.DownloadCount(), int64(3))
+
+				file, err := os.Open(filepath.Join(baseDir, "download.csv"))
+				assert.NoError(err)
+				defer file.Close()
+
+				records, err := csv.NewReader(file).ReadAll()
+				assert.NoError(err)
+				assert.Len(records, 2)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			s, err := New(baseDir, 1, 1, tc.bufferSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Stop()
+
+			for i := 0; i < tc.count; i++ {
+				assert.NoError(t, s.CreateDownload(mockDownload))
+			}
+
+			tc.expect(t, s, baseDir)
+		})
+	}
+}
+
+func TestStorage_OpenDownload(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := New(baseDir, 1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	assert := assert.New(t)
+	records := readDownloads(t, s)
+	assert.Equal([][]string{downloadHeader}, records)
+
+	assert.NoError(s.CreateDownload(mockDownload))
+	records = readDownloads(t, s)
+	assert.Len(records, 2)
+	assert.Len(records[1], len(downloadHeader))
+	assert.Equal(mockDownload.ID, records[1][0])
+	assert.Equal("false", records[1][6])
+	assert.Equal("[400,600]", records[1][7])
+	assert.Equal(mockDownload.Task.URL, records[1][9])
+
+	var parents []Parent
+	assert.NoError(json.Unmarshal([]byte(records[1][32]), &parents))
+	assert.Equal(mockDownload.Parents, parents)
+}
+
+func TestStorage_Rotate(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := New(baseDir, 1, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	// Each download with large url is about 256KB, so the file is rotated every four downloads.
+	download := mockDownload
+	download.Task.URL = strings.Repeat("a", 256*1024)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, s.CreateDownload(download))
+	}
+
+	assert := assert.New(t)
+	backups, err := filepath.Glob(filepath.Join(baseDir, "download-*.csv"))
+	assert.NoError(err)
+	assert.Len(backups, 2)
+
+	records := readDownloads(t, s)
+	assert.Equal(int64(len(records)-1), s.DownloadCount())
+	assert.Less(s.DownloadCount(), int64(20))
+
+	// Reopen storage and count the downloads from the files.
+	assert.NoError(s.Stop())
+	s, err = New(baseDir, 1, 2, 1)
+	assert.NoError(err)
+	assert.Equal(int64(len(records)-1), s.DownloadCount())
+}
+
+func TestStorage_ClearDownload(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := New(baseDir, 1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	assert := assert.New(t)
+	assert.NoError(s.CreateDownload(mockDownload))
+	assert.Equal(s.DownloadCount(), int64(1))
+
+	assert.NoError(s.ClearDownload())
+	assert.Equal(s.DownloadCount(), int64(0))
+	assert.Equal([][]string{downloadHeader}, readDownloads(t, s))
+
+	assert.NoError(s.CreateDownload(mockDownload))
+	rc, err := s.OpenDownload()
+	assert.NoError(err)
+	data, err := io.ReadAll(rc)
+	assert.NoError(err)
+	assert.NoError(rc.Close())
+	assert.Contains(string(data), mockDownload.Task.URL)
+}
@@ -0,0 +1,237 @@
+/*
+ *     Copyright 2025 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	// DownloadStateSucceeded is the state of the download succeeded.
+	DownloadStateSucceeded = "Succeeded"
+
+	// DownloadStateFailed is the state of the download failed.
+	DownloadStateFailed = "Failed"
+)
+
+// Task contains content for task.
+type Task struct {
+	// ID is task id.
+	ID string `json:"id"`
+
+	// URL is task download url.
+	URL string `json:"url"`
+
+	// Type is task type.
+	Type string `json:"type"`
+
+	// ContentLength is task total content length.
+	ContentLength int64 `json:"contentLength"`
+
+	// TotalPieceCount is total piece count.
+	TotalPieceCount int32 `json:"totalPieceCount"`
+
+	// BackToSourceLimit is back-to-source limit.
+	BackToSourceLimit int32 `json:"backToSourceLimit"`
+
+	// BackToSourcePeerCount is back-to-source peer count.
+	BackToSourcePeerCount int32 `json:"backToSourcePeerCount"`
+
+	// State is the download state of the task.
+	State string `json:"state"`
+
+	// CreatedAt is peer create nanosecond time.
+	CreatedAt int64 `json:"createdAt"`
+
+	// UpdatedAt is peer update nanosecond time.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// Host contains content for host.
+type Host struct {
+	// ID is host id.
+	ID string `json:"id"`
+
+	// Type is host type.
+	Type string `json:"type"`
+
+	// Hostname is host name.
+	Hostname string `json:"hostname"`
+
+	// IP is host ip.
+	IP string `json:"ip"`
+
+	// Port is grpc service port.
+	Port int32 `json:"port"`
+
+	// DownloadPort is piece downloading port.
+	DownloadPort int32 `json:"downloadPort"`
+
+	// Location path(area|country|province|city|...).
+	Location string `json:"location"`
+
+	// IDC where the peer host is located.
+	IDC string `json:"idc"`
+
+	// ConcurrentUploadLimit is concurrent upload limit count.
+	ConcurrentUploadLimit int32 `json:"concurrentUploadLimit"`
+
+	// ConcurrentUploadCount is concurrent upload count.
+	ConcurrentUploadCount int32 `json:"concurrentUploadCount"`
+
+	// UploadCount is total upload count.
+	UploadCount int64 `json:"uploadCount"`
+
+	// UploadFailedCount is upload failed count.
+	UploadFailedCount int64 `json:"uploadFailedCount"`
+
+	// CreatedAt is peer create nanosecond time.
+	CreatedAt int64 `json:"createdAt"`
+
+	// UpdatedAt is peer update nanosecond time.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// Parent contains content for parent.
+type Parent struct {
+	// ID is peer id.
+	ID string `json:"id"`
+
+	// State is the download state of the parent.
+	State string `json:"state"`
+
+	// Cost is the cost time of the parent download, unit is nanosecond.
+	Cost int64 `json:"cost"`
+
+	// FinishedPieceCount is finished piece count of the parent.
+	FinishedPieceCount int32 `json:"finishedPieceCount"`
+
+	// Host is the parent host.
+	Host Host `json:"host"`
+
+	// CreatedAt is peer create nanosecond time.
+	CreatedAt int64 `json:"createdAt"`
+
+	// UpdatedAt is peer update nanosecond time.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// Download contains content for a finished peer download.
+type Download struct {
+	// ID is peer id.
+	ID string `json:"id"`
+
+	// Tag is peer tag.
+	Tag string `json:"tag"`
+
+	// Application is peer application.
+	Application string `json:"application"`
+
+	// State is the outcome of the download, succeeded or failed.
+	State string `json:"state"`
+
+	// Cost is the cost time of the download, unit is nanosecond.
+	Cost int64 `json:"cost"`
+
+	// FinishedPieceCount is finished piece count of the peer.
+	FinishedPieceCount int32 `json:"finishedPieceCount"`
+
+	// BackToSource indicates whether the peer downloaded from the source.
+	BackToSource bool `json:"backToSource"`
+
+	// PieceCosts is the cost time of each downloaded piece, unit is nanosecond.
+	PieceCosts []int64 `json:"pieceCosts"`
+
+	// Task is the downloaded task.
+	Task Task `json:"task"`
+
+	// Host is the host of the peer.
+	Host Host `json:"host"`
+
+	// Parents are the parents of the peer.
+	Parents []Parent `json:"parents"`
+
+	// CreatedAt is peer create nanosecond time.
+	CreatedAt int64 `json:"createdAt"`
+
+	// UpdatedAt is peer update nanosecond time.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// downloadHeader is the csv header of the download records,
+// parents and piece costs are encoded as json columns.
+var downloadHeader = []string{
+	"id", "tag", "application", "state", "cost", "finished_piece_count", "back_to_source", "piece_costs",
+	"task_id", "task_url", "task_type", "task_content_length", "task_total_piece_count",
+	"task_back_to_source_limit", "task_back_to_source_peer_count", "task_state", "task_created_at", "task_updated_at",
+	"host_id", "host_type", "host_hostname", "host_ip", "host_port", "host_download_port", "host_location", "host_idc",
+	"host_concurrent_upload_limit", "host_concurrent_upload_count", "host_upload_count", "host_upload_failed_count",
+	"host_created_at", "host_updated_at",
+	"parents", "created_at", "updated_at",
+}
+
+// record encodes the download into a csv record.
+func (d *Download) record() ([]string, error) {
+	pieceCosts := d.PieceCosts
+	if pieceCosts == nil {
+		pieceCosts = []int64{}
+	}
+
+	pieceCostsJSON, err := json.Marshal(pieceCosts)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := d.Parents
+	if parents == nil {
+		parents = []Parent{}
+	}
+
+	parentsJSON, err := json.Marshal(parents)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		d.ID, d.Tag, d.Application, d.State, formatInt(d.Cost), formatInt(int64(d.FinishedPieceCount)),
+		strconv.FormatBool(d.BackToSource), string(pieceCostsJSON),
+		d.Task.ID, d.Task.URL, d.Task.Type, formatInt(d.Task.ContentLength), formatInt(int64(d.Task.TotalPieceCount)),
+		formatInt(int64(d.Task.BackToSourceLimit)), formatInt(int64(d.Task.BackToSourcePeerCount)), d.Task.State,
+		formatInt(d.Task.CreatedAt), formatInt(d.Task.UpdatedAt),
+		d.Host.ID, d.Host.Type, d.Host.Hostname, d.Host.IP, formatInt(int64(d.Host.Port)), formatInt(int64(d.Host.DownloadPort)),
+		d.Host.Location, d.Host.IDC, formatInt(int64(d.Host.ConcurrentUploadLimit)), formatInt(int64(d.Host.ConcurrentUploadCount)),
+		formatInt(d.Host.UploadCount), formatInt(d.Host.UploadFailedCount), formatInt(d.Host.CreatedAt), formatInt(d.Host.UpdatedAt),
+		string(parentsJSON), formatInt(d.CreatedAt), formatInt(d.UpdatedAt),
+	}, nil
+}
+
+// DurationsToNanoseconds converts durations to nanoseconds.
+func DurationsToNanoseconds(durations []time.Duration) []int64 {
+	nanoseconds := make([]int64, 0, len(durations))
+	for _, duration := range durations {
+		nanoseconds = append(nanoseconds, duration.Nanoseconds())
+	}
+
+	return nanoseconds
+}
+
+// formatInt formats int64 in base 10.
+func formatInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}